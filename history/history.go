@@ -0,0 +1,295 @@
+// Package history stores timestamped samples of named metrics in
+// fixed-size ring buffers, trimmed to a retention window.
+package history
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Sample is a metric value recorded at a point in time
+type Sample struct {
+	Time  time.Time
+	Value float64
+}
+
+// Ring is a bounded buffer of samples that overwrites the oldest sample
+// once full. Its memory grows with the samples pushed, so a ring sized for
+// a long window at a fast rate costs little while it is filled slowly.
+type Ring struct {
+	buf   []Sample
+	size  int
+	start int
+	n     int
+}
+
+// NewRing returns a ring holding up to size samples
+func NewRing(size int) *Ring {
+	if size < 1 {
+		size = 1
+	}
+	return &Ring{size: size}
+}
+
+// Push appends a sample, dropping the oldest when the ring is full
+func (r *Ring) Push(s Sample) {
+	// Until it is full the ring has not wrapped, so it grows at the end
+	if r.n < r.size {
+		r.buf = append(r.buf, s)
+		r.n++
+		return
+	}
+	r.buf[r.start] = s
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+// Len returns the number of samples held
+func (r *Ring) Len() int {
+	return r.n
+}
+
+// Cap returns the maximum number of samples the ring holds
+func (r *Ring) Cap() int {
+	return r.size
+}
+
+// At returns the i-th sample, oldest first
+func (r *Ring) At(i int) Sample {
+	return r.buf[(r.start+i)%len(r.buf)]
+}
+
+// Last returns the newest sample, if any
+func (r *Ring) Last() (Sample, bool) {
+	if r.n == 0 {
+		return Sample{}, false
+	}
+	return r.At(r.n - 1), true
+}
+
+// Samples returns a copy of the samples, oldest first
+func (r *Ring) Samples() []Sample {
+	out := make([]Sample, r.n)
+	for i := range out {
+		out[i] = r.At(i)
+	}
+	return out
+}
+
+// Since returns the samples recorded at or after t, oldest first
+func (r *Ring) Since(t time.Time) []Sample {
+	// Samples are pushed in time order, so find the first one in range
+	first := sort.Search(r.n, func(i int) bool { return !r.At(i).Time.Before(t) })
+	out := make([]Sample, r.n-first)
+	for i := range out {
+		out[i] = r.At(first + i)
+	}
+	return out
+}
+
+// Summary holds statistics over a series of samples
+type Summary struct {
+	Count int
+	Min   float64
+	Max   float64
+	Mean  float64
+	Last  float64
+}
+
+// Summarize computes statistics over samples
+func Summarize(samples []Sample) Summary {
+	if len(samples) == 0 {
+		return Summary{}
+	}
+	sum := Summary{Count: len(samples), Min: math.Inf(1), Max: math.Inf(-1)}
+	var total float64
+	for _, s := range samples {
+		total += s.Value
+		sum.Min = math.Min(sum.Min, s.Value)
+		sum.Max = math.Max(sum.Max, s.Value)
+	}
+	sum.Mean = total / float64(len(samples))
+	sum.Last = samples[len(samples)-1].Value
+	return sum
+}
+
+// Mark notes an event at a point in the history, such as the wall clock
+// being set
+type Mark struct {
+	Time time.Time
+	Text string
+}
+
+// Store keeps a ring per metric name. Each ring is sized to hold the
+// retention window at the shortest expected sampling interval, and only
+// allocates as samples arrive; reads only return samples within the
+// retention window of the newest sample.
+//
+// Samples are ordered and windowed with Time's comparisons, which use the
+// monotonic clock reading when both times carry one, as times from
+// time.Now do. A wall clock set back or forward then neither reorders
+// samples nor empties the window.
+type Store struct {
+	retention time.Duration
+	size      int
+	series    map[string]*Ring
+	names     []string // Metric names in the order they were first recorded
+	marks     []Mark
+}
+
+// New returns a store keeping retention worth of samples taken at least
+// interval apart
+func New(retention, interval time.Duration) *Store {
+	size := 1
+	if interval > 0 {
+		size = int(retention/interval) + 1
+	}
+	return &Store{
+		retention: retention,
+		size:      size,
+		series:    make(map[string]*Ring),
+	}
+}
+
+// Retention returns how far back the store keeps samples
+func (s *Store) Retention() time.Duration {
+	return s.retention
+}
+
+// Record adds a sample for each metric, all taken at time t
+func (s *Store) Record(t time.Time, metrics map[string]float64) {
+	for name, v := range metrics {
+		s.Add(name, t, v)
+	}
+}
+
+// Add records one sample of a metric
+func (s *Store) Add(name string, t time.Time, v float64) {
+	r, ok := s.series[name]
+	if !ok {
+		r = NewRing(s.size)
+		s.series[name] = r
+		s.names = append(s.names, name)
+	}
+	r.Push(Sample{Time: t, Value: v})
+}
+
+// Merge adds samples of a metric recorded elsewhere, e.g. on another run
+// or host, keeping the metric in time order. A sample at the same time as
+// one already held is skipped. When the ring overflows, the oldest samples
+// are dropped.
+func (s *Store) Merge(name string, samples []Sample) {
+	sorted := append([]Sample(nil), samples...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	var own []Sample
+	if r, ok := s.series[name]; ok {
+		own = r.Samples()
+	} else {
+		s.names = append(s.names, name)
+	}
+	ring := NewRing(s.size)
+	i, j := 0, 0
+	for i < len(own) || j < len(sorted) {
+		switch {
+		case j == len(sorted) || (i < len(own) && own[i].Time.Before(sorted[j].Time)):
+			ring.Push(own[i])
+			i++
+		case i < len(own) && own[i].Time.Equal(sorted[j].Time):
+			j++
+		default:
+			ring.Push(sorted[j])
+			j++
+		}
+	}
+	s.series[name] = ring
+}
+
+// Until returns a copy of the store holding only the samples recorded at
+// or before t, as it looked at that time
+func (s *Store) Until(t time.Time) *Store {
+	out := &Store{
+		retention: s.retention,
+		size:      s.size,
+		series:    make(map[string]*Ring, len(s.series)),
+		names:     s.Names(),
+	}
+	for name, r := range s.series {
+		n := sort.Search(r.n, func(i int) bool { return r.At(i).Time.After(t) })
+		cp := NewRing(n)
+		for i := 0; i < n; i++ {
+			cp.Push(r.At(i))
+		}
+		out.series[name] = cp
+	}
+	for _, mark := range s.marks {
+		if !mark.Time.After(t) {
+			out.marks = append(out.marks, mark)
+		}
+	}
+	return out
+}
+
+// Annotate adds a mark at t. Marks older than the retention window are
+// dropped as new ones arrive.
+func (s *Store) Annotate(t time.Time, text string) {
+	cutoff := t.Add(-s.retention)
+	kept := s.marks[:0]
+	for _, mark := range s.marks {
+		if !mark.Time.Before(cutoff) {
+			kept = append(kept, mark)
+		}
+	}
+	s.marks = append(kept, Mark{Time: t, Text: text})
+}
+
+// Marks returns the marks within the retention window of the newest
+// sample, oldest first
+func (s *Store) Marks() []Mark {
+	var newest time.Time
+	for _, r := range s.series {
+		if last, ok := r.Last(); ok && last.Time.After(newest) {
+			newest = last.Time
+		}
+	}
+	var out []Mark
+	for _, mark := range s.marks {
+		if !mark.Time.Before(newest.Add(-s.retention)) {
+			out = append(out, mark)
+		}
+	}
+	return out
+}
+
+// Names returns the recorded metric names in the order they first appeared
+func (s *Store) Names() []string {
+	return append([]string(nil), s.names...)
+}
+
+// Samples returns a metric's samples within the retention window, oldest first
+func (s *Store) Samples(name string) []Sample {
+	r, ok := s.series[name]
+	if !ok {
+		return nil
+	}
+	last, ok := r.Last()
+	if !ok {
+		return nil
+	}
+	return r.Since(last.Time.Add(-s.retention))
+}
+
+// Values returns a metric's values within the retention window, oldest first
+func (s *Store) Values(name string) []float64 {
+	samples := s.Samples(name)
+	values := make([]float64, len(samples))
+	for i, sample := range samples {
+		values[i] = sample.Value
+	}
+	return values
+}
+
+// Summary returns statistics over a metric's retained samples
+func (s *Store) Summary(name string) Summary {
+	return Summarize(s.Samples(name))
+}
@@ -0,0 +1,134 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRingOverwritesOldest(t *testing.T) {
+	r := NewRing(3)
+	base := time.Unix(0, 0)
+	for i := 0; i < 5; i++ {
+		r.Push(Sample{Time: base.Add(time.Duration(i) * time.Second), Value: float64(i)})
+	}
+
+	got := r.Samples()
+	if len(got) != 3 {
+		t.Fatalf("len = %d, want 3", len(got))
+	}
+	for i, want := range []float64{2, 3, 4} {
+		if got[i].Value != want {
+			t.Errorf("sample %d = %v, want %v", i, got[i].Value, want)
+		}
+	}
+	if since := r.Since(base.Add(3 * time.Second)); len(since) != 2 || since[0].Value != 3 {
+		t.Errorf("Since = %v, want samples 3 and 4", since)
+	}
+}
+
+func TestRingAllocatesAsItFills(t *testing.T) {
+	// A day at the fastest refresh rate, filled for a minute at 1s
+	s := New(24*time.Hour, 100*time.Millisecond)
+	base := time.Unix(0, 0)
+	for i := 0; i < 60; i++ {
+		s.Add("cpu.usage", base.Add(time.Duration(i)*time.Second), float64(i))
+	}
+	r := s.series["cpu.usage"]
+	if r.Cap() != 864001 || r.Len() != 60 {
+		t.Errorf("Cap %d, Len %d; want 864001 and 60", r.Cap(), r.Len())
+	}
+	if cap(r.buf) > 128 {
+		t.Errorf("ring allocated %d samples for 60", cap(r.buf))
+	}
+}
+
+func TestStoreRetention(t *testing.T) {
+	s := New(10*time.Second, time.Second)
+	base := time.Unix(0, 0)
+	// Samples arrive slower than the sizing interval, so the ring is not
+	// full but the oldest samples fall outside the window
+	for i := 0; i < 6; i++ {
+		s.Add("cpu", base.Add(time.Duration(i)*4*time.Second), float64(i))
+	}
+
+	values := s.Values("cpu")
+	if len(values) != 3 || values[0] != 3 {
+		t.Errorf("values = %v, want [3 4 5]", values)
+	}
+
+	sum := s.Summary("cpu")
+	if sum.Min != 3 || sum.Max != 5 || sum.Mean != 4 || sum.Last != 5 {
+		t.Errorf("summary = %+v", sum)
+	}
+	if len(s.Values("missing")) != 0 {
+		t.Error("unknown metric returned values")
+	}
+}
+
+func TestStoreUntil(t *testing.T) {
+	s := New(10*time.Second, time.Second)
+	base := time.Unix(0, 0)
+	for i := 0; i < 15; i++ {
+		s.Add("cpu", base.Add(time.Duration(i)*time.Second), float64(i))
+	}
+
+	// The ring holds samples 4 to 14; the copy ends at the cut
+	values := s.Until(base.Add(12 * time.Second)).Values("cpu")
+	if len(values) != 9 || values[0] != 4 || values[8] != 12 {
+		t.Errorf("values = %v, want 4 through 12", values)
+	}
+	if len(s.Until(base.Add(-time.Second)).Values("cpu")) != 0 {
+		t.Error("cut before the first sample returned values")
+	}
+	if len(s.Values("cpu")) != 11 {
+		t.Error("Until changed the store")
+	}
+}
+
+func TestStoreMarks(t *testing.T) {
+	s := New(10*time.Second, time.Second)
+	base := time.Unix(0, 0)
+	s.Annotate(base, "old")
+	for i := 0; i < 15; i++ {
+		s.Add("cpu", base.Add(time.Duration(i)*time.Second), float64(i))
+	}
+	s.Annotate(base.Add(12*time.Second), "clock set")
+
+	// The first mark fell out of the window of the newest sample
+	marks := s.Marks()
+	if len(marks) != 1 || marks[0].Text != "clock set" {
+		t.Errorf("marks = %v, want only the recent one", marks)
+	}
+	if len(s.Until(base.Add(11*time.Second)).Marks()) != 0 {
+		t.Error("Until kept a mark after the cut")
+	}
+}
+
+func TestStoreMerge(t *testing.T) {
+	s := New(time.Minute, time.Second)
+	base := time.Unix(0, 0)
+	for _, i := range []int{0, 2, 4} {
+		s.Add("cpu", base.Add(time.Duration(i)*time.Second), float64(i))
+	}
+	s.Merge("cpu", []Sample{
+		{base.Add(3 * time.Second), 3},
+		{base.Add(time.Second), 1},
+		{base.Add(2 * time.Second), 99}, // Already held
+		{base.Add(5 * time.Second), 5},
+	})
+	values := s.Values("cpu")
+	if len(values) != 6 {
+		t.Fatalf("values = %v, want 0 through 5", values)
+	}
+	for i, v := range values {
+		if v != float64(i) {
+			t.Errorf("values = %v, want 0 through 5", values)
+			break
+		}
+	}
+
+	s.Merge("gpu", []Sample{{base, 7}})
+	if names := s.Names(); len(names) != 2 || names[1] != "gpu" {
+		t.Errorf("names = %v after merging a new metric", names)
+	}
+}
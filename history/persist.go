@@ -0,0 +1,73 @@
+package history
+
+import (
+	"encoding/gob"
+	"io"
+	"time"
+)
+
+// saved is the on-disk form of a store
+type saved struct {
+	Names  []string
+	Series map[string][]Sample
+	Marks  []Mark
+}
+
+// Save writes every sample and mark held in the store to w. Times are
+// written as wall clock readings; their monotonic part is lost.
+func (s *Store) Save(w io.Writer) error {
+	out := saved{Names: s.names, Series: make(map[string][]Sample, len(s.series)), Marks: s.marks}
+	for name, r := range s.series {
+		out.Series[name] = r.Samples()
+	}
+	return gob.NewEncoder(w).Encode(out)
+}
+
+// Restore adds the samples and marks saved to r by Save, skipping those
+// recorded before since. Restored samples go before the store's own, so
+// only samples older than a metric's first recorded one are kept.
+func (s *Store) Restore(r io.Reader, since time.Time) error {
+	var in saved
+	if err := gob.NewDecoder(r).Decode(&in); err != nil {
+		return err
+	}
+
+	for _, name := range in.Names {
+		var keep []Sample
+		first, hasOwn := Sample{}, false
+		if own, ok := s.series[name]; ok && own.Len() > 0 {
+			first, hasOwn = own.At(0), true
+		}
+		for _, sample := range in.Series[name] {
+			if sample.Time.Before(since) || (hasOwn && !sample.Time.Before(first.Time)) {
+				continue
+			}
+			keep = append(keep, sample)
+		}
+		if len(keep) == 0 {
+			continue
+		}
+
+		ring := NewRing(s.size)
+		for _, sample := range keep {
+			ring.Push(sample)
+		}
+		if own, ok := s.series[name]; ok {
+			for i := 0; i < own.Len(); i++ {
+				ring.Push(own.At(i))
+			}
+		} else {
+			s.names = append(s.names, name)
+		}
+		s.series[name] = ring
+	}
+
+	var marks []Mark
+	for _, mark := range in.Marks {
+		if !mark.Time.Before(since) {
+			marks = append(marks, mark)
+		}
+	}
+	s.marks = append(marks, s.marks...)
+	return nil
+}
@@ -0,0 +1,47 @@
+package history
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSaveRestore(t *testing.T) {
+	base := time.Unix(1000, 0)
+	old := New(time.Minute, time.Second)
+	for i := 0; i < 10; i++ {
+		old.Add("cpu", base.Add(time.Duration(i)*time.Second), float64(i))
+	}
+	old.Add("gpu", base, 50)
+	old.Annotate(base.Add(5*time.Second), "clock set +1h0m0s")
+
+	var buf bytes.Buffer
+	if err := old.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// The restarted store has its first sample already; the saved ones go
+	// before it, less those older than since
+	s := New(time.Minute, time.Second)
+	s.Add("cpu", base.Add(20*time.Second), 20)
+	if err := s.Restore(&buf, base.Add(4*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	values := s.Values("cpu")
+	if len(values) != 7 || values[0] != 4 || values[5] != 9 || values[6] != 20 {
+		t.Errorf("cpu = %v, want 4 through 9 then 20", values)
+	}
+	if len(s.Values("gpu")) != 0 {
+		t.Error("restored a sample older than since")
+	}
+	if marks := s.Marks(); len(marks) != 1 || marks[0].Text != "clock set +1h0m0s" {
+		t.Errorf("marks = %v", marks)
+	}
+}
+
+func TestRestoreCorrupt(t *testing.T) {
+	s := New(time.Minute, time.Second)
+	if err := s.Restore(bytes.NewReader([]byte("not a history")), time.Time{}); err == nil {
+		t.Error("Restore accepted garbage")
+	}
+}
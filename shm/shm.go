@@ -0,0 +1,239 @@
+// Package shm shares encoded samples between processes through a ring of
+// fixed-size slots in a memory-mapped file. One writer appends records;
+// any number of readers map the same file and read the newest records
+// without coordinating with the writer.
+package shm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// File layout. The header holds the geometry, the sequence number of the
+// newest record and the writer's heartbeat; each slot starts with the
+// sequence number of the record it holds and the record length.
+const (
+	magic       = "MTOPRING"
+	version     = 1
+	headerSize  = 64
+	slotHeader  = 16
+	offVersion  = 8
+	offSlots    = 12
+	offSlotSize = 16
+	offSeq      = 24
+	offPID      = 32
+	offBeat     = 40
+)
+
+// ErrNoRecord is returned when the ring has no complete record to read
+var ErrNoRecord = errors.New("no record written yet")
+
+// ErrLocked is returned by Create when another writer owns the ring
+var ErrLocked = errors.New("ring is already being written by another process")
+
+// ring is a mapped ring file
+type ring struct {
+	file     *os.File
+	data     []byte
+	slots    int
+	slotSize int
+}
+
+// Writer appends records to a ring. Only one writer can hold a ring file
+// at a time.
+type Writer struct {
+	ring
+}
+
+// Create creates or takes over the ring file at path with the given number
+// of slots, each holding records of up to maxRecord bytes
+func Create(path string, slots, maxRecord int) (*Writer, error) {
+	if slots < 1 || maxRecord < 1 {
+		return nil, fmt.Errorf("invalid ring geometry: %d slots of %d bytes", slots, maxRecord)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Flock(int(f.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, unix.EWOULDBLOCK) {
+			return nil, ErrLocked
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	slotSize := align8(slotHeader + maxRecord)
+	size := headerSize + slots*slotSize
+	if err := f.Truncate(int64(size)); err != nil {
+		f.Close()
+		return nil, err
+	}
+	data, err := unix.Mmap(int(f.Fd()), 0, size, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to map %s: %w", path, err)
+	}
+
+	w := &Writer{ring{file: f, data: data, slots: slots, slotSize: slotSize}}
+	// Invalidate records left by an earlier writer before publishing the
+	// header. Only the sequence words are cleared, so a fresh file stays
+	// sparse.
+	clear(data[:headerSize])
+	for slot := headerSize; slot < size; slot += slotSize {
+		w.word(slot).Store(0)
+	}
+	copy(data, magic)
+	binary.LittleEndian.PutUint32(data[offVersion:], version)
+	binary.LittleEndian.PutUint32(data[offSlots:], uint32(slots))
+	binary.LittleEndian.PutUint32(data[offSlotSize:], uint32(slotSize))
+	binary.LittleEndian.PutUint32(data[offPID:], uint32(os.Getpid()))
+	w.beat(time.Now())
+	return w, nil
+}
+
+// Write appends a record, overwriting the oldest once the ring is full
+func (w *Writer) Write(record []byte) error {
+	if len(record) > w.slotSize-slotHeader {
+		return fmt.Errorf("record of %d bytes exceeds the %d byte slots", len(record), w.slotSize-slotHeader)
+	}
+	seq := w.word(offSeq).Load() + 1
+	slot := w.slot(seq)
+
+	// Readers check the slot sequence before and after copying, so a zero
+	// marks the slot as being rewritten
+	w.word(slot).Store(0)
+	binary.LittleEndian.PutUint64(w.data[slot+8:], uint64(len(record)))
+	copy(w.data[slot+slotHeader:], record)
+	w.word(slot).Store(seq)
+	w.word(offSeq).Store(seq)
+	w.beat(time.Now())
+	return nil
+}
+
+// Close unmaps the ring and releases the writer lock. The file stays so
+// readers can tell a stopped writer by its heartbeat.
+func (w *Writer) Close() error {
+	return w.close()
+}
+
+// Reader reads records from a ring written by another process
+type Reader struct {
+	ring
+}
+
+// Open maps the ring file at path for reading
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.Size() < headerSize {
+		f.Close()
+		return nil, fmt.Errorf("%s is not a sample ring", path)
+	}
+	data, err := unix.Mmap(int(f.Fd()), 0, int(info.Size()), unix.PROT_READ, unix.MAP_SHARED)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to map %s: %w", path, err)
+	}
+
+	r := &Reader{ring{file: f, data: data}}
+	if string(data[:len(magic)]) != magic || binary.LittleEndian.Uint32(data[offVersion:]) != version {
+		r.close()
+		return nil, fmt.Errorf("%s is not a version %d sample ring", path, version)
+	}
+	r.slots = int(binary.LittleEndian.Uint32(data[offSlots:]))
+	r.slotSize = int(binary.LittleEndian.Uint32(data[offSlotSize:]))
+	if r.slots < 1 || r.slotSize <= slotHeader || headerSize+r.slots*r.slotSize > len(data) {
+		r.close()
+		return nil, fmt.Errorf("%s has an invalid ring geometry", path)
+	}
+	return r, nil
+}
+
+// Latest returns a copy of the newest record and its sequence number
+func (r *Reader) Latest() ([]byte, uint64, error) {
+	// The writer may lap a slot while it is copied; retry on a torn read
+	for attempt := 0; attempt < 3; attempt++ {
+		seq := r.word(offSeq).Load()
+		if seq == 0 {
+			return nil, 0, ErrNoRecord
+		}
+		if record, ok := r.read(seq); ok {
+			return record, seq, nil
+		}
+	}
+	return nil, 0, ErrNoRecord
+}
+
+// Seq returns the sequence number of the newest record, 0 when empty
+func (r *Reader) Seq() uint64 {
+	return r.word(offSeq).Load()
+}
+
+// Owner returns the pid of the process writing the ring and when it last
+// wrote. A writer that restarts with a different geometry requires readers
+// to open the ring again.
+func (r *Reader) Owner() (pid int, heartbeat time.Time) {
+	pid = int(binary.LittleEndian.Uint32(r.data[offPID:]))
+	return pid, time.Unix(0, int64(r.word(offBeat).Load()))
+}
+
+// Close unmaps the ring
+func (r *Reader) Close() error {
+	return r.close()
+}
+
+// read copies record seq if its slot still holds it
+func (r *Reader) read(seq uint64) ([]byte, bool) {
+	slot := r.slot(seq)
+	if r.word(slot).Load() != seq {
+		return nil, false
+	}
+	n := binary.LittleEndian.Uint64(r.data[slot+8:])
+	if n > uint64(r.slotSize-slotHeader) {
+		return nil, false
+	}
+	record := make([]byte, n)
+	copy(record, r.data[slot+slotHeader:])
+	return record, r.word(slot).Load() == seq
+}
+
+// slot returns the offset of the slot holding record seq
+func (r *ring) slot(seq uint64) int {
+	return headerSize + int((seq-1)%uint64(r.slots))*r.slotSize
+}
+
+// word returns the 8-byte aligned word at off for atomic access
+func (r *ring) word(off int) *atomic.Uint64 {
+	return (*atomic.Uint64)(unsafe.Pointer(&r.data[off]))
+}
+
+func (r *ring) beat(t time.Time) {
+	r.word(offBeat).Store(uint64(t.UnixNano()))
+}
+
+func (r *ring) close() error {
+	err := unix.Munmap(r.data)
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func align8(n int) int {
+	return (n + 7) &^ 7
+}
@@ -0,0 +1,82 @@
+package shm
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples.ring")
+	w, err := Create(path, 3, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if _, _, err := r.Latest(); !errors.Is(err, ErrNoRecord) {
+		t.Errorf("empty ring: err = %v, want ErrNoRecord", err)
+	}
+
+	// Write past the end so the oldest slots are reused
+	for i := 1; i <= 5; i++ {
+		if err := w.Write([]byte(fmt.Sprintf("sample %d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record, seq, err := r.Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(record) != "sample 5" || seq != 5 {
+		t.Errorf("latest = %q (seq %d), want sample 5 (seq 5)", record, seq)
+	}
+
+	pid, beat := r.Owner()
+	if pid != os.Getpid() || time.Since(beat) > time.Minute {
+		t.Errorf("owner = %d at %v", pid, beat)
+	}
+
+	if err := w.Write(make([]byte, 65)); err == nil {
+		t.Error("oversized record was accepted")
+	}
+}
+
+func TestSingleWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples.ring")
+	w, err := Create(path, 2, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Create(path, 2, 16); !errors.Is(err, ErrLocked) {
+		t.Errorf("second writer: err = %v, want ErrLocked", err)
+	}
+
+	// The lock goes away with the writer
+	w.Close()
+	w2, err := Create(path, 2, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w2.Close()
+}
+
+func TestOpenRejectsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "not-a-ring")
+	if err := os.WriteFile(path, make([]byte, 128), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); err == nil {
+		t.Error("file without the ring header was opened")
+	}
+}
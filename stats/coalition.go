@@ -0,0 +1,86 @@
+package stats
+
+/*
+#include <libproc.h>
+#include <sys/proc_info.h>
+#include <stdint.h>
+
+// Private definitions from xnu's sys/proc_info.h and sys/coalition.h, which
+// are not in the public SDK
+#define MTOP_PROC_PIDCOALITIONINFO 20
+#define MTOP_COALITION_TYPE_RESOURCE 0
+
+struct mtop_proc_pidcoalitioninfo {
+    uint64_t coalition_id[2];
+    uint64_t reserved1;
+    uint64_t reserved2;
+    uint64_t reserved3;
+};
+
+// Leading fields of struct coalition_resource_usage; the kernel copies out
+// no more than the size passed in, so later additions don't matter
+struct mtop_coalition_usage {
+    uint64_t tasks_started;
+    uint64_t tasks_exited;
+    uint64_t time_nonempty;
+    uint64_t cpu_time;
+    uint64_t interrupt_wakeups;
+    uint64_t platform_idle_wakeups;
+    uint64_t bytesread;
+    uint64_t byteswritten;
+    uint64_t gpu_time;
+    uint64_t cpu_time_billed_to_me;
+    uint64_t cpu_time_billed_to_others;
+    uint64_t energy;
+};
+
+extern int coalition_info_resource_usage(uint64_t cid, void *cru, size_t sz);
+
+int getProcCoalition(int pid, uint64_t *id) {
+    struct mtop_proc_pidcoalitioninfo info;
+    int size = proc_pidinfo(pid, MTOP_PROC_PIDCOALITIONINFO, 0, &info, sizeof(info));
+    if (size != sizeof(info)) {
+        return -1;
+    }
+    *id = info.coalition_id[MTOP_COALITION_TYPE_RESOURCE];
+    return 0;
+}
+
+int getCoalitionUsage(uint64_t id, struct mtop_coalition_usage *usage) {
+    return coalition_info_resource_usage(id, usage, sizeof(*usage));
+}
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// getProcCoalition returns the ID of the resource coalition a process runs in
+func getProcCoalition(pid int) (uint64, error) {
+	var id C.uint64_t
+	if ret, errno := C.getProcCoalition(C.int(pid), &id); ret != 0 {
+		if errors.Is(errno, fs.ErrPermission) {
+			return 0, fmt.Errorf("failed to get coalition of pid %d: %w", pid, ErrPermissionDenied)
+		}
+		return 0, fmt.Errorf("failed to get coalition of pid %d", pid)
+	}
+	return uint64(id), nil
+}
+
+// getCoalitionUsage reads a resource coalition's accumulated usage
+func getCoalitionUsage(id uint64) (*coalitionUsage, error) {
+	var usage C.struct_mtop_coalition_usage
+	if C.getCoalitionUsage(C.uint64_t(id), &usage) != 0 {
+		return nil, fmt.Errorf("failed to get usage of coalition %d", id)
+	}
+	return &coalitionUsage{
+		CPUTime:     uint64(usage.cpu_time),
+		GPUTime:     uint64(usage.gpu_time),
+		Energy:      uint64(usage.energy),
+		DiskRead:    uint64(usage.bytesread),
+		DiskWritten: uint64(usage.byteswritten),
+		Exited:      uint64(usage.tasks_exited),
+	}, nil
+}
@@ -0,0 +1,78 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// coalitionCollector remembers each coalition's CPU and GPU time at the
+// previous sample so usage percentages can be derived
+type coalitionCollector struct {
+	times map[uint64][2]time.Duration
+	at    time.Time
+}
+
+// collectCoalitions reads the kernel's accounting for the resource
+// coalitions the processes run in. Unlike the per-process counters it
+// includes members that have exited and those whose task info is
+// restricted.
+func (c *Collector) collectCoalitions(ctx context.Context, procs []ProcessStats) ([]CoalitionStats, error) {
+	ids := make(map[uint64]bool)
+	for _, p := range procs {
+		if p.Coalition != 0 {
+			ids[p.Coalition] = true
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no process has a known coalition")
+	}
+
+	now := time.Now()
+	prev := c.coal
+	next := &coalitionCollector{times: make(map[uint64][2]time.Duration, len(ids)), at: now}
+	stats := make([]CoalitionStats, 0, len(ids))
+	for id := range ids {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		usage, err := getCoalitionUsage(id)
+		if err != nil {
+			continue
+		}
+		cs := CoalitionStats{
+			ID:          id,
+			CPUTime:     c.procs.machToDuration(usage.CPUTime),
+			Energy:      float64(usage.Energy) / 1e9,
+			DiskRead:    usage.DiskRead,
+			DiskWritten: usage.DiskWritten,
+			Exited:      int(usage.Exited),
+		}
+		gpu := time.Duration(usage.GPUTime)
+		if prev != nil {
+			if old, ok := prev.times[id]; ok {
+				elapsed := now.Sub(prev.at)
+				cs.CPU = busyPercent(cs.CPUTime, old[0], elapsed)
+				cs.GPU = busyPercent(gpu, old[1], elapsed)
+			}
+		}
+		next.times[id] = [2]time.Duration{cs.CPUTime, gpu}
+		stats = append(stats, cs)
+	}
+	c.coal = next
+	if len(stats) == 0 {
+		return nil, fmt.Errorf("coalition accounting is unavailable")
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].ID < stats[j].ID })
+	return stats, nil
+}
+
+// busyPercent is the time a counter advanced over elapsed wall time, as a
+// percentage; zero when the counter went backwards
+func busyPercent(cur, prev, elapsed time.Duration) float64 {
+	if elapsed <= 0 || cur < prev {
+		return 0
+	}
+	return float64(cur-prev) / float64(elapsed) * 100
+}
@@ -0,0 +1,15 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBusyPercent(t *testing.T) {
+	if got := busyPercent(3*time.Second, time.Second, 4*time.Second); got != 50 {
+		t.Errorf("busyPercent = %v, want 50", got)
+	}
+	if got := busyPercent(time.Second, 2*time.Second, time.Second); got != 0 {
+		t.Errorf("busyPercent of a counter going backwards = %v, want 0", got)
+	}
+}
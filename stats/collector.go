@@ -0,0 +1,205 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Groups are the collector groups in the order Collect runs them. Each
+// group fills its own fields of SystemStats, so groups can be sampled at
+// different rates and merged into one snapshot.
+//
+//   - system: Memory, CPU usage and load, Uptime
+//   - processes: Processes
+//   - coalitions: Coalitions, for the processes already in the stats
+//   - cpufreq: CPU.Clusters and CPU.CoreFreqs
+//   - thermal: Thermal
+//   - power: Power
+//   - wifi: Network.WiFi
+//   - disk: Disk
+//   - interfaces: Network.Interfaces
+//   - sockets: Network.Sockets
+var Groups = []string{"system", "processes", "coalitions", "cpufreq", "thermal", "power", "wifi", "disk", "interfaces", "sockets"}
+
+// Collector samples the Mac it runs on. Rates and usage percentages are
+// relative to the same Collector's previous sample of the group, so the
+// first sample reports them as zero, or CPU usage averaged since boot.
+//
+// Different groups may be collected from different goroutines at once,
+// but each group from one at a time. After Reset, a new sample of the
+// group may start while the one before it is still running; the thermal,
+// wifi and sockets groups remember nothing, so they need no Reset for it.
+type Collector struct {
+	// MemoryAccounting is the formula MemoryStats.Used is derived with
+	MemoryAccounting MemoryAccounting
+	// ProcessGPU adds each process's GPU usage, read from the I/O registry
+	ProcessGPU bool
+	// ProcessNet adds each process's network rates, read by running nettop
+	ProcessNet bool
+	// ProcessPorts adds the ports each process listens on, read from its
+	// socket descriptors
+	ProcessPorts bool
+	// SkipFDs leaves ProcessStats.FDs zero. Listing every process's file
+	// descriptors is the costliest call per process.
+	SkipFDs bool
+
+	cpu     cpuLoadCollector
+	swap    *swapSampler
+	procs   *processCollector
+	procGPU processGPUCollector
+	procNet processNetCollector
+	coal    *coalitionCollector
+	freq    slot[frequencyCollector]
+	power   slot[powerCollector]
+	disk    slot[diskSampler]
+	ifs     slot[ifSampler]
+}
+
+// slot holds the state a resettable group keeps between samples. A sample
+// takes the state out and puts it back when done. Reset in between starts
+// a new generation, so a sample that was stuck through it cannot put back
+// its state to race with the samples after the reset.
+type slot[T any] struct {
+	mu    sync.Mutex
+	state *T
+	gen   int
+}
+
+// take lends the state, nil before the first sample, to one sample
+func (s *slot[T]) take() (*T, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.state
+	s.state = nil
+	return state, s.gen
+}
+
+// put gives back the state a sample took at gen, reporting false if the
+// group was reset since
+func (s *slot[T]) put(state *T, gen int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if gen != s.gen {
+		return false
+	}
+	s.state = state
+	return true
+}
+
+// reset drops the state, returning it unless a sample has it
+func (s *slot[T]) reset() *T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.state
+	s.state = nil
+	s.gen++
+	return state
+}
+
+// peek calls f with the state unless a sample has it
+func (s *slot[T]) peek(f func(*T)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != nil {
+		f(s.state)
+	}
+}
+
+// NewCollector returns a Collector with the default memory accounting and
+// the per-process GPU and network readings off
+func NewCollector() *Collector {
+	return &Collector{procs: newProcessCollector()}
+}
+
+// Collect samples every group. Only failures of the system and processes
+// groups are returned; the other groups leave their fields empty when
+// their readings are unavailable.
+func (c *Collector) Collect(ctx context.Context) (SystemStats, error) {
+	return c.collectGroups(ctx, Groups)
+}
+
+// collectGroups samples the given groups in the order of Groups, as
+// Collect does
+func (c *Collector) collectGroups(ctx context.Context, groups []string) (SystemStats, error) {
+	var stats SystemStats
+	for _, g := range Groups {
+		if !slices.Contains(groups, g) {
+			continue
+		}
+		err := c.CollectGroup(ctx, g, &stats)
+		if ctx.Err() != nil {
+			return stats, ctx.Err()
+		}
+		if err != nil && (g == "system" || g == "processes") {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// CollectGroup samples one of Groups into its fields of stats. Errors wrap
+// ErrUnsupportedPlatform, ErrPermissionDenied or ErrSensorUnavailable
+// when one of them is the cause.
+func (c *Collector) CollectGroup(ctx context.Context, group string, stats *SystemStats) error {
+	var err error
+	switch group {
+	case "system":
+		return c.collectKernel(stats)
+	case "processes":
+		if stats.Processes, err = c.collectProcesses(ctx); err != nil {
+			return fmt.Errorf("failed to collect process stats: %w", err)
+		}
+	case "coalitions":
+		stats.Coalitions, err = c.collectCoalitions(ctx, stats.Processes)
+	case "cpufreq":
+		stats.CPU.Clusters, stats.CPU.CoreFreqs, err = c.collectCPUFrequency()
+	case "thermal":
+		stats.Thermal = collectThermalState()
+	case "power":
+		stats.Power, err = c.collectPower()
+	case "wifi":
+		stats.Network.WiFi, err = collectWiFiStats()
+	case "disk":
+		stats.Disk, err = c.collectDisk()
+	case "interfaces":
+		stats.Network.Interfaces, err = c.collectInterfaces()
+	case "sockets":
+		stats.Network.Sockets, err = collectSocketStats()
+	default:
+		return fmt.Errorf("unknown collector group %q", group)
+	}
+	return err
+}
+
+// Reset drops what a group remembers from its previous samples, so its
+// next sample starts fresh, closing its IOReport subscription if it has
+// one to reopen it. It may be called while a sample of the group is stuck:
+// that sample keeps what it started with, closes it when it returns, and
+// its result is not remembered.
+func (c *Collector) Reset(group string) {
+	switch group {
+	case "cpufreq":
+		if freq := c.freq.reset(); freq != nil {
+			freq.close()
+		}
+	case "power":
+		if power := c.power.reset(); power != nil {
+			power.close()
+		}
+	case "disk":
+		c.disk.reset()
+	case "interfaces":
+		c.ifs.reset()
+	}
+}
+
+// IOReportChannels returns the raw channels the cpufreq and power groups
+// read at their latest sample, nil before they have one or while a sample
+// of the group is running
+func (c *Collector) IOReportChannels() (cpu, energy []IOReportChannel) {
+	c.freq.peek(func(f *frequencyCollector) { cpu = f.last })
+	c.power.peek(func(p *powerCollector) { energy = p.last })
+	return cpu, energy
+}
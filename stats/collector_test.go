@@ -0,0 +1,83 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCollectGroupUnknown(t *testing.T) {
+	var stats SystemStats
+	if err := NewCollector().CollectGroup(context.Background(), "gpu", &stats); err == nil {
+		t.Error("unknown group: got nil error")
+	}
+}
+
+// A sample stuck through a Reset runs alongside the samples after it; run
+// with -race
+func TestResetWhileSampleStuck(t *testing.T) {
+	c := NewCollector()
+	taken, stuck, done := make(chan struct{}), make(chan struct{}), make(chan bool)
+	go func() {
+		_, gen := c.disk.take()
+		close(taken)
+		<-stuck
+		done <- c.disk.put(&diskSampler{ioTime: time.Second}, gen)
+	}()
+	<-taken
+	c.Reset("disk")
+
+	fresh, gen := c.disk.take()
+	if fresh != nil {
+		t.Fatal("sample after Reset got the stuck sample's state")
+	}
+	c.disk.put(&diskSampler{ioTime: 2 * time.Second}, gen)
+	close(stuck)
+	if <-done {
+		t.Error("stuck sample put back its state after Reset")
+	}
+	if state, _ := c.disk.take(); state == nil || state.ioTime != 2*time.Second {
+		t.Errorf("state after the stuck sample returned = %+v, want the fresh sample's", state)
+	}
+
+	// The debug dump reads the channels while a sample may be writing them
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		for i := 0; i < 100; i++ {
+			p, gen := c.power.take()
+			if p == nil {
+				p = &powerCollector{}
+			}
+			p.last = []IOReportChannel{{Name: "CPU Energy", Value: int64(i)}}
+			c.power.put(p, gen)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		c.IOReportChannels()
+	}
+	<-sampled
+	if _, energy := c.IOReportChannels(); len(energy) != 1 || energy[0].Value != 99 {
+		t.Errorf("energy channels = %+v, want the last sample's", energy)
+	}
+}
+
+// A Mac without the energy model subscription reports why until a Reset,
+// rather than trying to open it again every sample
+func TestPowerRemembersMissingSubscription(t *testing.T) {
+	c := NewCollector()
+	missing := errors.New("no Energy Model group")
+	_, gen := c.power.take()
+	c.power.put(&powerCollector{err: missing}, gen)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.collectPower(); !errors.Is(err, missing) {
+			t.Fatalf("sample %d: err = %v, want the remembered one", i, err)
+		}
+	}
+	c.Reset("power")
+	if _, err := c.collectPower(); errors.Is(err, missing) {
+		t.Error("Reset kept the remembered error")
+	}
+}
@@ -0,0 +1,51 @@
+package stats
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+
+	"golang.org/x/sys/unix"
+)
+
+// Kernel interfaces change between macOS releases: host_statistics64 may
+// fill fewer fields than mtop was built against, and sysctls get renamed or
+// change width. The helpers here check what the running kernel actually
+// returned so collectors can fall back instead of reading garbage.
+
+// SysctlNumber reads the first of names that exists as an integer,
+// accepting both 32- and 64-bit values. Later names are fallbacks for
+// sysctls that were renamed.
+func SysctlNumber(names ...string) (uint64, error) {
+	var lastErr error
+	for _, name := range names {
+		buf, err := unix.SysctlRaw(name)
+		if err != nil {
+			lastErr = fmt.Errorf("sysctl %s: %w", name, err)
+			continue
+		}
+		switch len(buf) {
+		case 4:
+			return uint64(binary.LittleEndian.Uint32(buf)), nil
+		case 8:
+			return binary.LittleEndian.Uint64(buf), nil
+		}
+		lastErr = fmt.Errorf("sysctl %s has unexpected size %d", name, len(buf))
+	}
+	return 0, lastErr
+}
+
+// vmStatsMissing lists the vm_statistics64 fields that lie beyond the
+// filled bytes reported by host_statistics64. Those fields were left zero
+// because the running kernel's structure is shorter than ours.
+func vmStatsMissing(filled int) []string {
+	var missing []string
+	t := reflect.TypeOf(VMStatistics{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if int(f.Offset+f.Type.Size()) > filled {
+			missing = append(missing, f.Name)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,30 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVMStatsMissing(t *testing.T) {
+	full := int(reflect.TypeOf(VMStatistics{}).Size())
+	if missing := vmStatsMissing(full); len(missing) != 0 {
+		t.Errorf("full structure reported missing fields %v", missing)
+	}
+
+	// A kernel predating the last field fills 8 bytes less
+	want := []string{"TotalUncompressedPagesInCompressor"}
+	if missing := vmStatsMissing(full - 8); !reflect.DeepEqual(missing, want) {
+		t.Errorf("missing = %v, want %v", missing, want)
+	}
+}
+
+// The build without cgo hands VMStatistics to host_statistics64 as is, so
+// it must match struct vm_statistics64: HOST_VM_INFO64_COUNT is 38
+func TestVMStatisticsLayout(t *testing.T) {
+	if size := reflect.TypeOf(VMStatistics{}).Size(); size != 38*4 {
+		t.Errorf("VMStatistics is %d bytes, want %d", size, 38*4)
+	}
+	if off := reflect.TypeOf(VMStatistics{}).Field(4).Offset; off != 16 {
+		t.Errorf("ZeroFillCount at offset %d, want 16", off)
+	}
+}
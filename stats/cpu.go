@@ -0,0 +1,56 @@
+package stats
+
+// Indexes into cpuTicks, matching CPU_STATE_* in mach/machine.h
+const (
+	cpuStateUser = iota
+	cpuStateSystem
+	cpuStateIdle
+	cpuStateNice
+)
+
+// cpuTicks holds the ticks a CPU spent in each state since boot. The kernel
+// counters are 32 bits wide and wrap, so deltas use unsigned arithmetic.
+type cpuTicks [4]uint32
+
+// cpuLoadCollector derives CPU usage from the tick counters of consecutive
+// samples
+type cpuLoadCollector struct {
+	last  cpuTicks
+	cores []cpuTicks
+}
+
+// collect turns a native sample into CPU stats. The first sample reports
+// the average usage since boot.
+func (c *cpuLoadCollector) collect(n *nativeStats) CPUStats {
+	stats := CPUStats{
+		Usage:   tickUsage(c.last, n.CPUTicks),
+		Cores:   make([]float64, len(n.Cores)),
+		LoadAvg: n.LoadAvg,
+	}
+	for i, ticks := range n.Cores {
+		var prev cpuTicks
+		if i < len(c.cores) {
+			prev = c.cores[i]
+		}
+		stats.Cores[i] = tickUsage(prev, ticks)
+	}
+
+	c.last, c.cores = n.CPUTicks, n.Cores
+	return stats
+}
+
+// tickUsage returns the percentage of non-idle ticks between two samples
+func tickUsage(prev, cur cpuTicks) float64 {
+	var busy, total uint64
+	for i := range cur {
+		d := uint64(cur[i] - prev[i])
+		total += d
+		if i != cpuStateIdle {
+			busy += d
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(busy) / float64(total) * 100
+}
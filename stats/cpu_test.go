@@ -0,0 +1,21 @@
+package stats
+
+import "testing"
+
+func TestTickUsage(t *testing.T) {
+	prev := cpuTicks{100, 50, 800, 50}
+	cur := cpuTicks{130, 60, 850, 60}
+	if got := tickUsage(prev, cur); got != 50 {
+		t.Errorf("usage = %v, want 50", got)
+	}
+
+	if got := tickUsage(cur, cur); got != 0 {
+		t.Errorf("usage without ticks = %v, want 0", got)
+	}
+
+	// A counter wrapping around 32 bits still yields the right delta
+	wrapped := cpuTicks{^uint32(0) - 9, 0, 0, 0}
+	if got := tickUsage(wrapped, cpuTicks{10, 0, 20, 0}); got != 50 {
+		t.Errorf("usage across wrap = %v, want 50", got)
+	}
+}
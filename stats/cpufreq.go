@@ -0,0 +1,161 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Registry tables listing the DVFS states of the efficiency and performance
+// clusters, in the same order as the active IOReport performance states
+const (
+	eClusterFreqTable = "voltage-states1-sram"
+	pClusterFreqTable = "voltage-states5-sram"
+)
+
+// FreqTables are the pmgr DVFS tables the CPU frequencies are read from,
+// for ReadPmgrTable
+var FreqTables = []string{eClusterFreqTable, pClusterFreqTable}
+
+// frequencyCollector derives CPU frequencies from the residency of each
+// core and cluster in its performance states, the way powermetrics does
+type frequencyCollector struct {
+	report *ioReport         // nil on Macs without the subscription or the tables
+	eFreqs []float64         // Efficiency cluster state frequencies in MHz
+	pFreqs []float64         // Performance cluster state frequencies in MHz
+	last   []IOReportChannel // Raw channels of the latest sample, for the debug dump
+}
+
+func newFrequencyCollector() (*frequencyCollector, error) {
+	report, err := openIOReport("CPU Stats", "")
+	if err != nil {
+		return nil, err
+	}
+	c := &frequencyCollector{report: report}
+	c.eFreqs = readFreqTable(eClusterFreqTable)
+	c.pFreqs = readFreqTable(pClusterFreqTable)
+	if len(c.eFreqs) == 0 && len(c.pFreqs) == 0 {
+		report.close()
+		return nil, fmt.Errorf("no CPU frequency tables found")
+	}
+	return c, nil
+}
+
+// close ends the IOReport subscription
+func (c *frequencyCollector) close() {
+	if c.report != nil {
+		c.report.close()
+	}
+}
+
+// readFreqTable reads a DVFS table and normalizes it to MHz. Older chips
+// report Hz, newer ones kHz.
+func readFreqTable(key string) []float64 {
+	raw, err := ReadPmgrTable(key)
+	if err != nil {
+		return nil
+	}
+	freqs := make([]float64, len(raw))
+	for i, f := range raw {
+		switch {
+		case f >= 100_000_000:
+			freqs[i] = float64(f) / 1e6
+		case f >= 100_000:
+			freqs[i] = float64(f) / 1e3
+		default:
+			freqs[i] = float64(f)
+		}
+	}
+	return freqs
+}
+
+// collectCPUFrequency reports per-cluster and per-core frequencies. The
+// IOReport subscription is opened on first use; where it or the frequency
+// tables are missing, the sysctl frequency is reported until a Reset.
+func (c *Collector) collectCPUFrequency() ([]ClusterStats, []float64, error) {
+	freq, gen := c.freq.take()
+	if freq == nil {
+		var err error
+		if freq, err = newFrequencyCollector(); err != nil {
+			freq = &frequencyCollector{}
+		}
+	}
+	var clusters []ClusterStats
+	var cores []float64
+	var err error
+	if freq.report == nil {
+		clusters, cores, err = collectStaticFrequency()
+	} else {
+		clusters, cores, err = freq.collect()
+	}
+	if !c.freq.put(freq, gen) {
+		freq.close()
+	}
+	return clusters, cores, err
+}
+
+// collectStaticFrequency falls back to the nominal frequency that Intel Macs
+// expose through sysctl
+func collectStaticFrequency() ([]ClusterStats, []float64, error) {
+	hz, err := SysctlNumber("hw.cpufrequency", "hw.cpufrequency_max")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get CPU frequency: %v: %w", err, ErrUnsupportedPlatform)
+	}
+	return []ClusterStats{{Name: "CPU", Frequency: float64(hz) / 1e6}}, nil, nil
+}
+
+func (c *frequencyCollector) collect() ([]ClusterStats, []float64, error) {
+	channels, err := c.report.sample()
+	if err != nil {
+		return nil, nil, err
+	}
+	c.last = channels
+
+	var clusters []ClusterStats
+	var cores []float64
+	for _, ch := range channels {
+		if ch.Format != IOReportFormatState {
+			continue
+		}
+
+		freqs := c.pFreqs
+		if strings.HasPrefix(ch.Name, "E") {
+			freqs = c.eFreqs
+		}
+		freq, active := residencyFrequency(ch.States, freqs)
+
+		switch ch.SubGroup {
+		case "CPU Complex Performance States":
+			clusters = append(clusters, ClusterStats{Name: ch.Name, Frequency: freq, Active: active})
+		case "CPU Core Performance States":
+			cores = append(cores, freq)
+		}
+	}
+
+	return clusters, cores, nil
+}
+
+// residencyFrequency averages the frequencies of the active states weighted
+// by time spent in each, returning the frequency in MHz and the percentage of
+// time spent active
+func residencyFrequency(states []IOReportState, freqs []float64) (float64, float64) {
+	var idle, active int64
+	var weighted float64
+	index := 0
+	for _, st := range states {
+		switch st.Name {
+		case "IDLE", "DOWN", "OFF":
+			idle += st.Residency
+			continue
+		}
+		if index < len(freqs) {
+			weighted += float64(st.Residency) * freqs[index]
+		}
+		active += st.Residency
+		index++
+	}
+
+	if active == 0 {
+		return 0, 0
+	}
+	return weighted / float64(active), float64(active) / float64(active+idle) * 100
+}
@@ -0,0 +1,123 @@
+package stats
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// diskCounters is one drive's I/O since boot, as the IOKit driver counts it
+type diskCounters struct {
+	name          string
+	read, written uint64
+	ioTime        time.Duration
+}
+
+// ifCounters is one network interface's traffic since boot
+type ifCounters struct {
+	name           string
+	up             bool
+	received, sent uint64
+}
+
+// perSecond is the rate of a counter that went from prev to cur over
+// elapsed, or 0 when it was reset in between
+func perSecond(prev, cur uint64, elapsed time.Duration) float64 {
+	if cur < prev || elapsed <= 0 {
+		return 0
+	}
+	return float64(cur-prev) / elapsed.Seconds()
+}
+
+// diskDevices turns each drive's counters into its rates since prev,
+// taken elapsed before. Drives without a name are numbered.
+func diskDevices(disks, prev []diskCounters, elapsed time.Duration) []DiskDevice {
+	devices := make([]DiskDevice, len(disks))
+	for i, d := range disks {
+		name := d.name
+		if name == "" {
+			name = fmt.Sprintf("drive%d", i)
+		}
+		devices[i] = DiskDevice{Name: name, Read: d.read, Written: d.written}
+		j := slices.IndexFunc(prev, func(p diskCounters) bool { return p.name == d.name })
+		if j < 0 {
+			continue
+		}
+		p := prev[j]
+		devices[i].ReadRate = perSecond(p.read, d.read, elapsed)
+		devices[i].WriteRate = perSecond(p.written, d.written, elapsed)
+		if d.ioTime >= p.ioTime && elapsed > 0 {
+			devices[i].Busy = min(float64(d.ioTime-p.ioTime)/float64(elapsed)*100, 100)
+		}
+	}
+	return devices
+}
+
+// ifSampler turns the interfaces' byte counters into rates
+type ifSampler struct {
+	prev map[string]ifCounters
+	at   time.Time
+}
+
+// collectInterfaces reads each network interface's traffic and its rates
+// since the previous call, in the order the kernel lists them
+func (c *Collector) collectInterfaces() ([]InterfaceStats, error) {
+	prev, gen := c.ifs.take()
+	counters, err := getInterfaceCounters()
+	if err != nil {
+		c.ifs.put(prev, gen)
+		return nil, err
+	}
+	now := time.Now()
+	stats := make([]InterfaceStats, len(counters))
+	next := &ifSampler{prev: make(map[string]ifCounters, len(counters)), at: now}
+	for i, ifc := range counters {
+		stats[i] = InterfaceStats{Name: ifc.name, Up: ifc.up, Received: ifc.received, Sent: ifc.sent}
+		if prev != nil {
+			if p, ok := prev.prev[ifc.name]; ok {
+				stats[i].RxRate = perSecond(p.received, ifc.received, now.Sub(prev.at))
+				stats[i].TxRate = perSecond(p.sent, ifc.sent, now.Sub(prev.at))
+			}
+		}
+		next.prev[ifc.name] = ifc
+	}
+	c.ifs.put(next, gen)
+	return stats, nil
+}
+
+// diskSampler turns the disks' cumulative I/O time into the share of each
+// interval spent on I/O. Requests in flight together each count their
+// own time, so a deep queue reads as fully busy.
+type diskSampler struct {
+	ioTime time.Duration
+	disks  []diskCounters
+	at     time.Time
+}
+
+// collectDisk reads the disks' I/O time and how busy they were since the
+// previous call, in total and for each drive
+func (c *Collector) collectDisk() (DiskStats, error) {
+	prev, gen := c.disk.take()
+	disks, err := getDiskCounters()
+	if err != nil {
+		c.disk.put(prev, gen)
+		return DiskStats{}, err
+	}
+	var total time.Duration
+	for _, d := range disks {
+		total += d.ioTime
+	}
+	now := time.Now()
+	disk := DiskStats{IOTime: total}
+	var prevDisks []diskCounters
+	var elapsed time.Duration
+	if prev != nil {
+		prevDisks, elapsed = prev.disks, now.Sub(prev.at)
+		if total >= prev.ioTime && elapsed > 0 {
+			disk.Busy = min(float64(total-prev.ioTime)/float64(elapsed)*100, 100)
+		}
+	}
+	disk.Devices = diskDevices(disks, prevDisks, elapsed)
+	c.disk.put(&diskSampler{total, disks, now}, gen)
+	return disk, nil
+}
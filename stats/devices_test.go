@@ -0,0 +1,23 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiskDevices(t *testing.T) {
+	prev := []diskCounters{{name: "disk0", read: 1000, written: 500, ioTime: time.Second}}
+	cur := []diskCounters{
+		{name: "disk0", read: 3000, written: 400, ioTime: 1500 * time.Millisecond},
+		{name: "", read: 10},
+	}
+	got := diskDevices(cur, prev, 2*time.Second)
+	want := []DiskDevice{
+		// Written went backwards, as after a driver reset: no rate
+		{Name: "disk0", Read: 3000, Written: 400, ReadRate: 1000, Busy: 25},
+		{Name: "drive1", Read: 10},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("diskDevices = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,34 @@
+// Package stats collects the system statistics mtop shows, from the Mach,
+// IOKit, IOReport and sysctl interfaces of macOS, so other Go programs can
+// embed the same readings without running mtop.
+//
+// A Collector keeps the previous sample of each reading to derive rates:
+//
+//	c := stats.NewCollector()
+//	c.Collect(ctx) // The first sample primes the rates
+//	time.Sleep(time.Second)
+//	s, err := c.Collect(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Printf("CPU %.1f%%, memory %.1f%%, package %.1f W\n", s.CPU.Usage, s.Memory.Usage, s.Power.Package)
+//
+// CollectGroup samples one of Groups at a time, for readings wanted at
+// different rates. Subscribe pushes samples instead, with options for the
+// collectors to run:
+//
+//	samples, err := stats.Subscribe(ctx, time.Second, stats.WithGroups("system", "power"))
+//	if err != nil {
+//		return err
+//	}
+//	for s := range samples {
+//		fmt.Printf("CPU %.1f%%, package %.1f W\n", s.CPU.Usage, s.Power.Package)
+//	}
+//
+// The package builds on darwin only. Without cgo, e.g. cross-compiled
+// from Linux, it reads the Mach statistics in pure Go, so the system,
+// processes and sockets groups still work, but processes lack their
+// resource usage, GPU usage, ports and open file counts; the coalitions,
+// cpufreq, power, wifi, disk and interfaces groups return errors wrapping
+// ErrUnsupportedPlatform; and thermal stays ThermalNominal.
+package stats
@@ -0,0 +1,17 @@
+package stats
+
+import "errors"
+
+// Causes collectors wrap their errors in, so callers can tell with
+// errors.Is why a reading is missing instead of matching messages
+var (
+	// ErrUnsupportedPlatform: this Mac or macOS version lacks the interface,
+	// e.g. neither Apple silicon frequency tables nor a nominal frequency
+	ErrUnsupportedPlatform = errors.New("not supported on this Mac")
+	// ErrPermissionDenied: the kernel refused, e.g. task info of another
+	// user's process without root
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrSensorUnavailable: the hardware behind the reading is missing or
+	// not reporting, e.g. no Wi-Fi interface
+	ErrSensorUnavailable = errors.New("sensor unavailable")
+)
@@ -0,0 +1,35 @@
+package stats
+
+import (
+	"time"
+)
+
+// processGPUCollector derives per-process GPU usage from the GPU time each
+// process's IOAccelerator clients have accumulated between samples
+type processGPUCollector struct {
+	last     map[int]uint64
+	lastTime time.Time
+}
+
+// collect returns GPU usage percentages keyed by pid
+func (c *processGPUCollector) collect() (map[int]float64, error) {
+	times, err := getGPUClientTimes()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(c.lastTime)
+	usage := make(map[int]float64, len(times))
+	if !c.lastTime.IsZero() && elapsed > 0 {
+		for pid, t := range times {
+			if prev, ok := c.last[pid]; ok && t >= prev {
+				usage[pid] = float64(t-prev) / float64(elapsed.Nanoseconds()) * 100
+			}
+		}
+	}
+
+	c.last = times
+	c.lastTime = now
+	return usage, nil
+}
@@ -0,0 +1,97 @@
+package stats
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework IOKit
+#include <CoreFoundation/CoreFoundation.h>
+#include <IOKit/IOKitLib.h>
+#include <stdio.h>
+
+typedef struct {
+    int pid;
+    uint64_t gpuTime;
+} gpu_client_t;
+
+static uint64_t sumAppUsage(CFArrayRef usage) {
+    uint64_t total = 0;
+    for (CFIndex i = 0; i < CFArrayGetCount(usage); i++) {
+        CFDictionaryRef d = CFArrayGetValueAtIndex(usage, i);
+        if (CFGetTypeID(d) != CFDictionaryGetTypeID()) {
+            continue;
+        }
+        CFNumberRef t = CFDictionaryGetValue(d, CFSTR("accumulatedGPUTime"));
+        int64_t v = 0;
+        if (t != NULL && CFNumberGetValue(t, kCFNumberSInt64Type, &v)) {
+            total += v;
+        }
+    }
+    return total;
+}
+
+// getGPUClientTimes walks the user clients of every IOAccelerator and
+// reports the GPU time accumulated by each, tagged with its creator's pid
+int getGPUClientTimes(gpu_client_t *out, int max) {
+    io_iterator_t iter;
+    if (IOServiceGetMatchingServices(0, IOServiceMatching("IOAccelerator"), &iter) != KERN_SUCCESS) {
+        return -1;
+    }
+
+    int n = 0;
+    io_object_t accel;
+    while ((accel = IOIteratorNext(iter)) != 0) {
+        io_iterator_t children;
+        if (IORegistryEntryGetChildIterator(accel, kIOServicePlane, &children) == KERN_SUCCESS) {
+            io_object_t child;
+            while ((child = IOIteratorNext(children)) != 0) {
+                CFTypeRef creator = IORegistryEntryCreateCFProperty(child, CFSTR("IOUserClientCreator"), kCFAllocatorDefault, 0);
+                CFTypeRef usage = IORegistryEntryCreateCFProperty(child, CFSTR("AppUsage"), kCFAllocatorDefault, 0);
+                if (n < max && creator != NULL && usage != NULL &&
+                    CFGetTypeID(creator) == CFStringGetTypeID() && CFGetTypeID(usage) == CFArrayGetTypeID()) {
+                    char buf[256];
+                    int pid;
+                    if (CFStringGetCString(creator, buf, sizeof(buf), kCFStringEncodingUTF8) &&
+                        sscanf(buf, "pid %d", &pid) == 1) {
+                        out[n].pid = pid;
+                        out[n].gpuTime = sumAppUsage(usage);
+                        n++;
+                    }
+                }
+                if (creator != NULL) {
+                    CFRelease(creator);
+                }
+                if (usage != NULL) {
+                    CFRelease(usage);
+                }
+                IOObjectRelease(child);
+            }
+            IOObjectRelease(children);
+        }
+        IOObjectRelease(accel);
+    }
+    IOObjectRelease(iter);
+    return n;
+}
+*/
+import "C"
+import (
+	"fmt"
+)
+
+// Maximum number of GPU user clients read per sample
+const maxGPUClients = 4096
+
+// getGPUClientTimes returns the GPU time in nanoseconds accumulated by each
+// process with an open IOAccelerator user client
+func getGPUClientTimes() (map[int]uint64, error) {
+	clients := make([]C.gpu_client_t, maxGPUClients)
+
+	n := int(C.getGPUClientTimes(&clients[0], C.int(len(clients))))
+	if n < 0 {
+		return nil, fmt.Errorf("failed to list IOAccelerator clients: %w", ErrSensorUnavailable)
+	}
+
+	times := make(map[int]uint64, n)
+	for _, c := range clients[:n] {
+		times[int(c.pid)] += uint64(c.gpuTime)
+	}
+	return times, nil
+}
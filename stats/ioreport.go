@@ -0,0 +1,274 @@
+package stats
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework IOKit -lIOReport
+#include <CoreFoundation/CoreFoundation.h>
+#include <IOKit/IOKitLib.h>
+#include <stdint.h>
+#include <stdlib.h>
+#include <string.h>
+
+// Private IOReport API from libIOReport.dylib, as used by powermetrics
+typedef struct IOReportSubscription *IOReportSubscriptionRef;
+
+extern CFDictionaryRef IOReportCopyChannelsInGroup(CFStringRef group, CFStringRef subgroup, uint64_t a, uint64_t b, uint64_t c);
+extern IOReportSubscriptionRef IOReportCreateSubscription(void *a, CFMutableDictionaryRef desired, CFMutableDictionaryRef *subbed, uint64_t id, CFTypeRef b);
+extern CFDictionaryRef IOReportCreateSamples(IOReportSubscriptionRef sub, CFMutableDictionaryRef subbed, CFTypeRef a);
+extern CFDictionaryRef IOReportCreateSamplesDelta(CFDictionaryRef prev, CFDictionaryRef cur, CFTypeRef a);
+extern CFStringRef IOReportChannelGetGroup(CFDictionaryRef ch);
+extern CFStringRef IOReportChannelGetSubGroup(CFDictionaryRef ch);
+extern CFStringRef IOReportChannelGetChannelName(CFDictionaryRef ch);
+extern CFStringRef IOReportChannelGetUnitLabel(CFDictionaryRef ch);
+extern int IOReportChannelGetFormat(CFDictionaryRef ch);
+extern int32_t IOReportStateGetCount(CFDictionaryRef ch);
+extern CFStringRef IOReportStateGetNameForIndex(CFDictionaryRef ch, int32_t i);
+extern int64_t IOReportStateGetResidency(CFDictionaryRef ch, int32_t i);
+extern int64_t IOReportSimpleGetIntegerValue(CFDictionaryRef ch, int32_t i);
+
+typedef struct {
+    IOReportSubscriptionRef sub;
+    CFMutableDictionaryRef subbed;
+    CFDictionaryRef prev;
+    CFDictionaryRef delta;
+    CFArrayRef channels;
+} ior_t;
+
+static ior_t *iorOpen(const char *group, const char *subgroup) {
+    CFStringRef g = CFStringCreateWithCString(NULL, group, kCFStringEncodingUTF8);
+    CFStringRef sg = NULL;
+    if (subgroup != NULL) {
+        sg = CFStringCreateWithCString(NULL, subgroup, kCFStringEncodingUTF8);
+    }
+    CFDictionaryRef chans = IOReportCopyChannelsInGroup(g, sg, 0, 0, 0);
+    CFRelease(g);
+    if (sg != NULL) {
+        CFRelease(sg);
+    }
+    if (chans == NULL) {
+        return NULL;
+    }
+
+    CFMutableDictionaryRef desired = CFDictionaryCreateMutableCopy(NULL, CFDictionaryGetCount(chans), chans);
+    CFRelease(chans);
+    CFMutableDictionaryRef subbed = NULL;
+    IOReportSubscriptionRef sub = IOReportCreateSubscription(NULL, desired, &subbed, 0, NULL);
+    CFRelease(desired);
+    if (sub == NULL) {
+        return NULL;
+    }
+
+    ior_t *r = calloc(1, sizeof(ior_t));
+    r->sub = sub;
+    r->subbed = subbed;
+    r->prev = IOReportCreateSamples(sub, subbed, NULL);
+    return r;
+}
+
+// iorSample takes a new sample and keeps its delta against the previous
+// one, returning the number of channels in the delta
+static int iorSample(ior_t *r) {
+    CFDictionaryRef cur = IOReportCreateSamples(r->sub, r->subbed, NULL);
+    if (cur == NULL) {
+        return -1;
+    }
+    if (r->delta != NULL) {
+        CFRelease(r->delta);
+        r->delta = NULL;
+        r->channels = NULL;
+    }
+    if (r->prev != NULL) {
+        r->delta = IOReportCreateSamplesDelta(r->prev, cur, NULL);
+        CFRelease(r->prev);
+    }
+    r->prev = cur;
+    if (r->delta == NULL) {
+        return -1;
+    }
+    r->channels = CFDictionaryGetValue(r->delta, CFSTR("IOReportChannels"));
+    if (r->channels == NULL) {
+        return 0;
+    }
+    return (int)CFArrayGetCount(r->channels);
+}
+
+// iorClose releases the subscription and its samples
+static void iorClose(ior_t *r) {
+    if (r->delta != NULL) {
+        CFRelease(r->delta);
+    }
+    if (r->prev != NULL) {
+        CFRelease(r->prev);
+    }
+    if (r->subbed != NULL) {
+        CFRelease(r->subbed);
+    }
+    CFRelease(r->sub);
+    free(r);
+}
+
+static void iorString(CFStringRef s, char *buf, int len) {
+    buf[0] = 0;
+    if (s != NULL) {
+        CFStringGetCString(s, buf, len, kCFStringEncodingUTF8);
+    }
+}
+
+static CFDictionaryRef iorChannel(ior_t *r, int i) {
+    return (CFDictionaryRef)CFArrayGetValueAtIndex(r->channels, i);
+}
+
+static int iorChannelInfo(ior_t *r, int i, char *group, char *subgroup, char *name, char *unit, int len) {
+    CFDictionaryRef ch = iorChannel(r, i);
+    iorString(IOReportChannelGetGroup(ch), group, len);
+    iorString(IOReportChannelGetSubGroup(ch), subgroup, len);
+    iorString(IOReportChannelGetChannelName(ch), name, len);
+    iorString(IOReportChannelGetUnitLabel(ch), unit, len);
+    return IOReportChannelGetFormat(ch);
+}
+
+static int iorStateCount(ior_t *r, int i) {
+    return IOReportStateGetCount(iorChannel(r, i));
+}
+
+static int64_t iorState(ior_t *r, int i, int s, char *name, int len) {
+    CFDictionaryRef ch = iorChannel(r, i);
+    iorString(IOReportStateGetNameForIndex(ch, s), name, len);
+    return IOReportStateGetResidency(ch, s);
+}
+
+static int64_t iorValue(ior_t *r, int i) {
+    return IOReportSimpleGetIntegerValue(iorChannel(r, i), 0);
+}
+
+// pmgrTable reads a table of uint32 pairs from the pmgr device in the I/O
+// registry, such as the DVFS frequency/voltage states of a CPU cluster
+static int pmgrTable(const char *key, uint32_t *out, int max) {
+    io_iterator_t iter;
+    if (IOServiceGetMatchingServices(0, IOServiceMatching("AppleARMIODevice"), &iter) != KERN_SUCCESS) {
+        return -1;
+    }
+
+    int n = 0;
+    io_object_t entry;
+    while ((entry = IOIteratorNext(iter)) != 0) {
+        io_name_t name;
+        if (IORegistryEntryGetName(entry, name) == KERN_SUCCESS && strcmp(name, "pmgr") == 0) {
+            CFStringRef k = CFStringCreateWithCString(NULL, key, kCFStringEncodingUTF8);
+            CFTypeRef data = IORegistryEntryCreateCFProperty(entry, k, kCFAllocatorDefault, 0);
+            CFRelease(k);
+            if (data != NULL) {
+                if (CFGetTypeID(data) == CFDataGetTypeID()) {
+                    CFIndex len = CFDataGetLength((CFDataRef)data);
+                    const uint8_t *bytes = CFDataGetBytePtr((CFDataRef)data);
+                    for (CFIndex off = 0; off + 8 <= len && n < max; off += 8) {
+                        memcpy(&out[n++], bytes + off, sizeof(uint32_t));
+                    }
+                }
+                CFRelease(data);
+            }
+        }
+        IOObjectRelease(entry);
+    }
+    IOObjectRelease(iter);
+    return n;
+}
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// ioReport is a subscription to a group of IOReport channels
+type ioReport struct {
+	r *C.ior_t
+}
+
+// openIOReport subscribes to the channels of an IOReport group. An empty
+// subgroup subscribes to the whole group.
+func openIOReport(group, subgroup string) (*ioReport, error) {
+	cGroup := C.CString(group)
+	defer C.free(unsafe.Pointer(cGroup))
+	var cSubgroup *C.char
+	if subgroup != "" {
+		cSubgroup = C.CString(subgroup)
+		defer C.free(unsafe.Pointer(cSubgroup))
+	}
+
+	r := C.iorOpen(cGroup, cSubgroup)
+	if r == nil {
+		return nil, fmt.Errorf("failed to subscribe to IOReport group %q: %w", group, ErrSensorUnavailable)
+	}
+	return &ioReport{r: r}, nil
+}
+
+// sample returns each channel's change since the previous sample
+func (rep *ioReport) sample() ([]IOReportChannel, error) {
+	n := int(C.iorSample(rep.r))
+	if n < 0 {
+		return nil, fmt.Errorf("failed to sample IOReport channels")
+	}
+
+	const bufLen = 128
+	buf := (*C.char)(C.malloc(bufLen * 4))
+	defer C.free(unsafe.Pointer(buf))
+	group := buf
+	subgroup := (*C.char)(unsafe.Add(unsafe.Pointer(buf), bufLen))
+	name := (*C.char)(unsafe.Add(unsafe.Pointer(buf), bufLen*2))
+	unit := (*C.char)(unsafe.Add(unsafe.Pointer(buf), bufLen*3))
+
+	channels := make([]IOReportChannel, 0, n)
+	for i := 0; i < n; i++ {
+		format := int(C.iorChannelInfo(rep.r, C.int(i), group, subgroup, name, unit, bufLen))
+		ch := IOReportChannel{
+			Group:    C.GoString(group),
+			SubGroup: C.GoString(subgroup),
+			Name:     C.GoString(name),
+			Unit:     C.GoString(unit),
+			Format:   format,
+		}
+
+		switch format {
+		case IOReportFormatSimple:
+			ch.Value = int64(C.iorValue(rep.r, C.int(i)))
+		case IOReportFormatState:
+			count := int(C.iorStateCount(rep.r, C.int(i)))
+			ch.States = make([]IOReportState, 0, count)
+			for s := 0; s < count; s++ {
+				residency := int64(C.iorState(rep.r, C.int(i), C.int(s), name, bufLen))
+				ch.States = append(ch.States, IOReportState{Name: C.GoString(name), Residency: residency})
+			}
+		}
+
+		channels = append(channels, ch)
+	}
+
+	return channels, nil
+}
+
+// close ends the subscription. The ioReport must not be sampled after.
+func (rep *ioReport) close() {
+	if rep.r != nil {
+		C.iorClose(rep.r)
+		rep.r = nil
+	}
+}
+
+// ReadPmgrTable returns the first value of each pair in a pmgr registry
+// table, e.g. the frequencies from "voltage-states5-sram"
+func ReadPmgrTable(key string) ([]uint32, error) {
+	cKey := C.CString(key)
+	defer C.free(unsafe.Pointer(cKey))
+
+	var out [64]C.uint32_t
+	n := int(C.pmgrTable(cKey, &out[0], C.int(len(out))))
+	if n < 0 {
+		return nil, fmt.Errorf("failed to read pmgr table %q", key)
+	}
+
+	values := make([]uint32, n)
+	for i := range values {
+		values[i] = uint32(out[i])
+	}
+	return values, nil
+}
@@ -0,0 +1,198 @@
+package stats
+
+/*
+#include <libproc.h>
+#include <netinet/in.h>
+#include <sys/proc_info.h>
+#include <sys/resource.h>
+#include <stdlib.h>
+#include <string.h>
+
+int getProcRusage(int pid, struct rusage_info_v4 *info) {
+    return proc_pid_rusage(pid, RUSAGE_INFO_V4, (rusage_info_t *)info);
+}
+
+int getTaskInfo(int pid, struct proc_taskinfo *info) {
+    int size = proc_pidinfo(pid, PROC_PIDTASKINFO, 0, info, sizeof(*info));
+    return size == sizeof(*info) ? 0 : -1;
+}
+
+int getProcPath(int pid, char *buf, int size) {
+    return proc_pidpath(pid, buf, size);
+}
+
+int getProcCwd(int pid, char *buf, int size) {
+    struct proc_vnodepathinfo info;
+    int n = proc_pidinfo(pid, PROC_PIDVNODEPATHINFO, 0, &info, sizeof(info));
+    if (n != sizeof(info)) {
+        return -1;
+    }
+    strlcpy(buf, info.pvi_cdir.vip_path, size);
+    return 0;
+}
+
+int getProcFDCount(int pid) {
+    int size = proc_pidinfo(pid, PROC_PIDLISTFDS, 0, NULL, 0);
+    if (size <= 0) {
+        return -1;
+    }
+    struct proc_fdinfo *fds = malloc(size);
+    if (fds == NULL) {
+        return -1;
+    }
+    size = proc_pidinfo(pid, PROC_PIDLISTFDS, 0, fds, size);
+    free(fds);
+    if (size < 0) {
+        return -1;
+    }
+    return size / (int)sizeof(struct proc_fdinfo);
+}
+
+// getProcPorts fills ports with the TCP ports a process listens on and
+// the UDP ports it has bound, up to max, returning how many or -1
+int getProcPorts(int pid, int *ports, int max) {
+    int size = proc_pidinfo(pid, PROC_PIDLISTFDS, 0, NULL, 0);
+    if (size <= 0) {
+        return -1;
+    }
+    struct proc_fdinfo *fds = malloc(size);
+    if (fds == NULL) {
+        return -1;
+    }
+    size = proc_pidinfo(pid, PROC_PIDLISTFDS, 0, fds, size);
+    if (size < 0) {
+        free(fds);
+        return -1;
+    }
+    int n = 0;
+    for (int i = 0; i < size / (int)sizeof(struct proc_fdinfo) && n < max; i++) {
+        if (fds[i].proc_fdtype != PROX_FDTYPE_SOCKET) {
+            continue;
+        }
+        struct socket_fdinfo info;
+        if (proc_pidfdinfo(pid, fds[i].proc_fd, PROC_PIDFDSOCKETINFO, &info, sizeof(info)) != sizeof(info)) {
+            continue;
+        }
+        int port = 0;
+        if (info.psi.soi_kind == SOCKINFO_TCP && info.psi.soi_proto.pri_tcp.tcpsi_state == TSI_S_LISTEN) {
+            port = info.psi.soi_proto.pri_tcp.tcpsi_ini.insi_lport;
+        } else if (info.psi.soi_kind == SOCKINFO_IN && info.psi.soi_protocol == IPPROTO_UDP) {
+            port = info.psi.soi_proto.pri_in.insi_lport;
+        }
+        if (port != 0) {
+            ports[n++] = ntohs((uint16_t)port);
+        }
+    }
+    free(fds);
+    return n;
+}
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+)
+
+// Size of the buffer proc_pidpath needs (PROC_PIDPATHINFO_MAXSIZE)
+const procPathMaxSize = 4096
+
+// ProcessRusage gets resource usage for a single process using proc_pid_rusage
+func ProcessRusage(pid int) (*Rusage, error) {
+	var info C.struct_rusage_info_v4
+
+	ret, errno := C.getProcRusage(C.int(pid), &info)
+	if ret != 0 {
+		if errors.Is(errno, fs.ErrPermission) {
+			return nil, fmt.Errorf("proc_pid_rusage failed for pid %d: %w", pid, ErrPermissionDenied)
+		}
+		return nil, fmt.Errorf("proc_pid_rusage failed for pid %d with error code: %d", pid, ret)
+	}
+
+	return &Rusage{
+		PhysFootprint:            uint64(info.ri_phys_footprint),
+		LifetimeMaxPhysFootprint: uint64(info.ri_lifetime_max_phys_footprint),
+		BilledEnergy:             uint64(info.ri_billed_energy),
+		DiskRead:                 uint64(info.ri_diskio_bytesread),
+		DiskWritten:              uint64(info.ri_diskio_byteswritten),
+	}, nil
+}
+
+// getProcTaskInfo gets task information for a single process using proc_pidinfo
+func getProcTaskInfo(pid int) (*procTaskInfo, error) {
+	var info C.struct_proc_taskinfo
+
+	ret, errno := C.getTaskInfo(C.int(pid), &info)
+	if ret != 0 {
+		if errors.Is(errno, fs.ErrPermission) {
+			return nil, fmt.Errorf("proc_pidinfo failed for pid %d: %w", pid, ErrPermissionDenied)
+		}
+		return nil, fmt.Errorf("proc_pidinfo failed for pid %d", pid)
+	}
+
+	return &procTaskInfo{
+		ResidentSize: uint64(info.pti_resident_size),
+		TotalUser:    uint64(info.pti_total_user),
+		TotalSystem:  uint64(info.pti_total_system),
+		ThreadCount:  int32(info.pti_threadnum),
+	}, nil
+}
+
+// getProcPath gets the executable path of a process using proc_pidpath
+func getProcPath(pid int) (string, error) {
+	var buf [procPathMaxSize]C.char
+
+	n := C.getProcPath(C.int(pid), &buf[0], C.int(len(buf)))
+	if n <= 0 {
+		return "", fmt.Errorf("proc_pidpath failed for pid %d", pid)
+	}
+
+	return C.GoStringN(&buf[0], n), nil
+}
+
+// ProcessCwd gets the current working directory of a process
+func ProcessCwd(pid int) (string, error) {
+	var buf [procPathMaxSize]C.char
+
+	if ret, errno := C.getProcCwd(C.int(pid), &buf[0], C.int(len(buf))); ret != 0 {
+		if errors.Is(errno, fs.ErrPermission) {
+			return "", fmt.Errorf("failed to get working directory of pid %d: %w", pid, ErrPermissionDenied)
+		}
+		return "", fmt.Errorf("failed to get working directory of pid %d", pid)
+	}
+
+	return C.GoString(&buf[0]), nil
+}
+
+// getProcFDCount counts the open file descriptors of a process using
+// proc_pidinfo(PROC_PIDLISTFDS)
+func getProcFDCount(pid int) (int, error) {
+	n := int(C.getProcFDCount(C.int(pid)))
+	if n < 0 {
+		return 0, fmt.Errorf("failed to list file descriptors of pid %d", pid)
+	}
+	return n, nil
+}
+
+// Most ports listed for one process
+const procPortsMax = 64
+
+// getProcPorts returns the TCP ports a process listens on and the UDP
+// ports it has bound, sorted, from proc_pidfdinfo on each of its sockets
+func getProcPorts(pid int) ([]int, error) {
+	var buf [procPortsMax]C.int
+	n := int(C.getProcPorts(C.int(pid), &buf[0], procPortsMax))
+	if n < 0 {
+		return nil, fmt.Errorf("failed to list sockets of pid %d", pid)
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	ports := make([]int, n)
+	for i := range ports {
+		ports[i] = int(buf[i])
+	}
+	slices.Sort(ports)
+	return slices.Compact(ports), nil
+}
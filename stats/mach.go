@@ -0,0 +1,178 @@
+//go:build cgo && !purego
+
+package stats
+
+/*
+#include <mach/mach.h>
+#include <mach/mach_host.h>
+#include <mach/mach_time.h>
+#include <mach/host_info.h>
+#include <mach/processor_info.h>
+#include <mach/vm_statistics.h>
+#include <pthread.h>
+#include <stdlib.h>
+#include <string.h>
+#include <sys/sysctl.h>
+#include <sys/time.h>
+
+// Cores beyond this are left out of the per-core ticks
+#define MTOP_MAX_CPUS 512
+
+// Everything read from the kernel in one collectNative call
+struct native_stats {
+    struct vm_statistics64 vm;
+    mach_msg_type_number_t vm_count;
+    natural_t cpu_ticks[CPU_STATE_MAX];
+    natural_t core_ticks[MTOP_MAX_CPUS][CPU_STATE_MAX];
+    int ncores;
+    double loadavg[3];
+    int64_t boot_sec;
+    int32_t boot_usec;
+};
+
+// The host port, taken once: every mach_host_self() call adds a reference
+// to the send right that must be given back, and mtop samples for as long
+// as it runs. Mach collectors share it and never deallocate it.
+static mach_port_t host_port = MACH_PORT_NULL;
+static pthread_once_t host_port_once = PTHREAD_ONCE_INIT;
+
+static void initHostPort(void) {
+    host_port = mach_host_self();
+}
+
+static mach_port_t hostPort(void) {
+    pthread_once(&host_port_once, initHostPort);
+    return host_port;
+}
+
+int collectNative(struct native_stats *s) {
+    mach_port_t host = hostPort();
+    s->vm_count = HOST_VM_INFO64_COUNT;
+
+    kern_return_t kr = host_statistics64(
+        host,
+        HOST_VM_INFO64,
+        (host_info64_t)&s->vm,
+        &s->vm_count
+    );
+    if (kr != KERN_SUCCESS) {
+        return kr;
+    }
+
+    // The rest is best effort and left zero on failure
+    host_cpu_load_info_data_t load;
+    mach_msg_type_number_t count = HOST_CPU_LOAD_INFO_COUNT;
+    if (host_statistics(host, HOST_CPU_LOAD_INFO, (host_info_t)&load, &count) == KERN_SUCCESS) {
+        memcpy(s->cpu_ticks, load.cpu_ticks, sizeof(s->cpu_ticks));
+    }
+
+    natural_t ncpu;
+    processor_info_array_t info;
+    mach_msg_type_number_t ninfo;
+    if (host_processor_info(host, PROCESSOR_CPU_LOAD_INFO, &ncpu, &info, &ninfo) == KERN_SUCCESS) {
+        processor_cpu_load_info_t cpus = (processor_cpu_load_info_t)info;
+        for (natural_t i = 0; i < ncpu && i < MTOP_MAX_CPUS; i++) {
+            memcpy(s->core_ticks[i], cpus[i].cpu_ticks, sizeof(s->core_ticks[i]));
+            s->ncores++;
+        }
+        vm_deallocate(mach_task_self(), (vm_address_t)info, ninfo * sizeof(integer_t));
+    }
+
+    if (getloadavg(s->loadavg, 3) != 3) {
+        memset(s->loadavg, 0, sizeof(s->loadavg));
+    }
+
+    struct timeval boot;
+    size_t len = sizeof(boot);
+    if (sysctlbyname("kern.boottime", &boot, &len, NULL, 0) == 0) {
+        s->boot_sec = boot.tv_sec;
+        s->boot_usec = boot.tv_usec;
+    }
+
+    return 0;
+}
+
+void getTimebase(uint32_t *numer, uint32_t *denom) {
+    mach_timebase_info_data_t tb;
+    mach_timebase_info(&tb);
+    *numer = tb.numer;
+    *denom = tb.denom;
+}
+*/
+import "C"
+import (
+	"fmt"
+	"time"
+)
+
+// collectNativeStats reads the VM statistics, CPU tick counters, load
+// averages and boot time in a single cgo call
+func collectNativeStats() (*nativeStats, error) {
+	var cs C.struct_native_stats
+
+	ret := C.collectNative(&cs)
+	if ret != 0 {
+		return nil, fmt.Errorf("host_statistics64 failed with error code: %d", ret)
+	}
+
+	// Convert C struct to Go struct
+	v := &cs.vm
+	stats := &nativeStats{
+		VM: VMStatistics{
+			FreeCount:                          uint32(v.free_count),
+			ActiveCount:                        uint32(v.active_count),
+			InactiveCount:                      uint32(v.inactive_count),
+			WireCount:                          uint32(v.wire_count),
+			ZeroFillCount:                      uint64(v.zero_fill_count),
+			Reactivations:                      uint64(v.reactivations),
+			Pageins:                            uint64(v.pageins),
+			Pageouts:                           uint64(v.pageouts),
+			Faults:                             uint64(v.faults),
+			CowFaults:                          uint64(v.cow_faults),
+			Lookups:                            uint64(v.lookups),
+			Hits:                               uint64(v.hits),
+			Purges:                             uint64(v.purges),
+			PurgeableCount:                     uint32(v.purgeable_count),
+			SpeculativeCount:                   uint32(v.speculative_count),
+			Decompressions:                     uint64(v.decompressions),
+			Compressions:                       uint64(v.compressions),
+			Swapins:                            uint64(v.swapins),
+			Swapouts:                           uint64(v.swapouts),
+			CompressorPageCount:                uint32(v.compressor_page_count),
+			ThrottledCount:                     uint32(v.throttled_count),
+			ExternalPageCount:                  uint32(v.external_page_count),
+			InternalPageCount:                  uint32(v.internal_page_count),
+			TotalUncompressedPagesInCompressor: uint64(v.total_uncompressed_pages_in_compressor),
+		},
+		VMFilled: int(cs.vm_count) * int(C.sizeof_natural_t),
+		CPUTicks: ticksFromC(cs.cpu_ticks),
+		Cores:    make([]cpuTicks, int(cs.ncores)),
+	}
+	for i := range stats.Cores {
+		stats.Cores[i] = ticksFromC(cs.core_ticks[i])
+	}
+	for i := range stats.LoadAvg {
+		stats.LoadAvg[i] = float64(cs.loadavg[i])
+	}
+	if cs.boot_sec > 0 {
+		stats.BootTime = time.Unix(int64(cs.boot_sec), int64(cs.boot_usec)*1000)
+	}
+
+	return stats, nil
+}
+
+// ticksFromC converts the cpu_ticks array of a host or processor load info
+func ticksFromC(t [C.CPU_STATE_MAX]C.natural_t) cpuTicks {
+	var out cpuTicks
+	for i := range out {
+		out[i] = uint32(t[i])
+	}
+	return out
+}
+
+// machTimebase returns the ratio used to convert mach absolute time to nanoseconds
+func machTimebase() (numer, denom uint32) {
+	var n, d C.uint32_t
+	C.getTimebase(&n, &d)
+	return uint32(n), uint32(d)
+}
@@ -0,0 +1,116 @@
+//go:build darwin && (!cgo || purego)
+
+package stats
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// The Mach calls of mach.go made without cgo, the way x/sys/unix makes
+// its libc calls: each function is imported from libSystem and called
+// through an assembly trampoline (mach_purego.s) by the runtime's libc
+// call path. Built without cgo, or with -tags purego.
+
+// Flavors from mach/host_info.h and mach/processor_info.h
+const (
+	hostCPULoadInfo      = 3 // HOST_CPU_LOAD_INFO
+	hostVMInfo64         = 4 // HOST_VM_INFO64
+	processorCPULoadInfo = 2 // PROCESSOR_CPU_LOAD_INFO
+	kernSuccess          = 0
+)
+
+//go:linkname syscall_syscall6 syscall.syscall6
+func syscall_syscall6(fn, a1, a2, a3, a4, a5, a6 uintptr) (r1, r2 uintptr, err syscall.Errno)
+
+// machTaskSelf is mach_task_self(), which C reads from a variable the
+// trap fills in at startup; the trap is asked once instead
+var machTaskSelf = sync.OnceValue(func() uintptr {
+	r, _, _ := syscall_syscall6(libc_task_self_trap_trampoline_addr, 0, 0, 0, 0, 0, 0)
+	return uintptr(uint32(r))
+})
+
+// machHostPort is the host port, taken once as in mach.go
+var machHostPort = sync.OnceValue(func() uintptr {
+	r, _, _ := syscall_syscall6(libc_mach_host_self_trampoline_addr, 0, 0, 0, 0, 0, 0)
+	return uintptr(uint32(r))
+})
+
+// collectNativeStats reads the VM statistics, CPU tick counters, load
+// averages and boot time without cgo
+func collectNativeStats() (*nativeStats, error) {
+	stats := &nativeStats{}
+	host := machHostPort()
+
+	count := uint32(unsafe.Sizeof(stats.VM) / 4)
+	r, _, _ := syscall_syscall6(libc_host_statistics64_trampoline_addr, host, hostVMInfo64,
+		uintptr(unsafe.Pointer(&stats.VM)), uintptr(unsafe.Pointer(&count)), 0, 0)
+	if kr := int32(r); kr != kernSuccess {
+		return nil, fmt.Errorf("host_statistics64 failed with error code: %d", kr)
+	}
+	stats.VMFilled = int(count) * 4
+
+	// The rest is best effort and left zero on failure
+	count = uint32(len(stats.CPUTicks))
+	r, _, _ = syscall_syscall6(libc_host_statistics_trampoline_addr, host, hostCPULoadInfo,
+		uintptr(unsafe.Pointer(&stats.CPUTicks)), uintptr(unsafe.Pointer(&count)), 0, 0)
+	if int32(r) != kernSuccess {
+		stats.CPUTicks = cpuTicks{}
+	}
+
+	var ncpu, ninfo uint32
+	var info *cpuTicks // Allocated by the kernel, outside the Go heap
+	r, _, _ = syscall_syscall6(libc_host_processor_info_trampoline_addr, host, processorCPULoadInfo,
+		uintptr(unsafe.Pointer(&ncpu)), uintptr(unsafe.Pointer(&info)), uintptr(unsafe.Pointer(&ninfo)), 0)
+	if int32(r) == kernSuccess {
+		stats.Cores = append([]cpuTicks(nil), unsafe.Slice(info, ncpu)...)
+		syscall_syscall6(libc_vm_deallocate_trampoline_addr, machTaskSelf(), uintptr(unsafe.Pointer(info)), uintptr(ninfo)*4, 0, 0, 0)
+	}
+
+	// struct loadavg: three fixed-point averages and their scale
+	if buf, err := unix.SysctlRaw("vm.loadavg"); err == nil && len(buf) >= 24 {
+		if scale := float64(binary.LittleEndian.Uint64(buf[16:])); scale > 0 {
+			for i := range stats.LoadAvg {
+				stats.LoadAvg[i] = float64(binary.LittleEndian.Uint32(buf[4*i:])) / scale
+			}
+		}
+	}
+
+	if boot, err := unix.SysctlTimeval("kern.boottime"); err == nil && boot.Sec > 0 {
+		stats.BootTime = time.Unix(boot.Unix())
+	}
+
+	return stats, nil
+}
+
+// machTimebase returns the ratio used to convert mach absolute time to nanoseconds
+func machTimebase() (numer, denom uint32) {
+	var tb struct{ numer, denom uint32 }
+	syscall_syscall6(libc_mach_timebase_info_trampoline_addr, uintptr(unsafe.Pointer(&tb)), 0, 0, 0, 0, 0)
+	return tb.numer, tb.denom
+}
+
+// Trampoline addresses, filled in by mach_purego.s
+var (
+	libc_mach_host_self_trampoline_addr      uintptr
+	libc_task_self_trap_trampoline_addr      uintptr
+	libc_host_statistics64_trampoline_addr   uintptr
+	libc_host_statistics_trampoline_addr     uintptr
+	libc_host_processor_info_trampoline_addr uintptr
+	libc_vm_deallocate_trampoline_addr       uintptr
+	libc_mach_timebase_info_trampoline_addr  uintptr
+)
+
+//go:cgo_import_dynamic libc_mach_host_self mach_host_self "/usr/lib/libSystem.B.dylib"
+//go:cgo_import_dynamic libc_task_self_trap task_self_trap "/usr/lib/libSystem.B.dylib"
+//go:cgo_import_dynamic libc_host_statistics64 host_statistics64 "/usr/lib/libSystem.B.dylib"
+//go:cgo_import_dynamic libc_host_statistics host_statistics "/usr/lib/libSystem.B.dylib"
+//go:cgo_import_dynamic libc_host_processor_info host_processor_info "/usr/lib/libSystem.B.dylib"
+//go:cgo_import_dynamic libc_vm_deallocate vm_deallocate "/usr/lib/libSystem.B.dylib"
+//go:cgo_import_dynamic libc_mach_timebase_info mach_timebase_info "/usr/lib/libSystem.B.dylib"
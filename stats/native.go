@@ -0,0 +1,55 @@
+package stats
+
+// The Go forms of what the cgo calls read, shared with the builds without
+// cgo (nocgo.go)
+
+// coalitionUsage holds the subset of coalition_resource_usage used by mtop.
+// Totals cover every task that has been in the coalition, exited or not.
+type coalitionUsage struct {
+	CPUTime     uint64 // CPU time in mach absolute time units
+	GPUTime     uint64 // GPU time in nanoseconds
+	Energy      uint64 // Energy billed in nanojoules
+	DiskRead    uint64 // Bytes read from disk
+	DiskWritten uint64 // Bytes written to disk
+	Exited      uint64 // Tasks that have left the coalition
+}
+
+// IOReport channel formats
+const (
+	IOReportFormatSimple = 1
+	IOReportFormatState  = 2
+)
+
+// IOReportChannel is the change of one channel between two samples
+type IOReportChannel struct {
+	Group    string
+	SubGroup string
+	Name     string
+	Unit     string
+	Format   int
+	Value    int64           // Delta for simple channels
+	States   []IOReportState // Residencies for state channels
+}
+
+// IOReportState is the residency of a state channel in one of its states
+type IOReportState struct {
+	Name      string
+	Residency int64
+}
+
+// Rusage holds the subset of rusage_info_v4 used by mtop
+type Rusage struct {
+	PhysFootprint            uint64 // Current physical footprint in bytes
+	LifetimeMaxPhysFootprint uint64 // Peak physical footprint in bytes
+	BilledEnergy             uint64 // Energy billed to the process in nanojoules
+	DiskRead                 uint64 // Bytes read from disk
+	DiskWritten              uint64 // Bytes written to disk
+}
+
+// procTaskInfo holds the subset of proc_taskinfo used by mtop
+type procTaskInfo struct {
+	ResidentSize uint64 // Resident memory in bytes
+	TotalUser    uint64 // User CPU time in mach absolute time units
+	TotalSystem  uint64 // System CPU time in mach absolute time units
+	ThreadCount  int32  // Number of threads
+}
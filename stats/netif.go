@@ -0,0 +1,84 @@
+package stats
+
+/*
+#include <stdlib.h>
+#include <sys/types.h>
+#include <sys/socket.h>
+#include <sys/sysctl.h>
+#include <net/if.h>
+#include <net/route.h>
+
+// ifCounters is one interface's traffic since boot
+typedef struct {
+    char name[IFNAMSIZ];
+    int up;
+    uint64_t ibytes;
+    uint64_t obytes;
+} ifCounters;
+
+// listInterfaces fills out with the 64-bit byte counters of up to max
+// interfaces from the NET_RT_IFLIST2 dump, as netstat -ib reads them, and
+// returns how many or -1
+int listInterfaces(ifCounters *out, int max) {
+    int mib[6] = {CTL_NET, PF_ROUTE, 0, 0, NET_RT_IFLIST2, 0};
+    size_t len;
+    if (sysctl(mib, 6, NULL, &len, NULL, 0) < 0) {
+        return -1;
+    }
+    char *buf = malloc(len);
+    if (buf == NULL) {
+        return -1;
+    }
+    if (sysctl(mib, 6, buf, &len, NULL, 0) < 0) {
+        free(buf);
+        return -1;
+    }
+
+    int n = 0;
+    for (char *p = buf; p < buf + len && n < max; ) {
+        struct if_msghdr *ifm = (struct if_msghdr *)p;
+        if (ifm->ifm_msglen == 0) {
+            break;
+        }
+        if (ifm->ifm_type == RTM_IFINFO2) {
+            struct if_msghdr2 *ifm2 = (struct if_msghdr2 *)p;
+            ifCounters *c = &out[n];
+            if (if_indextoname(ifm2->ifm_index, c->name) != NULL) {
+                c->up = (ifm2->ifm_flags & IFF_UP) != 0;
+                c->ibytes = ifm2->ifm_data.ifi_ibytes;
+                c->obytes = ifm2->ifm_data.ifi_obytes;
+                n++;
+            }
+        }
+        p += ifm->ifm_msglen;
+    }
+    free(buf);
+    return n;
+}
+*/
+import "C"
+import "fmt"
+
+// Most interfaces read at once; more are left out
+const maxInterfaces = 128
+
+// getInterfaceCounters returns the traffic of every network interface
+// since boot
+func getInterfaceCounters() ([]ifCounters, error) {
+	var out [maxInterfaces]C.ifCounters
+	n := C.listInterfaces(&out[0], maxInterfaces)
+	if n < 0 {
+		return nil, fmt.Errorf("failed to list network interfaces: %w", ErrSensorUnavailable)
+	}
+	ifs := make([]ifCounters, n)
+	for i := range ifs {
+		c := &out[i]
+		ifs[i] = ifCounters{
+			name:     C.GoString(&c.name[0]),
+			up:       c.up != 0,
+			received: uint64(c.ibytes),
+			sent:     uint64(c.obytes),
+		}
+	}
+	return ifs, nil
+}
@@ -0,0 +1,123 @@
+package stats
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// processNetCollector attributes network traffic to processes by running
+// nettop once per sample and parsing its CSV. mtop does not talk to the
+// kernel's network statistics (ntstat) control socket itself: its messages
+// are private and change between macOS releases, and nettop tracks them.
+// nettop reports cumulative bytes per process, so rates are derived from
+// the change between samples.
+type processNetCollector struct {
+	last     map[int]procNetBytes
+	lastTime time.Time
+}
+
+// procNetBytes is the cumulative traffic of one process
+type procNetBytes struct {
+	in, out uint64
+}
+
+// procNetRate is the traffic rate of one process in bytes per second
+type procNetRate struct {
+	In, Out float64
+}
+
+// collect returns per-process network rates keyed by pid; nettop is
+// killed if ctx is done first
+func (c *processNetCollector) collect(ctx context.Context) (map[int]procNetRate, error) {
+	out, err := exec.CommandContext(ctx, "nettop", "-P", "-x", "-L", "1", "-J", "bytes_in,bytes_out").Output()
+	if err != nil {
+		return nil, fmt.Errorf("nettop failed: %w", err)
+	}
+	current, err := parseNettop(out)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(c.lastTime).Seconds()
+	rates := make(map[int]procNetRate, len(current))
+	if !c.lastTime.IsZero() && elapsed > 0 {
+		for pid, cur := range current {
+			prev, ok := c.last[pid]
+			if !ok || cur.in < prev.in || cur.out < prev.out {
+				continue
+			}
+			rates[pid] = procNetRate{
+				In:  float64(cur.in-prev.in) / elapsed,
+				Out: float64(cur.out-prev.out) / elapsed,
+			}
+		}
+	}
+
+	c.last = current
+	c.lastTime = now
+	return rates, nil
+}
+
+// parseNettop parses nettop CSV output, where the "name.pid" column comes
+// right before the requested byte counters (after the time column, if any).
+// Output whose rows don't read that way is an error, not an empty sample,
+// so a change in nettop's format doesn't pass for idle processes.
+func parseNettop(out []byte) (map[int]procNetBytes, error) {
+	result := make(map[int]procNetBytes)
+	inCol, outCol := -1, -1
+	rows := 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ",")
+		if inCol < 0 {
+			for i, f := range fields {
+				switch strings.TrimSpace(f) {
+				case "bytes_in":
+					inCol = i
+				case "bytes_out":
+					outCol = i
+				}
+			}
+			continue
+		}
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		rows++
+		if inCol == 0 || outCol < 0 || len(fields) <= inCol || len(fields) <= outCol {
+			continue
+		}
+
+		name := fields[inCol-1]
+		dot := strings.LastIndexByte(name, '.')
+		if dot < 0 {
+			continue
+		}
+		pid, err := strconv.Atoi(name[dot+1:])
+		if err != nil {
+			continue
+		}
+		in, err := strconv.ParseUint(strings.TrimSpace(fields[inCol]), 10, 64)
+		if err != nil {
+			continue
+		}
+		out, err := strconv.ParseUint(strings.TrimSpace(fields[outCol]), 10, 64)
+		if err != nil {
+			continue
+		}
+		result[pid] = procNetBytes{in: in, out: out}
+	}
+
+	if inCol < 0 || outCol < 0 || (rows > 0 && len(result) == 0) {
+		return nil, fmt.Errorf("unexpected nettop output")
+	}
+	return result, scanner.Err()
+}
@@ -0,0 +1,30 @@
+package stats
+
+import "testing"
+
+func TestParseNettop(t *testing.T) {
+	out := []byte("time,,bytes_in,bytes_out,\n" +
+		"12:00:00.000000,launchd.1,100,200,\n" +
+		"12:00:00.000000,Google Chrome H.512,3000,4000,\n\n")
+	got, err := parseNettop(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[1] != (procNetBytes{in: 100, out: 200}) || got[512] != (procNetBytes{in: 3000, out: 4000}) || len(got) != 2 {
+		t.Errorf("parseNettop = %v", got)
+	}
+
+	if got, err := parseNettop([]byte("time,,bytes_in,bytes_out,\n")); err != nil || len(got) != 0 {
+		t.Errorf("header only = %v, %v, want no processes and no error", got, err)
+	}
+
+	for name, out := range map[string]string{
+		"no header":       "12:00:00.000000,launchd.1,100,200,\n",
+		"missing counter": "time,,bytes_in,\n12:00:00.000000,launchd.1,100,\n",
+		"moved columns":   "time,,bytes_in,bytes_out,\n12:00:00.000000,100,200,launchd.1,\n",
+	} {
+		if _, err := parseNettop([]byte(out)); err == nil {
+			t.Errorf("%s: parseNettop succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+//go:build !cgo
+
+package stats
+
+import "fmt"
+
+// Builds without cgo, e.g. cross-compiled from Linux, read the Mach
+// statistics through mach_purego.go. The collectors below use IOKit,
+// IOReport, libproc and Foundation, which need cgo, so they report their
+// readings unavailable: processes list without their resource usage and
+// the power, frequency, GPU, disk, interface and Wi-Fi readings are empty
+// and the thermal state stays nominal.
+
+var errNoCgo = fmt.Errorf("%w: mtop was built without cgo", ErrUnsupportedPlatform)
+
+func getProcCoalition(pid int) (uint64, error)             { return 0, errNoCgo }
+func getCoalitionUsage(id uint64) (*coalitionUsage, error) { return nil, errNoCgo }
+func getDiskCounters() ([]diskCounters, error)             { return nil, errNoCgo }
+func getInterfaceCounters() ([]ifCounters, error)          { return nil, errNoCgo }
+func getGPUClientTimes() (map[int]uint64, error)           { return nil, errNoCgo }
+func collectWiFiStats() (*WiFiStats, error)                { return nil, errNoCgo }
+func collectThermalState() ThermalState                    { return ThermalNominal }
+
+type ioReport struct{}
+
+func openIOReport(group, subgroup string) (*ioReport, error) { return nil, errNoCgo }
+func (rep *ioReport) sample() ([]IOReportChannel, error)     { return nil, errNoCgo }
+func (rep *ioReport) close()                                 {}
+
+// ReadPmgrTable reads a table of the pmgr IORegistry entry; unavailable
+// without cgo
+func ReadPmgrTable(key string) ([]uint32, error) { return nil, errNoCgo }
+
+// ProcessRusage gets resource usage for a single process; unavailable
+// without cgo
+func ProcessRusage(pid int) (*Rusage, error) { return nil, errNoCgo }
+
+// ProcessCwd returns the working directory of a process; unavailable
+// without cgo
+func ProcessCwd(pid int) (string, error) { return "", errNoCgo }
+
+func getProcTaskInfo(pid int) (*procTaskInfo, error) { return nil, errNoCgo }
+func getProcPath(pid int) (string, error)            { return "", errNoCgo }
+func getProcFDCount(pid int) (int, error)            { return 0, errNoCgo }
+func getProcPorts(pid int) ([]int, error)            { return nil, errNoCgo }
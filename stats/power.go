@@ -0,0 +1,96 @@
+package stats
+
+import (
+	"strings"
+	"time"
+)
+
+// powerCollector turns the IOReport energy model counters into power draw
+// by dividing each energy delta by the time between samples
+type powerCollector struct {
+	report   *ioReport // nil on Macs without the energy model subscription
+	err      error     // Why the subscription couldn't be opened
+	lastTime time.Time
+	last     []IOReportChannel // Raw channels of the latest sample, for the debug dump
+}
+
+// collectPower reports CPU, GPU and package power in watts. The IOReport
+// subscription is opened on first use; where it is missing, the error
+// opening it is reported until a Reset.
+func (c *Collector) collectPower() (PowerStats, error) {
+	power, gen := c.power.take()
+	if power == nil {
+		report, err := openIOReport("Energy Model", "")
+		power = &powerCollector{report: report, err: err, lastTime: time.Now()}
+	}
+	var stats PowerStats
+	var err error
+	if power.report == nil {
+		err = power.err
+	} else {
+		stats, err = power.collect()
+	}
+	if !c.power.put(power, gen) {
+		power.close()
+	}
+	return stats, err
+}
+
+// close ends the IOReport subscription
+func (c *powerCollector) close() {
+	if c.report != nil {
+		c.report.close()
+	}
+}
+
+func (c *powerCollector) collect() (PowerStats, error) {
+	var power PowerStats
+
+	channels, err := c.report.sample()
+	if err != nil {
+		return power, err
+	}
+	c.last = channels
+	now := time.Now()
+	elapsed := now.Sub(c.lastTime).Seconds()
+	c.lastTime = now
+	if elapsed <= 0 {
+		return power, nil
+	}
+
+	for _, ch := range channels {
+		if ch.Format != IOReportFormatSimple {
+			continue
+		}
+		watts := energyToJoules(ch.Value, ch.Unit) / elapsed
+
+		switch {
+		case strings.HasSuffix(ch.Name, "CPU Energy"):
+			power.CPU += watts
+		case ch.Name == "GPU Energy":
+			power.GPU += watts
+		case strings.HasPrefix(ch.Name, "ANE"):
+			power.ANE += watts
+		case strings.HasPrefix(ch.Name, "DRAM"):
+			power.DRAM += watts
+		}
+	}
+
+	// Matches powermetrics' "Combined Power (CPU + GPU + ANE)"
+	power.Package = power.CPU + power.GPU + power.ANE
+
+	return power, nil
+}
+
+// energyToJoules converts an energy counter to joules using its unit label
+func energyToJoules(value int64, unit string) float64 {
+	switch strings.TrimSpace(unit) {
+	case "mJ":
+		return float64(value) / 1e3
+	case "uJ", "µJ":
+		return float64(value) / 1e6
+	case "nJ":
+		return float64(value) / 1e9
+	}
+	return float64(value)
+}
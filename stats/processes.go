@@ -0,0 +1,223 @@
+package stats
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Process states from p_stat in sys/proc.h
+var processStates = map[int8]string{
+	1: "idle",
+	2: "running",
+	3: "sleeping",
+	4: "stopped",
+	5: "zombie",
+}
+
+// processCollector samples per-process resource usage. It keeps an entry per
+// live process so static fields are fetched once and usage percentages can
+// be derived from the CPU time seen in the previous sample.
+type processCollector struct {
+	entries  map[int]*procEntry
+	scan     uint64 // Number of the current scan, used to find exited processes
+	lastTime time.Time
+	numer    uint64
+	denom    uint64
+}
+
+// procEntry is what the collector remembers about a process between scans
+type procEntry struct {
+	start     time.Time
+	uid       uint32
+	comm      string // p_comm as last seen, changes when the process execs
+	path      string
+	coalition uint64        // Resource coalition, fixed when the process is spawned
+	cpu       time.Duration // CPU time at the previous scan
+	hasCPU    bool
+	denied    bool // proc_pidinfo was refused, so per-task counters are skipped
+	scan      uint64
+}
+
+func newProcessCollector() *processCollector {
+	numer, denom := machTimebase()
+	if denom == 0 {
+		numer, denom = 1, 1
+	}
+	return &processCollector{
+		entries: make(map[int]*procEntry),
+		numer:   uint64(numer),
+		denom:   uint64(denom),
+	}
+}
+
+// collectProcesses lists all processes with their current resource usage,
+// adding GPU usage, network rates and ports when they are on. The scan stops with
+// ctx's error once ctx is done.
+func (c *Collector) collectProcesses(ctx context.Context) ([]ProcessStats, error) {
+	procs, err := c.procs.collect(ctx, !c.SkipFDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// GPU usage is best effort; not every GPU driver reports client usage
+	if c.ProcessGPU {
+		if usage, err := c.procGPU.collect(); err == nil {
+			for i := range procs {
+				procs[i].GPU = usage[procs[i].PID]
+			}
+		}
+	}
+
+	// Network rates are best effort; nettop may be unavailable
+	if c.ProcessNet {
+		if rates, err := c.procNet.collect(ctx); err == nil {
+			for i := range procs {
+				if r, ok := rates[procs[i].PID]; ok {
+					procs[i].NetIn = r.In
+					procs[i].NetOut = r.Out
+				}
+			}
+		}
+	}
+
+	// Ports are best effort; other users' processes can't be inspected
+	if c.ProcessPorts {
+		for i := range procs {
+			if i%procScanBatch == 0 && ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			procs[i].Ports, _ = getProcPorts(procs[i].PID)
+		}
+	}
+	return procs, nil
+}
+
+// Processes scanned between context checks
+const procScanBatch = 256
+
+// collect scans every process, counting each one's open files if fds is set
+func (c *processCollector) collect(ctx context.Context, fds bool) ([]ProcessStats, error) {
+	kprocs, err := unix.SysctlKinfoProcSlice("kern.proc.all")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(c.lastTime)
+	c.scan++
+	added := 0
+	procs := make([]ProcessStats, 0, len(kprocs))
+
+	for i := range kprocs {
+		if i%procScanBatch == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		kp := &kprocs[i]
+		pid := int(kp.Proc.P_pid)
+		start := time.Unix(kp.Proc.P_starttime.Unix())
+		uid := kp.Eproc.Ucred.Uid
+
+		// A known pid with another start time was reused by a new process
+		e, ok := c.entries[pid]
+		if !ok || !e.start.Equal(start) {
+			e = &procEntry{start: start, uid: uid}
+			e.coalition, _ = getProcCoalition(pid)
+			c.entries[pid] = e
+			added++
+		}
+		if uid != e.uid {
+			e.uid, e.denied = uid, false
+		}
+		// The name and path only change on exec
+		if e.comm == "" || !commEqual(kp.Proc.P_comm[:], e.comm) {
+			e.comm = unix.ByteSliceToString(kp.Proc.P_comm[:])
+			e.path, _ = getProcPath(pid)
+		}
+		e.scan = c.scan
+
+		proc := ProcessStats{
+			PID:       pid,
+			PPID:      int(kp.Eproc.Ppid),
+			Name:      e.comm,
+			Path:      e.path,
+			UID:       uid,
+			State:     processStates[kp.Proc.P_stat],
+			StartTime: start,
+			Coalition: e.coalition,
+		}
+
+		// Task info is unavailable for other users' processes unless running
+		// as root, and so are the fd list and rusage; don't retry every scan
+		if !e.denied {
+			c.sampleTask(&proc, e, elapsed, fds)
+		}
+
+		procs = append(procs, proc)
+	}
+
+	// Only walk the cache for exited processes when the pid set changed
+	if added > 0 || len(c.entries) != len(kprocs) {
+		for pid, e := range c.entries {
+			if e.scan != c.scan {
+				delete(c.entries, pid)
+			}
+		}
+	}
+	c.lastTime = now
+
+	sort.Slice(procs, func(i, j int) bool { return procs[i].PID < procs[j].PID })
+
+	return procs, nil
+}
+
+// sampleTask fills in the counters that change every scan: memory, threads,
+// CPU time, open files if fds is set, disk I/O and energy
+func (c *processCollector) sampleTask(proc *ProcessStats, e *procEntry, elapsed time.Duration, fds bool) {
+	info, err := getProcTaskInfo(proc.PID)
+	if err != nil {
+		// A process exiting mid-scan fails too, but is gone by the next
+		e.denied = errors.Is(err, ErrPermissionDenied)
+		return
+	}
+	proc.RSS = info.ResidentSize
+	proc.Threads = int(info.ThreadCount)
+	proc.CPUTime = c.machToDuration(info.TotalUser + info.TotalSystem)
+
+	if e.hasCPU && elapsed > 0 && proc.CPUTime >= e.cpu {
+		proc.CPU = float64(proc.CPUTime-e.cpu) / float64(elapsed) * 100
+	}
+	e.cpu, e.hasCPU = proc.CPUTime, true
+
+	// Listing every descriptor is the costliest call per process
+	if fds {
+		if n, err := getProcFDCount(proc.PID); err == nil {
+			proc.FDs = n
+		}
+	}
+
+	if usage, err := ProcessRusage(proc.PID); err == nil {
+		proc.DiskRead = usage.DiskRead
+		proc.DiskWritten = usage.DiskWritten
+		proc.Energy = float64(usage.BilledEnergy) / 1e9
+	}
+}
+
+// commEqual reports whether a NUL-terminated p_comm buffer holds name,
+// without allocating a string for the comparison
+func commEqual(comm []byte, name string) bool {
+	if i := bytes.IndexByte(comm, 0); i >= 0 {
+		comm = comm[:i]
+	}
+	return string(comm) == name
+}
+
+// machToDuration converts mach absolute time units to a duration
+func (c *processCollector) machToDuration(t uint64) time.Duration {
+	return time.Duration(t * c.numer / c.denom)
+}
@@ -0,0 +1,18 @@
+package stats
+
+import "testing"
+
+func TestCommEqual(t *testing.T) {
+	comm := make([]byte, 17)
+	copy(comm, "launchd")
+
+	if !commEqual(comm, "launchd") {
+		t.Error("NUL-terminated name did not match")
+	}
+	if commEqual(comm, "launch") || commEqual(comm, "launchd2") {
+		t.Error("different name matched")
+	}
+	if !commEqual([]byte("sixteen-chars-ok"), "sixteen-chars-ok") {
+		t.Error("name filling the whole buffer did not match")
+	}
+}
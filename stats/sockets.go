@@ -0,0 +1,91 @@
+package stats
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// Record kinds in the pcblist_n sysctl output (XSO_* in netinet/in_pcb.h)
+const (
+	xsoInpcb = 0x10
+	xsoTcpcb = 0x20
+)
+
+// Offset of t_state in struct xtcpcb_n: xt_len, xt_kind, t_segq,
+// t_dupacks and t_timer[TCPT_NTIMERS_EXT] come before it
+const xtcpcbStateOffset = 36
+
+// Size of struct xinpgen, which opens and closes each pcblist
+const xinpgenSize = 24
+
+// TCPStates are the TCP states SocketStats counts, in the order of TCPS_*
+// from netinet/tcp_fsm.h
+var TCPStates = []string{
+	"CLOSED",
+	"LISTEN",
+	"SYN_SENT",
+	"SYN_RECEIVED",
+	"ESTABLISHED",
+	"CLOSE_WAIT",
+	"FIN_WAIT_1",
+	"CLOSING",
+	"LAST_ACK",
+	"FIN_WAIT_2",
+	"TIME_WAIT",
+}
+
+// collectSocketStats counts TCP sockets by state and open UDP sockets
+func collectSocketStats() (SocketStats, error) {
+	stats := SocketStats{TCP: make(map[string]int)}
+
+	err := walkPcbList("net.inet.tcp.pcblist_n", func(kind uint32, rec []byte) {
+		if kind != xsoTcpcb || len(rec) < xtcpcbStateOffset+4 {
+			return
+		}
+		state := int(int32(binary.LittleEndian.Uint32(rec[xtcpcbStateOffset:])))
+		if state >= 0 && state < len(TCPStates) {
+			stats.TCP[TCPStates[state]]++
+			stats.TCPTotal++
+		}
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	err = walkPcbList("net.inet.udp.pcblist_n", func(kind uint32, rec []byte) {
+		if kind == xsoInpcb {
+			stats.UDP++
+		}
+	})
+	return stats, err
+}
+
+// walkPcbList calls fn for each record of a pcblist_n sysctl. Each record
+// starts with its length and kind and is padded to 8 bytes.
+func walkPcbList(name string, fn func(kind uint32, rec []byte)) error {
+	buf, err := unix.SysctlRaw(name)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if len(buf) < xinpgenSize {
+		return nil
+	}
+
+	off := roundUp64(int(binary.LittleEndian.Uint32(buf)))
+	for off+8 <= len(buf) {
+		length := int(binary.LittleEndian.Uint32(buf[off:]))
+		if length <= xinpgenSize || off+length > len(buf) {
+			break
+		}
+		kind := binary.LittleEndian.Uint32(buf[off+4:])
+		fn(kind, buf[off:off+length])
+		off += roundUp64(length)
+	}
+	return nil
+}
+
+func roundUp64(n int) int {
+	return (n + 7) &^ 7
+}
@@ -0,0 +1,156 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Sampler takes the samples of a subscription. *Collector is one; a
+// Sampler that is also an io.Closer is closed when the subscription ends,
+// from the goroutine that called Collect.
+type Sampler interface {
+	Collect(ctx context.Context) (SystemStats, error)
+}
+
+// subscription holds what the options of Subscribe set
+type subscription struct {
+	collector    *Collector
+	groups       []string
+	processGPU   bool
+	processNet   bool
+	processPorts bool
+	sampler      Sampler
+}
+
+// Option configures a subscription started by Subscribe
+type Option func(*subscription) error
+
+// WithCollector samples with c, e.g. one whose MemoryAccounting is set,
+// instead of a new Collector. Nothing else may collect with c meanwhile.
+func WithCollector(c *Collector) Option {
+	return func(s *subscription) error {
+		s.collector = c
+		return nil
+	}
+}
+
+// WithGroups collects only the named groups of Groups, in their usual
+// order, instead of all of them
+func WithGroups(groups ...string) Option {
+	return func(s *subscription) error {
+		for _, g := range groups {
+			if !slices.Contains(Groups, g) {
+				return fmt.Errorf("unknown collector group %q (want %s)", g, strings.Join(Groups, ", "))
+			}
+		}
+		s.groups = append(s.groups, groups...)
+		return nil
+	}
+}
+
+// WithProcessGPU adds each process's GPU usage, as Collector.ProcessGPU
+func WithProcessGPU() Option {
+	return func(s *subscription) error {
+		s.processGPU = true
+		return nil
+	}
+}
+
+// WithProcessNet adds each process's network rates, as
+// Collector.ProcessNet
+func WithProcessNet() Option {
+	return func(s *subscription) error {
+		s.processNet = true
+		return nil
+	}
+}
+
+// WithProcessPorts adds the ports each process listens on, as
+// Collector.ProcessPorts
+func WithProcessPorts() Option {
+	return func(s *subscription) error {
+		s.processPorts = true
+		return nil
+	}
+}
+
+// WithSampler takes the samples from sampler instead of a Collector, for
+// programs scheduling the collector groups themselves. The collector
+// options do not apply to it.
+func WithSampler(sampler Sampler) Option {
+	return func(s *subscription) error {
+		s.sampler = sampler
+		return nil
+	}
+}
+
+// Subscribe collects a sample every interval, the first right away, and
+// pushes each one on the returned channel until ctx is done, when the
+// channel is closed. A consumer that falls behind gets the newest sample
+// rather than a backlog. Samples that fail to collect are skipped.
+//
+// Rates and usage need a previous sample, so they read zero in the first
+// sample of a new Collector.
+func Subscribe(ctx context.Context, interval time.Duration, opts ...Option) (<-chan SystemStats, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval %v must be positive", interval)
+	}
+	var sub subscription
+	for _, opt := range opts {
+		if err := opt(&sub); err != nil {
+			return nil, err
+		}
+	}
+	sampler := sub.sampler
+	if sampler == nil {
+		c := sub.collector
+		if c == nil {
+			c = NewCollector()
+		}
+		c.ProcessGPU = c.ProcessGPU || sub.processGPU
+		c.ProcessNet = c.ProcessNet || sub.processNet
+		c.ProcessPorts = c.ProcessPorts || sub.processPorts
+		sampler = groupSampler{c, sub.groups}
+	}
+
+	out := make(chan SystemStats, 1)
+	go func() {
+		defer close(out)
+		if closer, ok := sampler.(interface{ Close() error }); ok {
+			defer closer.Close()
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if stats, err := sampler.Collect(ctx); err == nil {
+				select {
+				case <-out:
+				default:
+				}
+				out <- stats
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// groupSampler collects some groups of a Collector, or all of them
+type groupSampler struct {
+	c      *Collector
+	groups []string
+}
+
+func (g groupSampler) Collect(ctx context.Context) (SystemStats, error) {
+	if len(g.groups) == 0 {
+		return g.c.Collect(ctx)
+	}
+	return g.c.collectGroups(ctx, g.groups)
+}
@@ -0,0 +1,65 @@
+package stats
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// countingSampler numbers its samples in Uptime and notes when it closes
+type countingSampler struct {
+	n      int
+	closed chan struct{}
+}
+
+func (s *countingSampler) Collect(ctx context.Context) (SystemStats, error) {
+	s.n++
+	return SystemStats{Uptime: time.Duration(s.n)}, nil
+}
+
+func (s *countingSampler) Close() error {
+	close(s.closed)
+	return nil
+}
+
+func TestSubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if _, err := Subscribe(ctx, 0); err == nil {
+		t.Error("zero interval accepted")
+	}
+	if _, err := Subscribe(ctx, time.Second, WithGroups("system", "gpu")); err == nil {
+		t.Error("unknown group accepted")
+	}
+
+	sampler := &countingSampler{closed: make(chan struct{})}
+	samples, err := Subscribe(ctx, time.Millisecond, WithSampler(sampler))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := <-samples
+	time.Sleep(20 * time.Millisecond)
+	// The reader fell behind, so it gets the newest sample
+	if latest := <-samples; latest.Uptime <= first.Uptime+1 {
+		t.Errorf("after falling behind got sample %d, want a newer one than %d", latest.Uptime, first.Uptime+1)
+	}
+
+	cancel()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-samples:
+			if ok {
+				continue
+			}
+			select {
+			case <-sampler.closed:
+			default:
+				t.Error("sampler not closed before the channel")
+			}
+			return
+		case <-timeout:
+			t.Fatal("channel not closed after cancel")
+		}
+	}
+}
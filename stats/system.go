@@ -0,0 +1,229 @@
+package stats
+
+import (
+	"fmt"
+	"time"
+)
+
+// VMStatistics is struct vm_statistics64 from mach/vm_statistics.h
+type VMStatistics struct {
+	FreeCount                          uint32
+	ActiveCount                        uint32
+	InactiveCount                      uint32
+	WireCount                          uint32
+	ZeroFillCount                      uint64
+	Reactivations                      uint64
+	Pageins                            uint64
+	Pageouts                           uint64
+	Faults                             uint64
+	CowFaults                          uint64
+	Lookups                            uint64
+	Hits                               uint64
+	Purges                             uint64
+	PurgeableCount                     uint32
+	SpeculativeCount                   uint32
+	Decompressions                     uint64
+	Compressions                       uint64
+	Swapins                            uint64
+	Swapouts                           uint64
+	CompressorPageCount                uint32
+	ThrottledCount                     uint32
+	ExternalPageCount                  uint32
+	InternalPageCount                  uint32
+	TotalUncompressedPagesInCompressor uint64
+}
+
+// nativeStats is everything read from the Mach kernel APIs each tick,
+// gathered in a single call by collectNativeStats
+type nativeStats struct {
+	VM       VMStatistics
+	VMFilled int        // Bytes of VM the kernel filled in
+	CPUTicks cpuTicks   // Ticks of all CPUs combined
+	Cores    []cpuTicks // Ticks of each CPU
+	LoadAvg  [3]float64
+	BootTime time.Time // Zero when unknown
+}
+
+// ReadVMStatistics calls host_statistics64 to get detailed VM statistics.
+// It also returns the fields the running kernel did not fill in, which are
+// left zero.
+func ReadVMStatistics() (*VMStatistics, []string, error) {
+	native, err := collectNativeStats()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &native.VM, vmStatsMissing(native.VMFilled), nil
+}
+
+// getPageSize gets the system page size using sysconf(_SC_PAGESIZE)
+func getPageSize() (uint64, error) {
+	// Fallback to checking vm.pagesize or using 4096
+	pageSize, err := SysctlNumber("hw.pagesize", "vm.pagesize")
+	if err != nil || pageSize == 0 {
+		return 4096, nil
+	}
+	return pageSize, nil
+}
+
+// collectKernel fills memory, CPU load and uptime from one native sample;
+// GPU stats are not collected yet
+func (c *Collector) collectKernel(stats *SystemStats) error {
+	// VM, CPU load and boot time come from one cgo call
+	native, err := collectNativeStats()
+	if err != nil {
+		return fmt.Errorf("failed to collect kernel stats: %w", err)
+	}
+
+	stats.Memory, err = c.collectMemory(native)
+	if err != nil {
+		return fmt.Errorf("failed to collect memory stats: %w", err)
+	}
+
+	clusters, freqs := stats.CPU.Clusters, stats.CPU.CoreFreqs
+	stats.CPU = c.cpu.collect(native)
+	stats.CPU.Clusters, stats.CPU.CoreFreqs = clusters, freqs
+	stats.GPU = GPUStats{}
+
+	stats.Uptime = 0
+	if !native.BootTime.IsZero() {
+		stats.Uptime = time.Since(native.BootTime)
+	}
+	return nil
+}
+
+// collectMemory derives memory usage from a native sample and sysctls
+func (c *Collector) collectMemory(native *nativeStats) (MemoryStats, error) {
+	var memStats MemoryStats
+
+	// Get total physical memory using sysctl
+	physmem, err := SysctlNumber("hw.memsize", "hw.physmem")
+	if err != nil {
+		return memStats, fmt.Errorf("failed to get physical memory: %w", err)
+	}
+
+	// Get page size
+	pageSize, err := getPageSize()
+	if err != nil {
+		return memStats, fmt.Errorf("failed to get page size: %w", err)
+	}
+
+	// VM statistics from host_statistics64
+	vmStats, missing := &native.VM, vmStatsMissing(native.VMFilled)
+
+	// Calculate total pages for validation
+	totalPages := physmem / pageSize
+
+	counters := MemoryCounters{
+		PageSize:    pageSize,
+		TotalPages:  totalPages,
+		Free:        uint64(vmStats.FreeCount),
+		Active:      uint64(vmStats.ActiveCount),
+		Inactive:    uint64(vmStats.InactiveCount),
+		Wired:       uint64(vmStats.WireCount),
+		Speculative: uint64(vmStats.SpeculativeCount),
+		Compressed:  uint64(vmStats.CompressorPageCount),
+		Purgeable:   uint64(vmStats.PurgeableCount),
+		External:    uint64(vmStats.ExternalPageCount),
+		Internal:    uint64(vmStats.InternalPageCount),
+	}
+
+	usedPages, availablePages, warnings := deriveMemoryPages(counters, c.MemoryAccounting)
+
+	memStats.Total = physmem
+	memStats.Used = usedPages * pageSize
+	memStats.Available = availablePages * pageSize
+	memStats.Usage = float64(memStats.Used) / float64(memStats.Total) * 100
+	memStats.Accounting = c.MemoryAccounting
+	memStats.Warnings = warnings
+	for _, field := range missing {
+		memStats.Warnings = append(memStats.Warnings,
+			fmt.Sprintf("vm_statistics64.%s not provided by this kernel; treated as 0", field))
+	}
+	memStats.Raw = counters
+
+	// Get memory pressure level
+	memStats.Pressure, _ = collectMemoryPressure()
+
+	// Get swap information
+	memStats.Swap, _ = collectSwapStats()
+	memStats.Swap.Rate = c.swapRate(vmStats.Swapins+vmStats.Swapouts, pageSize)
+
+	return memStats, nil
+}
+
+// deriveMemoryPages computes used and available pages from the raw
+// counters. Instead of silently clamping, it reports every inconsistency
+// found in the counters along with the correction applied.
+func deriveMemoryPages(c MemoryCounters, accounting MemoryAccounting) (used, available uint64, warnings []string) {
+	var signedUsed int64
+	switch accounting {
+	case AccountingActivityMonitor:
+		// Activity Monitor: App Memory (internal - purgeable) + Wired + Compressed
+		signedUsed = int64(c.Internal) - int64(c.Purgeable) + int64(c.Wired) + int64(c.Compressed)
+	default:
+		// Used memory = active + inactive + wired + speculative + compressed - purgeable - external
+		signedUsed = int64(c.Active) + int64(c.Inactive) + int64(c.Wired) +
+			int64(c.Speculative) + int64(c.Compressed) -
+			int64(c.Purgeable) - int64(c.External)
+	}
+
+	if signedUsed < 0 {
+		warnings = append(warnings, fmt.Sprintf("used pages went negative (%d); clamped to 0", signedUsed))
+		signedUsed = 0
+	}
+	used = uint64(signedUsed)
+
+	if used+c.Free > c.TotalPages {
+		clamped := uint64(0)
+		if c.Free < c.TotalPages {
+			clamped = c.TotalPages - c.Free
+		}
+		warnings = append(warnings, fmt.Sprintf("used + free pages (%d) exceed physical pages (%d); used clamped from %d to %d",
+			used+c.Free, c.TotalPages, used, clamped))
+		used = clamped
+	}
+
+	// Available memory = free + inactive + purgeable
+	available = c.Free + c.Inactive + c.Purgeable
+	if available > c.TotalPages {
+		warnings = append(warnings, fmt.Sprintf("available pages (%d) exceed physical pages (%d); clamped",
+			available, c.TotalPages))
+		available = c.TotalPages
+	}
+
+	return used, available, warnings
+}
+
+// collectMemoryPressure reads the kernel's memory pressure level
+func collectMemoryPressure() (MemoryPressure, error) {
+	level, err := SysctlNumber("kern.memorystatus_vm_pressure_level")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get memory pressure level: %w", err)
+	}
+	return MemoryPressure(level), nil
+}
+
+func collectSwapStats() (SwapStats, error) {
+	var swapStats SwapStats
+	return swapStats, nil
+}
+
+// swapSampler holds the cumulative pages swapped in and out at a reading
+type swapSampler struct {
+	pages uint64
+	at    time.Time
+}
+
+// swapRate turns the cumulative pages swapped in and out into bytes per
+// second since the previous call
+func (c *Collector) swapRate(pages, pageSize uint64) float64 {
+	now := time.Now()
+	var rate float64
+	if prev := c.swap; prev != nil && pages >= prev.pages {
+		if elapsed := now.Sub(prev.at); elapsed > 0 {
+			rate = float64((pages-prev.pages)*pageSize) / elapsed.Seconds()
+		}
+	}
+	c.swap = &swapSampler{pages, now}
+	return rate
+}
@@ -0,0 +1,17 @@
+package stats
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework Foundation
+#import <Foundation/Foundation.h>
+
+int getThermalState(void) {
+    return (int)[[NSProcessInfo processInfo] thermalState];
+}
+*/
+import "C"
+
+// collectThermalState reads the system thermal state from NSProcessInfo
+func collectThermalState() ThermalState {
+	return ThermalState(C.getThermalState())
+}
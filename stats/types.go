@@ -0,0 +1,315 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SystemStats represents current system resource usage
+type SystemStats struct {
+	CPU        CPUStats         `json:"cpu"`
+	Memory     MemoryStats      `json:"memory"`
+	GPU        GPUStats         `json:"gpu"`
+	Uptime     time.Duration    `json:"uptime"`
+	Thermal    ThermalState     `json:"thermal"`
+	Power      PowerStats       `json:"power"`
+	Network    NetworkStats     `json:"network"`
+	Disk       DiskStats        `json:"disk"`
+	Processes  []ProcessStats   `json:"processes"`
+	Coalitions []CoalitionStats `json:"coalitions,omitempty"` // Resource coalition accounting, while grouping by coalition
+
+	// Readings of the exec plugins configured in mtop, by plugin and
+	// metric name. Collector leaves it empty.
+	Plugins map[string]map[string]float64 `json:"plugins,omitempty"`
+}
+
+// ThermalState mirrors NSProcessInfoThermalState
+type ThermalState int
+
+const (
+	ThermalNominal ThermalState = iota
+	ThermalFair
+	ThermalSerious
+	ThermalCritical
+)
+
+func (t ThermalState) String() string {
+	switch t {
+	case ThermalNominal:
+		return "Nominal"
+	case ThermalFair:
+		return "Fair"
+	case ThermalSerious:
+		return "Serious"
+	case ThermalCritical:
+		return "Critical"
+	}
+	return "Unknown"
+}
+
+// MarshalText encodes the thermal state as a lowercase name in JSON output
+func (t ThermalState) MarshalText() ([]byte, error) {
+	return []byte(strings.ToLower(t.String())), nil
+}
+
+// UnmarshalText decodes a thermal state written by MarshalText
+func (t *ThermalState) UnmarshalText(text []byte) error {
+	for s := ThermalNominal; s <= ThermalCritical; s++ {
+		if strings.EqualFold(string(text), s.String()) {
+			*t = s
+			return nil
+		}
+	}
+	if strings.EqualFold(string(text), "unknown") {
+		*t = -1
+		return nil
+	}
+	return fmt.Errorf("unknown thermal state %q", text)
+}
+
+// CPUStats holds CPU usage information
+type CPUStats struct {
+	Usage   float64    `json:"usage"`    // Overall CPU usage percentage
+	Cores   []float64  `json:"cores"`    // Per-core usage percentages
+	LoadAvg [3]float64 `json:"load_avg"` // 1, 5, 15 minute load averages
+	Temp    float64    `json:"temp"`     // CPU temperature in Celsius
+
+	Clusters  []ClusterStats `json:"clusters"`   // Per-cluster frequency and residency
+	CoreFreqs []float64      `json:"core_freqs"` // Per-core frequency in MHz
+}
+
+// ClusterStats holds frequency information for a CPU cluster
+type ClusterStats struct {
+	Name      string  `json:"name"`      // Cluster name, e.g. ECPU or PCPU
+	Frequency float64 `json:"frequency"` // Average frequency in MHz
+	Active    float64 `json:"active"`    // Percentage of time not idle
+}
+
+// MemoryStats holds memory usage information
+type MemoryStats struct {
+	Total     uint64         `json:"total"`     // Total memory in bytes
+	Used      uint64         `json:"used"`      // Used memory in bytes
+	Available uint64         `json:"available"` // Available memory in bytes
+	Usage     float64        `json:"usage"`     // Memory usage percentage
+	Pressure  MemoryPressure `json:"pressure"`  // Kernel memory pressure level
+	Swap      SwapStats      `json:"swap"`
+
+	Accounting MemoryAccounting `json:"accounting"`         // Formula used to derive Used
+	Warnings   []string         `json:"warnings,omitempty"` // Inconsistencies found in the raw counters
+	Raw        MemoryCounters   `json:"-"`                  // Raw page counts, for the debug view
+}
+
+// MemoryCounters holds the raw page counts memory usage is derived from
+type MemoryCounters struct {
+	PageSize    uint64
+	TotalPages  uint64
+	Free        uint64
+	Active      uint64
+	Inactive    uint64
+	Wired       uint64
+	Speculative uint64
+	Compressed  uint64
+	Purgeable   uint64
+	External    uint64
+	Internal    uint64
+}
+
+// MemoryAccounting selects the formula for used memory
+type MemoryAccounting int
+
+const (
+	AccountingDefault         MemoryAccounting = iota // mtop's formula, close to top
+	AccountingActivityMonitor                         // Matches Activity Monitor's Memory Used
+)
+
+func (a MemoryAccounting) String() string {
+	if a == AccountingActivityMonitor {
+		return "activity-monitor"
+	}
+	return "default"
+}
+
+// MarshalText encodes the accounting mode by name in JSON output
+func (a MemoryAccounting) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText decodes an accounting mode written by MarshalText
+func (a *MemoryAccounting) UnmarshalText(text []byte) error {
+	mode, err := ParseMemoryAccounting(string(text))
+	*a = mode
+	return err
+}
+
+// ParseMemoryAccounting parses an accounting mode by name: default or
+// activity-monitor
+func ParseMemoryAccounting(s string) (MemoryAccounting, error) {
+	switch s {
+	case "default", "":
+		return AccountingDefault, nil
+	case "activity-monitor":
+		return AccountingActivityMonitor, nil
+	}
+	return 0, fmt.Errorf("unknown memory mode %q (want default or activity-monitor)", s)
+}
+
+// MemoryPressure mirrors kern.memorystatus_vm_pressure_level
+type MemoryPressure int
+
+const (
+	PressureNormal   MemoryPressure = 1
+	PressureWarn     MemoryPressure = 2
+	PressureCritical MemoryPressure = 4
+)
+
+func (p MemoryPressure) String() string {
+	switch p {
+	case PressureNormal:
+		return "Normal"
+	case PressureWarn:
+		return "Warn"
+	case PressureCritical:
+		return "Critical"
+	}
+	return "Unknown"
+}
+
+// MarshalText encodes the pressure level as a lowercase name in JSON output
+func (p MemoryPressure) MarshalText() ([]byte, error) {
+	return []byte(strings.ToLower(p.String())), nil
+}
+
+// UnmarshalText decodes a pressure level written by MarshalText. Unknown
+// levels decode as 0, which MarshalText writes as "unknown".
+func (p *MemoryPressure) UnmarshalText(text []byte) error {
+	*p = 0
+	for _, level := range []MemoryPressure{PressureNormal, PressureWarn, PressureCritical} {
+		if strings.EqualFold(string(text), level.String()) {
+			*p = level
+		}
+	}
+	return nil
+}
+
+// SwapStats holds swap usage information
+type SwapStats struct {
+	Total uint64  `json:"total"` // Total swap in bytes
+	Used  uint64  `json:"used"`  // Used swap in bytes
+	Usage float64 `json:"usage"` // Swap usage percentage
+	Rate  float64 `json:"rate"`  // Bytes swapped in and out per second since the previous sample
+}
+
+// GPUStats holds GPU usage information
+type GPUStats struct {
+	Usage       float64 `json:"usage"`        // GPU usage percentage
+	MemoryUsage float64 `json:"memory_usage"` // GPU memory usage percentage
+	MemoryUsed  uint64  `json:"memory_used"`  // GPU memory used in bytes
+	MemoryTotal uint64  `json:"memory_total"` // Total GPU memory in bytes
+	Temp        float64 `json:"temp"`         // GPU temperature in Celsius
+}
+
+// PowerStats holds power draw in watts
+type PowerStats struct {
+	CPU     float64 `json:"cpu"`     // CPU power in watts
+	GPU     float64 `json:"gpu"`     // GPU power in watts
+	ANE     float64 `json:"ane"`     // Neural Engine power in watts
+	DRAM    float64 `json:"dram"`    // DRAM power in watts
+	Package float64 `json:"package"` // Combined CPU + GPU + ANE power in watts
+}
+
+// DiskStats holds disk activity across all drives
+type DiskStats struct {
+	IOTime  time.Duration `json:"io_time"`           // Time spent on reads and writes since boot
+	Busy    float64       `json:"busy"`              // Percentage of the time since the previous sample spent on I/O
+	Devices []DiskDevice  `json:"devices,omitempty"` // Each drive, by BSD name
+}
+
+// DiskDevice holds the activity of one drive
+type DiskDevice struct {
+	Name      string  `json:"name"`       // BSD name of the whole disk, e.g. disk0
+	Read      uint64  `json:"read"`       // Bytes read since boot
+	Written   uint64  `json:"written"`    // Bytes written since boot
+	ReadRate  float64 `json:"read_rate"`  // Bytes read per second since the previous sample
+	WriteRate float64 `json:"write_rate"` // Bytes written per second since the previous sample
+	Busy      float64 `json:"busy"`       // Percentage of the time since the previous sample spent on I/O
+}
+
+// NetworkStats holds network information
+type NetworkStats struct {
+	WiFi       *WiFiStats       `json:"wifi,omitempty"` // Nil when there is no Wi-Fi interface
+	Sockets    SocketStats      `json:"sockets"`
+	Interfaces []InterfaceStats `json:"interfaces,omitempty"` // Each network interface, by BSD name
+}
+
+// InterfaceStats holds the traffic of one network interface
+type InterfaceStats struct {
+	Name     string  `json:"name"`     // BSD name, e.g. en0
+	Up       bool    `json:"up"`       // Whether the interface is up
+	Received uint64  `json:"received"` // Bytes received since boot
+	Sent     uint64  `json:"sent"`     // Bytes sent since boot
+	RxRate   float64 `json:"rx_rate"`  // Bytes received per second since the previous sample
+	TxRate   float64 `json:"tx_rate"`  // Bytes sent per second since the previous sample
+}
+
+// SocketStats holds open socket counts
+type SocketStats struct {
+	TCP      map[string]int `json:"tcp"`       // TCP sockets by state, e.g. ESTABLISHED
+	TCPTotal int            `json:"tcp_total"` // Total TCP sockets
+	UDP      int            `json:"udp"`       // Total UDP sockets
+}
+
+// WiFiStats holds the state of the Wi-Fi interface
+type WiFiStats struct {
+	Interface    string  `json:"interface"`     // BSD interface name, e.g. en0
+	SSID         string  `json:"ssid"`          // Network name; empty without location permission
+	BSSID        string  `json:"bssid"`         // Access point hardware address
+	PowerOn      bool    `json:"power_on"`      // Whether the radio is on
+	RSSI         int     `json:"rssi"`          // Received signal strength in dBm
+	Noise        int     `json:"noise"`         // Noise floor in dBm
+	Channel      int     `json:"channel"`       // Channel number
+	Band         string  `json:"band"`          // Channel band, e.g. 5 GHz
+	ChannelWidth int     `json:"channel_width"` // Channel width in MHz
+	PHYMode      string  `json:"phy_mode"`      // Active PHY mode, e.g. 802.11ax
+	TxRate       float64 `json:"tx_rate"`       // Transmit rate in Mbps
+}
+
+// ProcessStats holds resource usage for a single process
+type ProcessStats struct {
+	PID       int           `json:"pid"`
+	PPID      int           `json:"ppid"`
+	Name      string        `json:"name"`
+	Path      string        `json:"path"` // Executable path
+	UID       uint32        `json:"uid"`
+	CPU       float64       `json:"cpu"`        // CPU usage percentage since the previous sample
+	CPUTime   time.Duration `json:"cpu_time"`   // Cumulative CPU time
+	RSS       uint64        `json:"rss"`        // Resident memory in bytes
+	Threads   int           `json:"threads"`    // Number of threads
+	FDs       int           `json:"fds"`        // Open file descriptors
+	State     string        `json:"state"`      // Scheduling state (running, sleeping, ...)
+	StartTime time.Time     `json:"start_time"` // Process start time
+
+	DiskRead    uint64  `json:"disk_read"`    // Cumulative bytes read from disk
+	DiskWritten uint64  `json:"disk_written"` // Cumulative bytes written to disk
+	Energy      float64 `json:"energy"`       // Cumulative energy billed in joules
+	GPU         float64 `json:"gpu"`          // GPU usage percentage since the previous sample
+	NetIn       float64 `json:"net_in"`       // Network receive rate in bytes per second
+	NetOut      float64 `json:"net_out"`      // Network send rate in bytes per second
+	Coalition   uint64  `json:"coalition"`    // ID of the resource coalition the process runs in, 0 if unknown
+
+	Ports []int `json:"ports,omitempty"` // TCP ports listened on and UDP ports bound, sorted
+}
+
+// CoalitionStats is the kernel's accounting for a resource coalition: an
+// app or launchd job together with the XPC services and helpers spawned on
+// its behalf, including members that have already exited
+type CoalitionStats struct {
+	ID          uint64        `json:"id"`
+	CPU         float64       `json:"cpu"`          // CPU usage percentage since the previous sample
+	CPUTime     time.Duration `json:"cpu_time"`     // Cumulative CPU time
+	GPU         float64       `json:"gpu"`          // GPU usage percentage since the previous sample
+	Energy      float64       `json:"energy"`       // Cumulative energy billed in joules
+	DiskRead    uint64        `json:"disk_read"`    // Cumulative bytes read from disk
+	DiskWritten uint64        `json:"disk_written"` // Cumulative bytes written to disk
+	Exited      int           `json:"exited"`       // Members that have exited
+}
@@ -0,0 +1,98 @@
+package stats
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework CoreWLAN -framework Foundation
+#import <CoreWLAN/CoreWLAN.h>
+#include <string.h>
+
+typedef struct {
+    char ifname[32];
+    char ssid[64];
+    char bssid[32];
+    int powerOn;
+    int rssi;
+    int noise;
+    int channel;
+    int band;
+    int width;
+    int phyMode;
+    double txRate;
+} wifi_info_t;
+
+static void copyString(NSString *s, char *buf, size_t len) {
+    buf[0] = 0;
+    if (s != nil) {
+        strlcpy(buf, [s UTF8String], len);
+    }
+}
+
+int getWiFiInfo(wifi_info_t *info) {
+    @autoreleasepool {
+        CWInterface *iface = [[CWWiFiClient sharedWiFiClient] interface];
+        if (iface == nil) {
+            return -1;
+        }
+        memset(info, 0, sizeof(*info));
+        copyString([iface interfaceName], info->ifname, sizeof(info->ifname));
+        copyString([iface ssid], info->ssid, sizeof(info->ssid));
+        copyString([iface bssid], info->bssid, sizeof(info->bssid));
+        info->powerOn = [iface powerOn] ? 1 : 0;
+        info->rssi = (int)[iface rssiValue];
+        info->noise = (int)[iface noiseMeasurement];
+        CWChannel *ch = [iface wlanChannel];
+        if (ch != nil) {
+            info->channel = (int)[ch channelNumber];
+            info->band = (int)[ch channelBand];
+            info->width = (int)[ch channelWidth];
+        }
+        info->phyMode = (int)[iface activePHYMode];
+        info->txRate = [iface transmitRate];
+    }
+    return 0;
+}
+*/
+import "C"
+import (
+	"fmt"
+)
+
+// CWChannelBand values
+var wifiBands = map[int]string{1: "2.4 GHz", 2: "5 GHz", 3: "6 GHz"}
+
+// CWChannelWidth values in MHz
+var wifiWidths = map[int]int{1: 20, 2: 40, 3: 80, 4: 160}
+
+// CWPHYMode values
+var wifiPHYModes = map[int]string{
+	1: "802.11a",
+	2: "802.11b",
+	3: "802.11g",
+	4: "802.11n",
+	5: "802.11ac",
+	6: "802.11ax",
+	7: "802.11be",
+}
+
+// collectWiFiStats reads the state of the default Wi-Fi interface from CoreWLAN
+func collectWiFiStats() (*WiFiStats, error) {
+	var info C.wifi_info_t
+
+	if C.getWiFiInfo(&info) != 0 {
+		return nil, fmt.Errorf("no Wi-Fi interface found: %w", ErrSensorUnavailable)
+	}
+
+	return &WiFiStats{
+		Interface:    C.GoString(&info.ifname[0]),
+		SSID:         C.GoString(&info.ssid[0]),
+		BSSID:        C.GoString(&info.bssid[0]),
+		PowerOn:      info.powerOn != 0,
+		RSSI:         int(info.rssi),
+		Noise:        int(info.noise),
+		Channel:      int(info.channel),
+		Band:         wifiBands[int(info.band)],
+		ChannelWidth: wifiWidths[int(info.width)],
+		PHYMode:      wifiPHYModes[int(info.phyMode)],
+		TxRate:       float64(info.txRate),
+	}, nil
+}
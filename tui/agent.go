@@ -0,0 +1,257 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/khoi/mtop/shm"
+)
+
+// Geometry of the ring the agent writes: a few slots so a reader copying
+// one record is not lapped, each large enough for a full process list
+const (
+	agentSlots     = 4
+	agentMaxRecord = 2 << 20
+)
+
+// A reader treats an agent that has not written for this long as stopped
+const agentStale = 5 * time.Second
+
+// sampleRingPath is the per-user ring file the agent writes and readers
+// attach to
+func sampleRingPath() string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("mtop-%d.ring", os.Getuid()))
+}
+
+// runAgent implements "mtop agent": collect samples in the background and
+// publish them for other mtop processes to read
+func runAgent(args []string) int {
+	fs := flag.NewFlagSet("agent", flag.ExitOnError)
+	interval := fs.Duration("interval", time.Second, "How often to collect a sample")
+	path := fs.String("ring", sampleRingPath(), "Path of the shared sample ring")
+	socketPath := fs.String("socket", agentSocketPath(), "Path of the socket attaching TUIs read the history from")
+	window := fs.Duration("history", defaultAgentHistory, "How much metric history to keep for TUIs that attach later")
+	metricsAddr := fs.String("metrics", "", "Serve mtop's own metrics for Prometheus at this address (e.g. 127.0.0.1:9273)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s agent [OPTIONS]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Collects samples and shares them with other mtop processes on this machine,\n")
+		fmt.Fprintf(os.Stderr, "which read them instead of collecting their own unless started with --local.\n")
+		fmt.Fprintf(os.Stderr, "It also keeps a history of the samples, which a TUI starts its charts with when\n")
+		fmt.Fprintf(os.Stderr, "it attaches, so closing the terminal loses nothing.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *interval < minRefreshRate {
+		fmt.Fprintf(os.Stderr, "Invalid --interval: must be at least %v\n", minRefreshRate)
+		return 2
+	}
+	if *window <= 0 {
+		fmt.Fprintf(os.Stderr, "Invalid --history: must be positive\n")
+		return 2
+	}
+
+	w, err := shm.Create(*path, agentSlots, agentMaxRecord)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", *path, err)
+		return 1
+	}
+	defer w.Close()
+
+	hist := newAgentHistory(*window, *interval)
+	ln, err := listenAgentSocket(*socketPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listening on %s: %v\n", *socketPath, err)
+		return 1
+	}
+	defer os.Remove(*socketPath)
+	defer ln.Close()
+	go func() {
+		if err := hist.serve(ln); err != nil {
+			fmt.Fprintf(os.Stderr, "Error serving history: %v\n", err)
+		}
+	}()
+
+	// A signal also interrupts a collection in progress
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	// Readers may show any panel, so run every collector
+	subscribe("agent", onDemandCollectors...)
+	if *metricsAddr != "" {
+		go func() {
+			if err := serveInternalMetrics(*metricsAddr); err != nil {
+				fmt.Fprintf(os.Stderr, "Error serving metrics: %v\n", err)
+			}
+		}()
+	}
+
+	sched := newScheduler()
+	var prev time.Time
+	for now := time.Now(); ; {
+		err := publishSample(ctx, w, hist, sched, now, *interval)
+		if ctx.Err() != nil {
+			return 0
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error publishing sample: %v\n", err)
+		}
+		internals.sampled(err == nil, missedTicks(prev, now, *interval, err))
+		prev = now
+		select {
+		case now = <-ticker.C:
+		case <-ctx.Done():
+			return 0
+		}
+	}
+}
+
+// missedTicks counts the samples lost before the one at now: ticks the
+// ticker dropped while the previous collection overran, plus this one if
+// it failed
+func missedTicks(prev, now time.Time, interval time.Duration, err error) uint64 {
+	var missed uint64
+	if !prev.IsZero() {
+		if gap := now.Sub(prev); gap > interval+interval/2 {
+			missed = uint64((gap+interval/2)/interval - 1)
+		}
+	}
+	if err != nil {
+		missed++
+	}
+	return missed
+}
+
+// publishSample collects a snapshot, records it in the history and
+// appends it to the ring
+func publishSample(ctx context.Context, w *shm.Writer, hist *agentHistory, sched *scheduler, now time.Time, interval time.Duration) error {
+	stats, err := sched.collect(ctx, now, interval)
+	if err != nil {
+		return err
+	}
+	hist.record(now, stats)
+	record, err := encodeSample(stats)
+	if err != nil {
+		return err
+	}
+	return w.Write(record)
+}
+
+func encodeSample(stats SystemStats) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(stats); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeSample(record []byte) (SystemStats, error) {
+	var stats SystemStats
+	err := gob.NewDecoder(bytes.NewReader(record)).Decode(&stats)
+	return stats, err
+}
+
+// agentReader reads the samples a running agent publishes
+type agentReader struct {
+	ring  *shm.Reader
+	seq   uint64 // Sequence number of the decoded sample
+	stats SystemStats
+	beat  time.Time // Newest heartbeat read from the ring
+	seen  time.Time // When the heartbeat last changed, on the local monotonic clock
+}
+
+// attachAgent opens the ring at path if an agent is writing it
+func attachAgent(path string) (*agentReader, error) {
+	ring, err := shm.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errNoAgent
+	}
+	if err != nil {
+		return nil, err
+	}
+	a := &agentReader{ring: ring}
+	if err := a.check(); err != nil {
+		ring.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+// check reports an agent that stopped writing. The heartbeat is a wall
+// time written by another process, so only the first check compares it
+// with the clock; after that the agent is stale when the heartbeat has not
+// changed for agentStale, which setting the wall clock cannot fake.
+func (a *agentReader) check() error {
+	pid, beat := a.ring.Owner()
+	now := time.Now()
+	switch {
+	case a.seen.IsZero():
+		a.seen = now.Add(-max(now.Sub(beat), 0))
+	case !beat.Equal(a.beat):
+		a.seen = now
+	}
+	a.beat = beat
+	if age := now.Sub(a.seen); age > agentStale {
+		return fmt.Errorf("agent %d stopped writing %v ago", pid, age.Round(time.Second))
+	}
+	return nil
+}
+
+// latest returns the newest sample, decoding it only when the agent has
+// written a new one
+func (a *agentReader) latest() (SystemStats, error) {
+	if err := a.check(); err != nil {
+		return SystemStats{}, err
+	}
+	if seq := a.ring.Seq(); seq != 0 && seq == a.seq {
+		return a.stats, nil
+	}
+	record, seq, err := a.ring.Latest()
+	if err != nil {
+		return SystemStats{}, err
+	}
+	stats, err := decodeSample(record)
+	if err != nil {
+		return SystemStats{}, fmt.Errorf("failed to decode sample %d: %w", seq, err)
+	}
+	a.seq, a.stats = seq, stats
+	return stats, nil
+}
+
+func (a *agentReader) close() error {
+	return a.ring.Close()
+}
+
+// agentNote marks the header while samples come from an agent
+func (m model) agentNote() string {
+	if m.agent == nil {
+		return ""
+	}
+	pid, _ := m.agent.ring.Owner()
+	return fmt.Sprintf(" (agent %d)", pid)
+}
+
+// agentSample reads one sample from a running agent, for one-shot output
+func agentSample(path string) (SystemStats, error) {
+	a, err := attachAgent(path)
+	if err != nil {
+		return SystemStats{}, err
+	}
+	defer a.close()
+	return a.latest()
+}
+
+// errNoAgent is returned when there is no ring for an agent to write
+var errNoAgent = errors.New("no mtop agent is running; start one with \"mtop agent\"")
@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/khoi/mtop/shm"
+)
+
+func TestSampleRoundTrip(t *testing.T) {
+	stats := SystemStats{
+		CPU:     CPUStats{Usage: 42.5, Cores: []float64{10, 75}, LoadAvg: [3]float64{1, 2, 3}},
+		Memory:  MemoryStats{Total: 16 << 30, Used: 8 << 30, Usage: 50, Pressure: PressureWarn},
+		Thermal: ThermalSerious,
+		Uptime:  time.Hour,
+		Processes: []ProcessStats{
+			{PID: 1, Name: "launchd", CPU: 0.5, RSS: 12 << 20},
+		},
+	}
+	record, err := encodeSample(stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeSample(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, stats) {
+		t.Errorf("decoded %+v, want %+v", got, stats)
+	}
+}
+
+func TestAgentReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	if _, err := attachAgent(path); err != errNoAgent {
+		t.Fatalf("attach without a ring = %v, want errNoAgent", err)
+	}
+
+	w, err := shm.Create(path, 2, 4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	a, err := attachAgent(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.close()
+	if _, err := a.latest(); err == nil {
+		t.Error("latest before the first sample succeeded")
+	}
+
+	record, _ := encodeSample(SystemStats{CPU: CPUStats{Usage: 12}})
+	if err := w.Write(record); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := a.latest()
+	if err != nil || stats.CPU.Usage != 12 {
+		t.Errorf("latest = %v, %v; want CPU 12", stats.CPU.Usage, err)
+	}
+
+	want := fmt.Sprintf(" (agent %d)", os.Getpid())
+	if note := (model{agent: a}).agentNote(); note != want {
+		t.Errorf("header note = %q, want %q", note, want)
+	}
+}
+
+func TestAgentStaleByHeartbeat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	w, err := shm.Create(path, 2, 4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	a, err := attachAgent(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.close()
+
+	// An unchanged heartbeat goes stale on the local clock, whatever the
+	// wall time written in it says
+	a.seen = time.Now().Add(-agentStale - time.Second)
+	if err := a.check(); err == nil {
+		t.Error("check passed with a heartbeat unchanged for longer than agentStale")
+	}
+	record, _ := encodeSample(SystemStats{})
+	if err := w.Write(record); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.check(); err != nil {
+		t.Errorf("check after a new heartbeat = %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/khoi/mtop/history"
+)
+
+// How much history the agent keeps by default for TUIs that attach later
+const defaultAgentHistory = time.Hour
+
+// How long a request on the agent socket may take
+const agentSocketTimeout = 5 * time.Second
+
+// agentSocketPath is the per-user socket the agent serves its history on,
+// next to the sample ring
+func agentSocketPath() string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("mtop-%d.sock", os.Getuid()))
+}
+
+// agentHistory is the metric history of every sample the agent publishes.
+// A TUI that attaches reads it over the socket, so its charts start with
+// what happened while no terminal was open.
+type agentHistory struct {
+	mu    sync.Mutex
+	store *history.Store
+}
+
+func newAgentHistory(retention, interval time.Duration) *agentHistory {
+	return &agentHistory{store: history.New(retention, interval)}
+}
+
+func (h *agentHistory) record(t time.Time, stats SystemStats) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.store.Record(t, historyMetrics(stats))
+}
+
+// listenAgentSocket listens on path, replacing a socket left behind by an
+// agent that is gone but not one another agent still serves
+func listenAgentSocket(path string) (net.Listener, error) {
+	if conn, err := net.DialTimeout("unix", path, agentSocketTimeout); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("another agent is serving %s", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	// Samples list every process's command line; keep them to this user
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// serve answers the requests of each connection to ln until ln is closed.
+// A request is one line naming what to send; "history" sends the history
+// in the format --keep-history saves.
+func (h *agentHistory) serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go h.handle(conn)
+	}
+}
+
+func (h *agentHistory) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(agentSocketTimeout))
+	request, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return
+	}
+	switch strings.TrimSpace(request) {
+	case "history":
+		// Encode under the lock, then write without holding up sampling
+		var buf bytes.Buffer
+		h.mu.Lock()
+		err := h.store.Save(&buf)
+		h.mu.Unlock()
+		if err == nil {
+			conn.Write(buf.Bytes())
+		}
+	}
+}
+
+// fetchAgentHistory adds the history of the agent serving path to store,
+// skipping samples before since
+func fetchAgentHistory(path string, store *history.Store, since time.Time) error {
+	conn, err := net.DialTimeout("unix", path, agentSocketTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(agentSocketTimeout))
+	if _, err := fmt.Fprintln(conn, "history"); err != nil {
+		return err
+	}
+	return store.Restore(conn, since)
+}
+
+// restoreAgentHistory starts the model's history with the agent's, within
+// the retention window of now
+func (m *model) restoreAgentHistory(path string, now time.Time) error {
+	if err := fetchAgentHistory(path, m.history, now.Add(-historyRetention)); err != nil {
+		return fmt.Errorf("failed to read the agent's history: %w", err)
+	}
+	return nil
+}
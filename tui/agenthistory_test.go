@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAgentHistorySocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	hist := newAgentHistory(time.Hour, time.Second)
+	start := time.Now().Add(-time.Minute)
+	for i := range 3 {
+		stats := fixtureStats()
+		stats.CPU.Usage = float64(10 * (i + 1))
+		hist.record(start.Add(time.Duration(i)*time.Second), stats)
+	}
+	ln, err := listenAgentSocket(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go hist.serve(ln)
+	if _, err := listenAgentSocket(path); err == nil {
+		t.Error("a second agent took over a served socket")
+	}
+
+	// The attaching TUI's own first sample comes after the agent's
+	m := model{history: newHistory()}
+	m.history.Record(time.Now(), map[string]float64{metricCPU: 99})
+	if err := m.restoreAgentHistory(path, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	got := m.history.Values(metricCPU)
+	if len(got) != 4 || got[0] != 10 || got[2] != 30 || got[3] != 99 {
+		t.Errorf("cpu history = %v, want the agent's three samples before the TUI's", got)
+	}
+
+	// A socket left by an agent that is gone is replaced
+	ln.Close()
+	ln, err = listenAgentSocket(path)
+	if err != nil {
+		t.Fatalf("stale socket not replaced: %v", err)
+	}
+	ln.Close()
+}
@@ -0,0 +1,147 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/khoi/mtop/widgets"
+)
+
+// alertMetric is a reading that can be given an alert threshold
+type alertMetric struct {
+	unit  string
+	value func(stats SystemStats) float64
+}
+
+// Metrics that can be named in the [alerts] config section
+var alertMetrics = map[string]alertMetric{
+	"cpu":      {"%", func(s SystemStats) float64 { return s.CPU.Usage }},
+	"memory":   {"%", func(s SystemStats) float64 { return s.Memory.Usage }},
+	"swap":     {"%", func(s SystemStats) float64 { return s.Memory.Swap.Usage }},
+	"gpu":      {"%", func(s SystemStats) float64 { return s.GPU.Usage }},
+	"temp":     {"°C", func(s SystemStats) float64 { return s.CPU.Temp }},
+	"gpu_temp": {"°C", func(s SystemStats) float64 { return s.GPU.Temp }},
+	"load":     {"", func(s SystemStats) float64 { return s.CPU.LoadAvg[0] }},
+	"power":    {" W", func(s SystemStats) float64 { return s.Power.Package }},
+	"tcp":      {"", func(s SystemStats) float64 { return float64(s.Network.Sockets.TCPTotal) }},
+}
+
+// On-demand collectors that alert metrics read from
+var alertCollectors = map[string]string{"tcp": "sockets"}
+
+// Alert thresholds by metric name, set from the [alerts] config section.
+// A metric alerts while its reading is above the threshold.
+var alertThresholds = map[string]float64{}
+
+// Styles of alerting gauges and the header indicator, set by applyTheme
+var (
+	alertStyle          lipgloss.Style
+	alertIndicatorStyle lipgloss.Style
+)
+
+// applyAlerts checks and records the alert thresholds from the config
+func applyAlerts(thresholds map[string]float64) error {
+	for name, limit := range thresholds {
+		if _, ok := alertMetrics[name]; !ok {
+			return fmt.Errorf("unknown alert metric %q (want %s)", name, strings.Join(sortedKeys(alertMetrics), ", "))
+		}
+		if limit <= 0 {
+			return fmt.Errorf("alert threshold for %s must be positive", name)
+		}
+		alertThresholds[name] = limit
+	}
+
+	// Keep the collectors that alerting readings come from running
+	var needs []string
+	for name := range alertThresholds {
+		if c, ok := alertCollectors[name]; ok {
+			needs = append(needs, c)
+		}
+	}
+	subscribe("alerts", needs...)
+	return nil
+}
+
+// alerting reports whether a metric is above its alert threshold
+func alerting(stats SystemStats, name string) bool {
+	limit, ok := alertThresholds[name]
+	return ok && alertMetrics[name].value(stats) > limit
+}
+
+// activeAlerts describes every metric above its threshold, by name
+func activeAlerts(stats SystemStats) map[string]string {
+	alerts := make(map[string]string)
+	for name := range alertThresholds {
+		if alerting(stats, name) {
+			alerts[name] = describeAlert(stats, name)
+		}
+	}
+	return alerts
+}
+
+// alerts describes everything alerting by name: metrics above their
+// thresholds or above normal for the hour of the week and, with anomaly
+// alerts on, anomalous history metrics
+func (m model) alerts() map[string]string {
+	alerts := activeAlerts(m.stats)
+	if m.week != nil {
+		for name, text := range m.week.aboveNormal(m.sampledAt, m.stats) {
+			alerts[name] = text
+		}
+	}
+	for name, text := range m.anomalyAlerts() {
+		alerts[name] = text
+	}
+	return alerts
+}
+
+// knownAlert reports whether name can alert: an [alerts] metric, one
+// followed by " normal" for [normal], or a history metric for [anomaly]
+func knownAlert(name string) bool {
+	metric, isNormal := strings.CutSuffix(name, " normal")
+	_, known := alertMetrics[metric]
+	return known || (!isNormal && historyMetric(name))
+}
+
+// describeAlert shows a metric's reading against its threshold
+func describeAlert(stats SystemStats, name string) string {
+	metric := alertMetrics[name]
+	return fmt.Sprintf("%s %.1f%s > %g%s",
+		name, metric.value(stats), metric.unit, alertThresholds[name], metric.unit)
+}
+
+// renderAlerts is the flashing header indicator listing alerts in name
+// order, those muted at now marked, empty without any
+func renderAlerts(alerts map[string]string, now time.Time) string {
+	if len(alerts) == 0 {
+		return ""
+	}
+	texts := make([]string, 0, len(alerts))
+	for _, name := range sortedKeys(alerts) {
+		if alertMuted(name, now) {
+			texts = append(texts, alerts[name]+" (muted)")
+		} else {
+			texts = append(texts, alerts[name])
+		}
+	}
+	return " | " + alertIndicatorStyle.Render(glyphs.warn+" "+strings.Join(texts, ", "))
+}
+
+// alertGauge draws a usage bar, fully in the alert color while the metric
+// is above its threshold
+func alertGauge(stats SystemStats, name string, percent float64, width int) string {
+	if alerting(stats, name) {
+		return alertBar(percent, width)
+	}
+	return widgets.Bar(percent, width)
+}
+
+// alertText styles a reading in the alert color while its metric alerts
+func alertText(stats SystemStats, name, text string) string {
+	if alerting(stats, name) {
+		return alertStyle.Render(text)
+	}
+	return text
+}
@@ -0,0 +1,33 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestActiveAlerts(t *testing.T) {
+	defer func(thresholds map[string]float64) { alertThresholds = thresholds }(alertThresholds)
+	alertThresholds = map[string]float64{}
+
+	if err := applyAlerts(map[string]float64{"cpu": 30, "memory": 90, "temp": 50}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"cpu": "cpu 37.5% > 30%", "temp": "temp 58.0°C > 50°C"}
+	if got := activeAlerts(fixtureStats()); !reflect.DeepEqual(got, want) {
+		t.Errorf("alerts = %q, want %q", got, want)
+	}
+
+	if err := applyAlerts(map[string]float64{"cpu_temp": 90}); err == nil {
+		t.Error("unknown metric was accepted")
+	}
+	if err := applyAlerts(map[string]float64{"cpu": 0}); err == nil {
+		t.Error("zero threshold was accepted")
+	}
+}
+
+func TestAlertGolden(t *testing.T) {
+	defer func(thresholds map[string]float64) { alertThresholds = thresholds }(alertThresholds)
+	alertThresholds = map[string]float64{"cpu": 30, "temp": 50}
+
+	checkGolden(t, "overview_alert_80x24", Render(fixtureStats(), 80, 24, OverviewMode))
+}
@@ -0,0 +1,227 @@
+package tui
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runAlerts implements "mtop alerts": only "mtop alerts test" for now
+func runAlerts(args []string) int {
+	if len(args) == 0 || args[0] != "test" {
+		fmt.Fprintf(os.Stderr, "Usage: %s alerts test RULE [OPTIONS]\n", os.Args[0])
+		return 2
+	}
+	return runAlertsTest(args[1:])
+}
+
+// runAlertsTest implements "mtop alerts test": it checks one alert rule
+// against a live sample or a recording and shows the notifications and
+// hooks it would trigger, or triggers them with --send
+func runAlertsTest(args []string) int {
+	fs := flag.NewFlagSet("alerts test", flag.ExitOnError)
+	configPath := fs.String("config", defaultConfigPath(), "Config file with the [alerts], [notify], [[channels]], [[hooks]], [mute] and [[maintenance]] to test")
+	file := fs.String("file", "", "Test against a recording (JSON Lines in the import format, or a session from mtop record) instead of a live sample")
+	send := fs.Bool("send", false, "Send the notifications and run the hooks instead of printing them; hooks get MTOP_ALERT_TEST=1")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s alerts test RULE [OPTIONS]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "RULE is an [alerts] metric (%s), with the config's\n", strings.Join(sortedKeys(alertMetrics), ", "))
+		fmt.Fprintf(os.Stderr, "threshold, or one given in the rule, e.g. cpu>95. A recording is replayed with\n")
+		fmt.Fprintf(os.Stderr, "its own timing; a live sample is taken to last past every delay, then clear.\n")
+		fmt.Fprintf(os.Stderr, "Exits 1 if the rule never alerts.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	// The rule comes before the options
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fs.Usage()
+		return 2
+	}
+	rule := args[0]
+	fs.Parse(args[1:])
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	explicit := false
+	fs.Visit(func(f *flag.Flag) { explicit = explicit || f.Name == "config" })
+	cfg, err := loadConfig(*configPath, explicit)
+	if err == nil {
+		err = applyAlerts(cfg.Alerts)
+	}
+	if err == nil {
+		err = applyChannels(cfg.Channels)
+	}
+	if err == nil {
+		err = applyNotify(cfg.Notify)
+	}
+	if err == nil {
+		err = applyHooks(cfg.Hooks)
+	}
+	if err == nil {
+		err = applyMute(cfg.Mute)
+	}
+	if err == nil {
+		err = applyMaintenance(cfg.Maintenance)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config %s: %v\n", *configPath, err)
+		return 1
+	}
+	name, limit, err := parseAlertRule(rule)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid rule: %v\n", err)
+		return 2
+	}
+	// Only the rule under test alerts
+	alertThresholds = map[string]float64{name: limit}
+
+	var shots []snapshot
+	if *file != "" {
+		if shots, _, err = readReplay(*file); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", *file, err)
+			return 1
+		}
+	} else {
+		// Sample twice so the rate-based readings have a delta
+		collectSystemStats(context.Background())
+		time.Sleep(time.Second)
+		stats, err := collectSystemStats(context.Background())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error collecting system stats: %v\n", err)
+			return 1
+		}
+		shots = []snapshot{{time.Now(), stats}}
+	}
+
+	source := "a live sample"
+	if *file != "" {
+		source = fmt.Sprintf("%d samples from %s", len(shots), *file)
+	}
+	fmt.Printf("Testing %s > %g%s against %s\n", name, limit, alertMetrics[name].unit, source)
+	if !testAlertRule(os.Stdout, name, shots, *file == "", *send) {
+		return 1
+	}
+	return 0
+}
+
+// parseAlertRule reads a rule as METRIC, taking the threshold from the
+// config, or METRIC>THRESHOLD
+func parseAlertRule(rule string) (string, float64, error) {
+	name, value, hasLimit := strings.Cut(strings.ReplaceAll(rule, " ", ""), ">")
+	if _, ok := alertMetrics[name]; !ok {
+		return "", 0, fmt.Errorf("unknown alert metric %q (want %s)", name, strings.Join(sortedKeys(alertMetrics), ", "))
+	}
+	if !hasLimit {
+		limit, ok := alertThresholds[name]
+		if !ok {
+			return "", 0, fmt.Errorf("the config sets no threshold for %s; give one as %s>LIMIT", name, name)
+		}
+		return name, limit, nil
+	}
+	limit, err := strconv.ParseFloat(value, 64)
+	if err != nil || limit <= 0 {
+		return "", 0, fmt.Errorf("threshold %q must be a positive number", value)
+	}
+	return name, limit, nil
+}
+
+// testAlertRule plays samples through the notifier and the hooks as the
+// TUI would, writing what fires when. A live sample has no timing of its
+// own, so it is held for the longest delay and then cleared. It reports
+// whether the rule alerted at all.
+func testAlertRule(w io.Writer, name string, shots []snapshot, live, send bool) bool {
+	n, hooks := newNotifier(), newHookRunner()
+	if live {
+		var hold time.Duration
+		for _, h := range alertHooks {
+			hold = max(hold, h.After)
+		}
+		last := shots[len(shots)-1]
+		end := last.at.Add(max(hold, notifyAfter))
+		shots = append(shots, snapshot{end, last.stats}, snapshot{end.Add(time.Second), last.stats})
+	}
+
+	alerted, wasMuted, peak := false, false, 0.0
+	var prev map[string]string
+	for i, shot := range shots {
+		active := activeAlerts(shot.stats)
+		if live && i == len(shots)-1 {
+			active = nil
+		}
+		peak = max(peak, alertMetrics[name].value(shot.stats))
+		at := shot.at.Format("2006-01-02 15:04:05")
+		if text, ok := active[name]; ok && prev[name] == "" {
+			fmt.Fprintf(w, "%s  firing    %s\n", at, text)
+			alerted = true
+		} else if !ok && prev[name] != "" {
+			fmt.Fprintf(w, "%s  resolved  %s\n", at, name)
+		}
+		if muted := alertMuted(name, shot.at); muted != wasMuted {
+			if muted {
+				fmt.Fprintf(w, "%s  muted     %s (quiet hours or maintenance)\n", at, name)
+			} else {
+				fmt.Fprintf(w, "%s  unmuted   %s\n", at, name)
+			}
+			wasMuted = muted
+		}
+		prev = active
+
+		for _, notice := range n.due(active, shot.at) {
+			for _, channel := range routeChannels(notice.Name) {
+				if !send {
+					fmt.Fprintf(w, "%s  notify    %s via %s (dry run)\n", at, notice.Text, channel)
+					continue
+				}
+				test := alertNotice{notice.Name, notice.Text + " (test)"}
+				if err := notifyChannels[channel].send(test); err != nil {
+					fmt.Fprintf(w, "%s  notify    %s via %s: %v\n", at, notice.Text, channel, err)
+				} else {
+					fmt.Fprintf(w, "%s  notify    %s via %s: sent\n", at, notice.Text, channel)
+				}
+			}
+		}
+		for _, h := range hooks.due(active, shot.stats, shot.at) {
+			desc := strings.Join(h.command, " ") + " " + strings.Join(alertVars(h.env), " ")
+			if !send {
+				fmt.Fprintf(w, "%s  hook      %s (dry run)\n", at, desc)
+				continue
+			}
+			h.env = append(h.env, "MTOP_ALERT_TEST=1")
+			if err := h.run(); err != nil {
+				fmt.Fprintf(w, "%s  hook      %s: %v\n", at, desc, err)
+			} else {
+				fmt.Fprintf(w, "%s  hook      %s: ran\n", at, desc)
+			}
+		}
+	}
+
+	if !alerted {
+		metric := alertMetrics[name]
+		fmt.Fprintf(w, "%s never went above %g%s (peak %.1f%s)\n", name, alertThresholds[name], metric.unit, peak, metric.unit)
+	}
+	if notifyAfter <= 0 {
+		fmt.Fprintf(w, "Notifications are off: [notify] after is not set\n")
+	}
+	if len(alertHooks) == 0 {
+		fmt.Fprintf(w, "No [[hooks]] are configured\n")
+	}
+	return alerted
+}
+
+// alertVars picks the MTOP_ALERT* variables out of a hook's environment
+func alertVars(env []string) []string {
+	var vars []string
+	for _, v := range env {
+		if strings.HasPrefix(v, "MTOP_ALERT") {
+			vars = append(vars, v)
+		}
+	}
+	return vars
+}
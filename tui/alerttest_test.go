@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAlertRule(t *testing.T) {
+	defer func(th map[string]float64) { alertThresholds = th }(alertThresholds)
+	alertThresholds = map[string]float64{"cpu": 90}
+	for rule, want := range map[string]float64{"cpu": 90, "cpu>95": 95, "cpu > 80.5": 80.5} {
+		if name, limit, err := parseAlertRule(rule); err != nil || name != "cpu" || limit != want {
+			t.Errorf("parseAlertRule(%q) = %s, %g, %v; want cpu, %g", rule, name, limit, err, want)
+		}
+	}
+	for _, bad := range []string{"memory", "fans>1", "cpu>lots", "cpu>0"} {
+		if _, _, err := parseAlertRule(bad); err == nil {
+			t.Errorf("parseAlertRule(%q): no error", bad)
+		}
+	}
+}
+
+func TestAlertRuleDryRun(t *testing.T) {
+	defer func(th map[string]float64, h []hookConfig, after time.Duration) {
+		alertThresholds, alertHooks, notifyAfter = th, h, after
+	}(alertThresholds, alertHooks, notifyAfter)
+	alertThresholds = map[string]float64{"cpu": 90}
+	alertHooks = []hookConfig{{Alert: "cpu", After: time.Minute, Fire: []string{"hot.sh"}, Clear: []string{"cool.sh"}}}
+	notifyAfter = 30 * time.Second
+
+	at := time.Date(2026, 10, 17, 9, 0, 0, 0, time.Local)
+	var shots []snapshot
+	for i, usage := range []float64{40, 95, 97, 96, 50} {
+		stats := fixtureStats()
+		stats.CPU.Usage = usage
+		shots = append(shots, snapshot{at.Add(time.Duration(i) * 30 * time.Second), stats})
+	}
+	var b strings.Builder
+	if !testAlertRule(&b, "cpu", shots, false, false) {
+		t.Fatal("rule did not alert")
+	}
+	want := strings.Join([]string{
+		"2026-10-17 09:00:30  firing    cpu 95.0% > 90%",
+		"2026-10-17 09:01:00  notify    cpu 97.0% > 90% via desktop (dry run)",
+		"2026-10-17 09:01:30  hook      hot.sh MTOP_ALERT=cpu MTOP_ALERT_STATE=firing MTOP_ALERT_MESSAGE=cpu 96.0% > 90% MTOP_ALERT_SINCE=",
+		"2026-10-17 09:02:00  resolved  cpu",
+		"2026-10-17 09:02:00  hook      cool.sh MTOP_ALERT=cpu MTOP_ALERT_STATE=resolved",
+	}, "\n")
+	got := b.String()
+	for _, line := range strings.Split(want, "\n") {
+		if !strings.Contains(got, line) {
+			t.Errorf("output lacks %q:\n%s", line, got)
+		}
+	}
+
+	// A live sample is held past the delays and then cleared
+	b.Reset()
+	testAlertRule(&b, "cpu", shots[2:3], true, false)
+	if got := b.String(); !strings.Contains(got, "hot.sh") || !strings.Contains(got, "cool.sh") {
+		t.Errorf("live test did not fire and clear the hook:\n%s", got)
+	}
+
+	b.Reset()
+	if testAlertRule(&b, "cpu", shots[:1], false, false) || !strings.Contains(b.String(), "cpu never went above 90% (peak 40.0%)") {
+		t.Errorf("quiet recording:\n%s", b.String())
+	}
+}
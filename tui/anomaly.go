@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/khoi/mtop/history"
+)
+
+// Anomaly detection settings, set from the [anomaly] config section. A
+// sample is anomalous when it is more than anomalyZ deviations from the
+// exponentially weighted moving average of the samples before it.
+var (
+	anomalyZ       = 4.0
+	anomalyAlpha   = 0.1 // Weight of each new sample in the average
+	anomalyWarmup  = 20  // Samples seen before any is judged
+	anomalyAlert   bool  // Report anomalies with the threshold alerts
+	anomalyMetrics = []string{metricCPU, metricMemory, metricGPU}
+)
+
+// Smallest deviation a sample is measured against, so a series that has
+// been flat does not flag the first small wiggle. The default metrics are
+// percentages, so this is one percentage point.
+const anomalyMinDeviation = 1.0
+
+// anomalyConfig holds the [anomaly] config section
+type anomalyConfig struct {
+	Z       float64  `toml:"z"`       // Deviations from the moving average, e.g. 4
+	Alpha   float64  `toml:"alpha"`   // Weight of each new sample, between 0 and 1
+	Warmup  int      `toml:"warmup"`  // Samples before any is judged
+	Alert   bool     `toml:"alert"`   // Alert (and notify) while the newest sample is anomalous
+	Metrics []string `toml:"metrics"` // History metrics to alert on, e.g. "cpu.usage"
+}
+
+// applyAnomaly checks and records the anomaly settings from the config
+func applyAnomaly(cfg anomalyConfig) error {
+	if cfg.Z < 0 || cfg.Warmup < 0 {
+		return fmt.Errorf("anomaly z and warmup must not be negative")
+	}
+	if cfg.Alpha < 0 || cfg.Alpha >= 1 {
+		return fmt.Errorf("anomaly alpha must be between 0 and 1")
+	}
+	for _, name := range cfg.Metrics {
+		if !historyMetric(name) {
+			return fmt.Errorf("unknown anomaly metric %q", name)
+		}
+	}
+	if cfg.Z != 0 {
+		anomalyZ = cfg.Z
+	}
+	if cfg.Alpha != 0 {
+		anomalyAlpha = cfg.Alpha
+	}
+	if cfg.Warmup != 0 {
+		anomalyWarmup = cfg.Warmup
+	}
+	if len(cfg.Metrics) > 0 {
+		anomalyMetrics = cfg.Metrics
+	}
+	anomalyAlert = cfg.Alert
+	return nil
+}
+
+// anomaly is a sample outside the moving average's bands
+type anomaly struct {
+	index int     // Position in the samples searched
+	z     float64 // Signed deviations from the average
+}
+
+// findAnomalies runs an exponentially weighted moving average and variance
+// over samples and returns the samples outside the bands. Anomalous
+// samples move the average only as far as a sample on the band edge
+// would, so one spike does not widen the bands enough to hide the next,
+// while a lasting shift still stops being flagged once they catch up.
+func findAnomalies(samples []history.Sample) []anomaly {
+	if len(samples) == 0 {
+		return nil
+	}
+	var found []anomaly
+	mean, variance := samples[0].Value, 0.0
+	for i := 1; i < len(samples); i++ {
+		diff := samples[i].Value - mean
+		band := anomalyZ * max(math.Sqrt(variance), anomalyMinDeviation)
+		if i >= anomalyWarmup && math.Abs(diff) > band {
+			found = append(found, anomaly{i, diff / band * anomalyZ})
+			diff = math.Copysign(band, diff)
+		}
+		incr := anomalyAlpha * diff
+		mean += incr
+		variance = (1 - anomalyAlpha) * (variance + diff*incr)
+	}
+	return found
+}
+
+// anomalyNote summarizes the anomalies in a series for its chart title
+func anomalyNote(samples []history.Sample, found []anomaly) string {
+	if len(found) == 0 {
+		return ""
+	}
+	last := found[len(found)-1]
+	noun := "anomalies"
+	if len(found) == 1 {
+		noun = "anomaly"
+	}
+	return fmt.Sprintf(" (%d %s, last at %s z=%+.1f)",
+		len(found), noun, samples[last.index].Time.Format("15:04:05"), last.z)
+}
+
+// anomalyMarks places a rising or falling marker, by cell, at each anomaly
+// of a chart width cells wide. The chart holds its newest values on the
+// right edge, two per cell, as widgets.Chart lays them out.
+func anomalyMarks(width, samples int, found []anomaly) map[int]string {
+	marks := make(map[int]string)
+	for _, a := range found {
+		x := width*2 - samples + a.index
+		if x < 0 {
+			continue
+		}
+		marks[x/2] = alertStyle.Render(glyphs.rising)
+		if a.z < 0 {
+			marks[x/2] = alertStyle.Render(glyphs.falling)
+		}
+	}
+	return marks
+}
+
+// anomalyAlerts describes each alerting metric whose newest sample is
+// anomalous, by metric name
+func (m model) anomalyAlerts() map[string]string {
+	if !anomalyAlert {
+		return nil
+	}
+	alerts := make(map[string]string)
+	for _, name := range anomalyMetrics {
+		samples := m.history.Samples(name)
+		found := findAnomalies(samples)
+		if len(found) > 0 && found[len(found)-1].index == len(samples)-1 {
+			alerts[name] = fmt.Sprintf("%s anomaly z=%+.1f", name, found[len(found)-1].z)
+		}
+	}
+	return alerts
+}
@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khoi/mtop/history"
+)
+
+// steadySamples is a series wobbling around level, one a second
+func steadySamples(n int, level float64) []history.Sample {
+	start := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	samples := make([]history.Sample, n)
+	for i := range samples {
+		samples[i] = history.Sample{Time: start.Add(time.Duration(i) * time.Second), Value: level + float64(i%3)}
+	}
+	return samples
+}
+
+func TestFindAnomalies(t *testing.T) {
+	samples := steadySamples(40, 20)
+	if found := findAnomalies(samples); len(found) != 0 {
+		t.Errorf("steady series has anomalies %v", found)
+	}
+
+	samples[30].Value = 90
+	samples[35].Value = 0
+	found := findAnomalies(samples)
+	if len(found) != 2 || found[0].index != 30 || found[0].z <= 0 || found[1].index != 35 || found[1].z >= 0 {
+		t.Errorf("anomalies = %v, want a spike at 30 and a drop at 35", found)
+	}
+
+	// Nothing is judged before the warmup
+	samples = steadySamples(40, 20)
+	samples[5].Value = 90
+	if found := findAnomalies(samples); len(found) != 0 {
+		t.Errorf("anomaly during warmup: %v", found)
+	}
+
+	// A lasting shift is flagged at first, then becomes the new normal
+	samples = steadySamples(120, 20)
+	for i := 30; i < len(samples); i++ {
+		samples[i].Value += 40
+	}
+	found = findAnomalies(samples)
+	if len(found) == 0 || found[0].index != 30 || found[len(found)-1].index > 60 {
+		t.Errorf("anomalies after a shift = %v", found)
+	}
+}
+
+func TestAnomalyChartAndAlert(t *testing.T) {
+	defer func(alert bool) { anomalyAlert = alert }(anomalyAlert)
+	m := model{width: 80, history: newHistory()}
+	samples := steadySamples(40, 20)
+	samples[39].Value = 95
+	for _, s := range samples {
+		m.history.Add(metricCPU, s.Time, s.Value)
+	}
+
+	chart := m.renderHistoryChart("CPU Usage History", m.history.Samples(metricCPU), 4)
+	title, _, _ := strings.Cut(chart, "\n")
+	if title != "CPU Usage History (1 anomaly, last at 10:00:39 z=+73.9):" {
+		t.Errorf("title = %q", title)
+	}
+	lines := strings.Split(strings.TrimSuffix(chart, "\n"), "\n")
+	if bottom := lines[len(lines)-1]; !strings.HasSuffix(bottom, glyphs.rising) {
+		t.Errorf("bottom row %q does not end with the anomaly marker", bottom)
+	}
+
+	anomalyAlert = false
+	if alerts := m.alerts(); len(alerts) != 0 {
+		t.Errorf("alerts with anomaly alerts off: %v", alerts)
+	}
+	anomalyAlert = true
+	if got := m.alerts()[metricCPU]; got != "cpu.usage anomaly z=+73.9" {
+		t.Errorf("anomaly alert = %q", got)
+	}
+}
+
+func TestApplyAnomaly(t *testing.T) {
+	for _, cfg := range []anomalyConfig{
+		{Z: -1},
+		{Alpha: 1},
+		{Metrics: []string{"cpu"}},
+	} {
+		if err := applyAnomaly(cfg); err == nil {
+			t.Errorf("applyAnomaly(%+v) succeeded", cfg)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package tui
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/khoi/mtop/widgets"
+	"golang.org/x/sys/unix"
+)
+
+// Exit code of mtop compare when a reading deviates beyond the tolerance
+const baselineDeviation = 3
+
+// Average CPU usage from which a sample run is compared with the load
+// profile rather than the idle one
+const baselineLoadUsage = 80.0
+
+// baselineMetric is a reading compared against the reference profiles
+type baselineMetric struct {
+	name   string
+	format func(float64) string
+	get    func(SystemStats) float64
+	info   bool // Shown for context but never flagged
+}
+
+// CPU usage only tells which profile applies: a few percent at idle is
+// noise, not a deviation
+var baselineMetrics = []baselineMetric{
+	{"cpu.usage", formatBaselinePercent, func(s SystemStats) float64 { return s.CPU.Usage }, true},
+	{"cpu.temp", formatBaselineCelsius, func(s SystemStats) float64 { return s.CPU.Temp }, false},
+	{"power.cpu", formatBaselineWatts, func(s SystemStats) float64 { return s.Power.CPU }, false},
+	{"power.package", formatBaselineWatts, func(s SystemStats) float64 { return s.Power.Package }, false},
+}
+
+func formatBaselinePercent(v float64) string { return fmt.Sprintf("%.1f%%", v) }
+func formatBaselineCelsius(v float64) string { return fmt.Sprintf("%.0f°C", v) }
+func formatBaselineWatts(v float64) string   { return fmt.Sprintf("%.2f W", v) }
+
+// hardwareBaseline holds typical readings of a Mac model at idle (nothing
+// but the desktop running) and under an all-core CPU load such as
+// mtop stress --cpu N, keyed by baselineMetrics names. They are rounded
+// figures of stock machines at room temperature, meant to spot a Mac that
+// is far off, not to grade small differences.
+type hardwareBaseline struct {
+	Name   string
+	Title  string
+	Models []string // hw.model identifiers the baseline applies to
+	Idle   map[string]float64
+	Load   map[string]float64
+}
+
+var hardwareBaselines = []hardwareBaseline{
+	{
+		Name: "m1-air", Title: "MacBook Air (M1, 2020)", Models: []string{"MacBookAir10,1"},
+		Idle: map[string]float64{"cpu.usage": 3, "cpu.temp": 35, "power.cpu": 0.15, "power.package": 0.3},
+		Load: map[string]float64{"cpu.usage": 100, "cpu.temp": 90, "power.cpu": 13, "power.package": 14},
+	},
+	{
+		Name: "m1-mini", Title: "Mac mini (M1, 2020)", Models: []string{"Macmini9,1"},
+		Idle: map[string]float64{"cpu.usage": 3, "cpu.temp": 35, "power.cpu": 0.15, "power.package": 0.3},
+		Load: map[string]float64{"cpu.usage": 100, "cpu.temp": 70, "power.cpu": 20, "power.package": 21},
+	},
+	{
+		Name: "m2-air", Title: "MacBook Air (M2, 2022)", Models: []string{"Mac14,2"},
+		Idle: map[string]float64{"cpu.usage": 3, "cpu.temp": 35, "power.cpu": 0.15, "power.package": 0.3},
+		Load: map[string]float64{"cpu.usage": 100, "cpu.temp": 100, "power.cpu": 15, "power.package": 16},
+	},
+	{
+		Name: "m2-pro-14", Title: "MacBook Pro 14-inch (M2 Pro/Max, 2023)", Models: []string{"Mac14,5", "Mac14,9"},
+		Idle: map[string]float64{"cpu.usage": 3, "cpu.temp": 40, "power.cpu": 0.3, "power.package": 0.5},
+		Load: map[string]float64{"cpu.usage": 100, "cpu.temp": 85, "power.cpu": 35, "power.package": 37},
+	},
+	{
+		Name: "m3-air", Title: "MacBook Air 13-inch (M3, 2024)", Models: []string{"Mac15,12"},
+		Idle: map[string]float64{"cpu.usage": 3, "cpu.temp": 35, "power.cpu": 0.15, "power.package": 0.3},
+		Load: map[string]float64{"cpu.usage": 100, "cpu.temp": 100, "power.cpu": 17, "power.package": 18},
+	},
+	{
+		Name: "m3-pro-14", Title: "MacBook Pro 14-inch (M3 Pro/Max, 2023)", Models: []string{"Mac15,6", "Mac15,8"},
+		Idle: map[string]float64{"cpu.usage": 3, "cpu.temp": 40, "power.cpu": 0.3, "power.package": 0.5},
+		Load: map[string]float64{"cpu.usage": 100, "cpu.temp": 90, "power.cpu": 40, "power.package": 42},
+	},
+	{
+		Name: "m4-mini", Title: "Mac mini (M4, 2024)", Models: []string{"Mac16,10"},
+		Idle: map[string]float64{"cpu.usage": 3, "cpu.temp": 35, "power.cpu": 0.2, "power.package": 0.4},
+		Load: map[string]float64{"cpu.usage": 100, "cpu.temp": 80, "power.cpu": 30, "power.package": 32},
+	},
+}
+
+// findBaseline looks up a baseline by name
+func findBaseline(name string) (hardwareBaseline, error) {
+	var names []string
+	for _, b := range hardwareBaselines {
+		if b.Name == name {
+			return b, nil
+		}
+		names = append(names, b.Name)
+	}
+	return hardwareBaseline{}, fmt.Errorf("unknown baseline %q (want %s)", name, strings.Join(names, ", "))
+}
+
+// baselineForModel returns the baseline of a hw.model identifier
+func baselineForModel(model string) (hardwareBaseline, bool) {
+	for _, b := range hardwareBaselines {
+		for _, m := range b.Models {
+			if m == model {
+				return b, true
+			}
+		}
+	}
+	return hardwareBaseline{}, false
+}
+
+// runCompare implements "mtop compare": sample this Mac for a while and
+// compare the averages with a model's typical readings
+func runCompare(args []string) int {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	name := fs.String("baseline", "", "Baseline to compare with (default: this Mac's model, if known)")
+	state := fs.String("state", "", "Profile to compare with: idle or load (default: load when CPU usage averages 80% or more)")
+	duration := fs.Duration("duration", 10*time.Second, "How long to sample")
+	tolerance := fs.Float64("tolerance", 50, "Relative difference in percent above which a reading is flagged")
+	list := fs.Bool("list", false, "List the baselines and exit")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s compare [OPTIONS]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Samples this Mac and compares the averages with typical readings of a model at\n")
+		fmt.Fprintf(os.Stderr, "idle or under an all-core load (run %s stress --cpu N alongside for the latter).\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Exits with status %d when any reading is outside the tolerance.\n\n", baselineDeviation)
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *list {
+		for _, b := range hardwareBaselines {
+			fmt.Printf("%-12s %s\n", b.Name, b.Title)
+		}
+		return 0
+	}
+	if *state != "" && *state != "idle" && *state != "load" {
+		fmt.Fprintf(os.Stderr, "Invalid --state: want idle or load\n")
+		return 2
+	}
+	if *duration < time.Second {
+		fmt.Fprintf(os.Stderr, "Invalid --duration: must be at least 1s\n")
+		return 2
+	}
+
+	var baseline hardwareBaseline
+	if *name != "" {
+		b, err := findBaseline(*name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --baseline: %v\n", err)
+			return 2
+		}
+		baseline = b
+	} else {
+		model, _ := unix.Sysctl("hw.model")
+		b, ok := baselineForModel(model)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "No baseline for this Mac (%s); pick one with --baseline (see --list)\n", model)
+			return 2
+		}
+		baseline = b
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Fprintf(os.Stderr, "Sampling for %v...\n", *duration)
+	averages, err := sampleBaselineMetrics(ctx, *duration)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error collecting system stats: %v\n", err)
+		return 1
+	}
+	if *state == "" {
+		*state = "idle"
+		if averages["cpu.usage"] >= baselineLoadUsage {
+			*state = "load"
+		}
+	}
+	if printBaselineReport(os.Stdout, baseline, *state, averages, *tolerance) > 0 {
+		return baselineDeviation
+	}
+	return 0
+}
+
+// sampleBaselineMetrics averages the baseline metrics over one-second
+// samples for the duration. Metrics that read zero throughout, such as
+// power without IOReport access, are left out.
+func sampleBaselineMetrics(ctx context.Context, duration time.Duration) (map[string]float64, error) {
+	// Rates need a first sample to measure from
+	if _, err := collectSystemStats(ctx); err != nil {
+		return nil, err
+	}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	sums := make(map[string]float64)
+	n := 0
+	for end := time.Now().Add(duration); time.Now().Before(end); {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		stats, err := collectSystemStats(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range baselineMetrics {
+			sums[m.name] += m.get(stats)
+		}
+		n++
+	}
+	averages := make(map[string]float64)
+	for name, sum := range sums {
+		if sum != 0 {
+			averages[name] = sum / float64(n)
+		}
+	}
+	return averages, nil
+}
+
+// printBaselineReport writes each reading next to the baseline's and
+// returns the number that deviate beyond the tolerance
+func printBaselineReport(w io.Writer, b hardwareBaseline, state string, averages map[string]float64, tolerance float64) int {
+	profile := b.Idle
+	if state == "load" {
+		profile = b.Load
+	}
+	fmt.Fprintf(w, "Compared with a typical %s at %s\n\n", b.Title, state)
+	fmt.Fprintf(w, "%-14s %10s %10s %9s\n", "METRIC", "THIS MAC", "TYPICAL", "DIFF")
+	deviations, compared := 0, 0
+	for _, m := range baselineMetrics {
+		ref, ok := profile[m.name]
+		if !ok {
+			continue
+		}
+		v, ok := averages[m.name]
+		if !ok {
+			fmt.Fprintf(w, "%-14s %s %s\n", m.name, widgets.Pad("no reading", 10, true), widgets.Pad(m.format(ref), 10, true))
+			continue
+		}
+		diff := relativeDiff(v, ref)
+		status := "ok"
+		switch {
+		case m.info:
+			status = "-"
+		case diff > tolerance:
+			deviations++
+			status = "HIGH"
+			if v < ref {
+				status = "LOW"
+			}
+		}
+		if !m.info {
+			compared++
+		}
+		// widgets.Pad counts runes, so °C lines up
+		fmt.Fprintf(w, "%-14s %s %s %8.0f%%  %s\n", m.name, widgets.Pad(m.format(v), 10, true), widgets.Pad(m.format(ref), 10, true), diff, status)
+	}
+	fmt.Fprintf(w, "\n%d of %d readings differ by more than %.0f%%\n", deviations, compared, tolerance)
+	return deviations
+}
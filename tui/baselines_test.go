@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHardwareBaselinesComplete(t *testing.T) {
+	models := map[string]string{}
+	for _, b := range hardwareBaselines {
+		for _, m := range baselineMetrics {
+			if _, ok := b.Idle[m.name]; !ok {
+				t.Errorf("%s has no idle %s", b.Name, m.name)
+			}
+			if b.Load[m.name] <= b.Idle[m.name] {
+				t.Errorf("%s: load %s is not above idle", b.Name, m.name)
+			}
+		}
+		for _, m := range b.Models {
+			if other, dup := models[m]; dup {
+				t.Errorf("model %s is in both %s and %s", m, other, b.Name)
+			}
+			models[m] = b.Name
+		}
+	}
+	if b, ok := baselineForModel("Mac14,2"); !ok || b.Name != "m2-air" {
+		t.Errorf("Mac14,2 = %q, want m2-air", b.Name)
+	}
+	if _, err := findBaseline("m9-ultra"); err == nil {
+		t.Error("unknown baseline found")
+	}
+}
+
+func TestBaselineReport(t *testing.T) {
+	b, _ := findBaseline("m2-air")
+	var out strings.Builder
+	averages := map[string]float64{"cpu.usage": 100, "cpu.temp": 100, "power.cpu": 4}
+	if n := printBaselineReport(&out, b, "load", averages, 50); n != 1 {
+		t.Errorf("%d deviations, want power.cpu only:\n%s", n, out.String())
+	}
+	for _, want := range []string{"power.cpu          4.00 W    15.00 W", "LOW", "power.package  no reading", "1 of 2 readings"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report lacks %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Busiest processes listed in each batch mode snapshot
+const batchProcesses = 10
+
+// Output formats of batch mode, named by --format
+var batchFormats = []string{"text", "json", "csv", "influx", "statsd"}
+
+// runBatch writes samples snapshots, interval apart, like top -l, in one
+// of batchFormats: plain text, one import format JSON line per sample
+// (which --replay, --compare and mtop import read back), CSV rows under a
+// header, InfluxDB line protocol or StatsD gauges. With samples 0 it
+// streams until killed or the reader goes away. A first sample only primes
+// the rate-based collectors. With summary, JSON ends with a line of each
+// metric's percentiles, also when a signal stops the stream.
+func runBatch(w io.Writer, samples int, interval time.Duration, format string, summary bool) error {
+	if format == "csv" {
+		if _, err := io.WriteString(w, formatCSVHeader()); err != nil {
+			return err
+		}
+	}
+	ctx := context.Background()
+	hooks := newHookRunner()
+	var sum *runSummarizer
+	if summary {
+		sum = newRunSummarizer()
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+	}
+	collectSystemStats(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+loop:
+	for i := 0; samples == 0 || i < samples; i++ {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			break loop
+		}
+		stats, err := collectSystemStats(ctx)
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		stats = selectDevices(stats)
+		now := time.Now()
+		if runs := hooks.due(activeAlerts(stats), stats, now); len(runs) > 0 {
+			go runHooks(runs, os.Stderr)
+		}
+		if sum != nil {
+			sum.add(now, stats)
+		}
+		var out string
+		switch format {
+		case "json":
+			if jsonFields != nil {
+				out, err = formatFieldsLine(now, stats)
+			} else {
+				out, err = formatSampleLine(now, stats)
+			}
+		case "csv":
+			out = formatCSVRow(now, stats)
+		case "influx":
+			out = formatInfluxLines(now, exportProcesses(now, stats))
+		case "statsd":
+			out = formatStatsdGauges(stats)
+		default:
+			out = formatBatchSnapshot(now, stats)
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, out); err != nil {
+			return err
+		}
+	}
+	if sum == nil || sum.samples == 0 {
+		return nil
+	}
+	line, err := sum.formatSummaryLine()
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, line)
+	return err
+}
+
+// formatSampleLine renders one sample as an import format JSON line
+func formatSampleLine(at time.Time, stats SystemStats) (string, error) {
+	line, err := json.Marshal(importRecord{Time: at, Host: hostname, Stats: &stats})
+	if err != nil {
+		return "", err
+	}
+	return string(line) + "\n", nil
+}
+
+// formatBatchSnapshot renders one sample as plain text with the system
+// readings, any alerts and the busiest processes, ending in a blank line
+func formatBatchSnapshot(at time.Time, stats SystemStats) string {
+	const gb = 1024 * 1024 * 1024
+	var b strings.Builder
+	fmt.Fprintf(&b, "Time: %s | Host: %s | Uptime: %v\n",
+		at.Format("2006-01-02 15:04:05"), hostname, stats.Uptime.Round(time.Second))
+	fmt.Fprintf(&b, "CPU: %.1f%% | Load: %.2f %.2f %.2f | Temp: %.1f°C | Thermal: %s\n",
+		stats.CPU.Usage, stats.CPU.LoadAvg[0], stats.CPU.LoadAvg[1], stats.CPU.LoadAvg[2],
+		stats.CPU.Temp, stats.Thermal)
+	fmt.Fprintf(&b, "Memory: %.1f%% (%.1f GB / %.1f GB, pressure %s) | Swap: %.1f%% (%s / %s)\n",
+		stats.Memory.Usage, float64(stats.Memory.Used)/gb, float64(stats.Memory.Total)/gb, stats.Memory.Pressure,
+		stats.Memory.Swap.Usage, formatBytes(stats.Memory.Swap.Used), formatBytes(stats.Memory.Swap.Total))
+	fmt.Fprintf(&b, "GPU: %.1f%% | Power: %.2f W (CPU %.2f W, GPU %.2f W, ANE %.2f W)\n",
+		stats.GPU.Usage, stats.Power.Package, stats.Power.CPU, stats.Power.GPU, stats.Power.ANE)
+	if alerts := activeAlerts(stats); len(alerts) > 0 {
+		texts := make([]string, 0, len(alerts))
+		for _, name := range sortedKeys(alerts) {
+			texts = append(texts, alerts[name])
+		}
+		fmt.Fprintf(&b, "Alerts: %s\n", strings.Join(texts, ", "))
+	}
+
+	procs := make([]ProcessStats, len(stats.Processes))
+	copy(procs, stats.Processes)
+	sort.SliceStable(procs, func(i, j int) bool { return procs[i].CPU > procs[j].CPU })
+	procs = procs[:min(len(procs), batchProcesses)]
+	fmt.Fprintf(&b, "Processes: %d\n", len(stats.Processes))
+	fmt.Fprintf(&b, "%7s %6s %10s  %s\n", "PID", "CPU%", "MEM", "NAME")
+	for _, p := range procs {
+		fmt.Fprintf(&b, "%7d %6.1f %10s  %s\n", p.PID, p.CPU, formatBytes(p.RSS), p.Name)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatBatchSnapshot(t *testing.T) {
+	stats := fixtureStats()
+	at := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	out := formatBatchSnapshot(at, stats)
+
+	for _, want := range []string{
+		"Time: 2026-10-17 10:00:00 |",
+		"CPU: 37.5% | Load: 3.12 2.48 1.97 | Temp: 58.0°C | Thermal: Fair\n",
+		"Memory: 68.8% (11.0 GB / 16.0 GB, pressure",
+		"Swap: 25.0% (512.0 MB / 2.0 GB)\n",
+		"GPU: 23.0% |",
+		"\n    PID   CPU%        MEM  NAME\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("snapshot lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "\x1b[") {
+		t.Errorf("snapshot has escape sequences:\n%q", out)
+	}
+	if !strings.HasSuffix(out, "\n\n") {
+		t.Error("snapshot does not end with a blank line")
+	}
+
+	rows := strings.Split(strings.SplitN(out, "NAME\n", 2)[1], "\n")
+	if n := len(rows) - 2; n != min(len(stats.Processes), batchProcesses) {
+		t.Errorf("%d process rows, want %d", n, min(len(stats.Processes), batchProcesses))
+	}
+}
+
+func TestSampleLinesReplay(t *testing.T) {
+	var b strings.Builder
+	start := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		stats := fixtureStats()
+		stats.CPU.Usage = float64(10 * i)
+		line, err := formatSampleLine(start.Add(time.Duration(i)*time.Second), stats)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Count(line, "\n") != 1 || !strings.HasSuffix(line, "\n") {
+			t.Fatalf("not a single line: %q", line)
+		}
+		b.WriteString(line)
+	}
+	shots, _, err := loadReplay(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shots) != 3 || shots[2].stats.CPU.Usage != 20 {
+		t.Errorf("read back %d samples: %+v", len(shots), shots)
+	}
+}
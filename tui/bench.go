@@ -0,0 +1,358 @@
+package tui
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/khoi/mtop/stats"
+	"golang.org/x/sys/unix"
+)
+
+// Exit code used when a benchmarked command exceeds one of its budgets
+const benchBudgetExceeded = 3
+
+// benchBudget holds the limits a benchmarked command must stay within.
+// Zero values mean no limit.
+type benchBudget struct {
+	MaxRSS    uint64        // Peak resident memory in bytes
+	MaxWall   time.Duration // Wall clock time
+	MaxEnergy float64       // Energy in joules
+}
+
+// benchResult holds what was measured while running the command
+type benchResult struct {
+	Wall     time.Duration
+	MaxRSS   uint64
+	Energy   float64
+	ExitCode int
+}
+
+// runBench implements "mtop bench": run a command, measure it and compare
+// the measurements against the declared budgets
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	maxRSS := fs.String("max-rss", "", "Fail if peak resident memory exceeds this size (e.g. 512M, 2G)")
+	maxTime := fs.Duration("max-time", 0, "Fail if wall time exceeds this duration (e.g. 30s)")
+	maxEnergy := fs.Float64("max-energy", 0, "Fail if the command uses more than this many joules")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s bench [OPTIONS] -- COMMAND [ARGS...]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Runs COMMAND and reports its wall time, peak RSS and energy use,\ncounting every process it starts.\n")
+		fmt.Fprintf(os.Stderr, "Exits with status %d when any budget is exceeded.\n\n", benchBudgetExceeded)
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	var budget benchBudget
+	if *maxRSS != "" {
+		size, err := parseSize(*maxRSS)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --max-rss: %v\n", err)
+			return 2
+		}
+		budget.MaxRSS = size
+	}
+	budget.MaxWall = *maxTime
+	budget.MaxEnergy = *maxEnergy
+
+	result, err := benchCommand(fs.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running command: %v\n", err)
+		return 1
+	}
+
+	violations := printBenchReport(os.Stderr, fs.Args(), result, budget)
+	if result.ExitCode != 0 {
+		return result.ExitCode
+	}
+	if violations > 0 {
+		return benchBudgetExceeded
+	}
+	return 0
+}
+
+// How often the command's process tree is read while it runs
+const benchPollInterval = 50 * time.Millisecond
+
+// benchCommand runs the command to completion while sampling the resource
+// usage of it and every process it starts
+func benchCommand(argv []string) (benchResult, error) {
+	var result benchResult
+
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return result, err
+	}
+	watcher, err := watchExit(cmd.Process.Pid)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return result, err
+	}
+	defer watcher.close()
+
+	// Energy and footprints are only readable while a process is around,
+	// so the tree is read periodically and once more when the command has
+	// exited but is not yet reaped
+	tree := newBenchTree(cmd.Process.Pid)
+	for {
+		exited := watcher.wait(benchPollInterval)
+		if exited {
+			result.Wall = time.Since(start)
+		}
+		tree.poll()
+		if exited {
+			break
+		}
+	}
+
+	waitErr := cmd.Wait()
+	var exitErr *exec.ExitError
+	if waitErr != nil && !errors.As(waitErr, &exitErr) {
+		return result, waitErr
+	}
+	result.ExitCode = cmd.ProcessState.ExitCode()
+
+	result.MaxRSS = tree.peakRSS
+	// ru_maxrss is reported in bytes on macOS
+	if rusage, ok := cmd.ProcessState.SysUsage().(*syscall.Rusage); ok {
+		result.MaxRSS = max(result.MaxRSS, uint64(rusage.Maxrss))
+	}
+	result.Energy = tree.energy()
+
+	return result, nil
+}
+
+// benchProc is a process as the tree walk sees it
+type benchProc struct {
+	pid, ppid int
+	start     time.Time
+}
+
+// benchKey tells apart processes that had the same pid
+type benchKey struct {
+	pid   int
+	start time.Time
+}
+
+// benchTree is a benchmarked command and every process started under it,
+// with the newest usage read from each. Processes stay members when their
+// parent exits and they are reparented to launchd.
+type benchTree struct {
+	members map[int]time.Time // Live members by pid, with their start time
+	usage   map[benchKey]stats.Rusage
+	peakRSS uint64 // Largest total footprint seen, or one process's lifetime peak
+}
+
+// newBenchTree returns a tree holding the command's process
+func newBenchTree(pid int) *benchTree {
+	return &benchTree{
+		members: map[int]time.Time{pid: {}},
+		usage:   make(map[benchKey]stats.Rusage),
+	}
+}
+
+// poll adds the processes started since the last poll and reads the usage
+// of every live member
+func (t *benchTree) poll() {
+	if kprocs, err := unix.SysctlKinfoProcSlice("kern.proc.all"); err == nil {
+		procs := make([]benchProc, len(kprocs))
+		for i := range kprocs {
+			kp := &kprocs[i]
+			procs[i] = benchProc{int(kp.Proc.P_pid), int(kp.Eproc.Ppid), time.Unix(kp.Proc.P_starttime.Unix())}
+		}
+		t.adopt(procs)
+	}
+	t.read(stats.ProcessRusage)
+}
+
+// adopt drops the members that are gone and adds the processes whose
+// parent is a member. A child that started before its parent has a stale
+// ppid left over from pid reuse and is not adopted.
+func (t *benchTree) adopt(procs []benchProc) {
+	byPID := make(map[int]benchProc, len(procs))
+	for _, p := range procs {
+		byPID[p.pid] = p
+	}
+	for pid, start := range t.members {
+		switch p, ok := byPID[pid]; {
+		case ok && start.IsZero():
+			t.members[pid] = p.start
+		case !ok || !p.start.Equal(start):
+			delete(t.members, pid)
+		}
+	}
+	// The process list is in no particular order, so go over it until a
+	// pass adds nobody
+	for added := true; added; {
+		added = false
+		for _, p := range procs {
+			if _, ok := t.members[p.pid]; ok {
+				continue
+			}
+			if parent, ok := t.members[p.ppid]; ok && !p.start.Before(parent) {
+				t.members[p.pid] = p.start
+				added = true
+			}
+		}
+	}
+}
+
+// read records the usage of each live member and the peak footprint
+func (t *benchTree) read(usage func(pid int) (*stats.Rusage, error)) {
+	var total uint64
+	for pid, start := range t.members {
+		u, err := usage(pid)
+		if err != nil {
+			continue
+		}
+		t.usage[benchKey{pid, start}] = *u
+		total += u.PhysFootprint
+		t.peakRSS = max(t.peakRSS, u.LifetimeMaxPhysFootprint)
+	}
+	t.peakRSS = max(t.peakRSS, total)
+}
+
+// energy returns the joules billed to every process of the tree
+func (t *benchTree) energy() float64 {
+	var nj uint64
+	for _, u := range t.usage {
+		nj += u.BilledEnergy
+	}
+	return float64(nj) / 1e9
+}
+
+// exitWatcher tells when a child has exited, through a kqueue NOTE_EXIT
+// filter, while it is still a zombie whose usage can be read
+type exitWatcher struct {
+	kq     int
+	exited bool
+}
+
+func watchExit(pid int) (*exitWatcher, error) {
+	kq, err := unix.Kqueue()
+	if err != nil {
+		return nil, fmt.Errorf("kqueue: %w", err)
+	}
+	w := &exitWatcher{kq: kq}
+	var ev unix.Kevent_t
+	unix.SetKevent(&ev, pid, unix.EVFILT_PROC, unix.EV_ADD|unix.EV_ONESHOT)
+	ev.Fflags = unix.NOTE_EXIT
+	if _, err := unix.Kevent(kq, []unix.Kevent_t{ev}, nil, nil); err != nil {
+		// A child that exited before the filter was added is gone already
+		if !errors.Is(err, syscall.ESRCH) {
+			unix.Close(kq)
+			return nil, fmt.Errorf("watching pid %d: %w", pid, err)
+		}
+		w.exited = true
+	}
+	return w, nil
+}
+
+// wait blocks for up to timeout and reports whether the child has exited
+func (w *exitWatcher) wait(timeout time.Duration) bool {
+	if w.exited {
+		return true
+	}
+	ts := unix.NsecToTimespec(int64(timeout))
+	events := make([]unix.Kevent_t, 1)
+	if n, err := unix.Kevent(w.kq, nil, events, &ts); err == nil && n > 0 {
+		w.exited = true
+	}
+	return w.exited
+}
+
+func (w *exitWatcher) close() {
+	unix.Close(w.kq)
+}
+
+// printBenchReport writes the measurements and budget diff, returning the
+// number of budgets that were exceeded
+func printBenchReport(w io.Writer, argv []string, r benchResult, b benchBudget) int {
+	violations := 0
+	line := func(name, value, limit string, measured, budget float64) {
+		status := ""
+		if budget > 0 {
+			if measured > budget {
+				status = fmt.Sprintf("EXCEEDED (+%.1f%%)", (measured-budget)/budget*100)
+				violations++
+			} else {
+				status = "ok"
+			}
+		} else {
+			limit = "-"
+		}
+		fmt.Fprintf(w, "  %-10s %12s  budget %12s  %s\n", name, value, limit, status)
+	}
+
+	fmt.Fprintf(w, "\nmtop bench: %s (exit status %d)\n", strings.Join(argv, " "), r.ExitCode)
+	line("Wall time", r.Wall.Round(time.Millisecond).String(), b.MaxWall.String(),
+		r.Wall.Seconds(), b.MaxWall.Seconds())
+	line("Peak RSS", formatBytes(r.MaxRSS), formatBytes(b.MaxRSS),
+		float64(r.MaxRSS), float64(b.MaxRSS))
+	line("Energy", fmt.Sprintf("%.2f J", r.Energy), fmt.Sprintf("%.2f J", b.MaxEnergy),
+		r.Energy, b.MaxEnergy)
+
+	return violations
+}
+
+// parseSize parses sizes like "512M", "2G" or "1048576" into bytes
+func parseSize(s string) (uint64, error) {
+	s = strings.TrimSpace(strings.ToUpper(s))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "B"), "I")
+
+	multiplier := uint64(1)
+	if n := len(s); n > 0 {
+		switch s[n-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			s = s[:n-1]
+		}
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return uint64(value * float64(multiplier)), nil
+}
+
+// formatBytes renders a byte count using binary units
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
+}
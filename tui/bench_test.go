@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/khoi/mtop/stats"
+)
+
+func TestBenchTreeFollowsDescendants(t *testing.T) {
+	at := func(s int) time.Time { return time.Unix(int64(s), 0) }
+	tree := newBenchTree(100)
+	tree.adopt([]benchProc{
+		{pid: 102, ppid: 101, start: at(3)}, // Listed before its parent
+		{pid: 101, ppid: 100, start: at(2)},
+		{pid: 100, ppid: 50, start: at(1)},
+		{pid: 200, ppid: 1, start: at(2)},
+		{pid: 300, ppid: 102, start: at(0)}, // Stale ppid from a reused pid
+	})
+	usage := map[int]stats.Rusage{
+		100: {PhysFootprint: 10, LifetimeMaxPhysFootprint: 15, BilledEnergy: 1e9},
+		101: {PhysFootprint: 20, LifetimeMaxPhysFootprint: 20, BilledEnergy: 2e9},
+		102: {PhysFootprint: 30, LifetimeMaxPhysFootprint: 30, BilledEnergy: 3e9},
+		200: {PhysFootprint: 1 << 30, BilledEnergy: 1e12},
+	}
+	read := func(pid int) (*stats.Rusage, error) {
+		if u, ok := usage[pid]; ok {
+			return &u, nil
+		}
+		return nil, errors.New("no such process")
+	}
+	tree.read(read)
+	if len(tree.members) != 3 || tree.peakRSS != 60 || tree.energy() != 6 {
+		t.Fatalf("members %v, peak %d, energy %g; want 100-102, 60 and 6", tree.members, tree.peakRSS, tree.energy())
+	}
+
+	// The command exits and its grandchild, reparented to launchd, goes on
+	usage[102] = stats.Rusage{PhysFootprint: 5, LifetimeMaxPhysFootprint: 80, BilledEnergy: 5e9}
+	tree.adopt([]benchProc{{pid: 102, ppid: 1, start: at(3)}, {pid: 101, ppid: 1, start: at(9)}})
+	tree.read(read)
+	if len(tree.members) != 1 || tree.peakRSS != 80 || tree.energy() != 8 {
+		t.Errorf("members %v, peak %d, energy %g; want 102, 80 and 8", tree.members, tree.peakRSS, tree.energy())
+	}
+}
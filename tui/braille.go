@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/khoi/mtop/history"
+	"github.com/khoi/mtop/widgets"
+)
+
+// Lines taken by the header and footer around a view's content
+const viewChromeLines = 8
+
+// Bounds for the height of detail view history charts, in rows
+const (
+	minChartHeight = 3
+	maxChartHeight = 16
+)
+
+// chartLayer is a series drawn over a history chart, scaled to 0-100
+type chartLayer struct {
+	values []float64
+	style  lipgloss.Style
+}
+
+// renderHistoryChart draws a percentage history as a braille chart with a
+// 0-100% axis, spanning the terminal width, with its anomalies and the
+// history's notes marked. Layers are drawn over it in order, each cell
+// showing the last one with dots there.
+func (m model) renderHistoryChart(title string, samples []history.Sample, height int, layers ...chartLayer) string {
+	const axis = 5
+	width := m.width - axis - 1
+	values := make([]float64, len(samples))
+	for i, s := range samples {
+		values[i] = s.Value
+	}
+	lines := widgets.Chart(values, width, height, 100)
+	found := findAnomalies(samples)
+	over := make([]map[int]string, len(lines))
+	for y := range over {
+		over[y] = make(map[int]string)
+	}
+	for _, layer := range layers {
+		for y, line := range widgets.Chart(layer.values, width, height, 100) {
+			for x, c := range []rune(line) {
+				if c != ' ' {
+					over[y][x] = layer.style.Render(string(c))
+				}
+			}
+		}
+	}
+	if len(lines) > 1 {
+		maps.Copy(over[0], noteMarks(width, samples, m.history.Marks()))
+	}
+	if len(lines) > 0 {
+		maps.Copy(over[len(lines)-1], anomalyMarks(width, len(samples), found))
+	}
+	for y := range lines {
+		lines[y] = drawOver(lines[y], over[y])
+	}
+
+	var b strings.Builder
+	b.Grow(len(title) + 2 + len(lines)*(m.width*3+axis+4))
+	b.WriteString(title)
+	b.WriteString(m.historyNote())
+	b.WriteString(anomalyNote(samples, found))
+	b.WriteString(":\n")
+	for i, line := range lines {
+		label := ""
+		switch i {
+		case 0:
+			label = "100%"
+		case len(lines) - 1:
+			label = "0%"
+		}
+		fmt.Fprintf(&b, "%*s%s%s\n", axis, label, glyphs.axis, line)
+	}
+	return b.String()
+}
+
+// drawOver replaces the cells of row found in over, by index
+func drawOver(row string, over map[int]string) string {
+	if len(over) == 0 {
+		return row
+	}
+	var b strings.Builder
+	for i, c := range []rune(row) {
+		if cell, ok := over[i]; ok {
+			b.WriteString(cell)
+		} else {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// chartHeight returns how many rows a history chart can use next to the
+// given view content without pushing the footer off screen
+func (m model) chartHeight(content string) int {
+	h := m.height - viewChromeLines - strings.Count(content, "\n") - 2
+	if h < minChartHeight {
+		return minChartHeight
+	}
+	if h > maxChartHeight {
+		return maxChartHeight
+	}
+	return h
+}
@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Minimum change in percentage points between refreshes worth highlighting;
+// set from the config file
+var changeThreshold = 1.0
+
+// Change highlight styles, set by applyTheme
+var changedStyle, risingStyle, fallingStyle lipgloss.Style
+
+// highlightChange renders a formatted value and, if it moved by at least
+// threshold since the previous refresh, highlights it and appends a ▲/▼
+// delta. The highlight lasts until the next refresh.
+func (m model) highlightChange(value string, cur, prev, threshold float64) string {
+	if !m.hasPrev {
+		return value
+	}
+
+	diff := cur - prev
+	switch {
+	case diff >= threshold:
+		return changedStyle.Render(value) + " " + risingStyle.Render(fmt.Sprintf("%s%.1f", glyphs.rising, diff))
+	case diff <= -threshold:
+		return changedStyle.Render(value) + " " + fallingStyle.Render(fmt.Sprintf("%s%.1f", glyphs.falling, -diff))
+	}
+	return value
+}
+
+// prevCore returns a core's usage from the previous refresh, or its current
+// usage if the core count changed
+func (m model) prevCore(i int) float64 {
+	if i < len(m.prevStats.CPU.Cores) {
+		return m.prevStats.CPU.Cores[i]
+	}
+	return m.stats.CPU.Cores[i]
+}
@@ -0,0 +1,222 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// channelConfig is a [[channels]] entry of the config file: somewhere
+// alert notifications can be sent, named for [notify] to route alerts to
+type channelConfig struct {
+	Name     string `toml:"name"`
+	Type     string `toml:"type"`     // One of channelTypes
+	URL      string `toml:"url"`      // Endpoint of webhook, slack and discord channels
+	To       string `toml:"to"`       // Recipients of an email channel, comma-separated
+	From     string `toml:"from"`     // Sender of an email channel; default sendmail's
+	Sendmail string `toml:"sendmail"` // sendmail program of an email channel; default /usr/sbin/sendmail
+}
+
+// Channel types: a macOS notification, a JSON POST, a Slack or Discord
+// incoming webhook, and mail handed to sendmail
+var channelTypes = []string{"desktop", "webhook", "slack", "discord", "email"}
+
+// The channel every config has, posting macOS notifications
+const desktopChannel = "desktop"
+
+// notifyChannel delivers alert notifications
+type notifyChannel interface {
+	send(n alertNotice) error
+}
+
+// alertNotice is an alert to notify about
+type alertNotice struct {
+	Name string // Alert name, e.g. "cpu" or "cpu normal"
+	Text string // What the header shows, e.g. "cpu 97.0% > 90%"
+}
+
+// Channels by name, set from the config; desktop unless the config names
+// another channel desktop
+var notifyChannels = map[string]notifyChannel{desktopChannel: desktopNotifier{}}
+
+// Client for the channels that post over HTTP
+var channelClient = &http.Client{Timeout: 10 * time.Second}
+
+// How long sendmail may take to accept a mail, and how long its output may
+// stay open after it exits or is killed
+const (
+	sendmailTimeout   = 10 * time.Second
+	sendmailWaitDelay = 500 * time.Millisecond
+)
+
+// applyChannels checks the [[channels]] entries and builds their channels
+func applyChannels(cfgs []channelConfig) error {
+	channels := map[string]notifyChannel{desktopChannel: desktopNotifier{}}
+	seen := map[string]bool{}
+	for i, c := range cfgs {
+		if c.Name == "" {
+			return fmt.Errorf("channel %d has no name", i+1)
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("channel %q is configured twice", c.Name)
+		}
+		seen[c.Name] = true
+		ch, err := newChannel(c)
+		if err != nil {
+			return fmt.Errorf("channel %q: %v", c.Name, err)
+		}
+		channels[c.Name] = ch
+	}
+	notifyChannels = channels
+	return nil
+}
+
+// newChannel builds a channel of its configured type
+func newChannel(c channelConfig) (notifyChannel, error) {
+	switch c.Type {
+	case "webhook", "slack", "discord":
+		u, err := url.Parse(c.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("url must be an http or https URL")
+		}
+		return webhookNotifier{kind: c.Type, url: c.URL}, nil
+	case "email":
+		if strings.TrimSpace(c.To) == "" {
+			return nil, fmt.Errorf("an email channel needs to")
+		}
+		sendmail := c.Sendmail
+		if sendmail == "" {
+			sendmail = "/usr/sbin/sendmail"
+		}
+		return emailNotifier{to: c.To, from: c.From, sendmail: expandHome(sendmail)}, nil
+	case "desktop":
+		return desktopNotifier{}, nil
+	}
+	return nil, fmt.Errorf("unknown type %q (want %s)", c.Type, strings.Join(channelTypes, ", "))
+}
+
+// checkRoutes checks that [notify] names only configured channels
+func checkRoutes(defaults []string, routes map[string][]string) error {
+	check := func(names []string) error {
+		for _, name := range names {
+			if _, ok := notifyChannels[name]; !ok {
+				return fmt.Errorf("unknown channel %q (want %s)", name, strings.Join(sortedKeys(notifyChannels), ", "))
+			}
+		}
+		return nil
+	}
+	if err := check(defaults); err != nil {
+		return err
+	}
+	for alert, names := range routes {
+		if err := check(names); err != nil {
+			return fmt.Errorf("route for %s: %v", alert, err)
+		}
+	}
+	return nil
+}
+
+// routeChannels names the channels an alert notifies: its route if it has
+// one, else the default channels
+func routeChannels(alert string) []string {
+	if names, ok := notifyRoutes[alert]; ok {
+		return names
+	}
+	return notifyDefaultChannels
+}
+
+// notificationTitle heads a notification sent off this Mac
+func notificationTitle() string {
+	return "mtop alert on " + hostname
+}
+
+// desktopNotifier posts macOS notifications
+type desktopNotifier struct{}
+
+func (desktopNotifier) send(n alertNotice) error {
+	return postNotification("mtop alert", n.Text)
+}
+
+// webhookNotifier posts a notification as JSON: Slack's and Discord's
+// incoming webhook payloads, or for a plain webhook the alert itself
+type webhookNotifier struct {
+	kind string // webhook, slack or discord
+	url  string
+}
+
+func (w webhookNotifier) send(n alertNotice) error {
+	var payload any
+	switch w.kind {
+	case "slack":
+		payload = map[string]string{"text": fmt.Sprintf("*%s*\n%s", notificationTitle(), n.Text)}
+	case "discord":
+		payload = map[string]string{"content": fmt.Sprintf("**%s**\n%s", notificationTitle(), n.Text)}
+	default:
+		payload = map[string]string{"host": hostname, "alert": n.Name, "text": n.Text, "time": time.Now().Format(time.RFC3339)}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := channelClient.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// emailNotifier hands a plain text mail to sendmail, which reads the
+// recipients from its headers
+type emailNotifier struct {
+	to, from, sendmail string
+}
+
+func (e emailNotifier) send(n alertNotice) error {
+	var msg strings.Builder
+	if e.from != "" {
+		fmt.Fprintf(&msg, "From: %s\r\n", e.from)
+	}
+	fmt.Fprintf(&msg, "To: %s\r\nSubject: %s: %s\r\n\r\n%s\r\n", e.to, notificationTitle(), n.Text, n.Text)
+	ctx, cancel := context.WithTimeout(context.Background(), sendmailTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, e.sendmail, "-t")
+	cmd.Stdin = strings.NewReader(msg.String())
+	cmd.WaitDelay = sendmailWaitDelay
+	out, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s timed out after %v", e.sendmail, sendmailTimeout)
+	}
+	// A sendmail that queued the mail and left a child delivering it has
+	// done its part
+	if err != nil && !errors.Is(err, exec.ErrWaitDelay) {
+		return fmt.Errorf("%s failed: %w: %s", e.sendmail, err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// sendNotices sends each notice to the channels routed for it, carrying
+// on past failures and returning the first
+func sendNotices(notices []alertNotice) error {
+	var first error
+	for _, n := range notices {
+		for _, name := range routeChannels(n.Name) {
+			if err := notifyChannels[name].send(n); err != nil && first == nil {
+				first = fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+	return first
+}
@@ -0,0 +1,129 @@
+package tui
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyChannelsAndRoutes(t *testing.T) {
+	defer func(c map[string]notifyChannel, d []string, r map[string][]string) {
+		notifyChannels, notifyDefaultChannels, notifyRoutes = c, d, r
+	}(notifyChannels, notifyDefaultChannels, notifyRoutes)
+	for _, bad := range []channelConfig{
+		{Type: "slack", URL: "https://hooks.slack.com/x"},
+		{Name: "chat", Type: "irc"},
+		{Name: "chat", Type: "slack", URL: "hooks.slack.com/x"},
+		{Name: "mail", Type: "email"},
+	} {
+		if err := applyChannels([]channelConfig{bad}); err == nil {
+			t.Errorf("applyChannels(%+v): no error", bad)
+		}
+	}
+	if err := applyChannels([]channelConfig{{Name: "a", Type: "desktop"}, {Name: "a", Type: "desktop"}}); err == nil {
+		t.Error("applyChannels with a name twice: no error")
+	}
+
+	if err := applyChannels([]channelConfig{{Name: "oncall", Type: "discord", URL: "https://discord.com/api/webhooks/1"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range []notifyConfig{
+		{Channels: []string{"pager"}},
+		{Route: map[string][]string{"cpu": {"pager"}}},
+		{Route: map[string][]string{"fans": {"oncall"}}},
+	} {
+		if err := applyNotify(bad); err == nil {
+			t.Errorf("applyNotify(%+v): no error", bad)
+		}
+	}
+	if err := applyNotify(notifyConfig{Route: map[string][]string{"cpu": {"desktop", "oncall"}, "cpu.usage": {}}}); err != nil {
+		t.Fatal(err)
+	}
+	for alert, want := range map[string][]string{
+		"cpu":       {"desktop", "oncall"},
+		"cpu.usage": {},
+		"memory":    {"desktop"},
+	} {
+		if got := routeChannels(alert); !reflect.DeepEqual(got, want) {
+			t.Errorf("routeChannels(%q) = %q, want %q", alert, got, want)
+		}
+	}
+}
+
+func TestWebhookPayloads(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = nil
+		json.NewDecoder(r.Body).Decode(&got)
+		if r.URL.Path == "/fail" {
+			http.Error(w, "no such hook", http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	notice := alertNotice{"cpu", "cpu 97.0% > 90%"}
+	for kind, want := range map[string]map[string]string{
+		"slack":   {"text": "*" + notificationTitle() + "*\ncpu 97.0% > 90%"},
+		"discord": {"content": "**" + notificationTitle() + "**\ncpu 97.0% > 90%"},
+	} {
+		if err := (webhookNotifier{kind, srv.URL}).send(notice); err != nil {
+			t.Fatalf("%s: %v", kind, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s payload = %q, want %q", kind, got, want)
+		}
+	}
+	if err := (webhookNotifier{"webhook", srv.URL}).send(notice); err != nil {
+		t.Fatal(err)
+	}
+	if got["alert"] != "cpu" || got["text"] != notice.Text || got["host"] != hostname || got["time"] == "" {
+		t.Errorf("webhook payload = %q", got)
+	}
+	if err := (webhookNotifier{"webhook", srv.URL + "/fail"}).send(notice); err == nil || !strings.Contains(err.Error(), "no such hook") {
+		t.Errorf("send to a failing hook: err = %v", err)
+	}
+}
+
+func TestEmailChannel(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "mail")
+	sendmail := filepath.Join(dir, "sendmail")
+	script := "#!/bin/sh\necho \"$@\" > " + out + ".args\ncat > " + out + "\n"
+	if err := os.WriteFile(sendmail, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	ch, err := newChannel(channelConfig{Name: "mail", Type: "email", To: "ops@example.com", From: "mtop@example.com", Sendmail: sendmail})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ch.send(alertNotice{"memory", "memory 95.0% > 90%"}); err != nil {
+		t.Fatal(err)
+	}
+	args, _ := os.ReadFile(out + ".args")
+	mail, _ := os.ReadFile(out)
+	if strings.TrimSpace(string(args)) != "-t" {
+		t.Errorf("sendmail args = %q, want -t", args)
+	}
+	for _, want := range []string{"From: mtop@example.com\r\n", "To: ops@example.com\r\n", "Subject: " + notificationTitle() + ": memory 95.0% > 90%\r\n"} {
+		if !strings.Contains(string(mail), want) {
+			t.Errorf("mail %q lacks %q", mail, want)
+		}
+	}
+	// A sendmail that leaves a child delivering in the background
+	if err := os.WriteFile(sendmail, []byte("#!/bin/sh\ncat > /dev/null\nsleep 5 &\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err := ch.send(alertNotice{"memory", "memory 95.0% > 90%"}); err != nil {
+		t.Errorf("sendmail leaving a child running: %v", err)
+	}
+	if took := time.Since(start); took > 3*time.Second {
+		t.Errorf("send took %v, want it to stop waiting for sendmail's child", took)
+	}
+}
@@ -0,0 +1,652 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/khoi/mtop/stats"
+)
+
+// Main runs the mtop command line on os.Args, as the mtop binary does,
+// exiting the process for the subcommands and on errors
+func Main() {
+	// Dispatch subcommands before parsing the top-level flags
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "bench":
+			os.Exit(runBench(os.Args[2:]))
+		case "stress":
+			os.Exit(runStress(os.Args[2:]))
+		case "verify":
+			os.Exit(runVerify(os.Args[2:]))
+		case "agent":
+			os.Exit(runAgent(os.Args[2:]))
+		case "import":
+			os.Exit(runImport(os.Args[2:]))
+		case "record":
+			os.Exit(runRecord(os.Args[2:]))
+		case "feed":
+			os.Exit(runFeed(os.Args[2:]))
+		case "replay":
+			// mtop replay FILE [OPTIONS] is --replay FILE --play [OPTIONS]
+			if len(os.Args) < 3 || strings.HasPrefix(os.Args[2], "-") {
+				fmt.Fprintf(os.Stderr, "Usage: %s replay FILE [--speed N] [OPTIONS]\n", os.Args[0])
+				os.Exit(2)
+			}
+			os.Args = append([]string{os.Args[0], "--replay", os.Args[2], "--play"}, os.Args[3:]...)
+		case "history":
+			os.Exit(runHistory(os.Args[2:]))
+		case "compare":
+			os.Exit(runCompare(os.Args[2:]))
+		case "service":
+			os.Exit(runService(os.Args[2:]))
+		case "alerts":
+			os.Exit(runAlerts(os.Args[2:]))
+		}
+	}
+
+	// Parse command line flags
+	configPath := flag.String("config", defaultConfigPath(), "Path to the TOML config file")
+	jsonMode := flag.Bool("json", false, "Output system stats in JSON format instead of TUI")
+	memoryMode := flag.String("memory-mode", "default", "How used memory is computed: default or activity-monitor")
+	debugDump := flag.Bool("debug-dump", false, "Print the raw counters behind every displayed number and exit")
+	historyWindow := flag.Duration("history", historyRetention, "How much metric history to keep for charts and statistics")
+	keepHistory := flag.Bool("keep-history", false, "Save the history on exit and restore it on the next start, within the --history window")
+	historyDBFlag := flag.Bool("history-db", false, "Append every sample to a SQLite database, read back with mtop history")
+	historyDBRetention := flag.Duration("history-db-retention", defaultHistoryDBRetention, "How long --history-db keeps samples (0 keeps them all)")
+	logFile := flag.String("log-file", "", "Append every sample and alert event to this file as JSON Lines, readable with --replay and mtop import")
+	logMaxSize := flag.Int("log-max-size", defaultLogMaxSize, "Rotate --log-file once it reaches this many MB (0: never)")
+	logMaxAge := flag.Duration("log-max-age", defaultLogMaxAge, "Rotate --log-file once its first sample is this old (0: never)")
+	logKeep := flag.Int("log-keep", defaultLogKeep, "How many rotated --log-file files to keep, as FILE.1 (newest) to FILE.N")
+	themeName := flag.String("theme", themes[0].Name, "Color theme: default, solarized, monochrome or high-contrast")
+	adaptive := flag.Bool("adaptive", false, "Sample less often while readings are steady")
+	attach := flag.Bool("attach", false, "Require a running \"mtop agent\" to read samples from")
+	local := flag.Bool("local", false, "Collect samples in this process even when an agent is running")
+	remote := flag.String("remote", "", "Show another Mac's samples, read over SSH from mtop feed on user@host (its agent, when running)")
+	remoteCommand := flag.String("remote-command", "mtop", "Command that runs mtop on the --remote host")
+	ascii := flag.Bool("ascii", false, "Draw with ASCII only, for terminals that cannot show Unicode (default: detected from TERM and the locale)")
+	replayPath := flag.String("replay", "", "Replay a recording (JSON Lines in the import format, or a session from mtop record) instead of showing live samples")
+	play := flag.Bool("play", false, "Start --replay playing instead of paused")
+	speed := flag.Int("speed", minReplaySpeed, "Replay speed in samples per refresh: 1 plays at the recorded pace, 8 eight times faster (up to 64)")
+	interval := intervalFlag(time.Second)
+	flag.Var(&interval, "interval", "Refresh interval, as a duration (500ms) or seconds (2)")
+	flag.Var(&interval, "d", "Shorthand for --interval")
+	promAddr := flag.String("prometheus", "", "Serve system metrics for Prometheus on this address (e.g. :9100) instead of showing the TUI")
+	otlpEndpoint := flag.String("otlp-endpoint", "", "Push metrics every --interval to this OpenTelemetry collector over OTLP/HTTP (e.g. http://localhost:4318), with headers from $OTEL_EXPORTER_OTLP_HEADERS")
+	serveAddr := flag.String("serve", "", "Serve stats, processes and history as JSON on this address (e.g. :8080) instead of showing the TUI")
+	allowOrigin := flag.String("allow-origin", "", "Comma-separated origins of browser pages allowed to open --serve's /ws stream, including pages on this host (* for any)")
+	format := flag.String("format", "", "Output format of --samples and --stream: text, json (same as --json), csv, influx (line protocol) or statsd (gauges)")
+	stream := flag.Bool("stream", false, "With --json or --format csv, influx or statsd, print one sample per --interval until killed (or for --samples N)")
+	influxURL := flag.String("influx-url", "", "POST samples in --format influx to this InfluxDB write URL every --interval, authenticated with $INFLUX_TOKEN")
+	statsdAddr := flag.String("statsd", "", "Send --format statsd gauges to this StatsD host:port over UDP every --interval")
+	flag.StringVar(&statsdPrefix, "statsd-prefix", statsdPrefix, "Prefix of StatsD metric names")
+	flag.BoolVar(stream, "watch", false, "Same as --stream")
+	selectInterface := flag.String("select-interface", "", "Only export and print these network interfaces, comma-separated names or patterns (en0,utun*)")
+	selectDisk := flag.String("select-disk", "", "Only export and print these disks, comma-separated names or patterns (disk0)")
+	flag.IntVar(&exportProcessCount, "export-processes", exportProcessCount, "Give this many processes their own series in Prometheus, OTLP and InfluxDB exports (0: none)")
+	flag.StringVar(&exportProcessOrder, "export-process-sort", exportProcessOrder, "Export the processes using the most cpu or memory")
+	flag.DurationVar(&exportProcessInterval, "export-process-interval", 0, "Pick the exported processes again only this often, exporting the same ones in between (0: every sample)")
+	fields := flag.String("fields", "", "With --json, print only these fields of each sample, comma-separated dotted paths (cpu,memory.swap,gpu.temp)")
+	summary := flag.Bool("summary", false, "With --json and --samples or --stream, end with a line giving each metric's p50, p95 and max across the run")
+	samples := flag.Int("samples", 0, "Print this many snapshots in --format (plain text by default), one per --interval, and exit")
+	comparePath := flag.String("compare", "", "Chart a recording (JSON Lines in the import format) next to live samples in the Compare view")
+	columns := flag.String("columns", "", "Comma-separated process table columns, in display order (e.g. pid,user,cpu,mem,name)")
+	quiet := flag.Bool("quiet", false, "For benchmarking: run at background priority, skip the costlier collectors and keep samples in memory only")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "mtop - System monitor for macOS\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: %s [OPTIONS]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s bench [OPTIONS] -- COMMAND [ARGS...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s stress [--cpu N] [--mem SIZE] [--duration D] [--seed N]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s verify [--tolerance PERCENT]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s compare [--baseline NAME] [--state idle|load] [--duration D] [--list]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s agent [--interval D] [--history D] [--ring PATH] [--metrics ADDR]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s import [--history-file PATH] [--baseline-file PATH] [--history D] FILE.jsonl...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s record -o FILE [--interval D] [--duration D]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s replay FILE [--speed N] [OPTIONS]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s feed [--interval D] [--history D]   (run by --remote over SSH)\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s history [--db PATH] [--since D] [--metric NAMES] [--format text|csv|json]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s service install|uninstall|status [--name NAME] [-- MTOP_ARGS...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s alerts test RULE [--file FILE.jsonl] [--send]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s           Start interactive TUI mode\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --json    Output current stats as JSON\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -d 2      Refresh every two seconds\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --samples 5 -d 10 >> mtop.log\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --samples 60 --json > before.jsonl   Record for --replay or --compare\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s record -o slow-build.mtop && %s replay slow-build.mtop --speed 8\n", os.Args[0], os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --json --stream | jq .stats.cpu.usage\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --json --stream --fields cpu.usage,memory.swap   Only those fields\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --json --samples 60 --summary | tail -1   p50/p95/max of each metric\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --format csv --samples 600 > usage.csv\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --influx-url 'http://localhost:8086/api/v2/write?org=me&bucket=mtop'\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --statsd localhost:8125 --statsd-prefix mac.mtop.\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --prometheus :9100   Serve /metrics for Prometheus\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --prometheus :9100 --select-interface en0 --select-disk disk0   Export only those devices\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --prometheus :9100 --export-processes 10 --export-process-sort memory --export-process-interval 5m\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --otlp-endpoint http://localhost:4318   Feed an OpenTelemetry collector\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --serve :8080 && curl localhost:8080/api/v1/processes?limit=5\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --debug-dump > mtop-debug.txt\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --quiet -d 5   Watch a benchmark while disturbing it as little as possible\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s bench --max-rss 512M --max-time 30s -- make build\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s stress --cpu 4 --mem 2G --duration 30s\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s verify    Compare readings against vm_stat, top and iostat\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s compare --baseline m2-air   Check this Mac against a typical one at idle\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s agent &   Share samples with every mtop started after it\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s service install -- --prometheus :9100   Serve metrics from login on, via launchd\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --remote me@build-1   Watch another Mac over SSH (mtop must be installed there)\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s import other-host.jsonl && %s --keep-history --history 1h\n", os.Args[0], os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s history --since 1h --metric cpu.usage   Readings recorded with --history-db\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --log-file ~/mtop.jsonl --log-max-size 50   Log samples and alerts while watching\n", os.Args[0])
+	}
+	flag.Parse()
+
+	// Flags given on the command line take precedence over the config file
+	setFlags := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	cfg, err := loadConfig(*configPath, setFlags["config"])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+	if !setFlags["memory-mode"] && cfg.MemoryMode != "" {
+		*memoryMode = cfg.MemoryMode
+	}
+	if !setFlags["history"] && cfg.History != 0 {
+		*historyWindow = cfg.History
+	}
+	if !setFlags["theme"] && cfg.Theme != "" {
+		*themeName = cfg.Theme
+	}
+	if !setFlags["keep-history"] && cfg.KeepHistory {
+		*keepHistory = true
+	}
+	if !setFlags["history-db"] && cfg.HistoryDB {
+		*historyDBFlag = true
+	}
+	if !setFlags["history-db-retention"] && cfg.DBRetention != 0 {
+		*historyDBRetention = cfg.DBRetention
+	}
+	if !setFlags["log-file"] && cfg.LogFile != "" {
+		*logFile = expandHome(cfg.LogFile)
+	}
+	if !setFlags["log-max-size"] && cfg.LogMaxSize != nil {
+		*logMaxSize = *cfg.LogMaxSize
+	}
+	if !setFlags["log-max-age"] && cfg.LogMaxAge != nil {
+		*logMaxAge = *cfg.LogMaxAge
+	}
+	if !setFlags["log-keep"] && cfg.LogKeep != nil {
+		*logKeep = *cfg.LogKeep
+	}
+	if !setFlags["adaptive"] && cfg.Adaptive {
+		*adaptive = true
+	}
+	adaptiveSampling = *adaptive
+	if !setFlags["columns"] && len(cfg.Columns) > 0 {
+		*columns = strings.Join(cfg.Columns, ",")
+	}
+	if err := applyCollectors(cfg.Collectors); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+	if err := applyCadences(cfg.Cadence); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+	// Before the layout, which replaces the default the plugins' panel joins
+	if err := applyPlugins(cfg.Plugins); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+	if err := applyOverviewLayout(cfg.Overview); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+	if err := applyAlerts(cfg.Alerts); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+	if err := applyNormal(cfg.Normal); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+	if err := applyAnomaly(cfg.Anomaly); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+	if err := applyChannels(cfg.Channels); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+	if err := applyNotify(cfg.Notify); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+	if err := applyHooks(cfg.Hooks); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+	if err := applyMute(cfg.Mute); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+	if err := applyMaintenance(cfg.Maintenance); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+	if err := applyThresholds(cfg.Thresholds); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+	if err := applyStatusTemplates(cfg.Header, cfg.Footer); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+	if err := bindKeys(cfg.KeyPreset, cfg.Keys); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+
+	// Quiet mode overrides the collectors and notifications the config turns on
+	if *quiet {
+		if err := enterQuietMode(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		// Samples stay in memory: no history or usual readings are saved
+		*keepHistory = false
+		*historyDBFlag = false
+		*logFile = ""
+	}
+	// Another Mac's samples are not this one's history
+	if *remote != "" {
+		if *replayPath != "" || *attach || *local {
+			fmt.Fprintf(os.Stderr, "--remote cannot be combined with --replay, --attach or --local\n")
+			os.Exit(2)
+		}
+		*keepHistory = false
+		*historyDBFlag = false
+		*logFile = ""
+	}
+
+	accounting, err := stats.ParseMemoryAccounting(*memoryMode)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --memory-mode: %v\n", err)
+		os.Exit(1)
+	}
+	collector.MemoryAccounting = accounting
+
+	if *historyWindow <= 0 {
+		fmt.Fprintf(os.Stderr, "Invalid --history: must be positive\n")
+		os.Exit(1)
+	}
+	historyRetention = *historyWindow
+
+	// Without --ascii or the ascii setting, the terminal decides
+	switch {
+	case setFlags["ascii"]:
+	case cfg.ASCII != nil:
+		*ascii = *cfg.ASCII
+	default:
+		*ascii = detectASCII()
+	}
+	setASCII(*ascii)
+
+	t, err := findTheme(*themeName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --theme: %v\n", err)
+		os.Exit(1)
+	}
+	defaultTheme = t
+	applyTheme(t)
+
+	startView := OverviewMode
+	if cfg.DefaultView != "" {
+		if startView, err = parseView(cfg.DefaultView); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid config %s: %v\n", *configPath, err)
+			os.Exit(1)
+		}
+	}
+	if cfg.RefreshRate != 0 {
+		if err := checkRefreshRate(cfg.RefreshRate); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid config %s: refresh_rate %v\n", *configPath, err)
+			os.Exit(1)
+		}
+		if !setFlags["interval"] && !setFlags["d"] {
+			interval = intervalFlag(cfg.RefreshRate)
+		}
+	}
+	refreshRate := time.Duration(interval)
+
+	// --influx-url streams the line protocol to InfluxDB instead of stdout
+	if *influxURL != "" {
+		if (*format != "" && *format != "influx") || *jsonMode {
+			fmt.Fprintf(os.Stderr, "--influx-url sends --format influx; it cannot be combined with another format\n")
+			os.Exit(2)
+		}
+		*format = "influx"
+		*stream = *stream || *samples == 0
+	}
+	// --statsd likewise sends gauges to a StatsD server
+	if *statsdAddr != "" {
+		if *influxURL != "" || (*format != "" && *format != "statsd") || *jsonMode {
+			fmt.Fprintf(os.Stderr, "--statsd sends --format statsd; it cannot be combined with another format\n")
+			os.Exit(2)
+		}
+		*format = "statsd"
+		*stream = *stream || *samples == 0
+	}
+	if selectedInterfaces, err = parseDeviceSelection(*selectInterface); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --select-interface: %v\n", err)
+		os.Exit(2)
+	}
+	if selectedDisks, err = parseDeviceSelection(*selectDisk); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --select-disk: %v\n", err)
+		os.Exit(2)
+	}
+	if err := checkExportProcesses(exportProcessCount, exportProcessOrder, exportProcessInterval); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
+	}
+	if err := checkStatsdPrefix(statsdPrefix); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --statsd-prefix %v\n", err)
+		os.Exit(2)
+	}
+
+	// --json is short for --format json
+	switch {
+	case *jsonMode && *format != "" && *format != "json":
+		fmt.Fprintf(os.Stderr, "--json and --format %s cannot be combined\n", *format)
+		os.Exit(2)
+	case *jsonMode:
+		*format = "json"
+	case *format == "json":
+		*jsonMode = true
+	case *format != "" && !slices.Contains(batchFormats, *format):
+		fmt.Fprintf(os.Stderr, "Invalid --format: want %s\n", strings.Join(batchFormats, ", "))
+		os.Exit(2)
+	case (*format == "csv" || *format == "influx" || *format == "statsd") && *samples == 0 && !*stream:
+		// A lone CSV snapshot is a header and one row; influx and statsd are
+		// one sample
+		*samples = 1
+	}
+
+	// One-shot output reports every collector
+	if *debugDump || *jsonMode || *samples > 0 {
+		subscribe("output", onDemandCollectors...)
+	}
+
+	if *samples < 0 {
+		fmt.Fprintf(os.Stderr, "Invalid --samples: must not be negative\n")
+		os.Exit(2)
+	}
+	if *stream && (*format == "" || *format == "text") {
+		fmt.Fprintf(os.Stderr, "--stream needs --json or --format csv, influx or statsd; use --samples N for plain text\n")
+		os.Exit(2)
+	}
+	if *fields != "" {
+		if !*jsonMode {
+			fmt.Fprintf(os.Stderr, "--fields needs --json\n")
+			os.Exit(2)
+		}
+		if jsonFields, err = parseFields(*fields); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --fields: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	if *summary && (!*jsonMode || (*samples == 0 && !*stream)) {
+		fmt.Fprintf(os.Stderr, "--summary needs --json with --samples N or --stream\n")
+		os.Exit(2)
+	}
+	if *samples > 0 || *stream {
+		// Batch mode, like top -l: no TTY needed, for scripts and cron.
+		// Streaming is batch mode without an end.
+		var out io.Writer = os.Stdout
+		if *influxURL != "" {
+			out = newInfluxPoster(*influxURL)
+		}
+		if *statsdAddr != "" {
+			sender, err := newStatsdSender(*statsdAddr)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --statsd: %v\n", err)
+				os.Exit(2)
+			}
+			out = sender
+		}
+		if err := runBatch(out, *samples, refreshRate, *format, *summary); err != nil {
+			fmt.Fprintf(os.Stderr, "Error collecting system stats: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *promAddr != "" {
+		fmt.Fprintf(os.Stderr, "Serving metrics on http://%s/metrics\n", *promAddr)
+		if err := runExporter(*promAddr, refreshRate); err != nil {
+			fmt.Fprintf(os.Stderr, "Error serving metrics: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *otlpEndpoint != "" {
+		fmt.Fprintf(os.Stderr, "Exporting metrics to %s\n", otlpMetricsURL(*otlpEndpoint))
+		if err := runOTLP(*otlpEndpoint, refreshRate); err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting metrics: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *serveAddr != "" {
+		if *allowOrigin != "" {
+			liveOrigins = strings.Split(*allowOrigin, ",")
+		}
+		fmt.Fprintf(os.Stderr, "Serving the API on http://%s/api/v1/\n", *serveAddr)
+		if err := runServer(*serveAddr, refreshRate); err != nil {
+			fmt.Fprintf(os.Stderr, "Error serving the API: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *debugDump {
+		// Sample twice so the rate-based collectors have a delta to show
+		collectSystemStats(context.Background())
+		time.Sleep(refreshRate)
+		stats, err := collectSystemStats(context.Background())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error collecting system stats: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Print(formatDebugDump(collectDebugDump(stats)))
+		return
+	}
+
+	if *jsonMode {
+		// JSON output mode; a running agent already has a sample ready
+		var stats SystemStats
+		err := errNoAgent
+		if !*local {
+			stats, err = agentSample(sampleRingPath())
+		}
+		if err != nil {
+			// With an interval given, rates cover it as in --debug-dump
+			if setFlags["interval"] || setFlags["d"] {
+				collectSystemStats(context.Background())
+				time.Sleep(refreshRate)
+			}
+			stats, err = collectSystemStats(context.Background())
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error collecting system stats: %v\n", err)
+			os.Exit(1)
+		}
+
+		var doc any = selectDevices(stats)
+		if jsonFields != nil {
+			if doc, err = selectFields(doc, jsonFields); err != nil {
+				fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		jsonData, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(string(jsonData))
+		return
+	}
+
+	// TUI mode
+	// Share a running agent's samples instead of collecting them again
+	var agent *agentReader
+	if *attach && *local {
+		fmt.Fprintf(os.Stderr, "--attach and --local cannot be combined\n")
+		os.Exit(2)
+	}
+	if *attach && *quiet {
+		fmt.Fprintf(os.Stderr, "--attach and --quiet cannot be combined: the agent runs at normal priority\n")
+		os.Exit(2)
+	}
+	if !*local && !*quiet && *replayPath == "" && *remote == "" {
+		agent, err = attachAgent(sampleRingPath())
+		if err != nil && *attach {
+			fmt.Fprintf(os.Stderr, "Cannot attach: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	var m model
+	if *replayPath != "" {
+		shots, notes, err := readReplay(*replayPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot replay %s: %v\n", *replayPath, err)
+			os.Exit(1)
+		}
+		m = newReplayModel(shots, notes)
+	} else {
+		opts := []Option{withAgent(agent)}
+		var feed *remoteFeed
+		if *remote != "" {
+			fmt.Fprintf(os.Stderr, "Connecting to %s...\n", *remote)
+			if feed, err = startRemote(*remote, *remoteCommand, refreshRate); err != nil {
+				fmt.Fprintf(os.Stderr, "Cannot monitor %s: %v\n", *remote, err)
+				os.Exit(1)
+			}
+			opts = append(opts, withRemote(feed))
+		}
+		if *historyDBFlag {
+			if *historyDBRetention < 0 {
+				fmt.Fprintf(os.Stderr, "Invalid --history-db-retention: must not be negative\n")
+				os.Exit(2)
+			}
+			db, err := openHistoryDB(historyDBPath(), *historyDBRetention)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Cannot open the history database: %v\n", err)
+				os.Exit(1)
+			}
+			opts = append(opts, withHistoryDB(db))
+		}
+		if *logFile != "" {
+			log, err := openSampleLog(*logFile, int64(*logMaxSize)<<20, *logMaxAge, *logKeep)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Cannot open --log-file: %v\n", err)
+				os.Exit(1)
+			}
+			opts = append(opts, withSampleLog(log))
+		}
+		if m, err = NewModel(opts...); err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting: %v\n", err)
+			os.Exit(1)
+		}
+		if agent != nil {
+			if err := m.restoreAgentHistory(agentSocketPath(), time.Now()); err != nil {
+				m.setStatus(err.Error())
+			}
+		}
+		if feed != nil {
+			m.restoreRemoteHistory()
+		}
+	}
+	m.viewMode = startView
+	m.refreshRate = refreshRate
+	if m.replay {
+		// One sample per tick at the recording's own interval is its
+		// original pace
+		if !setFlags["interval"] && !setFlags["d"] {
+			m.refreshRate = recordedInterval(m.scrollback)
+		}
+		if *speed < minReplaySpeed || *speed > maxReplaySpeed {
+			fmt.Fprintf(os.Stderr, "Invalid --speed: must be between %d and %d\n", minReplaySpeed, maxReplaySpeed)
+			os.Exit(2)
+		}
+		m.replaySpeed, m.playing = *speed, *play
+	}
+	if *columns != "" {
+		cols, err := parseColumns(*columns)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --columns: %v\n", err)
+			os.Exit(1)
+		}
+		m.columns = cols
+	}
+	if *comparePath != "" {
+		baseline, err := loadBaseline(*comparePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot compare with %s: %v\n", *comparePath, err)
+			os.Exit(1)
+		}
+		m.baseline, m.baselineName = baseline, filepath.Base(*comparePath)
+	}
+	if len(normalLimits) > 0 && *replayPath == "" && *remote == "" {
+		if m.week, err = readWeekBaseline(weekBaselinePath()); err != nil {
+			m.week = make(weekBaseline)
+			m.setStatus(fmt.Sprintf("Usual readings not loaded: %v", err))
+		}
+	}
+	if *keepHistory && *replayPath == "" {
+		if err := m.restoreHistory(historyPath(), time.Now()); err != nil {
+			m.setStatus(fmt.Sprintf("History not restored: %v", err))
+		}
+	}
+
+	p := tea.NewProgram(m)
+	final, err := p.Run()
+	if err != nil {
+		fmt.Println("Error running program:", err)
+		os.Exit(1)
+	}
+	if *keepHistory && *replayPath == "" {
+		if err := saveHistory(historyPath(), final.(model).history); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving history: %v\n", err)
+		}
+	}
+	if db := final.(model).historyDB; db != nil {
+		db.close()
+	}
+	if log := final.(model).sampleLog; log != nil {
+		log.close()
+	}
+	if feed := final.(model).remote; feed != nil {
+		feed.close()
+	}
+	if week := final.(model).week; week != nil && !*quiet {
+		if err := saveWeekBaseline(weekBaselinePath(), week); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving usual readings: %v\n", err)
+		}
+	}
+}
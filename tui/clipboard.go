@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"golang.org/x/sys/unix"
+)
+
+// copyToClipboard places text on the macOS pasteboard using pbcopy
+func copyToClipboard(text string) error {
+	cmd := exec.Command("pbcopy")
+	cmd.Stdin = strings.NewReader(text)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("pbcopy failed: %w", err)
+	}
+	return nil
+}
+
+// processArgs returns the full command line of a process from
+// kern.procargs2, which holds argc followed by the executable path and the
+// NUL-separated arguments
+func processArgs(pid int) ([]string, error) {
+	buf, err := unix.SysctlRaw("kern.procargs2", pid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read arguments of pid %d: %w", pid, err)
+	}
+	if len(buf) < 4 {
+		return nil, fmt.Errorf("short argument buffer for pid %d", pid)
+	}
+
+	argc := int(binary.LittleEndian.Uint32(buf[:4]))
+	buf = buf[4:]
+
+	// Skip the executable path and the NUL padding after it
+	if i := bytes.IndexByte(buf, 0); i >= 0 {
+		buf = buf[i:]
+	}
+	buf = bytes.TrimLeft(buf, "\x00")
+
+	args := make([]string, 0, argc)
+	for len(args) < argc && len(buf) > 0 {
+		i := bytes.IndexByte(buf, 0)
+		if i < 0 {
+			i = len(buf)
+		}
+		args = append(args, string(buf[:i]))
+		if i == len(buf) {
+			break
+		}
+		buf = buf[i+1:]
+	}
+	return args, nil
+}
+
+// commandLine returns the process's command line quoted for a shell,
+// falling back to its executable path or name
+func commandLine(p ProcessStats) string {
+	args, err := processArgs(p.PID)
+	if err != nil || len(args) == 0 {
+		if p.Path != "" {
+			return shellQuote(p.Path)
+		}
+		return shellQuote(p.Name)
+	}
+	for i, a := range args {
+		args[i] = shellQuote(a)
+	}
+	return strings.Join(args, " ")
+}
+
+// shellQuote quotes an argument for a POSIX shell. Anything beyond plain
+// words goes in single quotes, inside which the shell expands nothing.
+func shellQuote(a string) string {
+	if a != "" && strings.Trim(a, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:,+@%") == "" {
+		return a
+	}
+	return "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+}
+
+// copySelectedPID copies the selected process's pid
+func (m *model) copySelectedPID() {
+	p, ok := m.selectedProcess()
+	if !ok {
+		return
+	}
+	m.copyWithStatus(strconv.Itoa(p.PID), fmt.Sprintf("Copied PID %d", p.PID))
+}
+
+// copySelectedCommand copies the selected process's command line
+func (m *model) copySelectedCommand() {
+	p, ok := m.selectedProcess()
+	if !ok {
+		return
+	}
+	m.copyWithStatus(commandLine(p), fmt.Sprintf("Copied command line of %s (%d)", p.Name, p.PID))
+}
+
+// copyPanel copies the current panel as plain text
+func (m *model) copyPanel() {
+	m.copyWithStatus(ansi.Strip(m.renderContent()), "Copied panel to clipboard")
+}
+
+func (m *model) copyWithStatus(text, status string) {
+	if err := copyToClipboard(text); err != nil {
+		m.setStatus(fmt.Sprintf("Copy failed: %v", err))
+		return
+	}
+	m.setStatus(status)
+}
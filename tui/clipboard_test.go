@@ -0,0 +1,23 @@
+package tui
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	args := []string{"plain", "", "$HOME", "`id`", "it's", "a b", `back\\slash "q"`, "naïve ☃", "--flag=x,y", "*", "~"}
+	for _, a := range args {
+		quoted := shellQuote(a)
+		out, err := exec.Command("sh", "-c", "printf %s "+quoted).Output()
+		if err != nil {
+			t.Fatalf("sh -c printf %%s %s: %v", quoted, err)
+		}
+		if string(out) != a {
+			t.Errorf("shellQuote(%q) = %s, which the shell reads as %q", a, quoted, out)
+		}
+	}
+	if got := shellQuote("/usr/bin/true"); got != "/usr/bin/true" {
+		t.Errorf("plain path quoted as %s", got)
+	}
+}
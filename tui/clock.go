@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+)
+
+// Smallest difference between the wall and monotonic clocks across one
+// sample that counts as the wall clock being set. Tick jitter and NTP
+// slewing stay far below it.
+const clockJumpThreshold = 2 * time.Second
+
+// clockJump returns how far the wall clock was set between two readings
+// from time.Now: the wall time that passed less the monotonic time that
+// did. It is zero when either reading has no monotonic part, e.g. a
+// sample decoded from the agent, as then the two cannot be told apart.
+func clockJump(prev, now time.Time) time.Duration {
+	if prev.IsZero() || prev == prev.Round(0) || now == now.Round(0) {
+		return 0
+	}
+	return now.Round(0).Sub(prev.Round(0)) - now.Sub(prev)
+}
+
+// noteClockJump marks the history and tells the user when the wall clock
+// was set between the samples at prev and now. Rates and charts use the
+// monotonic readings and are unaffected; only the times shown change.
+func (m *model) noteClockJump(prev, now time.Time) {
+	jump := clockJump(prev, now)
+	if jump > -clockJumpThreshold && jump < clockJumpThreshold {
+		return
+	}
+	by := jump.Round(time.Second).String()
+	if jump > 0 {
+		by = "+" + by
+	}
+	m.history.Annotate(now, "clock set "+by)
+	m.setStatus(fmt.Sprintf("Wall clock set %s at %s", by, now.Format("15:04:05")))
+}
+
+// historyNote names the newest mark in the history for chart titles
+func (m model) historyNote() string {
+	marks := m.history.Marks()
+	if len(marks) == 0 {
+		return ""
+	}
+	mark := marks[len(marks)-1]
+	return fmt.Sprintf(" (%s at %s)", mark.Text, mark.Time.Format("15:04:05"))
+}
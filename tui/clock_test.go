@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClockJump(t *testing.T) {
+	now := time.Now()
+	if jump := clockJump(now, now.Add(time.Second)); jump != 0 {
+		t.Errorf("jump between monotonic readings one second apart = %v", jump)
+	}
+	// Without monotonic readings, e.g. decoded samples, nothing is reported
+	if jump := clockJump(now.Round(0), now.Round(0).Add(time.Hour)); jump != 0 {
+		t.Errorf("jump without monotonic readings = %v", jump)
+	}
+	if jump := clockJump(time.Time{}, now); jump != 0 {
+		t.Errorf("jump from the zero time = %v", jump)
+	}
+}
+
+func TestHistoryNoteInChartTitle(t *testing.T) {
+	m := model{width: 60, height: 24, history: newHistory()}
+	at := time.Date(2026, 3, 29, 3, 0, 1, 0, time.Local)
+	m.history.Add(metricCPU, at, 50)
+	if title := strings.SplitN(m.renderHistoryChart("CPU Usage History", nil, 4), "\n", 2)[0]; title != "CPU Usage History:" {
+		t.Errorf("title without marks = %q", title)
+	}
+
+	m.history.Annotate(at, "clock set +1h0m0s")
+	title := strings.SplitN(m.renderHistoryChart("CPU Usage History", nil, 4), "\n", 2)[0]
+	if title != "CPU Usage History (clock set +1h0m0s at 03:00:01):" {
+		t.Errorf("title = %q", title)
+	}
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// processGrouping is how the process table folds processes into rows
+type processGrouping int
+
+const (
+	groupNone      processGrouping = iota
+	groupApp                       // By app bundle in the executable path, see appName
+	groupCoalition                 // By resource coalition, as the kernel accounts for them
+)
+
+// Plural nouns for the rows of each grouping, as shown in the table header
+var groupingNouns = []string{"processes", "apps", "coalitions"}
+
+// groupProcesses folds processes into one row per app or coalition, named
+// after the member with the lowest PID and summing the members' usage.
+// Coalition rows take CPU, GPU, energy and disk totals from the kernel's
+// accounting when there is any; processes without a known coalition are
+// grouped by app.
+func groupProcesses(procs []ProcessStats, grouping processGrouping, coalitions []CoalitionStats) []ProcessStats {
+	if grouping == groupNone {
+		return procs
+	}
+	accounting := make(map[uint64]CoalitionStats, len(coalitions))
+	for _, c := range coalitions {
+		accounting[c.ID] = c
+	}
+
+	sorted := make([]ProcessStats, len(procs))
+	copy(sorted, procs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].PID < sorted[j].PID })
+
+	type group struct {
+		row     ProcessStats
+		members int
+	}
+	groups := make(map[string]*group)
+	var order []string
+	for _, p := range sorted {
+		key := "app " + appName(p)
+		if grouping == groupCoalition && p.Coalition != 0 {
+			key = fmt.Sprintf("coalition %d", p.Coalition)
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &group{row: p}
+			g.row.Name = appName(p)
+			groups[key] = g
+			order = append(order, key)
+		} else {
+			g.row.CPU += p.CPU
+			g.row.CPUTime += p.CPUTime
+			g.row.RSS += p.RSS
+			g.row.Threads += p.Threads
+			g.row.FDs += p.FDs
+			g.row.DiskRead += p.DiskRead
+			g.row.DiskWritten += p.DiskWritten
+			g.row.Energy += p.Energy
+			g.row.GPU += p.GPU
+			g.row.NetIn += p.NetIn
+			g.row.NetOut += p.NetOut
+			g.row.Ports = slices.Concat(g.row.Ports, p.Ports)
+		}
+		g.members++
+	}
+
+	rows := make([]ProcessStats, 0, len(order))
+	for _, key := range order {
+		g := groups[key]
+		if c, ok := accounting[g.row.Coalition]; ok && grouping == groupCoalition {
+			g.row.CPU, g.row.CPUTime, g.row.GPU = c.CPU, c.CPUTime, c.GPU
+			g.row.Energy, g.row.DiskRead, g.row.DiskWritten = c.Energy, c.DiskRead, c.DiskWritten
+		}
+		if g.members > 1 {
+			g.row.Name = fmt.Sprintf("%s (%d)", g.row.Name, g.members)
+			slices.Sort(g.row.Ports)
+			g.row.Ports = slices.Compact(g.row.Ports)
+		}
+		rows = append(rows, g.row)
+	}
+	return rows
+}
+
+// tableProcesses returns the rows of the process table for stats under the
+// current grouping
+func (m model) tableProcesses(stats SystemStats) []ProcessStats {
+	return groupProcesses(stats.Processes, m.procGroup, stats.Coalitions)
+}
+
+// cycleGrouping switches the process table to the next grouping
+func (m *model) cycleGrouping() {
+	m.procGroup = (m.procGroup + 1) % processGrouping(len(groupingNouns))
+	m.procCursor, m.procOffset = 0, 0
+	m.setStatus("Showing " + groupingNouns[m.procGroup])
+}
@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGroupProcesses(t *testing.T) {
+	procs := []ProcessStats{
+		{PID: 310, Name: "WebContent", Path: "/System/Library/Frameworks/WebKit.framework/XPCServices/WebContent.xpc/WebContent", CPU: 20, RSS: 300, Coalition: 7},
+		{PID: 300, Name: "Safari", Path: "/Applications/Safari.app/Contents/MacOS/Safari", CPU: 5, RSS: 200, Coalition: 7, Ports: []int{5353, 8080}},
+		{PID: 320, Name: "Safari Helper", Path: "/Applications/Safari.app/Contents/MacOS/Safari Helper", CPU: 1, RSS: 50, Coalition: 9, Ports: []int{443, 5353}},
+		{PID: 400, Name: "restricted", CPU: 0, RSS: 10},
+	}
+
+	apps := groupProcesses(procs, groupApp, nil)
+	if len(apps) != 3 {
+		t.Fatalf("got %d app rows, want 3: %+v", len(apps), apps)
+	}
+	if apps[0].Name != "Safari (2)" || apps[0].PID != 300 || apps[0].CPU != 6 || apps[0].RSS != 250 || formatPorts(apps[0].Ports) != "443,5353,8080" {
+		t.Errorf("Safari app row = %+v", apps[0])
+	}
+	if apps[1].Name != "WebContent" {
+		t.Errorf("XPC service grouped with %q, want its own row by path", apps[1].Name)
+	}
+
+	coalitions := []CoalitionStats{{ID: 7, CPU: 40, CPUTime: time.Minute, Energy: 12}}
+	rows := groupProcesses(procs, groupCoalition, coalitions)
+	if len(rows) != 3 {
+		t.Fatalf("got %d coalition rows, want 3: %+v", len(rows), rows)
+	}
+	if r := rows[0]; r.Name != "Safari (2)" || r.RSS != 500 || r.CPU != 40 || r.CPUTime != time.Minute || r.Energy != 12 {
+		t.Errorf("Safari coalition row = %+v, want members' memory and the kernel's CPU and energy", r)
+	}
+	if r := rows[1]; r.Name != "Safari" || r.CPU != 1 {
+		t.Errorf("coalition without accounting = %+v, want the member sums", r)
+	}
+	if r := rows[2]; r.Name != "restricted" || r.Coalition != 0 {
+		t.Errorf("process without a coalition = %+v, want an app row", r)
+	}
+
+	if got := groupProcesses(procs, groupNone, coalitions); len(got) != len(procs) {
+		t.Errorf("ungrouped table has %d rows, want %d", len(got), len(procs))
+	}
+}
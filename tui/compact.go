@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/khoi/mtop/widgets"
+)
+
+// Smallest terminal the full views are laid out for. Below it every view
+// collapses to the compact layout instead of wrapping.
+const (
+	compactWidth  = 80
+	compactHeight = 24
+)
+
+// Header and footer lines of the compact layout: tab bar, status, two
+// rules and the key hint
+const compactChromeLines = 5
+
+// compact reports whether the terminal is below the full layout's size
+func (m model) compact() bool {
+	return m.width < compactWidth || m.height < compactHeight
+}
+
+// compactGauges lists the compact layout's rows, most important first:
+// usage gauges, load, temperature and power, then the busiest processes
+func (m model) compactGauges() []string {
+	barWidth := max(m.width-13, 1)
+	gauge := func(label, alert string, percent float64) string {
+		return fmt.Sprintf("%-4s %s %5.1f%%", label, alertGauge(m.stats, alert, percent, barWidth), percent)
+	}
+	rows := []string{
+		gauge("CPU", "cpu", m.stats.CPU.Usage),
+		gauge("MEM", "memory", m.stats.Memory.Usage),
+		gauge("GPU", "gpu", m.stats.GPU.Usage),
+		gauge("SWAP", "swap", m.stats.Memory.Swap.Usage),
+		fmt.Sprintf("Load %.2f %.2f %.2f", m.stats.CPU.LoadAvg[0], m.stats.CPU.LoadAvg[1], m.stats.CPU.LoadAvg[2]),
+		fmt.Sprintf("Temp %s | Power %.1f W",
+			alertText(m.stats, "temp", fmt.Sprintf("%.1f°C", m.stats.CPU.Temp)), m.stats.Power.Package),
+	}
+
+	procs := make([]ProcessStats, len(m.stats.Processes))
+	copy(procs, m.stats.Processes)
+	sort.SliceStable(procs, func(i, j int) bool { return procs[i].CPU > procs[j].CPU })
+	nameWidth := max(m.width-8, 1)
+	for _, p := range procs {
+		rows = append(rows, fmt.Sprintf("%s %6.1f%%", widgets.Pad(p.Name, nameWidth, false), p.CPU))
+	}
+	return rows
+}
+
+// renderCompact draws the whole frame for a small terminal: one column of
+// the readings that fit, every line cut to the width so nothing wraps
+func (m model) renderCompact() string {
+	var lines []string
+	lines = append(lines, strings.TrimSuffix(tabBar(m.viewMode, m.width), "\n"))
+	var status string
+	if m.noting {
+		status = m.notePrompt()
+	} else if m.muting {
+		status = m.mutePrompt()
+	} else if m.paused {
+		status = m.pausedStatus()
+	} else {
+		status = fmt.Sprintf("%s | Thermal: %s%s",
+			m.lastUpdate.Format("15:04:05"), renderThermal(m.stats.Thermal), renderAlerts(m.alerts(), m.sampledAt))
+	}
+	lines = append(lines, m.remoteNote()+status)
+	rule := ruleStyle.Render(strings.Repeat(glyphs.rule, m.width))
+	lines = append(lines, rule)
+
+	var notes []string
+	if m.lastError != "" {
+		notes = append(notes, glyphs.warn+" "+m.lastError)
+	}
+	if m.statusMsg != "" && time.Since(m.statusAt) < statusDuration {
+		notes = append(notes, glyphs.ok+" "+m.statusMsg)
+	}
+
+	rows := m.compactGauges()
+	room := max(m.height-compactChromeLines-len(notes), 1)
+	if len(rows) > room {
+		rows = rows[:room]
+	}
+	lines = append(lines, rows...)
+	lines = append(lines, rule)
+	lines = append(lines, notes...)
+	lines = append(lines, helpStyle.Render(fmt.Sprintf("%s: Help | %s: Quit | %dx%d for full views",
+		keyFor("help"), keyFor("quit"), compactWidth, compactHeight)))
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(ansi.Truncate(line, m.width, ""))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestCompactLayoutFits(t *testing.T) {
+	stats := fixtureStats()
+	for _, size := range []struct{ width, height int }{{79, 40}, {120, 23}, {40, 12}, {24, 6}} {
+		for _, tab := range viewTabs {
+			t.Run(fmt.Sprintf("%s_%dx%d", tab.name, size.width, size.height), func(t *testing.T) {
+				frame := Render(stats, size.width, size.height, tab.mode)
+				lines := strings.Split(strings.TrimSuffix(frame, "\n"), "\n")
+				if len(lines) > size.height {
+					t.Errorf("%d lines, taller than %d", len(lines), size.height)
+				}
+				for _, line := range lines {
+					if w := ansi.StringWidth(line); w > size.width {
+						t.Errorf("line %q is %d wide", line, w)
+					}
+				}
+				if !strings.Contains(frame, "CPU ") {
+					t.Error("the CPU gauge was dropped")
+				}
+			})
+		}
+	}
+}
+
+func TestCompactOnlyBelowMinimum(t *testing.T) {
+	if (model{width: compactWidth, height: compactHeight}).compact() {
+		t.Error("the minimum size is compact")
+	}
+	if !(model{width: compactWidth, height: compactHeight - 1}).compact() {
+		t.Error("one row short is not compact")
+	}
+}
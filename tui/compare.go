@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/khoi/mtop/history"
+	"github.com/khoi/mtop/widgets"
+)
+
+// Metrics the comparison view charts side by side, all in percent
+var compareMetrics = []struct{ name, title string }{
+	{metricCPU, "CPU"},
+	{metricMemory, "Memory"},
+	{metricGPU, "GPU"},
+}
+
+// loadBaseline reads a recording in the import format into a history of
+// its own, for the comparison view
+func loadBaseline(path string) (*history.Store, error) {
+	f, err := openRecording(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	store := newHistory()
+	n, err := importSamples(store, nil, f, filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, errors.New("no samples")
+	}
+	return store, nil
+}
+
+// alignSamples spreads samples over points equal steps of the span ending
+// at end, each step taking the last value at or before it. Steps before
+// the first sample are left out, so a series shorter than the span lines
+// up with the right edge like a live chart.
+func alignSamples(samples []history.Sample, end time.Time, span time.Duration, points int) []float64 {
+	values := make([]float64, 0, points)
+	start := end.Add(-span)
+	j := 0
+	for i := 1; i <= points; i++ {
+		t := start.Add(span * time.Duration(i) / time.Duration(points))
+		for j < len(samples) && !samples[j].Time.After(t) {
+			j++
+		}
+		if j > 0 {
+			values = append(values, samples[j-1].Value)
+		}
+	}
+	return values
+}
+
+// newestSample is the time of a store's last CPU sample
+func newestSample(store *history.Store) time.Time {
+	samples := store.Samples(metricCPU)
+	if len(samples) == 0 {
+		return time.Time{}
+	}
+	return samples[len(samples)-1].Time
+}
+
+// renderCompare charts the recording given with --compare next to the live
+// history. Both charts span the history window and end at their newest
+// sample, so the same column is the same time before the end in each.
+func (m model) renderCompare() string {
+	var b strings.Builder
+	if m.baseline == nil {
+		b.WriteString("No recording to compare with. Start mtop with --compare FILE.jsonl, a\n")
+		b.WriteString("recording in the format mtop import reads (for example --json snapshots).\n")
+		return b.String()
+	}
+
+	span := m.history.Retention()
+	recordedEnd, liveEnd := newestSample(m.baseline), newestSample(m.history)
+	fmt.Fprintf(&b, "Recorded %s (to %s) | Live | last %v of each\n",
+		m.baselineName, recordedEnd.Format("2006-01-02 15:04:05"), span)
+
+	const axis = 5
+	half := (m.width - 1) / 2
+	chartWidth := max(half-axis-1, 1)
+	height := (m.height - viewChromeLines - 1) / len(compareMetrics)
+	height = min(max(height-2, minChartHeight), maxChartHeight)
+
+	for _, metric := range compareMetrics {
+		recorded := m.baseline.Samples(metric.name)
+		live := m.history.Samples(metric.name)
+		fmt.Fprintf(&b, "%s: recorded avg %s | live avg %s%s\n", metric.title,
+			compareMean(recorded), compareMean(live), compareDelta(recorded, live))
+
+		left := widgets.Chart(alignSamples(recorded, recordedEnd, span, chartWidth*2), chartWidth, height, 100)
+		right := widgets.Chart(alignSamples(live, liveEnd, span, chartWidth*2), chartWidth, height, 100)
+		for i := range left {
+			label := ""
+			switch i {
+			case 0:
+				label = "100%"
+			case len(left) - 1:
+				label = "0%"
+			}
+			fmt.Fprintf(&b, "%*s%s%s %*s%s%s\n", axis, label, glyphs.axis, left[i], axis, label, glyphs.axis, right[i])
+		}
+		b.WriteString(timeAxis(span, recordedEnd.Format("15:04:05"), axis, chartWidth))
+		b.WriteString(" ")
+		b.WriteString(timeAxis(span, "now", axis, chartWidth))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// timeAxis labels the start and end of a chart under it
+func timeAxis(span time.Duration, end string, axis, width int) string {
+	start := "-" + span.String()
+	gap := max(width-ansi.StringWidth(start)-ansi.StringWidth(end), 1)
+	return strings.Repeat(" ", axis+1) + start + strings.Repeat(" ", gap) + end
+}
+
+// compareMean formats the mean of samples, or a dash without any
+func compareMean(samples []history.Sample) string {
+	if len(samples) == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", history.Summarize(samples).Mean)
+}
+
+// compareDelta is how far the live mean is from the recorded one
+func compareDelta(recorded, live []history.Sample) string {
+	if len(recorded) == 0 || len(live) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%+.1f)", history.Summarize(live).Mean-history.Summarize(recorded).Mean)
+}
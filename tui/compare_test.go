@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khoi/mtop/history"
+)
+
+func TestAlignSamples(t *testing.T) {
+	end := time.Date(2026, 10, 17, 10, 0, 10, 0, time.UTC)
+	samples := []history.Sample{
+		{Time: end.Add(-6 * time.Second), Value: 1},
+		{Time: end.Add(-3 * time.Second), Value: 2},
+		{Time: end, Value: 3},
+	}
+	// Steps end at -8s, -6s, -4s, -2s and 0s; -8s has no sample yet
+	got := alignSamples(samples, end, 10*time.Second, 5)
+	want := []float64{1, 1, 2, 3}
+	if len(got) != len(want) {
+		t.Fatalf("alignSamples = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("alignSamples = %v, want %v", got, want)
+		}
+	}
+	if got := alignSamples(nil, end, time.Second, 4); len(got) != 0 {
+		t.Errorf("alignSamples(nil) = %v", got)
+	}
+}
+
+func TestRenderCompare(t *testing.T) {
+	m := model{
+		stats:       fixtureStats(),
+		viewMode:    CompareMode,
+		refreshRate: time.Second,
+		width:       100,
+		height:      40,
+		history:     newHistory(),
+	}
+	if view := m.View(); !strings.Contains(view, "--compare") {
+		t.Errorf("compare view without a recording does not say how to load one:\n%s", view)
+	}
+
+	path := filepath.Join(t.TempDir(), "before.jsonl")
+	recording := `{"time": "2026-09-01T09:00:00Z", "stats": {"cpu": {"usage": 10}, "memory": {"usage": 40}}}
+{"time": "2026-09-01T09:00:01Z", "stats": {"cpu": {"usage": 20}, "memory": {"usage": 50}}}
+`
+	if err := os.WriteFile(path, []byte(recording), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := loadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.baseline, m.baselineName = baseline, "before.jsonl"
+	now := time.Now()
+	m.history.Add(metricCPU, now.Add(-time.Second), 30)
+	m.history.Add(metricCPU, now, 40)
+
+	view := m.View()
+	for _, want := range []string{"Recorded before.jsonl", "CPU: recorded avg 15.0% | live avg 35.0% (+20.0)", "now"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("compare view lacks %q:\n%s", want, view)
+		}
+	}
+	for _, line := range strings.Split(view, "\n") {
+		if w := len([]rune(line)); w > 100 {
+			t.Errorf("line is %d wide, wider than the terminal: %q", w, line)
+		}
+	}
+}
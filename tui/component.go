@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+)
+
+// Model is the TUI as a bubbletea component, for showing mtop's views
+// inside another program. Build one with NewModel and forward it messages
+// like any tea.Model.
+type Model = model
+
+// Provider supplies a Model's samples in place of the local collectors,
+// e.g. readings relayed from another Mac. Sample is called once per
+// refresh with the time of the tick.
+type Provider interface {
+	Sample(now time.Time) (SystemStats, error)
+}
+
+// Option configures a Model built by NewModel
+type Option func(*model) error
+
+// WithProvider takes samples from p instead of collecting them
+func WithProvider(p Provider) Option {
+	return func(m *model) error {
+		if p == nil {
+			return fmt.Errorf("nil provider")
+		}
+		m.provider = p
+		return nil
+	}
+}
+
+// WithRefreshRate sets the interval between samples
+func WithRefreshRate(rate time.Duration) Option {
+	return func(m *model) error {
+		if err := checkRefreshRate(rate); err != nil {
+			return fmt.Errorf("refresh rate %v", err)
+		}
+		m.refreshRate = rate
+		return nil
+	}
+}
+
+// WithView opens the model on a view named as in default_view, e.g.
+// "processes"
+func WithView(name string) Option {
+	return func(m *model) error {
+		mode, err := parseView(name)
+		if err != nil {
+			return err
+		}
+		m.viewMode = mode
+		return nil
+	}
+}
+
+// WithTheme draws the model with a built-in theme, by name, instead of
+// mtop's own. Each Model keeps its theme; cycling themes in it changes
+// only its own.
+func WithTheme(name string) Option {
+	return func(m *model) error {
+		t, err := findTheme(name)
+		if err != nil {
+			return err
+		}
+		m.theme = &t
+		return nil
+	}
+}
+
+// WithKeymap binds the model's keys as key_preset and [keys] do in the
+// config file: a preset ("" for the default) with per-action overrides on
+// top. Other Models keep their own bindings.
+func WithKeymap(preset string, overrides map[string][]string) Option {
+	return func(m *model) error {
+		keys := make(map[string]keyList, len(overrides))
+		for action, list := range overrides {
+			keys[action] = list
+		}
+		k, err := buildKeys(preset, keys)
+		if err != nil {
+			return err
+		}
+		m.keys = k
+		return nil
+	}
+}
+
+// useSettings puts the model's theme and key bindings in effect. Styles
+// and bindings are package state, so Update and View call it first, and
+// Models embedded in one program, which bubbletea drives from a single
+// goroutine, each draw and read keys with their own.
+func (m model) useSettings() {
+	t := defaultTheme
+	if m.theme != nil {
+		t = *m.theme
+	}
+	if t.Name != currentTheme.Name {
+		applyTheme(t)
+	}
+	k := defaultKeys
+	if m.keys != nil {
+		k = m.keys
+	}
+	useKeys(k)
+}
+
+// withHistoryDB appends every sample to db
+func withHistoryDB(db *historyDB) Option {
+	return func(m *model) error {
+		m.historyDB = db
+		return nil
+	}
+}
+
+// withSampleLog appends every sample and alert event to log
+func withSampleLog(log *sampleLog) Option {
+	return func(m *model) error {
+		m.sampleLog = log
+		return nil
+	}
+}
+
+// withAgent reads samples from a running agent while it keeps publishing
+func withAgent(agent *agentReader) Option {
+	return func(m *model) error {
+		m.agent = agent
+		return nil
+	}
+}
+
+// NewModel builds the TUI model with the options applied and takes its
+// first sample. A failed sample is shown in the footer rather than
+// returned, as later ticks may succeed.
+func NewModel(opts ...Option) (Model, error) {
+	m := model{
+		viewMode:    OverviewMode,
+		refreshRate: time.Second,
+		lastUpdate:  time.Now(),
+		width:       80,
+		height:      24,
+		session:     newCPUSession(),
+		sched:       newScheduler(),
+		notifier:    newNotifier(),
+		hooks:       newHookRunner(),
+		history:     newHistory(),
+		columns:     defaultProcessColumns,
+	}
+	for _, opt := range opts {
+		if err := opt(&m); err != nil {
+			return model{}, err
+		}
+	}
+	m.useSettings()
+	m.prime()
+	return m, nil
+}
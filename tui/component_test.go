@@ -0,0 +1,87 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fixedProvider hands out the same sample every time
+type fixedProvider struct{ stats SystemStats }
+
+func (p fixedProvider) Sample(time.Time) (SystemStats, error) { return p.stats, nil }
+
+func TestNewModelOptions(t *testing.T) {
+	defer applyTheme(themes[0])
+	defer bindKeys("", nil)
+
+	m, err := NewModel(
+		WithProvider(fixedProvider{fixtureStats()}),
+		WithRefreshRate(2*time.Second),
+		WithView("processes"),
+		WithTheme(themes[1].Name),
+		WithKeymap("vim", map[string][]string{"theme": {"x"}}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.stats.CPU.Usage != 37.5 || m.lastError != "" {
+		t.Errorf("first sample not taken from the provider: cpu %v, error %q", m.stats.CPU.Usage, m.lastError)
+	}
+	if m.viewMode != ProcessMode || m.refreshRate != 2*time.Second {
+		t.Errorf("view %v, refresh %v", m.viewMode, m.refreshRate)
+	}
+	if currentTheme.Name != themes[1].Name || keyFor("theme") != "x" || keyFor("top") != "gg" {
+		t.Errorf("theme %q, theme key %q, top key %q", currentTheme.Name, keyFor("theme"), keyFor("top"))
+	}
+
+	for name, opt := range map[string]Option{
+		"provider": WithProvider(nil),
+		"refresh":  WithRefreshRate(time.Nanosecond),
+		"view":     WithView("nope"),
+		"theme":    WithTheme("nope"),
+		"keymap":   WithKeymap("emacs", nil),
+	} {
+		if _, err := NewModel(WithProvider(fixedProvider{}), opt); err == nil {
+			t.Errorf("invalid %s option accepted", name)
+		}
+	}
+}
+
+func TestModelsKeepTheirOwnThemeAndKeys(t *testing.T) {
+	defer applyTheme(themes[0])
+	defer useKeys(defaultKeys)
+
+	vim, err := NewModel(WithProvider(fixedProvider{fixtureStats()}), WithTheme(themes[1].Name), WithKeymap("vim", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := NewModel(WithProvider(fixedProvider{fixtureStats()}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain.View()
+	if currentTheme.Name != defaultTheme.Name || keyFor("top") != "home" {
+		t.Errorf("plain model drew with theme %q, top key %q", currentTheme.Name, keyFor("top"))
+	}
+	vim.View()
+	if currentTheme.Name != themes[1].Name || keyFor("top") != "gg" {
+		t.Errorf("vim model drew with theme %q, top key %q", currentTheme.Name, keyFor("top"))
+	}
+
+	// Cycling themes in one model leaves the others alone
+	next, _ := vim.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+	if vim = next.(Model); vim.theme == nil || vim.theme.Name != themes[2].Name || defaultTheme.Name != themes[0].Name {
+		t.Errorf("cycled to %v, default theme %q", vim.theme, defaultTheme.Name)
+	}
+
+	// A failing option leaves nothing of the ones before it applied
+	plain.View()
+	if _, err := NewModel(WithTheme(themes[3].Name), WithKeymap("emacs", nil)); err == nil {
+		t.Fatal("unknown key preset accepted")
+	}
+	if currentTheme.Name != themes[0].Name {
+		t.Errorf("rejected model left theme %q applied", currentTheme.Name)
+	}
+}
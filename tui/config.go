@@ -0,0 +1,212 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/khoi/mtop/widgets"
+)
+
+// config holds the settings read from the config file. Zero values leave
+// the built-in defaults in place; command line flags override the file.
+type config struct {
+	RefreshRate time.Duration            `toml:"refresh_rate"` // e.g. "500ms"
+	DefaultView string                   `toml:"default_view"` // View shown at startup, e.g. "processes"
+	Theme       string                   `toml:"theme"`
+	MemoryMode  string                   `toml:"memory_mode"`
+	Columns     []string                 `toml:"columns"`  // Process table columns
+	Overview    [][]string               `toml:"overview"` // Rows of overview widgets
+	History     time.Duration            `toml:"history"`
+	KeepHistory bool                     `toml:"keep_history"` // Save the history on exit and restore it on start
+	Adaptive    bool                     `toml:"adaptive"`     // Sample less often while readings are steady
+	ASCII       *bool                    `toml:"ascii"`        // Draw with ASCII only; unset detects from TERM and the locale
+	Collectors  map[string]bool          `toml:"collectors"`   // Set a collector to false to disable it
+	Cadence     map[string]time.Duration `toml:"cadence"`      // How often each collector group is sampled
+	Alerts      map[string]float64       `toml:"alerts"`       // Metric name to alert threshold
+	Normal      map[string]float64       `toml:"normal"`       // Metric name to deviations above its usual reading for the hour of the week
+	Notify      notifyConfig             `toml:"notify"`       // Notifications for sustained alerts
+	Anomaly     anomalyConfig            `toml:"anomaly"`      // Marking and alerting on unusual samples
+	Thresholds  thresholdConfig          `toml:"thresholds"`
+	KeyPreset   string                   `toml:"key_preset"`  // "default" or "vim"
+	Header      string                   `toml:"header"`      // Status line template, e.g. "{hostname} | {clock}"
+	Footer      string                   `toml:"footer"`      // Footer template; "{keys}" is the key hints
+	Keys        map[string]keyList       `toml:"keys"`        // Action name to key(s)
+	Plugins     []pluginConfig           `toml:"plugins"`     // Exec plugins adding custom metrics
+	Hooks       []hookConfig             `toml:"hooks"`       // Commands run when alerts fire and clear
+	Channels    []channelConfig          `toml:"channels"`    // Places [notify] can send alert notifications
+	Mute        muteConfig               `toml:"mute"`        // Quiet hours for notifications and hooks
+	Maintenance []maintenanceConfig      `toml:"maintenance"` // Scheduled windows muting alerts
+
+	// Append every sample to a SQLite database, pruned after history_db_retention
+	HistoryDB   bool          `toml:"history_db"`
+	DBRetention time.Duration `toml:"history_db_retention"`
+
+	// Append every sample and alert event to a JSON Lines file, rotated by
+	// size and age; unset limits keep the flag defaults
+	LogFile    string         `toml:"log_file"` // A leading ~/ is the home directory
+	LogMaxSize *int           `toml:"log_max_size"`
+	LogMaxAge  *time.Duration `toml:"log_max_age"`
+	LogKeep    *int           `toml:"log_keep"`
+}
+
+// thresholdConfig holds the levels at which values are highlighted
+type thresholdConfig struct {
+	UsageWarn  float64 `toml:"usage_warn"`  // Usage percentage where bars turn yellow
+	UsageBad   float64 `toml:"usage_bad"`   // Usage percentage where bars turn red
+	Change     float64 `toml:"change"`      // Percentage point change highlighted between refreshes
+	FDWarn     float64 `toml:"fd_warn"`     // Fraction of kern.maxfilesperproc that warns
+	FDCritical float64 `toml:"fd_critical"` // Fraction of kern.maxfilesperproc that is critical
+}
+
+// keyList is one key or a list of keys bound to an action
+type keyList []string
+
+// UnmarshalTOML accepts either a single key string or an array of keys
+func (k *keyList) UnmarshalTOML(v any) error {
+	switch v := v.(type) {
+	case string:
+		*k = keyList{v}
+	case []any:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("keys must be strings, got %T", item)
+			}
+			*k = append(*k, s)
+		}
+	default:
+		return fmt.Errorf("keys must be a string or an array of strings, got %T", v)
+	}
+	return nil
+}
+
+// defaultConfigPath returns ~/.config/mtop/config.toml, honoring
+// XDG_CONFIG_HOME
+func defaultConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "mtop", "config.toml")
+}
+
+// expandHome replaces a leading ~/ in a config path with the home directory
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
+
+// loadConfig reads the config file at path. A missing file yields the
+// defaults unless it was named explicitly with --config.
+func loadConfig(path string, explicit bool) (config, error) {
+	var cfg config
+	if path == "" {
+		return cfg, nil
+	}
+
+	md, err := toml.DecodeFile(path, &cfg)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !explicit {
+			return config{}, nil
+		}
+		return cfg, err
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return cfg, fmt.Errorf("unknown setting %q", undecoded[0].String())
+	}
+	return cfg, nil
+}
+
+// parseView looks up a view by name
+func parseView(name string) (ViewMode, error) {
+	if mode, ok := viewNames[name]; ok {
+		return mode, nil
+	}
+	return 0, fmt.Errorf("unknown view %q (want %s)", name, strings.Join(sortedKeys(viewNames), ", "))
+}
+
+// Collectors that can be turned off in the [collectors] section
+var collectorNames = []string{"cpufreq", "thermal", "power", "wifi", "disk", "interfaces", "sockets", "process_gpu", "process_net", "process_ports", "coalitions"}
+
+// disabledCollectors holds the collectors turned off in the config file
+var disabledCollectors = map[string]bool{}
+
+// collectorEnabled reports whether a collector should run
+func collectorEnabled(name string) bool {
+	return !disabledCollectors[name]
+}
+
+// applyCollectors records which collectors the config turns off
+func applyCollectors(enabled map[string]bool) error {
+	for name, on := range enabled {
+		known := false
+		for _, c := range collectorNames {
+			known = known || c == name
+		}
+		if !known {
+			return fmt.Errorf("unknown collector %q (want %s)", name, strings.Join(collectorNames, ", "))
+		}
+		disabledCollectors[name] = !on
+	}
+	return nil
+}
+
+// applyThresholds overrides the highlight levels set in the config
+func applyThresholds(t thresholdConfig) error {
+	warn, bad := widgets.WarnLevel, widgets.BadLevel
+	if t.UsageWarn > 0 {
+		warn = t.UsageWarn
+	}
+	if t.UsageBad > 0 {
+		bad = t.UsageBad
+	}
+	if warn >= bad {
+		return fmt.Errorf("usage_warn (%g) must be below usage_bad (%g)", warn, bad)
+	}
+	widgets.WarnLevel, widgets.BadLevel = warn, bad
+
+	if t.Change > 0 {
+		changeThreshold = t.Change
+	}
+
+	fdWarn, fdCritical := fdWarnRatio, fdCriticalRatio
+	if t.FDWarn > 0 {
+		fdWarn = t.FDWarn
+	}
+	if t.FDCritical > 0 {
+		fdCritical = t.FDCritical
+	}
+	if fdWarn >= fdCritical || fdCritical > 1 {
+		return fmt.Errorf("fd_warn (%g) must be below fd_critical (%g), which must be at most 1", fdWarn, fdCritical)
+	}
+	fdWarnRatio, fdCriticalRatio = fdWarn, fdCritical
+	return nil
+}
+
+// sortedKeys returns the keys of a map in order, for error messages
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadExampleConfig(t *testing.T) {
+	cfg, err := loadConfig("../config.example.toml", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RefreshRate != time.Second || cfg.History != 5*time.Minute {
+		t.Errorf("durations = %v, %v", cfg.RefreshRate, cfg.History)
+	}
+	if _, err := parseView(cfg.DefaultView); err != nil {
+		t.Error(err)
+	}
+	if _, err := parseColumns(strings.Join(cfg.Columns, ",")); err != nil {
+		t.Error(err)
+	}
+	if got := cfg.Keys["processes"]; len(got) != 1 || got[0] != "7" {
+		t.Errorf("single key binding = %v", got)
+	}
+	if got := cfg.Keys["quit"]; len(got) != 2 {
+		t.Errorf("key list binding = %v", got)
+	}
+	if err := bindKeys(cfg.KeyPreset, cfg.Keys); err != nil {
+		t.Error(err)
+	}
+	if err := applyOverviewLayout(cfg.Overview); err != nil {
+		t.Error(err)
+	}
+	if len(cfg.Alerts) == 0 {
+		t.Error("example alerts were not decoded")
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "config.toml")
+	if _, err := loadConfig(missing, false); err != nil {
+		t.Errorf("missing default config: %v", err)
+	}
+	if _, err := loadConfig(missing, true); err == nil {
+		t.Error("missing explicit config was not an error")
+	}
+
+	typo := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(typo, []byte("refresh_rat = \"1s\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfig(typo, true); err == nil {
+		t.Error("unknown setting was not reported")
+	}
+}
+
+func TestBindKeysRejectsConflicts(t *testing.T) {
+	defer bindKeys("", nil)
+
+	if err := bindKeys("", map[string]keyList{"theme": {"q"}}); err == nil {
+		t.Error("binding q to theme while quit uses it was accepted")
+	}
+	if err := bindKeys("", map[string]keyList{"quit": {"x"}, "theme": {"q"}}); err != nil {
+		t.Errorf("rebinding quit away from q: %v", err)
+	}
+	if keyActions["q"] != "theme" || keyFor("quit") != "x" {
+		t.Errorf("bindings not applied: q=%s quit=%s", keyActions["q"], keyFor("quit"))
+	}
+}
@@ -0,0 +1,186 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/khoi/mtop/history"
+	"github.com/khoi/mtop/widgets"
+)
+
+// Metrics the correlation view starts with
+var defaultCorrelation = [2]string{metricGPU, metricGPUTemp}
+
+// Actions choosing the correlation view's first and second metric
+var correlateActions = [2]string{"correlate", "correlate_2"}
+
+// correlation returns the two metrics the correlation view charts
+func (m model) correlation() [2]string {
+	pair := defaultCorrelation
+	for i, metric := range m.correlate {
+		if metric != "" {
+			pair[i] = metric
+		}
+	}
+	return pair
+}
+
+// cycleCorrelation moves the i-th metric of the correlation view to the
+// next one recorded, skipping the other metric
+func (m *model) cycleCorrelation(i int) {
+	if m.viewMode != CorrelateMode {
+		return
+	}
+	pair := m.correlation()
+	names := historyMetricNames()
+	at := slices.Index(names, pair[i])
+	for range names {
+		at = (at + 1) % len(names)
+		if names[at] != pair[1-i] {
+			break
+		}
+	}
+	pair[i] = names[at]
+	m.correlate = pair
+	m.setStatus(fmt.Sprintf("Correlating %s with %s", pair[0], pair[1]))
+}
+
+// pairSamples matches the samples of two metrics taken at the same time.
+// Every metric of a sample is recorded with the sample's time, so the
+// pairs are the samples both metrics have.
+func pairSamples(a, b []history.Sample) (xs, ys []float64) {
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i].Time.Before(b[j].Time):
+			i++
+		case b[j].Time.Before(a[i].Time):
+			j++
+		default:
+			xs = append(xs, a[i].Value)
+			ys = append(ys, b[j].Value)
+			i++
+			j++
+		}
+	}
+	return xs, ys
+}
+
+// pearson is the correlation coefficient of xs and ys. It is undefined,
+// and ok false, with fewer than three pairs or when either side is flat.
+func pearson(xs, ys []float64) (r float64, ok bool) {
+	n := float64(len(xs))
+	if len(xs) < 3 || len(xs) != len(ys) {
+		return 0, false
+	}
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX, meanY = meanX/n, meanY/n
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, false
+	}
+	return max(-1, min(1, cov/math.Sqrt(varX*varY))), true
+}
+
+// correlationStrength describes a correlation coefficient in words
+func correlationStrength(r float64) string {
+	direction := "positive"
+	if r < 0 {
+		direction = "negative"
+	}
+	switch a := math.Abs(r); {
+	case a >= 0.7:
+		return "strong " + direction
+	case a >= 0.4:
+		return "moderate " + direction
+	case a >= 0.2:
+		return "weak " + direction
+	}
+	return "none"
+}
+
+// formatCorrelationValue formats a value of any metric for the axis labels
+func formatCorrelationValue(v float64) string {
+	if math.Abs(v) >= 1e5 {
+		return fmt.Sprintf("%.3g", v)
+	}
+	return fmt.Sprintf("%.1f", v)
+}
+
+// renderCorrelation charts two metrics above each other over the history
+// window, ending at the same newest sample so a column is the same time
+// in both, and says how closely they move together. Each chart spans the
+// range its metric covered, as the coefficient does not depend on scale.
+func (m model) renderCorrelation() string {
+	pair := m.correlation()
+	var b strings.Builder
+	span := m.history.Retention()
+	series := [2][]history.Sample{m.history.Samples(pair[0]), m.history.Samples(pair[1])}
+
+	fmt.Fprintf(&b, "%s vs %s | ", pair[0], pair[1])
+	xs, ys := pairSamples(series[0], series[1])
+	if r, ok := pearson(xs, ys); ok {
+		fmt.Fprintf(&b, "r = %+.2f, %s, over %d samples in the last %v\n", r, correlationStrength(r), len(xs), span)
+	} else {
+		fmt.Fprintf(&b, "r undefined: needs 3 samples of both, neither flat (%d so far)\n", len(xs))
+	}
+
+	var end time.Time
+	labels := make([][2]string, len(series))
+	axis := 0
+	for i, samples := range series {
+		if len(samples) == 0 {
+			continue
+		}
+		if last := samples[len(samples)-1].Time; last.After(end) {
+			end = last
+		}
+		sum := history.Summarize(samples)
+		labels[i] = [2]string{formatCorrelationValue(sum.Max), formatCorrelationValue(sum.Min)}
+		axis = max(axis, ansi.StringWidth(labels[i][0]), ansi.StringWidth(labels[i][1]))
+	}
+	width := max(m.width-axis-1, 1)
+	height := (m.height - viewChromeLines - 4) / len(series)
+	height = min(max(height-1, minChartHeight), maxChartHeight)
+
+	for i, samples := range series {
+		fmt.Fprintf(&b, "%s (%s): ", pair[i], keyFor(correlateActions[i]))
+		if len(samples) == 0 {
+			b.WriteString("no samples\n")
+			continue
+		}
+		sum := history.Summarize(samples)
+		fmt.Fprintf(&b, "now %s, mean %s\n", formatCorrelationValue(sum.Last), formatCorrelationValue(sum.Mean))
+		values := alignSamples(samples, end, span, width*2)
+		for j := range values {
+			values[j] -= sum.Min
+		}
+		lines := widgets.Chart(values, width, height, max(sum.Max-sum.Min, 1e-9))
+		for j, line := range lines {
+			label := ""
+			switch j {
+			case 0:
+				label = labels[i][0]
+			case len(lines) - 1:
+				label = labels[i][1]
+			}
+			fmt.Fprintf(&b, "%*s%s%s\n", axis, label, glyphs.axis, line)
+		}
+	}
+	b.WriteString(timeAxis(span, "now", axis, width))
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khoi/mtop/history"
+)
+
+func TestPearson(t *testing.T) {
+	for _, tt := range []struct {
+		xs, ys []float64
+		want   float64
+		ok     bool
+	}{
+		{[]float64{1, 2, 3, 4}, []float64{10, 20, 30, 40}, 1, true},
+		{[]float64{1, 2, 3, 4}, []float64{4, 3, 2, 1}, -1, true},
+		{[]float64{1, 2, 3, 4}, []float64{1, 3, 2, 4}, 0.8, true},
+		{[]float64{1, 2, 3}, []float64{5, 5, 5}, 0, false},
+		{[]float64{1, 2}, []float64{1, 2}, 0, false},
+	} {
+		r, ok := pearson(tt.xs, tt.ys)
+		if ok != tt.ok || math.Abs(r-tt.want) > 1e-9 {
+			t.Errorf("pearson(%v, %v) = %v, %v, want %v, %v", tt.xs, tt.ys, r, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPairSamples(t *testing.T) {
+	at := func(s int) time.Time { return time.Date(2026, 10, 17, 10, 0, s, 0, time.UTC) }
+	a := []history.Sample{{Time: at(0), Value: 1}, {Time: at(1), Value: 2}, {Time: at(3), Value: 3}}
+	b := []history.Sample{{Time: at(1), Value: 20}, {Time: at(2), Value: 25}, {Time: at(3), Value: 30}}
+	xs, ys := pairSamples(a, b)
+	if len(xs) != 2 || xs[0] != 2 || xs[1] != 3 || ys[0] != 20 || ys[1] != 30 {
+		t.Errorf("pairSamples = %v, %v", xs, ys)
+	}
+}
+
+func TestRenderCorrelation(t *testing.T) {
+	m := model{stats: fixtureStats(), viewMode: CorrelateMode, width: 100, height: 40, history: newHistory()}
+	if view := m.renderCorrelation(); !strings.Contains(view, "gpu.usage vs gpu.temp | r undefined") {
+		t.Errorf("empty correlation view:\n%s", view)
+	}
+
+	start := time.Date(2026, 10, 17, 10, 0, 0, 0, time.Local)
+	for i := range 20 {
+		load := float64(i * 5)
+		m.history.Record(start.Add(time.Duration(i)*time.Second), map[string]float64{
+			metricGPU: load, metricGPUTemp: 40 + load/2, metricCPU: float64(20 - i),
+		})
+	}
+	view := m.renderCorrelation()
+	for _, want := range []string{"r = +1.00, strong positive, over 20 samples", "gpu.usage (r): now 95.0", "gpu.temp (R)", "87.5" + glyphs.axis, "now"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("correlation view lacks %q:\n%s", want, view)
+		}
+	}
+	for _, line := range strings.Split(view, "\n") {
+		if w := len([]rune(line)); w > 100 {
+			t.Errorf("line is %d wide, wider than the terminal: %q", w, line)
+		}
+	}
+
+	// The first metric moves on, skipping the second
+	m.correlate = [2]string{metricCPU, metricGPU}
+	if view := m.renderCorrelation(); !strings.Contains(view, "r = -1.00, strong negative") {
+		t.Errorf("cpu.usage vs gpu.usage:\n%s", view)
+	}
+	names := historyMetricNames()
+	m.correlate = [2]string{names[len(names)-2], names[0]}
+	m.cycleCorrelation(0)
+	if got := m.correlation(); got[0] != names[len(names)-1] {
+		t.Errorf("cycled to %v", got)
+	}
+	m.cycleCorrelation(0)
+	if got := m.correlation(); got[0] != names[1] {
+		t.Errorf("cycled onto the other metric: %v", got)
+	}
+}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/khoi/mtop/widgets"
+)
+
+// Layout of the per-core grid in the CPU view
+const (
+	coreGridGap = "   "
+	coreMinBar  = 10 // Narrowest bar before the grid drops a column
+)
+
+// coreGridColumns picks how many columns the per-core grid uses: the fewest
+// that keep it within rows lines, but no more than fit the width with
+// cells of fixed cells plus the narrowest bar
+func coreGridColumns(cores, width, rows, fixed int) int {
+	fit := max((width+len(coreGridGap))/(fixed+coreMinBar+len(coreGridGap)), 1)
+	want := (cores + rows - 1) / max(rows, 1)
+	return min(max(want, 1), fit)
+}
+
+// renderCoreGrid draws a usage bar per core, filled column by column and
+// spread over as many columns as it takes to stay within rows lines. Each
+// cell shows the core's frequency too when it is known.
+func (m model) renderCoreGrid(rows int) string {
+	cores := m.stats.CPU.Cores
+	freqs := len(m.stats.CPU.CoreFreqs) == len(cores)
+	fixed := len("Core 00: ") + len(" 100.0%")
+	if freqs {
+		fixed += len(" 0000 MHz")
+	}
+	cols := coreGridColumns(len(cores), m.width, rows, fixed)
+	bar := max(min((m.width-len(coreGridGap)*(cols-1))/cols-fixed, overviewBarWidth), 1)
+	rows = (len(cores) + cols - 1) / cols
+
+	var b strings.Builder
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			i := c*rows + r
+			if i >= len(cores) {
+				break
+			}
+			if c > 0 {
+				b.WriteString(coreGridGap)
+			}
+			fmt.Fprintf(&b, "Core %2d: %s %s", i, widgets.Bar(cores[i], bar),
+				m.highlightChange(fmt.Sprintf("%5.1f%%", cores[i]), cores[i], m.prevCore(i), changeThreshold))
+			if freqs {
+				fmt.Fprintf(&b, " %4.0f MHz", m.stats.CPU.CoreFreqs[i])
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Explanations for the CPU load metrics, shown with the "e" key
+var cpuDocs = []metricDoc{
+	{Name: "CPU Usage", Text: "Share of user, system and nice ticks among all ticks counted by " +
+		"host_statistics(HOST_CPU_LOAD_INFO) since the last refresh. Per-core usage uses the same ticks from " +
+		"host_processor_info. The first sample after start averages since boot."},
+	{Name: "Load Average", Text: "Average number of runnable threads over 1, 5 and 15 minutes, from getloadavg."},
+}
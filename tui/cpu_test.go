@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestCoreGridFitsTerminal(t *testing.T) {
+	stats := fixtureStats()
+	stats.CPU.Cores = make([]float64, 20)
+	stats.CPU.CoreFreqs = nil
+	for i := range stats.CPU.Cores {
+		stats.CPU.Cores[i] = float64(i * 5)
+	}
+
+	// Four rows would take five columns; narrower terminals fit fewer
+	for _, size := range []struct{ width, cols int }{
+		{80, 2},
+		{120, 4},
+		{200, 5},
+		{40, 1},
+	} {
+		m := model{stats: stats, width: size.width}
+		grid := strings.Split(strings.TrimSuffix(m.renderCoreGrid(4), "\n"), "\n")
+		if want := (20 + size.cols - 1) / size.cols; len(grid) != want {
+			t.Errorf("width %d: %d rows, want %d", size.width, len(grid), want)
+		}
+		for _, line := range grid {
+			if w := ansi.StringWidth(line); w > size.width {
+				t.Errorf("width %d: line is %d cells wide: %q", size.width, w, line)
+			}
+		}
+		if !strings.Contains(grid[0], "Core  0:") || !strings.Contains(strings.Join(grid, "\n"), "Core 19:") {
+			t.Errorf("width %d: grid does not list every core:\n%s", size.width, strings.Join(grid, "\n"))
+		}
+	}
+}
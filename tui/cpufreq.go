@@ -0,0 +1,13 @@
+package tui
+
+// Explanations for the CPU frequency metrics, shown with the "e" key
+var cpuFreqDocs = []metricDoc{
+	{Name: "Cluster Frequency", Text: "Average frequency of the cluster while it was active since the last refresh. " +
+		"Each active performance state's frequency, read from the pmgr DVFS tables in the I/O registry, " +
+		"is weighted by the time the cluster spent in that state according to the IOReport " +
+		"\"CPU Complex Performance States\" channels. On Intel Macs this is the nominal hw.cpufrequency."},
+	{Name: "Cluster Active", Text: "Percentage of time since the last refresh the cluster spent in an active " +
+		"performance state rather than IDLE, DOWN or OFF."},
+	{Name: "Core Frequency", Text: "Same residency-weighted average as the cluster frequency, from the " +
+		"per-core \"CPU Core Performance States\" channels."},
+}
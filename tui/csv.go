@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"encoding/csv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvColumns are the metric columns of CSV output: every history metric,
+// by its stable name, in sorted order after the time and host columns
+var csvColumns = func() []string {
+	metrics := historyMetrics(SystemStats{})
+	metrics[metricWiFiRSSI] = 0
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}()
+
+// formatCSVHeader is the header row of CSV output
+func formatCSVHeader() string {
+	return csvLine(append([]string{"time", "host"}, csvColumns...))
+}
+
+// formatCSVRow renders one sample as a CSV row under formatCSVHeader. A
+// metric the sample lacks, such as Wi-Fi signal without Wi-Fi, is empty.
+func formatCSVRow(at time.Time, stats SystemStats) string {
+	metrics := historyMetrics(stats)
+	row := []string{at.Format(time.RFC3339), hostname}
+	for _, name := range csvColumns {
+		v, ok := metrics[name]
+		if !ok {
+			row = append(row, "")
+			continue
+		}
+		row = append(row, strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	return csvLine(row)
+}
+
+// csvLine quotes fields as needed and ends the row with a newline
+func csvLine(fields []string) string {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Write(fields)
+	w.Flush()
+	return b.String()
+}
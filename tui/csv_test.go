@@ -0,0 +1,42 @@
+package tui
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSVOutput(t *testing.T) {
+	at := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	out := formatCSVHeader() + formatCSVRow(at, fixtureStats()) + formatCSVRow(at.Add(time.Second), SystemStats{})
+	rows, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("%d rows, want a header and two samples", len(rows))
+	}
+	header := rows[0]
+	if header[0] != "time" || header[1] != "host" || len(header) != len(csvColumns)+2 {
+		t.Errorf("header = %q", header)
+	}
+	col := func(name string) int {
+		for i, h := range header {
+			if h == name {
+				return i
+			}
+		}
+		t.Fatalf("no %s column in %q", name, header)
+		return 0
+	}
+	if got := rows[1][0]; got != "2026-10-17T10:00:00Z" {
+		t.Errorf("time = %q", got)
+	}
+	if got := rows[1][col(metricCPU)]; got != "37.5" {
+		t.Errorf("cpu.usage = %q, want 37.5", got)
+	}
+	if got := rows[2][col(metricWiFiRSSI)]; got != "" {
+		t.Errorf("wifi signal without Wi-Fi = %q, want empty", got)
+	}
+}
@@ -0,0 +1,147 @@
+package tui
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/khoi/mtop/stats"
+	"golang.org/x/sys/unix"
+)
+
+// debugSection is a titled group of raw values in the debug dump
+type debugSection struct {
+	Title  string
+	Values []debugValue
+}
+
+// debugValue is one raw reading and where it came from
+type debugValue struct {
+	Name  string
+	Value string
+}
+
+// Sysctls read by the collectors
+var debugSysctls = []string{
+	"hw.memsize",
+	"hw.pagesize",
+	"vm.pagesize",
+	"hw.ncpu",
+	"hw.cpufrequency",
+	"kern.memorystatus_vm_pressure_level",
+	"kern.maxfilesperproc",
+	"kern.maxfiles",
+	"kern.osproductversion",
+	"kern.osrelease",
+}
+
+// collectDebugDump reads the raw inputs every displayed number is derived
+// from, so discrepancies with other tools can be traced to their source
+func collectDebugDump(s SystemStats) []debugSection {
+	var sections []debugSection
+
+	vm := debugSection{Title: "host_statistics64 (vm_statistics64)"}
+	if vmStats, missing, err := stats.ReadVMStatistics(); err != nil {
+		vm.Values = append(vm.Values, debugValue{"error", err.Error()})
+	} else {
+		v := reflect.ValueOf(*vmStats)
+		for i := 0; i < v.NumField(); i++ {
+			vm.Values = append(vm.Values, debugValue{v.Type().Field(i).Name, fmt.Sprint(v.Field(i).Interface())})
+		}
+		for _, field := range missing {
+			vm.Values = append(vm.Values, debugValue{field, "not filled by this kernel"})
+		}
+	}
+	sections = append(sections, vm)
+
+	sysctls := debugSection{Title: "sysctl"}
+	for _, name := range debugSysctls {
+		sysctls.Values = append(sysctls.Values, debugValue{name, readDebugSysctl(name)})
+	}
+	sections = append(sections, sysctls)
+
+	mem := debugSection{Title: "Memory derivation (" + s.Memory.Accounting.String() + ")"}
+	mem.Values = append(mem.Values,
+		debugValue{"used", fmt.Sprintf("%d bytes", s.Memory.Used)},
+		debugValue{"available", fmt.Sprintf("%d bytes", s.Memory.Available)})
+	for _, w := range s.Memory.Warnings {
+		mem.Values = append(mem.Values, debugValue{"warning", w})
+	}
+	sections = append(sections, mem)
+
+	pmgr := debugSection{Title: "pmgr DVFS tables (I/O registry)"}
+	for _, key := range stats.FreqTables {
+		if table, err := stats.ReadPmgrTable(key); err != nil {
+			pmgr.Values = append(pmgr.Values, debugValue{key, err.Error()})
+		} else {
+			pmgr.Values = append(pmgr.Values, debugValue{key, fmt.Sprint(table)})
+		}
+	}
+	sections = append(sections, pmgr)
+
+	cpu, energy := collector.IOReportChannels()
+	if cpu != nil {
+		sections = append(sections, ioReportSection("IOReport \"CPU Stats\" (last delta)", cpu))
+	}
+	if energy != nil {
+		sections = append(sections, ioReportSection("IOReport \"Energy Model\" (last delta)", energy))
+	}
+
+	// Listed so users looking for temperature readings know where they are
+	// not coming from
+	sections = append(sections, debugSection{Title: "SMC", Values: []debugValue{
+		{"keys read", "none; thermal state comes from NSProcessInfo.thermalState"},
+	}})
+
+	return sections
+}
+
+// readDebugSysctl reads a sysctl as an integer or string value, whichever
+// the kernel returns
+func readDebugSysctl(name string) string {
+	if v, err := stats.SysctlNumber(name); err == nil {
+		return fmt.Sprint(v)
+	}
+	v, err := unix.Sysctl(name)
+	if err != nil {
+		return "unavailable: " + err.Error()
+	}
+	return v
+}
+
+// ioReportSection lists raw IOReport channel values, with state channels
+// reduced to their non-zero residencies
+func ioReportSection(title string, channels []stats.IOReportChannel) debugSection {
+	section := debugSection{Title: title}
+	for _, ch := range channels {
+		name := ch.SubGroup + " / " + ch.Name
+		switch ch.Format {
+		case stats.IOReportFormatSimple:
+			section.Values = append(section.Values, debugValue{name, fmt.Sprintf("%d %s", ch.Value, strings.TrimSpace(ch.Unit))})
+		case stats.IOReportFormatState:
+			var states []string
+			for _, st := range ch.States {
+				if st.Residency != 0 {
+					states = append(states, fmt.Sprintf("%s=%d", st.Name, st.Residency))
+				}
+			}
+			section.Values = append(section.Values, debugValue{name, strings.Join(states, " ")})
+		}
+	}
+	return section
+}
+
+// formatDebugDump renders the debug dump as plain text
+func formatDebugDump(sections []debugSection) string {
+	var b strings.Builder
+	for i, sec := range sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s:\n", sec.Title)
+		for _, v := range sec.Values {
+			fmt.Fprintf(&b, "  %-36s %s\n", v.Name, v.Value)
+		}
+	}
+	return b.String()
+}
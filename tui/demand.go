@@ -0,0 +1,70 @@
+package tui
+
+import "slices"
+
+// Collectors too expensive to run when nothing shows their readings: the
+// per-process GPU walk, the nettop run behind per-process network rates, the
+// per-process socket listing behind ports, the socket table scan, the
+// coalition accounting and the per-interface counters only exports read. They only run while a subscriber wants them,
+// and fill in on the refresh after a panel showing them appears.
+var onDemandCollectors = []string{"process_gpu", "process_net", "process_ports", "sockets", "coalitions", "interfaces"}
+
+// collectorDemand maps each subscriber, such as the visible view or the
+// alert rules, to the on-demand collectors it needs
+var collectorDemand = map[string][]string{}
+
+// subscribe replaces the collectors a subscriber needs; none unsubscribes
+func subscribe(subscriber string, collectors ...string) {
+	if len(collectors) == 0 {
+		delete(collectorDemand, subscriber)
+		return
+	}
+	collectorDemand[subscriber] = collectors
+}
+
+// collectorActive reports whether a collector should run now: it is enabled
+// in the config and, if it runs on demand, some subscriber needs it
+func collectorActive(name string) bool {
+	if !collectorEnabled(name) {
+		return false
+	}
+	if !slices.Contains(onDemandCollectors, name) {
+		return true
+	}
+	for _, collectors := range collectorDemand {
+		if slices.Contains(collectors, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// viewCollectors returns the on-demand collectors the visible panels read
+func (m model) viewCollectors() []string {
+	var needs []string
+	switch m.viewMode {
+	case ProcessMode:
+		if m.columnEnabled("gpu") {
+			needs = append(needs, "process_gpu")
+		}
+		if m.columnEnabled("netin") || m.columnEnabled("netout") {
+			needs = append(needs, "process_net")
+		}
+		if m.columnEnabled("ports") {
+			needs = append(needs, "process_ports")
+		}
+		if m.procGroup == groupCoalition {
+			needs = append(needs, "coalitions")
+		}
+	case NetworkMode:
+		needs = append(needs, "sockets")
+	case OverviewMode:
+		for _, row := range overviewLayout {
+			if slices.Contains(row, "network") {
+				needs = append(needs, "sockets")
+				break
+			}
+		}
+	}
+	return needs
+}
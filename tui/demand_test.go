@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCollectorDemand(t *testing.T) {
+	defer func(demand map[string][]string) { collectorDemand = demand }(collectorDemand)
+	collectorDemand = map[string][]string{}
+
+	if !collectorActive("thermal") {
+		t.Error("a regular collector is not active")
+	}
+	if collectorActive("sockets") {
+		t.Error("sockets run without a subscriber")
+	}
+
+	m := model{viewMode: NetworkMode}
+	subscribe("view", m.viewCollectors()...)
+	if !collectorActive("sockets") {
+		t.Error("sockets do not run while the network view is visible")
+	}
+	m.viewMode = CPUDetailMode
+	subscribe("view", m.viewCollectors()...)
+	if collectorActive("sockets") {
+		t.Error("sockets still run after leaving the network view")
+	}
+
+	// The process view only wakes the collectors behind visible columns
+	m = model{viewMode: ProcessMode, columns: []string{"pid", "gpu", "ports", "name"}}
+	if got := m.viewCollectors(); !slices.Equal(got, []string{"process_gpu", "process_ports"}) {
+		t.Errorf("process view needs %v, want [process_gpu process_ports]", got)
+	}
+}
+
+func TestAlertsSubscribe(t *testing.T) {
+	defer func(demand map[string][]string, thresholds map[string]float64) {
+		collectorDemand, alertThresholds = demand, thresholds
+	}(collectorDemand, alertThresholds)
+	collectorDemand, alertThresholds = map[string][]string{}, map[string]float64{}
+
+	if err := applyAlerts(map[string]float64{"tcp": 500}); err != nil {
+		t.Fatal(err)
+	}
+	if !collectorActive("sockets") {
+		t.Error("a tcp alert does not keep the socket collector running")
+	}
+}
@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Interfaces and disks that exports and JSON output include, as shell
+// patterns matched against BSD names; set from --select-interface and
+// --select-disk. Empty selects every device.
+var selectedInterfaces, selectedDisks []string
+
+// parseDeviceSelection splits a comma-separated list of device names or
+// patterns such as en* and checks each pattern
+func parseDeviceSelection(spec string) ([]string, error) {
+	var patterns []string
+	for _, p := range strings.Split(spec, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("bad pattern %q", p)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// deviceSelected reports whether name matches one of patterns, or there
+// are none
+func deviceSelected(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// selectDevices leaves out the interfaces and disks that are not
+// selected, and Wi-Fi when its interface is not. The totals across all
+// disks stay as they are.
+func selectDevices(stats SystemStats) SystemStats {
+	if len(selectedInterfaces) > 0 {
+		stats.Network.Interfaces = slices.DeleteFunc(slices.Clone(stats.Network.Interfaces), func(i InterfaceStats) bool {
+			return !deviceSelected(selectedInterfaces, i.Name)
+		})
+		if wifi := stats.Network.WiFi; wifi != nil && !deviceSelected(selectedInterfaces, wifi.Interface) {
+			stats.Network.WiFi = nil
+		}
+	}
+	if len(selectedDisks) > 0 {
+		stats.Disk.Devices = slices.DeleteFunc(slices.Clone(stats.Disk.Devices), func(d DiskDevice) bool {
+			return !deviceSelected(selectedDisks, d.Name)
+		})
+	}
+	return stats
+}
@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSelectDevices(t *testing.T) {
+	defer func() { selectedInterfaces, selectedDisks = nil, nil }()
+	stats := fixtureStats()
+	stats.Network.Interfaces = []InterfaceStats{{Name: "lo0"}, {Name: "en0", Received: 42}, {Name: "utun3"}, {Name: "en5"}}
+	stats.Disk.Devices = []DiskDevice{{Name: "disk0", Read: 7}, {Name: "disk4"}}
+	if got := selectDevices(stats); len(got.Network.Interfaces) != 4 || len(got.Disk.Devices) != 2 {
+		t.Errorf("nothing selected still left out devices: %+v", got.Network.Interfaces)
+	}
+
+	if _, err := parseDeviceSelection("en[0"); err == nil {
+		t.Error("bad pattern accepted")
+	}
+	var err error
+	if selectedInterfaces, err = parseDeviceSelection(" en*, ,lo0"); err != nil || len(selectedInterfaces) != 2 {
+		t.Fatalf("parsed %q: %v", selectedInterfaces, err)
+	}
+	selectedDisks = []string{"disk0"}
+	got := selectDevices(stats)
+	var names []string
+	for _, i := range got.Network.Interfaces {
+		names = append(names, i.Name)
+	}
+	if strings.Join(names, " ") != "lo0 en0 en5" || len(got.Disk.Devices) != 1 || got.Disk.Devices[0].Name != "disk0" {
+		t.Errorf("selected %v and %+v", names, got.Disk.Devices)
+	}
+	if len(stats.Network.Interfaces) != 4 {
+		t.Errorf("selecting changed the sample it was given: %+v", stats.Network.Interfaces)
+	}
+	if got.Network.WiFi == nil {
+		t.Errorf("Wi-Fi on %s dropped though en* is selected", stats.Network.WiFi.Interface)
+	}
+	selectedInterfaces = []string{"utun*"}
+	if got := selectDevices(stats); got.Network.WiFi != nil {
+		t.Error("Wi-Fi kept without its interface selected")
+	}
+
+	// Exports carry only what is selected
+	var b strings.Builder
+	writeSystemMetrics(&b, got)
+	metrics := b.String()
+	for _, want := range []string{`mtop_network_receive_bytes_total{interface="en0"} 42`, `mtop_disk_read_bytes_total{device="disk0"} 7`} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("metrics lack %s", want)
+		}
+	}
+	if strings.Contains(metrics, "utun3") || strings.Contains(metrics, "disk4") {
+		t.Error("metrics include devices that are not selected")
+	}
+	if lines := formatInfluxLines(time.Unix(0, 0), got); !strings.Contains(lines, ",interface=en5 received=0,") {
+		t.Errorf("influx lines lack en5:\n%s", lines)
+	}
+	if gauges := formatStatsdGauges(got); !strings.Contains(gauges, "mtop.disk.disk0.read_rate:0|g\n") {
+		t.Errorf("statsd gauges lack disk0:\n%s", gauges)
+	}
+}
@@ -0,0 +1,13 @@
+// Package tui is mtop's terminal UI and the command line modes around it.
+// Main runs the mtop command. Other bubbletea programs can embed the UI as
+// a component with NewModel, taking samples from their own Provider:
+//
+//	m, err := tui.NewModel(tui.WithView("processes"), tui.WithRefreshRate(2*time.Second))
+//	if err != nil {
+//		return err
+//	}
+//	// Forward messages to m.Update and place m.View in the parent's view
+//
+// Theme, key bindings and the settings the config file sets are shared by
+// every Model in the process.
+package tui
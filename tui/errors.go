@@ -0,0 +1,21 @@
+package tui
+
+import (
+	"errors"
+
+	"github.com/khoi/mtop/stats"
+)
+
+// errorCause names the cause of a collector error for the Errors view, or
+// "" when it is not one of the known causes
+func errorCause(err error) string {
+	switch {
+	case errors.Is(err, stats.ErrUnsupportedPlatform):
+		return "unsupported"
+	case errors.Is(err, stats.ErrPermissionDenied):
+		return "needs root"
+	case errors.Is(err, stats.ErrSensorUnavailable):
+		return "unavailable"
+	}
+	return ""
+}
@@ -0,0 +1,30 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/khoi/mtop/stats"
+)
+
+func TestErrorCause(t *testing.T) {
+	wifi := fmt.Errorf("no Wi-Fi interface found: %w", stats.ErrSensorUnavailable)
+	for _, tt := range []struct {
+		err  error
+		want string
+	}{
+		{wifi, "unavailable"},
+		{fmt.Errorf("proc_pidinfo failed for pid 1: %w", stats.ErrPermissionDenied), "needs root"},
+		{fmt.Errorf("failed to get CPU frequency: %v: %w", errors.New("sysctl"), stats.ErrUnsupportedPlatform), "unsupported"},
+		{errors.New("nettop failed"), ""},
+	} {
+		if got := errorCause(tt.err); got != tt.want {
+			t.Errorf("errorCause(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+
+	if got := (&groupHealth{lastError: wifi}).status(); got != "unavailable" {
+		t.Errorf("status of a group without its sensor = %q, want unavailable", got)
+	}
+}
@@ -0,0 +1,31 @@
+package tui_test
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/khoi/mtop/tui"
+)
+
+// shell embeds mtop's process view next to its own content
+type shell struct {
+	monitor tui.Model
+}
+
+func (s shell) Init() tea.Cmd { return s.monitor.Init() }
+
+func (s shell) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := s.monitor.Update(msg)
+	s.monitor = m.(tui.Model)
+	return s, cmd
+}
+
+func (s shell) View() string { return "my app\n" + s.monitor.View() }
+
+func ExampleNewModel() {
+	monitor, err := tui.NewModel(tui.WithView("processes"), tui.WithRefreshRate(2*time.Second))
+	if err != nil {
+		panic(err)
+	}
+	tea.NewProgram(shell{monitor}).Run()
+}
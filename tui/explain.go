@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// metricDoc explains what a displayed metric measures and how mtop derives
+// it. Docs are declared in the file named after the collector that
+// produces the metric, which is in package stats.
+type metricDoc struct {
+	Name string
+	Text string
+}
+
+// viewDocs returns the metrics explained for a view mode, in display order
+func viewDocs(mode ViewMode) []metricDoc {
+	switch mode {
+	case OverviewMode:
+		return concatDocs(cpuDocs, memoryDocs[:3], trendDocs, thermalDocs, uptimeDocs)
+	case CPUDetailMode:
+		return concatDocs(cpuDocs, cpuFreqDocs)
+	case MemoryDetailMode:
+		return memoryDocs
+	case FlameMode:
+		return flameDocs
+	case MemoryTreemapMode:
+		return treemapDocs
+	case ProcessMode:
+		return processDocs
+	case PowerMode:
+		return powerDocs
+	case NetworkMode:
+		return concatDocs(wifiDocs, socketDocs)
+	}
+	return nil
+}
+
+func concatDocs(lists ...[]metricDoc) []metricDoc {
+	var docs []metricDoc
+	for _, l := range lists {
+		docs = append(docs, l...)
+	}
+	return docs
+}
+
+var (
+	explainTitleStyle = lipgloss.NewStyle().Bold(true).Underline(true)
+	explainFocusStyle = lipgloss.NewStyle().Bold(true).Reverse(true)
+)
+
+// renderExplain shows the metrics of the current view with the focused
+// one's explanation below them
+func (m model) renderExplain() string {
+	docs := viewDocs(m.viewMode)
+	if len(docs) == 0 {
+		return fmt.Sprintf("No metric explanations for this view.\n\nPress %s or esc to close.\n", keyFor("explain"))
+	}
+	focus := m.explainFocus % len(docs)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Metric explanations | %s/%s: Focus | %s/esc: Close\n\n", keyFor("up"), keyFor("down"), keyFor("explain"))
+	for i, d := range docs {
+		if i == focus {
+			b.WriteString(explainFocusStyle.Render(" "+d.Name+" ") + "\n")
+		} else {
+			b.WriteString(" " + d.Name + "\n")
+		}
+	}
+
+	width := m.width - 4
+	if width < 20 {
+		width = 20
+	}
+	fmt.Fprintf(&b, "\n%s\n%s\n", explainTitleStyle.Render(docs[focus].Name),
+		lipgloss.NewStyle().Width(width).Render(docs[focus].Text))
+	return b.String()
+}
+
+// updateExplainKeys handles keys while the explanation panel is open. It
+// reports whether the key was consumed.
+func (m model) updateExplainKeys(key, action string) (model, bool) {
+	n := len(viewDocs(m.viewMode))
+	switch {
+	case action == "up", key == "shift+tab":
+		if n > 0 {
+			m.explainFocus = (m.explainFocus + n - 1) % n
+		}
+	case action == "down", key == "tab":
+		if n > 0 {
+			m.explainFocus = (m.explainFocus + 1) % n
+		}
+	case action == "explain", key == "esc":
+		m.explain = false
+	default:
+		return m, false
+	}
+	return m, true
+}
+
+var uptimeDocs = []metricDoc{
+	{Name: "Uptime", Text: "Time since the system booted, from the kern.boottime sysctl."},
+}
+
+// Explanations for the overview sparklines
+var trendDocs = []metricDoc{
+	{Name: "Trend", Text: "The sparkline after each usage gauge shows one refresh per cell, oldest on the " +
+		"left, on a fixed 0-100% scale. History is kept for the --history window (five minutes by default)."},
+}
@@ -0,0 +1,198 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// exportedSample is the newest sample, written by the collection loop and
+// read by the /metrics handler
+type exportedSample struct {
+	mu    sync.Mutex
+	stats SystemStats
+	at    time.Time
+}
+
+// runExporter collects a sample every interval with the same scheduler as
+// the TUI and serves the newest one on addr for Prometheus to scrape
+func runExporter(addr string, interval time.Duration) error {
+	latest := &exportedSample{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		latest.mu.Lock()
+		stats, at := latest.stats, latest.at
+		latest.mu.Unlock()
+		if !at.IsZero() {
+			writeSystemMetrics(w, stats)
+		}
+		internals.writePrometheus(w)
+	})
+	return serveSampling(addr, mux, interval, func(at time.Time, stats SystemStats) {
+		stats = exportProcesses(at, stats)
+		latest.mu.Lock()
+		latest.stats, latest.at = stats, at
+		latest.mu.Unlock()
+	}, withCollectors("interfaces"))
+}
+
+// Time open requests get to finish once the server is told to stop
+const serveShutdownGrace = 5 * time.Second
+
+// serveSampling serves handler on addr while streaming a sample every
+// interval, passing each to record with the time it arrived. It returns
+// when the server fails, or nil after an interrupt or SIGTERM has stopped
+// collection and let open requests finish.
+func serveSampling(addr string, handler http.Handler, interval time.Duration, record func(time.Time, SystemStats), opts ...streamOption) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	samples, err := streamStats(ctx, interval, opts...)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	errs := make(chan error, 1)
+	go func() { errs <- srv.ListenAndServe() }()
+
+	for {
+		select {
+		case stats, ok := <-samples:
+			if !ok {
+				samples = nil
+				continue
+			}
+			record(time.Now(), stats)
+		case err := <-errs:
+			return err
+		case <-ctx.Done():
+			shutdown, cancel := context.WithTimeout(context.Background(), serveShutdownGrace)
+			defer cancel()
+			return srv.Shutdown(shutdown)
+		}
+	}
+}
+
+// promWriter writes metric families in the Prometheus text format
+type promWriter struct{ w io.Writer }
+
+func (p promWriter) family(name, kind, help string) {
+	fmt.Fprintf(p.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes one series; labels alternate names and values
+func (p promWriter) sample(name string, value float64, labels ...string) {
+	var b strings.Builder
+	b.WriteString(name)
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i == 0 {
+			b.WriteByte('{')
+		} else {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", labels[i], promLabelEscaper.Replace(labels[i+1]))
+	}
+	if len(labels) > 0 {
+		b.WriteByte('}')
+	}
+	fmt.Fprintf(p.w, "%s %s\n", b.String(), strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// gauge writes a family with a single unlabeled series
+func (p promWriter) gauge(name, help string, value float64) {
+	p.family(name, "gauge", help)
+	p.sample(name, value)
+}
+
+// Label values escape backslashes, quotes and newlines
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// writeSystemMetrics writes a sample's readings as Prometheus gauges, with
+// a series per process for the processes it has, which exportProcesses
+// keeps to the few that are exported
+func writeSystemMetrics(w io.Writer, stats SystemStats) {
+	p := promWriter{w}
+	p.gauge("mtop_cpu_usage_percent", "CPU usage across all cores.", stats.CPU.Usage)
+	if len(stats.CPU.Cores) > 0 {
+		p.family("mtop_cpu_core_usage_percent", "gauge", "Usage of each CPU core.")
+		for i, usage := range stats.CPU.Cores {
+			p.sample("mtop_cpu_core_usage_percent", usage, "core", strconv.Itoa(i))
+		}
+	}
+	p.family("mtop_load_average", "gauge", "Load averages.")
+	for i, period := range []string{"1m", "5m", "15m"} {
+		p.sample("mtop_load_average", stats.CPU.LoadAvg[i], "period", period)
+	}
+	p.gauge("mtop_cpu_temperature_celsius", "CPU temperature.", stats.CPU.Temp)
+	p.gauge("mtop_thermal_state", "Thermal state: 0 nominal, 1 fair, 2 serious, 3 critical.", float64(stats.Thermal))
+	p.gauge("mtop_uptime_seconds", "Time since boot.", stats.Uptime.Seconds())
+
+	p.gauge("mtop_memory_total_bytes", "Physical memory.", float64(stats.Memory.Total))
+	p.gauge("mtop_memory_used_bytes", "Memory in use.", float64(stats.Memory.Used))
+	p.gauge("mtop_memory_usage_percent", "Memory in use as a share of physical memory.", stats.Memory.Usage)
+	p.gauge("mtop_memory_pressure", "Kernel memory pressure level: 1 normal, 2 warn, 4 critical.", float64(stats.Memory.Pressure))
+	p.gauge("mtop_swap_total_bytes", "Swap space.", float64(stats.Memory.Swap.Total))
+	p.gauge("mtop_swap_used_bytes", "Swap in use.", float64(stats.Memory.Swap.Used))
+	p.gauge("mtop_swap_usage_percent", "Swap in use as a share of swap space.", stats.Memory.Swap.Usage)
+
+	p.gauge("mtop_gpu_usage_percent", "GPU usage.", stats.GPU.Usage)
+	p.gauge("mtop_gpu_memory_used_bytes", "GPU memory in use.", float64(stats.GPU.MemoryUsed))
+	p.gauge("mtop_gpu_temperature_celsius", "GPU temperature.", stats.GPU.Temp)
+
+	p.family("mtop_power_watts", "gauge", "Power draw by domain.")
+	for _, d := range []struct {
+		name  string
+		watts float64
+	}{
+		{"cpu", stats.Power.CPU}, {"gpu", stats.Power.GPU}, {"ane", stats.Power.ANE},
+		{"dram", stats.Power.DRAM}, {"package", stats.Power.Package},
+	} {
+		p.sample("mtop_power_watts", d.watts, "domain", d.name)
+	}
+	p.gauge("mtop_disk_busy_percent", "Share of the last interval the disks spent on I/O.", stats.Disk.Busy)
+	if devices := stats.Disk.Devices; len(devices) > 0 {
+		p.family("mtop_disk_read_bytes_total", "counter", "Bytes each disk has read since boot.")
+		for _, d := range devices {
+			p.sample("mtop_disk_read_bytes_total", float64(d.Read), "device", d.Name)
+		}
+		p.family("mtop_disk_written_bytes_total", "counter", "Bytes each disk has written since boot.")
+		for _, d := range devices {
+			p.sample("mtop_disk_written_bytes_total", float64(d.Written), "device", d.Name)
+		}
+		p.family("mtop_disk_device_busy_percent", "gauge", "Share of the last interval each disk spent on I/O.")
+		for _, d := range devices {
+			p.sample("mtop_disk_device_busy_percent", d.Busy, "device", d.Name)
+		}
+	}
+	if ifs := stats.Network.Interfaces; len(ifs) > 0 {
+		p.family("mtop_network_receive_bytes_total", "counter", "Bytes each network interface has received since boot.")
+		for _, i := range ifs {
+			p.sample("mtop_network_receive_bytes_total", float64(i.Received), "interface", i.Name)
+		}
+		p.family("mtop_network_transmit_bytes_total", "counter", "Bytes each network interface has sent since boot.")
+		for _, i := range ifs {
+			p.sample("mtop_network_transmit_bytes_total", float64(i.Sent), "interface", i.Name)
+		}
+	}
+
+	if len(stats.Processes) == 0 {
+		return
+	}
+	procs := stats.Processes
+	p.family("mtop_process_cpu_percent", "gauge", "CPU usage of the exported processes.")
+	for _, proc := range procs {
+		p.sample("mtop_process_cpu_percent", proc.CPU, "pid", strconv.Itoa(proc.PID), "name", proc.Name)
+	}
+	p.family("mtop_process_resident_bytes", "gauge", "Resident memory of the exported processes.")
+	for _, proc := range procs {
+		p.sample("mtop_process_resident_bytes", float64(proc.RSS), "pid", strconv.Itoa(proc.PID), "name", proc.Name)
+	}
+}
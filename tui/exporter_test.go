@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteSystemMetrics(t *testing.T) {
+	stats := fixtureStats()
+	stats.Processes = append(stats.Processes, ProcessStats{PID: 4242, Name: `say "hi"\now`, CPU: 99})
+	var b strings.Builder
+	writeSystemMetrics(&b, stats)
+	out := b.String()
+
+	for _, want := range []string{
+		"mtop_cpu_usage_percent 37.5\n",
+		`mtop_cpu_core_usage_percent{core="0"} 82` + "\n",
+		`mtop_load_average{period="15m"} 1.97` + "\n",
+		"mtop_memory_used_bytes 1.1811160064e+10\n",
+		"mtop_swap_usage_percent 25\n",
+		"mtop_gpu_usage_percent 23\n",
+		`mtop_process_cpu_percent{pid="4242",name="say \"hi\"\\now"} 99` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("exposition lacks %q", want)
+		}
+	}
+
+	// Every series belongs to a family declared before it
+	declared := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		if name, ok := strings.CutPrefix(line, "# TYPE "); ok {
+			declared[strings.Fields(name)[0]] = true
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		name := strings.FieldsFunc(line, func(r rune) bool { return r == '{' || r == ' ' })[0]
+		if !declared[name] {
+			t.Errorf("series %q has no TYPE line before it", line)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/khoi/mtop/stats"
+)
+
+// Fractions of the file descriptor limit at which the FD column warns; set
+// from the config file
+var (
+	fdWarnRatio     = 0.80
+	fdCriticalRatio = 0.95
+)
+
+// fdLimit is kern.maxfilesperproc, the most files any process can have
+// open whatever it raises its rlimit to. Other processes' own rlimits are
+// not readable, and many raise theirs well above launchd's default, so
+// only this ceiling is certain to mean a process is running out. It is
+// far above the usual soft limits, so a process hitting its own rlimit
+// is not flagged.
+var fdLimit = sync.OnceValue(func() int {
+	max, err := stats.SysctlNumber("kern.maxfilesperproc")
+	if err != nil {
+		return 0
+	}
+	return int(max)
+})
+
+// FD warning styles, set by applyTheme
+var fdWarnStyle, fdCriticalStyle lipgloss.Style
+
+// decorateFDs colors an FD cell when the process nears the kernel's
+// per-process file ceiling
+func decorateFDs(p ProcessStats, cell string) string {
+	limit := fdLimit()
+	if limit <= 0 {
+		return cell
+	}
+	ratio := float64(p.FDs) / float64(limit)
+	switch {
+	case ratio >= fdCriticalRatio:
+		return fdCriticalStyle.Render(cell)
+	case ratio >= fdWarnRatio:
+		return fdWarnStyle.Render(cell)
+	}
+	return cell
+}
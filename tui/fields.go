@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// fieldTree is a selection of JSON fields: each selected name maps to the
+// selection below it, nil for the whole value
+type fieldTree map[string]fieldTree
+
+// Fields of the stats document that --json and JSON streams print, set
+// from --fields; nil prints every field
+var jsonFields fieldTree
+
+// parseFields reads a comma-separated list of dotted paths into the JSON
+// document of a sample, e.g. cpu,memory.swap,gpu.temp. A path through a
+// list, such as processes.name, selects in every element; a path through
+// a map, such as network.sockets.tcp.ESTABLISHED, selects by key.
+func parseFields(spec string) (fieldTree, error) {
+	tree := fieldTree{}
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		path := strings.Split(field, ".")
+		if err := checkFieldPath(reflect.TypeOf(SystemStats{}), path); err != nil {
+			return nil, fmt.Errorf("%s: %v", field, err)
+		}
+		tree.add(path)
+	}
+	if len(tree) == 0 {
+		return nil, fmt.Errorf("no fields given")
+	}
+	return tree, nil
+}
+
+// add selects a path; selecting a value selects everything below it
+func (t fieldTree) add(path []string) {
+	sub, seen := t[path[0]]
+	switch {
+	case len(path) == 1:
+		t[path[0]] = nil
+	case seen && sub == nil:
+		// Already selected whole
+	default:
+		if sub == nil {
+			sub = fieldTree{}
+			t[path[0]] = sub
+		}
+		sub.add(path[1:])
+	}
+}
+
+// checkFieldPath checks a path against the JSON names of typ's fields
+func checkFieldPath(typ reflect.Type, path []string) error {
+	for i, name := range path {
+		for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			typ = typ.Elem()
+		}
+		switch {
+		case typ.Kind() == reflect.Map:
+			// Keys vary, so anything below a map is accepted
+			return nil
+		case typ.Kind() != reflect.Struct || typ == reflect.TypeOf(time.Time{}):
+			return fmt.Errorf("%s has no fields", strings.Join(path[:i], "."))
+		}
+		var names []string
+		found := false
+		for j := 0; j < typ.NumField(); j++ {
+			f := typ.Field(j)
+			tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if tag == "" || tag == "-" {
+				continue
+			}
+			names = append(names, tag)
+			if tag == name {
+				typ, found = f.Type, true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown field %q (want %s)", name, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// selectFields encodes v as JSON and keeps only the selected fields
+func selectFields(v any, fields fieldTree) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	// Numbers stay as written, so byte counts keep every digit
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return fields.prune(doc), nil
+}
+
+// prune drops what the selection leaves out of a decoded JSON value
+func (t fieldTree) prune(v any) any {
+	if t == nil {
+		return v
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for name, sub := range t {
+			if child, ok := v[name]; ok {
+				out[name] = sub.prune(child)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, elem := range v {
+			out[i] = t.prune(elem)
+		}
+		return out
+	}
+	return v
+}
+
+// formatFieldsLine renders the selected fields of a sample as a JSON line
+// with its time and host, in place of the full import format line
+func formatFieldsLine(at time.Time, stats SystemStats) (string, error) {
+	doc, err := selectFields(stats, jsonFields)
+	if err != nil {
+		return "", err
+	}
+	line, err := json.Marshal(struct {
+		Time  time.Time `json:"time"`
+		Host  string    `json:"host"`
+		Stats any       `json:"stats"`
+	}{at, hostname, doc})
+	if err != nil {
+		return "", err
+	}
+	return string(line) + "\n", nil
+}
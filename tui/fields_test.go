@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFields(t *testing.T) {
+	tree, err := parseFields("cpu.usage, memory.swap,cpu, processes.name,network.sockets.tcp.ESTABLISHED")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub, ok := tree["cpu"]; !ok || sub != nil {
+		t.Errorf("cpu and cpu.usage = %v, want the whole of cpu", tree["cpu"])
+	}
+	if _, ok := tree["memory"]["swap"]; !ok || len(tree["memory"]) != 1 {
+		t.Errorf("memory = %v, want swap only", tree["memory"])
+	}
+	for _, bad := range []string{"", "cpu.fans", "uptime.seconds", "processes.start_time.year", "gpus"} {
+		if _, err := parseFields(bad); err == nil {
+			t.Errorf("parseFields(%q): no error", bad)
+		}
+	}
+}
+
+func TestFormatFieldsLine(t *testing.T) {
+	defer func(f fieldTree) { jsonFields = f }(jsonFields)
+	var err error
+	if jsonFields, err = parseFields("cpu.usage,memory.swap.used,processes.pid"); err != nil {
+		t.Fatal(err)
+	}
+	stats := fixtureStats()
+	line, err := formatFieldsLine(time.Unix(1700000000, 0), stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Stats map[string]json.RawMessage `json:"stats"`
+	}
+	if err := json.Unmarshal([]byte(line), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Stats) != 3 || string(got.Stats["cpu"]) != `{"usage":37.5}` {
+		t.Errorf("stats = %s", line)
+	}
+	if want := `{"swap":{"used":` + strconv.FormatUint(stats.Memory.Swap.Used, 10) + `}}`; string(got.Stats["memory"]) != want {
+		t.Errorf("memory = %s, want %s", got.Stats["memory"], want)
+	}
+	if procs := string(got.Stats["processes"]); !strings.HasPrefix(procs, `[{"pid":`) || strings.Contains(procs, "name") {
+		t.Errorf("processes = %s, want PIDs only", procs)
+	}
+}
@@ -0,0 +1,245 @@
+package tui
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// procKey identifies a process instance, guarding against pid reuse
+type procKey struct {
+	pid   int
+	start time.Time
+}
+
+// sessionProc is the CPU time a process consumed while mtop was running
+type sessionProc struct {
+	pid  int
+	ppid int
+	name string
+	base time.Duration // CPU time already used before the session started
+	used time.Duration // CPU time used during the session
+}
+
+// cpuSession accumulates per-process CPU time over the lifetime of mtop.
+// Exited processes are kept so their time still counts towards their parent.
+type cpuSession struct {
+	start time.Time
+	procs map[procKey]*sessionProc
+}
+
+func newCPUSession() *cpuSession {
+	return &cpuSession{
+		start: time.Now(),
+		procs: make(map[procKey]*sessionProc),
+	}
+}
+
+// update folds a new process sample into the session totals
+func (s *cpuSession) update(procs []ProcessStats) {
+	for _, p := range procs {
+		key := procKey{pid: p.PID, start: p.StartTime}
+		sp, ok := s.procs[key]
+		if !ok {
+			sp = &sessionProc{pid: p.PID}
+			// Only count time spent after the session started
+			if p.StartTime.Before(s.start) {
+				sp.base = p.CPUTime
+			}
+			s.procs[key] = sp
+		}
+		sp.ppid = p.PPID
+		sp.name = p.Name
+		if p.CPUTime > sp.base {
+			sp.used = p.CPUTime - sp.base
+		}
+	}
+}
+
+// flameNode is a process in the session tree with its cumulative CPU time
+type flameNode struct {
+	name     string
+	self     time.Duration
+	total    time.Duration
+	children []*flameNode
+}
+
+// tree arranges the session's processes by parentage under a single root
+func (s *cpuSession) tree() *flameNode {
+	root := &flameNode{name: "all"}
+
+	// When a pid was reused, the most recently started process owns it
+	byPID := make(map[int]procKey)
+	for key := range s.procs {
+		if cur, ok := byPID[key.pid]; !ok || key.start.After(cur.start) {
+			byPID[key.pid] = key
+		}
+	}
+
+	// A parent can't have started after its child: that pid was reused
+	// since, and the process goes under the root rather than under the
+	// unrelated one that holds it now
+	parents := make(map[procKey]procKey, len(s.procs))
+	for key, sp := range s.procs {
+		if pkey, ok := byPID[sp.ppid]; ok && sp.ppid != sp.pid && !pkey.start.After(key.start) {
+			parents[key] = pkey
+		}
+	}
+
+	// Processes started at the same instant can still name each other;
+	// break such a cycle where it is found, in a fixed order so the graph
+	// doesn't change between renders
+	keys := make([]procKey, 0, len(s.procs))
+	for key := range s.procs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].start.Equal(keys[j].start) {
+			return keys[i].start.Before(keys[j].start)
+		}
+		return keys[i].pid < keys[j].pid
+	})
+	for _, key := range keys {
+		// A walk longer than the number of links is in a cycle without key,
+		// which is broken when one of its own members comes up
+		steps := 0
+		for p, ok := parents[key]; ok && steps < len(parents); p, ok = parents[p] {
+			if p == key {
+				delete(parents, key)
+				break
+			}
+			steps++
+		}
+	}
+
+	nodes := make(map[procKey]*flameNode, len(s.procs))
+	for key, sp := range s.procs {
+		nodes[key] = &flameNode{name: sp.name, self: sp.used}
+	}
+	for _, key := range keys {
+		parent := root
+		if pkey, ok := parents[key]; ok {
+			parent = nodes[pkey]
+		}
+		parent.children = append(parent.children, nodes[key])
+	}
+
+	root.sum()
+	return root
+}
+
+// sum computes subtree totals, dropping idle branches and ordering children
+// from the busiest to the least busy
+func (n *flameNode) sum() time.Duration {
+	n.total = n.self
+	kept := n.children[:0]
+	for _, c := range n.children {
+		if c.sum() > 0 {
+			n.total += c.total
+			kept = append(kept, c)
+		}
+	}
+	n.children = kept
+	sort.Slice(n.children, func(i, j int) bool {
+		if n.children[i].total == n.children[j].total {
+			return n.children[i].name < n.children[j].name
+		}
+		return n.children[i].total > n.children[j].total
+	})
+	return n.total
+}
+
+// flameSegment is one labelled cell in a row of the flame graph
+type flameSegment struct {
+	start int
+	width int
+	node  *flameNode
+}
+
+// Palette used to shade flame graph cells, set by applyTheme
+var flamePalette []lipgloss.Color
+
+func (m model) renderFlame() string {
+	root := m.session.tree()
+	if root.total == 0 {
+		return "No CPU time recorded yet this session.\n"
+	}
+
+	width := m.width
+	maxDepth := m.height - 10
+	if maxDepth < 3 {
+		maxDepth = 3
+	}
+
+	rows := make([][]flameSegment, maxDepth)
+	var layout func(n *flameNode, x, w float64, depth int)
+	layout = func(n *flameNode, x, w float64, depth int) {
+		if depth >= maxDepth {
+			return
+		}
+		start, end := int(x+0.5), int(x+w+0.5)
+		if end-start < 1 {
+			return
+		}
+		rows[depth] = append(rows[depth], flameSegment{start: start, width: end - start, node: n})
+		cx := x
+		for _, c := range n.children {
+			cw := w * float64(c.total) / float64(n.total)
+			layout(c, cx, cw, depth+1)
+			cx += cw
+		}
+	}
+	layout(root, 0, float64(width), 0)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Session CPU time: %v since %s\n\n",
+		root.total.Round(time.Millisecond), m.session.start.Format("15:04:05"))
+	for _, row := range rows {
+		if len(row) == 0 {
+			break
+		}
+		b.WriteString(renderFlameRow(row, root.total))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// renderFlameRow draws one depth level of the flame graph, leaving gaps
+// where a parent has CPU time not attributed to any child
+func renderFlameRow(row []flameSegment, total time.Duration) string {
+	var b strings.Builder
+	pos := 0
+	for _, seg := range row {
+		if seg.start > pos {
+			b.WriteString(strings.Repeat(" ", seg.start-pos))
+		}
+
+		label := fmt.Sprintf("%s %.1f%%", seg.node.name, float64(seg.node.total)/float64(total)*100)
+		if r := []rune(label); len(r) > seg.width {
+			label = string(r[:seg.width])
+		}
+
+		h := fnv.New32a()
+		h.Write([]byte(seg.node.name))
+		style := lipgloss.NewStyle().
+			Width(seg.width).
+			Foreground(lipgloss.Color("0")).
+			Background(flamePalette[h.Sum32()%uint32(len(flamePalette))])
+		b.WriteString(style.Render(label))
+
+		pos = seg.start + seg.width
+	}
+	return b.String()
+}
+
+// Explanations for the flame graph, shown with the "e" key
+var flameDocs = []metricDoc{
+	{Name: "Session CPU time", Text: "CPU time each process used since mtop started, summed up its " +
+		"process tree. Each cell's width is proportional to its subtree's share; exited processes keep " +
+		"counting towards their parent."},
+}
@@ -0,0 +1,32 @@
+package tui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionTreeWithReusedPIDs(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &cpuSession{start: t0.Add(-time.Hour), procs: make(map[procKey]*sessionProc)}
+	s.update([]ProcessStats{
+		// Started together and naming each other as parent
+		{PID: 100, PPID: 200, Name: "a", CPUTime: time.Second, StartTime: t0},
+		{PID: 200, PPID: 100, Name: "b", CPUTime: 2 * time.Second, StartTime: t0},
+		// Its parent exited and pid 400 now belongs to a later process,
+		// itself a child of 300
+		{PID: 300, PPID: 400, Name: "c", CPUTime: 3 * time.Second, StartTime: t0.Add(time.Minute)},
+		{PID: 400, PPID: 300, Name: "d", CPUTime: 4 * time.Second, StartTime: t0.Add(time.Hour)},
+	})
+
+	root := s.tree()
+	if root.total != 10*time.Second {
+		t.Errorf("total = %v, want 10s", root.total)
+	}
+	if len(root.children) != 2 {
+		t.Fatalf("root has %d children, want the two cycles broken into 2 subtrees", len(root.children))
+	}
+	c := root.children[0]
+	if c.name != "c" || len(c.children) != 1 || c.children[0].name != "d" {
+		t.Errorf("busiest subtree = %s with %d children, want c with d under it", c.name, len(c.children))
+	}
+}
@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/khoi/mtop/widgets"
+)
+
+// glyphSet holds the characters mtop draws with outside of plain text
+type glyphSet struct {
+	rule        string // Header and footer separator
+	line        string // Split pane title rule
+	sep         string // Between the name and the tabs
+	moreLeft    string // Tabs hidden on the left
+	moreRight   string // Tabs hidden on the right
+	scrollLeft  string // Process table columns hidden on the left
+	scrollRight string // Process table columns hidden on the right
+	barFull     string
+	barEmpty    string
+	axis        string // Chart axis tick
+	warn        string
+	ok          string
+	rising      string
+	falling     string
+	cursor      string // Search prompt cursor
+	times       string
+	head        string // Position in the replay scrubber, and a shown chart series
+	hollow      string // A hidden chart series
+	mark        string // Note on a history chart
+	border      lipgloss.Border
+	keys        map[string]string // Symbols shown for named keys
+}
+
+var unicodeGlyphs = glyphSet{
+	rule: "━", line: "─", sep: "│",
+	moreLeft: "‹", moreRight: "›", scrollLeft: "◀", scrollRight: "▶",
+	barFull: "█", barEmpty: "░",
+	axis: "┤",
+	warn: "⚠", ok: "✓", rising: "▲", falling: "▼", cursor: "█", times: "×", head: "●", hollow: "○", mark: "◆",
+	border: lipgloss.RoundedBorder(),
+	keys:   map[string]string{"up": "↑", "down": "↓", "left": "←", "right": "→", " ": "space"},
+}
+
+var asciiGlyphs = glyphSet{
+	rule: "=", line: "-", sep: "|",
+	moreLeft: "<", moreRight: ">", scrollLeft: "<", scrollRight: ">",
+	barFull: "#", barEmpty: ".",
+	axis: "|",
+	warn: "!", ok: "*", rising: "^", falling: "v", cursor: "_", times: "x", head: "O", hollow: "o", mark: "+",
+	border: lipgloss.ASCIIBorder(),
+	keys:   map[string]string{" ": "space"},
+}
+
+// Glyphs in use; set with setASCII before applyTheme, which renders the
+// cached header and footer pieces with them
+var (
+	glyphs      = unicodeGlyphs
+	asciiOutput bool
+)
+
+// setASCII switches between Unicode and plain ASCII drawing
+func setASCII(on bool) {
+	asciiOutput = on
+	widgets.ASCII = on
+	glyphs = unicodeGlyphs
+	if on {
+		glyphs = asciiGlyphs
+	}
+}
+
+// detectASCII reports whether the terminal likely cannot show Unicode: a
+// dumb terminal, or a locale whose character set is not UTF-8. The first
+// of LC_ALL, LC_CTYPE and LANG that is set decides, as in setlocale.
+func detectASCII() bool {
+	if os.Getenv("TERM") == "dumb" {
+		return true
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			locale = strings.ToLower(locale)
+			return !strings.Contains(locale, "utf-8") && !strings.Contains(locale, "utf8")
+		}
+	}
+	// No locale at all is the C locale, but macOS terminals often leave
+	// it unset while handling UTF-8 fine
+	return false
+}
+
+// asciiFrame replaces what is left outside ASCII in a frame, such as
+// process names or the degree sign, once every drawing glyph is ASCII
+func asciiFrame(frame string) string {
+	for i := 0; i < len(frame); i++ {
+		if frame[i] >= utf8.RuneSelf {
+			return strings.Map(func(r rune) rune {
+				switch {
+				case r < utf8.RuneSelf:
+					return r
+				case r == '°':
+					return -1
+				}
+				return '?'
+			}, frame)
+		}
+	}
+	return frame
+}
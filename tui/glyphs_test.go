@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestASCIIGolden(t *testing.T) {
+	setASCII(true)
+	applyTheme(themes[0])
+	defer func() {
+		setASCII(false)
+		applyTheme(themes[0])
+	}()
+
+	stats := fixtureStats()
+	for _, v := range []struct {
+		mode ViewMode
+		name string
+	}{
+		{OverviewMode, "overview_ascii_80x24"},
+		{CPUDetailMode, "cpu_ascii_80x24"},
+		{ProcessMode, "processes_ascii_80x24"},
+	} {
+		frame := Render(stats, 80, 24, v.mode)
+		for i := 0; i < len(frame); i++ {
+			if frame[i] >= utf8.RuneSelf {
+				t.Errorf("%s: non-ASCII byte %#x at %d", v.name, frame[i], i)
+				break
+			}
+		}
+		checkGolden(t, v.name, frame)
+	}
+}
+
+func TestDetectASCII(t *testing.T) {
+	for _, tc := range []struct {
+		term, lcAll, lang string
+		want              bool
+	}{
+		{"xterm-256color", "", "en_US.UTF-8", false},
+		{"xterm-256color", "", "en_US.utf8", false},
+		{"xterm-256color", "C", "en_US.UTF-8", true}, // LC_ALL wins over LANG
+		{"xterm-256color", "", "en_US.ISO8859-1", true},
+		{"dumb", "", "en_US.UTF-8", true},
+		{"xterm-256color", "", "", false},
+	} {
+		t.Setenv("TERM", tc.term)
+		t.Setenv("LC_ALL", tc.lcAll)
+		t.Setenv("LC_CTYPE", "")
+		t.Setenv("LANG", tc.lang)
+		if got := detectASCII(); got != tc.want {
+			t.Errorf("TERM=%q LC_ALL=%q LANG=%q: detectASCII = %v, want %v", tc.term, tc.lcAll, tc.lang, got, tc.want)
+		}
+	}
+}
+
+func TestASCIIFrame(t *testing.T) {
+	if got := asciiFrame("CPU 45.0°C | Safari – Web"); got != "CPU 45.0C | Safari ? Web" {
+		t.Errorf("asciiFrame = %q", got)
+	}
+}
@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// helpEntry is one row of the help overlay: the keys and what they do
+type helpEntry struct {
+	keys  string
+	label string
+}
+
+// Global actions listed in the help overlay, in display order
+var helpActions = []struct{ action, label string }{
+	{"explain", "Explain metrics"},
+	{"theme", "Next theme"},
+	{"copy_panel", "Copy panel"},
+	{"faster", "Refresh faster"},
+	{"slower", "Refresh slower"},
+	{"split", "Split with processes"},
+	{"focus_pane", "Focus other pane"},
+	{"split_grow split_shrink", "Grow / shrink top pane"},
+	{"overlay overlay_2", "Toggle chart overlays"},
+	{"correlate correlate_2", "Next correlated metric"},
+	{"pause", "Pause / resume"},
+	{"older", "Older sample (paused)"},
+	{"newer", "Newer sample (paused)"},
+	{"seek", "Seek to a time (paused)"},
+	{"note", "Add a note to the timeline"},
+	{"mute", "Mute alerts"},
+	{"help", "Toggle this help"},
+	{"quit", "Quit"},
+}
+
+// Keys handled inside a single view, as actions with their labels
+var (
+	helpProcessKeys = []struct{ actions, label string }{
+		{"up down", "Select process"},
+		{"top bottom", "First / last process"},
+		{"page_up page_down", "Page up / down"},
+		{"left right", "Scroll columns"},
+		{"search", "Search"},
+		{"search_next search_prev", "Next / previous match"},
+		{"columns", "Choose columns"},
+		{"group", "Group by app / coalition"},
+		{"copy_pid copy_command", "Copy PID / command"},
+		{"reveal", "Reveal in Finder"},
+		{"terminal", "Open terminal in cwd"},
+	}
+	helpExplainKeys = []struct{ actions, label string }{
+		{"up down", "Select metric"},
+		{"explain", "Close"},
+	}
+)
+
+// Border and section styles of the help overlay, set by applyTheme
+var (
+	helpBoxStyle     lipgloss.Style
+	helpSectionStyle lipgloss.Style
+)
+
+// renderHelpBox lists every key binding in two columns inside a border
+func renderHelpBox() string {
+	var views, actions, process, explain []helpEntry
+	views = append(views, helpEntry{helpKeys("next_view", "prev_view"), "Next / previous view"})
+	for _, tab := range viewTabs {
+		views = append(views, helpEntry{helpKeys(tab.name), tab.help})
+	}
+	for _, it := range helpActions {
+		actions = append(actions, helpEntry{helpKeys(strings.Fields(it.action)...), it.label})
+	}
+	for _, it := range helpProcessKeys {
+		process = append(process, helpEntry{helpKeys(strings.Fields(it.actions)...), it.label})
+	}
+	for _, it := range helpExplainKeys {
+		explain = append(explain, helpEntry{helpKeys(strings.Fields(it.actions)...), it.label})
+	}
+	memory := []helpEntry{
+		{helpKeys("memory_accounting"), "Switch accounting"},
+		{helpKeys("memory_debug"), "Raw counters"},
+	}
+
+	left := helpSection("Views", views) + "\n" + helpSection("Actions", actions)
+	right := helpSection("Process view", process) + "\n" +
+		helpSection("Memory view", memory) + "\n" +
+		helpSection("Explain panel", explain)
+
+	return helpBoxStyle.Render(lipgloss.JoinHorizontal(lipgloss.Top,
+		strings.TrimSuffix(left, "\n"), "   ", strings.TrimSuffix(right, "\n")))
+}
+
+// helpSection renders a titled list of keys with their labels aligned
+func helpSection(title string, entries []helpEntry) string {
+	width := 0
+	for _, e := range entries {
+		width = max(width, ansi.StringWidth(e.keys))
+	}
+
+	var b strings.Builder
+	b.WriteString(helpSectionStyle.Render(title) + "\n")
+	for _, e := range entries {
+		b.WriteString(e.keys + strings.Repeat(" ", width-ansi.StringWidth(e.keys)) + "  " + e.label + "\n")
+	}
+	return b.String()
+}
+
+// overlay draws box centered over the first height lines of frame, padding
+// a shorter frame. The frame stays visible around the box; box rows that
+// do not fit are cut.
+func overlay(frame, box string, width, height int) string {
+	lines := strings.Split(frame, "\n")
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	boxLines := strings.Split(box, "\n")
+	boxWidth := lipgloss.Width(box)
+
+	x := max((width-boxWidth)/2, 0)
+	y := max((height-len(boxLines))/2, 0)
+	for i, boxLine := range boxLines {
+		row := y + i
+		if row >= height {
+			break
+		}
+		line := lines[row]
+		left := ansi.Truncate(line, x, "")
+		if w := ansi.StringWidth(left); w < x {
+			left += strings.Repeat(" ", x-w)
+		}
+		lines[row] = left + boxLine + ansi.TruncateLeft(line, x+boxWidth, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// updateHelpKeys handles keys while the help overlay is open. Only closing
+// it and quitting do anything, so keys don't act on the view underneath.
+func (m model) updateHelpKeys(key, action string) (model, tea.Cmd) {
+	switch {
+	case action == "quit":
+		m.quit = true
+		return m, tea.Quit
+	case action == "help", key == "esc":
+		m.help = false
+	}
+	return m, nil
+}
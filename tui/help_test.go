@@ -0,0 +1,38 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOverlay(t *testing.T) {
+	frame := "aaaaaaaaaa\nbbbbbbbbbb\ncccccccccc\ndddddddddd\nfooter"
+	box := "+--+\n+--+"
+
+	got := overlay(frame, box, 10, 4)
+	want := "aaaaaaaaaa\nbbb+--+bbb\nccc+--+ccc\ndddddddddd\nfooter"
+	if got != want {
+		t.Errorf("overlay =\n%s\nwant\n%s", got, want)
+	}
+
+	// Short lines are padded so the box keeps its column
+	got = overlay("x\ny", box, 10, 2)
+	want = "x  +--+\ny  +--+"
+	if got != want {
+		t.Errorf("overlay on short lines =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestHelpBoxListsBindings(t *testing.T) {
+	box := renderHelpBox()
+	for _, it := range helpActions {
+		if !strings.Contains(box, it.label) {
+			t.Errorf("help is missing %q", it.label)
+		}
+	}
+	for _, tab := range viewTabs {
+		if !strings.Contains(box, tab.help) {
+			t.Errorf("help is missing view %q", tab.help)
+		}
+	}
+}
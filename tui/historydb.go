@@ -0,0 +1,335 @@
+package tui
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/khoi/mtop/history"
+)
+
+// How long --history-db keeps samples by default
+const defaultHistoryDBRetention = 7 * 24 * time.Hour
+
+// How often samples older than the retention are deleted while recording
+const historyDBPruneEvery = time.Hour
+
+// One row per metric per sample, and one per note added in the TUI; times
+// are Unix milliseconds
+const historyDBSchema = `
+PRAGMA journal_mode = WAL;
+CREATE TABLE IF NOT EXISTS samples (
+	time   INTEGER NOT NULL,
+	metric TEXT    NOT NULL,
+	value  REAL    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS samples_time ON samples (time);
+CREATE INDEX IF NOT EXISTS samples_metric_time ON samples (metric, time);
+CREATE TABLE IF NOT EXISTS notes (
+	time INTEGER NOT NULL,
+	text TEXT    NOT NULL
+);
+`
+
+// historyDB records the history metrics of every sample in a SQLite
+// database, deleting those older than the retention, so past readings
+// outlive the in-memory history and can be queried with mtop history
+type historyDB struct {
+	db        *sqliteDB
+	insert    *sqliteStmt
+	retention time.Duration
+	pruned    time.Time
+}
+
+// historyRow is one recorded reading
+type historyRow struct {
+	at     time.Time
+	metric string
+	value  float64
+}
+
+// historyDBPath is where --history-db records, next to the saved history
+func historyDBPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "mtop", "history.db")
+}
+
+// openHistoryDB opens or creates the database at path and prunes it. A
+// retention of zero keeps everything.
+func openHistoryDB(path string, retention time.Duration) (*historyDB, error) {
+	if path == "" {
+		return nil, fmt.Errorf("no cache directory for the history database")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	db, err := openSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.exec(historyDBSchema); err != nil {
+		db.close()
+		return nil, fmt.Errorf("failed to create the schema: %w", err)
+	}
+	insert, err := db.prepare(`INSERT INTO samples (time, metric, value) VALUES (?, ?, ?)`)
+	if err != nil {
+		db.close()
+		return nil, err
+	}
+	h := &historyDB{db: db, insert: insert, retention: retention}
+	if err := h.prune(time.Now()); err != nil {
+		h.close()
+		return nil, err
+	}
+	return h, nil
+}
+
+// record appends a sample's metrics in one transaction, pruning once per
+// historyDBPruneEvery
+func (h *historyDB) record(at time.Time, metrics map[string]float64) error {
+	if err := h.db.exec("BEGIN"); err != nil {
+		return err
+	}
+	for name, v := range metrics {
+		if err := h.insert.exec(at.UnixMilli(), name, v); err != nil {
+			h.db.exec("ROLLBACK")
+			return err
+		}
+	}
+	if err := h.db.exec("COMMIT"); err != nil {
+		return err
+	}
+	if at.Sub(h.pruned) >= historyDBPruneEvery {
+		return h.prune(at)
+	}
+	return nil
+}
+
+// addNote records a note made at t
+func (h *historyDB) addNote(at time.Time, text string) error {
+	stmt, err := h.db.prepare(`INSERT INTO notes (time, text) VALUES (?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.finalize()
+	return stmt.exec(at.UnixMilli(), text)
+}
+
+// prune deletes the samples and notes older than the retention
+func (h *historyDB) prune(now time.Time) error {
+	h.pruned = now
+	if h.retention <= 0 {
+		return nil
+	}
+	for _, table := range []string{"samples", "notes"} {
+		stmt, err := h.db.prepare(`DELETE FROM ` + table + ` WHERE time < ?`)
+		if err != nil {
+			return err
+		}
+		err = stmt.exec(now.Add(-h.retention).UnixMilli())
+		stmt.finalize()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// query returns the readings since a time, oldest first, of the named
+// metrics or of all of them when none are named
+func (h *historyDB) query(since time.Time, metrics []string) ([]historyRow, error) {
+	sql := `SELECT time, metric, value FROM samples WHERE time >= ?`
+	args := []any{since.UnixMilli()}
+	if len(metrics) > 0 {
+		sql += ` AND metric IN (?` + strings.Repeat(`, ?`, len(metrics)-1) + `)`
+		for _, m := range metrics {
+			args = append(args, m)
+		}
+	}
+	stmt, err := h.db.prepare(sql + ` ORDER BY time, metric`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.finalize()
+	if err := stmt.bind(args...); err != nil {
+		return nil, err
+	}
+	var rows []historyRow
+	for {
+		ok, err := stmt.step()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return rows, nil
+		}
+		rows = append(rows, historyRow{time.UnixMilli(stmt.int64(0)), stmt.text(1), stmt.float64(2)})
+	}
+}
+
+// notes returns the notes since a time, oldest first
+func (h *historyDB) notes(since time.Time) ([]history.Mark, error) {
+	stmt, err := h.db.prepare(`SELECT time, text FROM notes WHERE time >= ? ORDER BY time`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.finalize()
+	if err := stmt.bind(since.UnixMilli()); err != nil {
+		return nil, err
+	}
+	var notes []history.Mark
+	for {
+		ok, err := stmt.step()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return notes, nil
+		}
+		notes = append(notes, history.Mark{Time: time.UnixMilli(stmt.int64(0)), Text: stmt.text(1)})
+	}
+}
+
+func (h *historyDB) close() error {
+	h.insert.finalize()
+	return h.db.close()
+}
+
+// Output formats of mtop history
+var historyFormats = []string{"text", "csv", "json"}
+
+// runHistory implements "mtop history": it prints readings recorded with
+// --history-db
+func runHistory(args []string) int {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	path := fs.String("db", historyDBPath(), "History database written by --history-db")
+	since := fs.Duration("since", time.Hour, "How far back to go")
+	metrics := fs.String("metric", "", "Comma-separated metrics to print, e.g. cpu.usage,memory.usage (default all)")
+	format := fs.String("format", "text", "Output format: text, csv or json (import format lines, for mtop import)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s history [OPTIONS]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Prints the readings recorded with --history-db, and the notes added\n")
+		fmt.Fprintf(os.Stderr, "meanwhile with %s.\n\n", keyFor("note"))
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+	if *since <= 0 {
+		fmt.Fprintf(os.Stderr, "Invalid --since: must be positive\n")
+		return 2
+	}
+	if !slices.Contains(historyFormats, *format) {
+		fmt.Fprintf(os.Stderr, "Invalid --format: want %s\n", strings.Join(historyFormats, ", "))
+		return 2
+	}
+	var names []string
+	if *metrics != "" {
+		names = strings.Split(*metrics, ",")
+		known := historyMetricNames()
+		for _, name := range names {
+			if !slices.Contains(known, name) {
+				fmt.Fprintf(os.Stderr, "Unknown metric %q (want %s)\n", name, strings.Join(known, ", "))
+				return 2
+			}
+		}
+	}
+	if _, err := os.Stat(*path); err != nil {
+		fmt.Fprintf(os.Stderr, "No history database: %v (record one with --history-db)\n", err)
+		return 1
+	}
+
+	h, err := openHistoryDB(*path, 0)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening %s: %v\n", *path, err)
+		return 1
+	}
+	defer h.close()
+	from := time.Now().Add(-*since)
+	rows, err := h.query(from, names)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", *path, err)
+		return 1
+	}
+	notes, err := h.notes(from)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", *path, err)
+		return 1
+	}
+	if err := writeHistoryRows(os.Stdout, rows, notes, *format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// historyMetricNames lists every metric that can be recorded, including
+// those only present with Wi-Fi on
+func historyMetricNames() []string {
+	return sortedKeys(historyMetrics(SystemStats{Network: NetworkStats{WiFi: &WiFiStats{PowerOn: true}}}))
+}
+
+// writeHistoryRows prints rows as aligned text, CSV with a header, or one
+// import format line per sample time. Notes go between the rows in time
+// order, as the metric "note" in text and CSV.
+func writeHistoryRows(w io.Writer, rows []historyRow, notes []history.Mark, format string) error {
+	if !slices.Contains(historyFormats, format) {
+		return fmt.Errorf("unknown format %q (want %s)", format, strings.Join(historyFormats, ", "))
+	}
+	if format == "csv" {
+		fmt.Fprintln(w, "time,metric,value")
+	}
+	for i, j := 0, 0; i < len(rows) || j < len(notes); {
+		if j < len(notes) && (i == len(rows) || notes[j].Time.Before(rows[i].at)) {
+			note := notes[j]
+			j++
+			switch format {
+			case "text":
+				fmt.Fprintf(w, "%s  %-20s %s\n", note.Time.Format(time.RFC3339), "note", note.Text)
+			case "csv":
+				fmt.Fprint(w, csvLine([]string{note.Time.Format(time.RFC3339Nano), "note", note.Text}))
+			case "json":
+				line, err := json.Marshal(importRecord{Time: note.Time, Note: note.Text})
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(w, "%s\n", line)
+			}
+			continue
+		}
+		r := rows[i]
+		switch format {
+		case "text":
+			fmt.Fprintf(w, "%s  %-20s %s\n", r.at.Format(time.RFC3339), r.metric, strconv.FormatFloat(r.value, 'f', -1, 64))
+			i++
+		case "csv":
+			fmt.Fprintf(w, "%s,%s,%s\n", r.at.Format(time.RFC3339Nano), r.metric, strconv.FormatFloat(r.value, 'f', -1, 64))
+			i++
+		case "json":
+			// Rows are ordered by time, so each sample's metrics are adjacent
+			rec := importRecord{Time: r.at, Metrics: map[string]float64{}}
+			for ; i < len(rows) && rows[i].at.Equal(rec.Time); i++ {
+				rec.Metrics[rows[i].metric] = rows[i].value
+			}
+			line, err := json.Marshal(rec)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "%s\n", line)
+		}
+	}
+	return nil
+}
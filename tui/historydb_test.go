@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	h, err := openHistoryDB(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Truncate(time.Millisecond)
+	for i, at := range []time.Time{now.Add(-2 * time.Hour), now.Add(-time.Minute), now} {
+		if err := h.record(at, map[string]float64{metricCPU: float64(i), metricMemory: 50}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h.close()
+
+	// Reopening prunes what is past the retention
+	h, err = openHistoryDB(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.close()
+	rows, err := h.query(now.Add(-24*time.Hour), []string{metricCPU})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].value != 1 || !rows[1].at.Equal(now) || rows[1].metric != metricCPU {
+		t.Fatalf("cpu.usage rows = %+v, want the two within the hour", rows)
+	}
+	if rows, _ := h.query(now, nil); len(rows) != 2 {
+		t.Errorf("every metric since now = %+v, want cpu and memory", rows)
+	}
+
+	var b strings.Builder
+	if err := writeHistoryRows(&b, rows, nil, "json"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(b.String(), "\n") != 2 || !strings.Contains(b.String(), `"metrics":{"cpu.usage":1}`) {
+		t.Errorf("json rows:\n%s", b.String())
+	}
+}
@@ -0,0 +1,198 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// hookConfig is a [[hooks]] entry of the config file: commands run when an
+// alert has fired for a while and when it clears, e.g. alert = "cpu",
+// after = "30s", fire = ["~/bin/cpu-hot.sh"]
+type hookConfig struct {
+	Alert   string        `toml:"alert"`   // Alert name as in the header, e.g. "cpu", "cpu normal", "cpu.usage"; "*" for any
+	After   time.Duration `toml:"after"`   // How long the alert must last before fire runs
+	Fire    []string      `toml:"fire"`    // Program and arguments; a leading ~/ is the home directory
+	Clear   []string      `toml:"clear"`   // Run when an alert that fired stops
+	Timeout time.Duration `toml:"timeout"` // How long a command may run; default hookTimeout
+}
+
+// How long a hook command may run unless its config says otherwise
+const hookTimeout = 30 * time.Second
+
+// How long a hook's output may stay open after it exits or is killed, e.g.
+// by a background child it started
+const hookWaitDelay = 500 * time.Millisecond
+
+// Hooks from the config file, in its order
+var alertHooks []hookConfig
+
+// applyHooks checks and records the hooks from the config. Alert names
+// are those of [alerts], [normal] with " normal" appended, and history
+// metrics for [anomaly] alerts.
+func applyHooks(hooks []hookConfig) error {
+	for i, h := range hooks {
+		switch {
+		case h.Alert == "":
+			return fmt.Errorf("hook %d has no alert", i+1)
+		case h.Alert != "*" && !knownAlert(h.Alert):
+			return fmt.Errorf("hook %d: unknown alert %q (want %s, a metric with \" normal\", a history metric or *)",
+				i+1, h.Alert, strings.Join(sortedKeys(alertMetrics), ", "))
+		case len(h.Fire) == 0 && len(h.Clear) == 0:
+			return fmt.Errorf("hook %d for %s has neither fire nor clear", i+1, h.Alert)
+		case (len(h.Fire) > 0 && h.Fire[0] == "") || (len(h.Clear) > 0 && h.Clear[0] == ""):
+			return fmt.Errorf("hook %d for %s: command must name a program", i+1, h.Alert)
+		case h.After < 0 || h.Timeout < 0:
+			return fmt.Errorf("hook %d for %s: durations must not be negative", i+1, h.Alert)
+		}
+		if h.Timeout == 0 {
+			hooks[i].Timeout = hookTimeout
+		}
+	}
+	alertHooks = hooks
+	return nil
+}
+
+// hookKey is a hook and one of the alerts it matches
+type hookKey struct {
+	hook  int
+	alert string
+}
+
+// hookRun is a hook command due to run, with its environment
+type hookRun struct {
+	command []string
+	env     []string
+	timeout time.Duration
+}
+
+// hookRunner tracks how long each alert has been active and which hooks
+// fired for it, so fire runs once per episode and clear only after fire
+type hookRunner struct {
+	since map[string]time.Time // When the alert started
+	fired map[hookKey]bool
+}
+
+func newHookRunner() *hookRunner {
+	return &hookRunner{since: make(map[string]time.Time), fired: make(map[hookKey]bool)}
+}
+
+// due returns the hook commands to run at now, given the active alerts by
+// name and the sample they were found in. Fire waits while an alert is
+// muted.
+func (r *hookRunner) due(active map[string]string, stats SystemStats, now time.Time) []hookRun {
+	if len(alertHooks) == 0 {
+		return nil
+	}
+	var runs []hookRun
+	for _, name := range sortedKeys(r.since) {
+		if _, ok := active[name]; ok {
+			continue
+		}
+		for i, h := range alertHooks {
+			key := hookKey{i, name}
+			if r.fired[key] && len(h.Clear) > 0 {
+				runs = append(runs, hookRun{h.Clear, hookEnv(name, "resolved", "", stats, r.since[name]), h.Timeout})
+			}
+			delete(r.fired, key)
+		}
+		delete(r.since, name)
+	}
+	for _, name := range sortedKeys(active) {
+		since, ok := r.since[name]
+		if !ok {
+			r.since[name], since = now, now
+		}
+		for i, h := range alertHooks {
+			key := hookKey{i, name}
+			if (h.Alert != "*" && h.Alert != name) || r.fired[key] || now.Sub(since) < h.After || alertMuted(name, now) {
+				continue
+			}
+			r.fired[key] = true
+			if len(h.Fire) > 0 {
+				runs = append(runs, hookRun{h.Fire, hookEnv(name, "firing", active[name], stats, since), h.Timeout})
+			}
+		}
+	}
+	return runs
+}
+
+// hookEnv describes an alert to a hook command in MTOP_* variables on top
+// of mtop's own environment
+func hookEnv(name, state, message string, stats SystemStats, since time.Time) []string {
+	env := append(os.Environ(),
+		"MTOP_ALERT="+name,
+		"MTOP_ALERT_STATE="+state,
+		"MTOP_ALERT_MESSAGE="+message,
+		"MTOP_ALERT_SINCE="+since.Format(time.RFC3339),
+		"MTOP_HOST="+hostname,
+	)
+	metric := strings.TrimSuffix(name, " normal")
+	if m, ok := alertMetrics[metric]; ok {
+		env = append(env, "MTOP_ALERT_VALUE="+strconv.FormatFloat(m.value(stats), 'f', -1, 64))
+		if limit, ok := alertThresholds[metric]; ok && metric == name {
+			env = append(env, "MTOP_ALERT_THRESHOLD="+strconv.FormatFloat(limit, 'f', -1, 64))
+		}
+	} else if v, ok := historyMetrics(stats)[name]; ok {
+		env = append(env, "MTOP_ALERT_VALUE="+strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	return env
+}
+
+// run runs a hook command to completion or its timeout. A hook that
+// exits leaving a background child holding its output has succeeded.
+func (h hookRun) run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, expandHome(h.command[0]), h.command[1:]...)
+	cmd.Env = h.env
+	cmd.WaitDelay = hookWaitDelay
+	out, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s timed out after %v", h.command[0], h.timeout)
+	}
+	if err != nil && !errors.Is(err, exec.ErrWaitDelay) {
+		if msg, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n"); msg != "" {
+			return fmt.Errorf("%s: %v: %s", h.command[0], err, msg)
+		}
+		return fmt.Errorf("%s: %v", h.command[0], err)
+	}
+	return nil
+}
+
+// hookCmd runs hook commands in the background, one after another, and
+// reports the first that fails
+func hookCmd(runs []hookRun) tea.Cmd {
+	if len(runs) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		for _, h := range runs {
+			if err := h.run(); err != nil {
+				return hookFailedMsg{err}
+			}
+		}
+		return nil
+	}
+}
+
+// hookFailedMsg reports a hook command that failed
+type hookFailedMsg struct{ err error }
+
+// runHooks runs hook commands one after another, warning on errs about
+// those that fail; batch runs start it in the background
+func runHooks(runs []hookRun, errs io.Writer) {
+	for _, h := range runs {
+		if err := h.run(); err != nil {
+			fmt.Fprintf(errs, "Warning: alert hook failed: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyHooks(t *testing.T) {
+	defer func(h []hookConfig) { alertHooks = h }(alertHooks)
+	for _, bad := range []hookConfig{
+		{Fire: []string{"true"}},
+		{Alert: "cpu"},
+		{Alert: "fans", Fire: []string{"true"}},
+		{Alert: "fans normal", Fire: []string{"true"}},
+		{Alert: "cpu", Fire: []string{""}},
+		{Alert: "cpu", Fire: []string{"true"}, After: -time.Second},
+	} {
+		if err := applyHooks([]hookConfig{bad}); err == nil {
+			t.Errorf("applyHooks(%+v): no error", bad)
+		}
+	}
+	for _, alert := range []string{"cpu", "cpu normal", "cpu.usage", "*"} {
+		if err := applyHooks([]hookConfig{{Alert: alert, Clear: []string{"true"}}}); err != nil {
+			t.Errorf("applyHooks for %q: %v", alert, err)
+		}
+	}
+	if alertHooks[0].Timeout != hookTimeout {
+		t.Errorf("default timeout = %v, want %v", alertHooks[0].Timeout, hookTimeout)
+	}
+}
+
+func TestHookRunner(t *testing.T) {
+	defer func(h []hookConfig, th map[string]float64) { alertHooks, alertThresholds = h, th }(alertHooks, alertThresholds)
+	alertThresholds = map[string]float64{"cpu": 90}
+	alertHooks = []hookConfig{
+		{Alert: "cpu", After: 30 * time.Second, Fire: []string{"hot"}, Clear: []string{"cool"}},
+		{Alert: "*", Clear: []string{"any-clear"}},
+	}
+	r := newHookRunner()
+	stats := fixtureStats()
+	stats.CPU.Usage = 97
+	active := map[string]string{"cpu": "cpu 97.0% > 90%"}
+	commands := func(runs []hookRun) []string {
+		var out []string
+		for _, h := range runs {
+			out = append(out, h.command[0])
+		}
+		return out
+	}
+
+	at := time.Unix(1700000000, 0)
+	if got := commands(r.due(active, stats, at)); len(got) != 0 {
+		t.Errorf("as the alert starts: %v, want nothing", got)
+	}
+	runs := r.due(active, stats, at.Add(30*time.Second))
+	if got := commands(runs); !slices.Equal(got, []string{"hot"}) {
+		t.Fatalf("after 30s: %v, want [hot]", got)
+	}
+	for _, want := range []string{"MTOP_ALERT=cpu", "MTOP_ALERT_STATE=firing", "MTOP_ALERT_VALUE=97", "MTOP_ALERT_THRESHOLD=90"} {
+		if !slices.Contains(runs[0].env, want) {
+			t.Errorf("fire environment lacks %s", want)
+		}
+	}
+	if got := commands(r.due(active, stats, at.Add(time.Minute))); len(got) != 0 {
+		t.Errorf("still alerting: %v, want nothing", got)
+	}
+	if got := commands(r.due(nil, stats, at.Add(2*time.Minute))); !slices.Equal(got, []string{"cool", "any-clear"}) {
+		t.Errorf("once cleared: %v, want [cool any-clear]", got)
+	}
+
+	// An alert too short to fire the hook does not clear it either
+	r.due(active, stats, at.Add(3*time.Minute))
+	if got := commands(r.due(nil, stats, at.Add(3*time.Minute+time.Second))); !slices.Equal(got, []string{"any-clear"}) {
+		t.Errorf("after a short alert: %v, want [any-clear]", got)
+	}
+}
+
+func TestHookRun(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "alert")
+	h := hookRun{
+		command: []string{"sh", "-c", `echo "$MTOP_ALERT $MTOP_ALERT_STATE" > "$1"`, "sh", out},
+		env:     append(os.Environ(), "MTOP_ALERT=cpu", "MTOP_ALERT_STATE=firing"),
+		timeout: time.Second,
+	}
+	if err := h.run(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(out); strings.TrimSpace(string(got)) != "cpu firing" {
+		t.Errorf("hook saw %q", got)
+	}
+	h.command = []string{"sh", "-c", "echo nope >&2; exit 3"}
+	if err := h.run(); err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("failing hook: %v", err)
+	}
+
+	// A background child keeps the output open past the hook's own exit
+	start := time.Now()
+	h.command = []string{"sh", "-c", "sleep 5 & echo started"}
+	if err := h.run(); err != nil {
+		t.Errorf("hook leaving a child running: %v", err)
+	}
+	h.command, h.timeout = []string{"sh", "-c", "sleep 5 & sleep 5"}, 50*time.Millisecond
+	if err := h.run(); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("slow hook with a child: %v, want a timeout", err)
+	}
+	if took := time.Since(start); took > 3*time.Second {
+		t.Errorf("hooks with background children took %v, want them cut off", took)
+	}
+}
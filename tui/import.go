@@ -0,0 +1,201 @@
+package tui
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/khoi/mtop/history"
+)
+
+// importRecord is one line of an import file: a sample time and either a
+// full snapshot, as printed by --json, or named history metrics. A line
+// may instead, or as well, carry a note made at that time, and --log-file
+// writes lines with only an event.
+type importRecord struct {
+	Time    time.Time          `json:"time"`
+	Host    string             `json:"host"` // Where the sample was taken, if not here
+	Stats   *SystemStats       `json:"stats"`
+	Metrics map[string]float64 `json:"metrics"`
+	Note    string             `json:"note,omitempty"`
+	Event   *logEvent          `json:"event,omitempty"`
+	Summary *runSummary        `json:"summary,omitempty"` // Ends a --summary run
+}
+
+// runImport implements "mtop import": it merges samples from JSON Lines
+// files into the saved history that --keep-history restores
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	path := fs.String("history-file", historyPath(), "Saved history to merge into")
+	baselinePath := fs.String("baseline-file", weekBaselinePath(), "Usual readings by hour of the week, for [normal] alerts")
+	window := fs.Duration("history", historyRetention, "History window the TUI runs with; sizes the stored series")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s import [OPTIONS] FILE.jsonl...\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Merges recorded samples into the history restored by --keep-history. Each line\n")
+		fmt.Fprintf(os.Stderr, "is {\"time\": ..., \"stats\": {...}} with a snapshot as printed by --json, or\n")
+		fmt.Fprintf(os.Stderr, "{\"time\": ..., \"metrics\": {\"cpu.usage\": 12.5, ...}}; \"host\" is optional.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	if *window <= 0 {
+		fmt.Fprintf(os.Stderr, "Invalid --history: must be positive\n")
+		return 2
+	}
+	historyRetention = *window
+
+	store, err := loadSavedHistory(*path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", *path, err)
+		return 1
+	}
+	// Recordings also teach the usual readings for [normal] alerts
+	week, err := readWeekBaseline(*baselinePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", *baselinePath, err)
+		return 1
+	}
+	for _, name := range fs.Args() {
+		f, err := openRecording(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		n, err := importSamples(store, week, f, filepath.Base(name))
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error importing %s: %v\n", name, err)
+			return 1
+		}
+		fmt.Printf("Imported %d samples from %s\n", n, name)
+	}
+	if err := saveHistory(*path, store); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving %s: %v\n", *path, err)
+		return 1
+	}
+	if err := saveWeekBaseline(*baselinePath, week); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving %s: %v\n", *baselinePath, err)
+		return 1
+	}
+	return 0
+}
+
+// loadSavedHistory reads the whole saved history at path, or an empty one
+// if nothing was saved yet
+func loadSavedHistory(path string) (*history.Store, error) {
+	store := newHistory()
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return store, store.Restore(f, time.Time{})
+}
+
+// importSamples merges the records read from r into store and returns how
+// many were read. Nothing is merged if any line is invalid. The start of
+// the imported samples is marked with source and, if given, the host.
+// Full snapshots are also learned into week unless it is nil, and notes
+// become marks.
+func importSamples(store *history.Store, week weekBaseline, r io.Reader, source string) (int, error) {
+	series := make(map[string][]history.Sample)
+	var shots []snapshot
+	var notes []history.Mark
+	var first time.Time
+	var host string
+	records := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec importRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return 0, fmt.Errorf("line %d: %w", line, err)
+		}
+		if rec.Time.IsZero() {
+			return 0, fmt.Errorf("line %d: no time", line)
+		}
+		if rec.Note != "" {
+			notes = append(notes, history.Mark{Time: rec.Time, Text: rec.Note})
+		}
+		if rec.Stats == nil && len(rec.Metrics) == 0 && (rec.Note != "" || rec.Event != nil || rec.Summary != nil) {
+			continue
+		}
+		metrics := rec.Metrics
+		if rec.Stats != nil {
+			metrics = historyMetrics(*rec.Stats)
+			shots = append(shots, snapshot{rec.Time, *rec.Stats})
+		}
+		if len(metrics) == 0 {
+			return 0, fmt.Errorf("line %d: neither stats nor metrics", line)
+		}
+		for name, v := range metrics {
+			if !historyMetric(name) {
+				return 0, fmt.Errorf("line %d: unknown metric %q", line, name)
+			}
+			series[name] = append(series[name], history.Sample{Time: rec.Time, Value: v})
+		}
+		if first.IsZero() || rec.Time.Before(first) {
+			first = rec.Time
+		}
+		if rec.Host != "" {
+			host = rec.Host
+		}
+		records++
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	names := make([]string, 0, len(series))
+	for name := range series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		store.Merge(name, series[name])
+	}
+	if week != nil {
+		for _, shot := range shots {
+			week.learn(shot.at, shot.stats)
+		}
+	}
+	if records > 0 {
+		note := "imported " + source
+		if host != "" {
+			note += " from " + host
+		}
+		store.Annotate(first, note)
+	}
+	for _, note := range notes {
+		store.Annotate(note.Time, note.Text)
+	}
+	return records, nil
+}
+
+// historyMetric reports whether name is a metric the history records
+func historyMetric(name string) bool {
+	if name == metricWiFiRSSI {
+		return true
+	}
+	_, ok := historyMetrics(SystemStats{})[name]
+	return ok
+}
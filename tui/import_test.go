@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportSamples(t *testing.T) {
+	input := `{"time": "2026-10-17T10:00:02Z", "host": "build-01", "metrics": {"cpu.usage": 20, "memory.usage": 50}}
+{"time": "2026-10-17T10:00:00Z", "stats": {"cpu": {"usage": 10}, "memory": {"usage": 40}}}
+
+{"time": "2026-10-17T10:00:01Z", "metrics": {"cpu.usage": 15}}
+`
+	store := newHistory()
+	n, err := importSamples(store, nil, strings.NewReader(input), "build.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("imported %d records, want 3", n)
+	}
+	if cpu := store.Values(metricCPU); len(cpu) != 3 || cpu[0] != 10 || cpu[1] != 15 || cpu[2] != 20 {
+		t.Errorf("cpu = %v, want 10 15 20 in time order", cpu)
+	}
+	marks := store.Marks()
+	if len(marks) != 1 || marks[0].Text != "imported build.jsonl from build-01" {
+		t.Errorf("marks = %v", marks)
+	}
+}
+
+func TestImportRejectsBadLines(t *testing.T) {
+	for _, input := range []string{
+		`{"metrics": {"cpu.usage": 1}}`,
+		`{"time": "2026-10-17T10:00:00Z"}`,
+		`{"time": "2026-10-17T10:00:00Z", "metrics": {"cpu.usge": 1}}`,
+		`not json`,
+	} {
+		store := newHistory()
+		if _, err := importSamples(store, nil, strings.NewReader(input), "x"); err == nil {
+			t.Errorf("%s was accepted", input)
+		}
+		if len(store.Names()) != 0 {
+			t.Errorf("%s left samples behind", input)
+		}
+	}
+}
+
+func TestImportIntoSavedHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.gob")
+	store, err := loadSavedHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	importSamples(store, nil, strings.NewReader(`{"time": "2026-10-17T10:00:00Z", "metrics": {"gpu.usage": 30}}`), "a")
+	if err := saveHistory(path, store); err != nil {
+		t.Fatal(err)
+	}
+	again, err := loadSavedHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gpu := again.Values(metricGPU); len(gpu) != 1 || gpu[0] != 30 {
+		t.Errorf("saved gpu history = %v", gpu)
+	}
+}
@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tag values escape commas, spaces and equals signs
+var influxTagEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `, `=`, `\=`)
+
+// formatInfluxLines renders one sample in the InfluxDB line protocol: one
+// mtop_<group> measurement per history metric group, tagged with the host,
+// with a field per metric and a nanosecond timestamp, e.g.
+// "mtop_cpu,host=mac load1=1.5,usage=42.1 1700000000000000000". Disks
+// and interfaces follow as mtop_disk_device and mtop_net_interface,
+// tagged with their name, and processes as mtop_process, tagged with their
+// PID and name.
+func formatInfluxLines(at time.Time, stats SystemStats) string {
+	fields := make(map[string][]string)
+	for name, v := range historyMetrics(stats) {
+		group, field, _ := strings.Cut(name, ".")
+		fields[group] = append(fields[group], field+"="+strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	groups := make([]string, 0, len(fields))
+	for group := range fields {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	var b strings.Builder
+	host := influxTagEscaper.Replace(hostname)
+	for _, group := range groups {
+		sort.Strings(fields[group])
+		fmt.Fprintf(&b, "mtop_%s,host=%s %s %d\n", group, host, strings.Join(fields[group], ","), at.UnixNano())
+	}
+	float := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	for _, d := range stats.Disk.Devices {
+		fmt.Fprintf(&b, "mtop_disk_device,host=%s,device=%s busy=%s,read=%d,read_rate=%s,write_rate=%s,written=%d %d\n",
+			host, influxTagEscaper.Replace(d.Name), float(d.Busy), d.Read, float(d.ReadRate), float(d.WriteRate), d.Written, at.UnixNano())
+	}
+	for _, i := range stats.Network.Interfaces {
+		fmt.Fprintf(&b, "mtop_net_interface,host=%s,interface=%s received=%d,rx_rate=%s,sent=%d,tx_rate=%s %d\n",
+			host, influxTagEscaper.Replace(i.Name), i.Received, float(i.RxRate), i.Sent, float(i.TxRate), at.UnixNano())
+	}
+	for _, proc := range stats.Processes {
+		fmt.Fprintf(&b, "mtop_process,host=%s,pid=%d,name=%s cpu=%s,rss=%d %d\n",
+			host, proc.PID, influxTagEscaper.Replace(proc.Name), float(proc.CPU), proc.RSS, at.UnixNano())
+	}
+	return b.String()
+}
+
+// influxPoster sends what is written to it to an InfluxDB write endpoint,
+// one request per write, authenticating with $INFLUX_TOKEN when it is set.
+// A failed write is reported and dropped so a restarting server does not
+// end the stream.
+type influxPoster struct {
+	url    string
+	token  string
+	client *http.Client
+	errs   io.Writer
+}
+
+func newInfluxPoster(url string) *influxPoster {
+	return &influxPoster{
+		url:    url,
+		token:  os.Getenv("INFLUX_TOKEN"),
+		client: &http.Client{Timeout: 10 * time.Second},
+		errs:   os.Stderr,
+	}
+}
+
+func (p *influxPoster) Write(lines []byte) (int, error) {
+	if err := p.post(lines); err != nil {
+		fmt.Fprintf(p.errs, "Warning: InfluxDB write failed: %v\n", err)
+	}
+	return len(lines), nil
+}
+
+func (p *influxPoster) post(lines []byte) error {
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(lines))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Token "+p.token)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
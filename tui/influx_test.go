@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInfluxLines(t *testing.T) {
+	defer func(h string) { hostname = h }(hostname)
+	hostname = "build box,1"
+	at := time.Unix(1700000000, 5)
+	stats := fixtureStats()
+	stats.Processes = []ProcessStats{{PID: 42, Name: "Google Chrome", CPU: 12.5, RSS: 1024}}
+	out := formatInfluxLines(at, stats)
+
+	for _, want := range []string{
+		`mtop_cpu,host=build\ box\,1 load1=`,
+		`mtop_disk,host=build\ box\,1 busy=`,
+		" 1700000000000000005\n",
+		`mtop_process,host=build\ box\,1,pid=42,name=Google\ Chrome cpu=12.5,rss=1024 `,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("line protocol lacks %q:\n%s", want, out)
+		}
+	}
+	if !strings.Contains(out, ",usage=37.5 ") {
+		t.Errorf("CPU usage field missing:\n%s", out)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		if !strings.HasPrefix(line, "mtop_") || strings.Count(line, " ")-strings.Count(line, `\ `) != 2 {
+			t.Errorf("malformed line %q", line)
+		}
+	}
+}
+
+func TestInfluxPoster(t *testing.T) {
+	var got, auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got, auth = string(body), r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	var errs strings.Builder
+	p := newInfluxPoster(srv.URL)
+	p.token, p.errs = "secret", &errs
+	io.WriteString(p, "mtop_cpu,host=a usage=1 1\n")
+	if got != "mtop_cpu,host=a usage=1 1\n" || auth != "Token secret" {
+		t.Errorf("posted %q with Authorization %q", got, auth)
+	}
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bucket not found", http.StatusNotFound)
+	})
+	if n, err := io.WriteString(p, "x"); n != 1 || err != nil {
+		t.Errorf("failed write returned %d, %v; want it dropped", n, err)
+	}
+	if !strings.Contains(errs.String(), "bucket not found") {
+		t.Errorf("failure not reported: %q", errs.String())
+	}
+}
@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// collectorTimes holds the running totals of one collector group
+type collectorTimes struct {
+	runs     uint64
+	errors   uint64
+	timeouts uint64 // Samples a watched group had no result for by its deadline
+	restarts uint64 // Times the watchdog restarted the group
+	total    time.Duration
+	last     time.Duration
+	longest  time.Duration
+}
+
+// internalStats counts what mtop's own collection costs, for the agent's
+// metrics endpoint. The scheduler records into it while the HTTP server
+// reads it, so every access holds mu.
+type internalStats struct {
+	mu         sync.Mutex
+	collectors map[string]*collectorTimes
+	published  uint64 // Samples written to the ring
+	dropped    uint64 // Samples skipped because collection overran the interval or failed
+}
+
+var internals = &internalStats{collectors: make(map[string]*collectorTimes)}
+
+// group returns a collector group's totals; s.mu must be held
+func (s *internalStats) group(name string) *collectorTimes {
+	c, ok := s.collectors[name]
+	if !ok {
+		c = &collectorTimes{}
+		s.collectors[name] = c
+	}
+	return c
+}
+
+// observe records one run of a collector group
+func (s *internalStats) observe(group string, took time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.group(group)
+	c.runs++
+	c.total += took
+	c.last = took
+	c.longest = max(c.longest, took)
+	if err != nil {
+		c.errors++
+	}
+}
+
+// timedOut records a watched group missing its deadline
+func (s *internalStats) timedOut(group string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.group(group).timeouts++
+}
+
+// restarted records the watchdog restarting a group
+func (s *internalStats) restarted(group string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.group(group).restarts++
+}
+
+// sampled records the outcome of one agent interval: a published sample,
+// or samples dropped
+func (s *internalStats) sampled(published bool, dropped uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if published {
+		s.published++
+	}
+	s.dropped += dropped
+}
+
+// internalMetric is one metric family in the exposition
+type internalMetric struct {
+	name, kind, help string
+	value            func(c *collectorTimes) float64
+}
+
+// Per-collector metric families, labeled by collector group
+var collectorMetrics = []internalMetric{
+	{"mtop_internal_collector_runs_total", "counter", "Times each collector group ran.",
+		func(c *collectorTimes) float64 { return float64(c.runs) }},
+	{"mtop_internal_collector_errors_total", "counter", "Collector group runs that failed.",
+		func(c *collectorTimes) float64 { return float64(c.errors) }},
+	{"mtop_internal_collector_timeouts_total", "counter", "Samples a collector group had no result for by the watchdog deadline.",
+		func(c *collectorTimes) float64 { return float64(c.timeouts) }},
+	{"mtop_internal_collector_restarts_total", "counter", "Times the watchdog restarted a stuck collector group.",
+		func(c *collectorTimes) float64 { return float64(c.restarts) }},
+	{"mtop_internal_collector_duration_seconds_total", "counter", "Time spent in each collector group.",
+		func(c *collectorTimes) float64 { return c.total.Seconds() }},
+	{"mtop_internal_collector_last_duration_seconds", "gauge", "Duration of the latest run of each collector group.",
+		func(c *collectorTimes) float64 { return c.last.Seconds() }},
+	{"mtop_internal_collector_max_duration_seconds", "gauge", "Longest run of each collector group.",
+		func(c *collectorTimes) float64 { return c.longest.Seconds() }},
+}
+
+// writePrometheus writes the metrics in the Prometheus text format
+func (s *internalStats) writePrometheus(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	groups := make([]string, 0, len(s.collectors))
+	for name := range s.collectors {
+		groups = append(groups, name)
+	}
+	sort.Strings(groups)
+	for _, m := range collectorMetrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		for _, group := range groups {
+			fmt.Fprintf(w, "%s{collector=%q} %g\n", m.name, group, m.value(s.collectors[group]))
+		}
+	}
+
+	writeSingle := func(name, kind, help string, value float64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", name, help, name, kind, name, value)
+	}
+	writeSingle("mtop_internal_samples_published_total", "counter", "Samples written to the shared ring.", float64(s.published))
+	writeSingle("mtop_internal_samples_dropped_total", "counter", "Samples lost to slow or failed collection.", float64(s.dropped))
+	writeSingle("mtop_internal_goroutines", "gauge", "Goroutines in the mtop process.", float64(runtime.NumGoroutine()))
+}
+
+// serveInternalMetrics serves /metrics on addr until the process exits
+func serveInternalMetrics(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		internals.writePrometheus(w)
+	})
+	return http.ListenAndServe(addr, mux)
+}
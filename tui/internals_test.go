@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInternalMetricsExposition(t *testing.T) {
+	s := &internalStats{collectors: make(map[string]*collectorTimes)}
+	s.observe("system", 20*time.Millisecond, nil)
+	s.observe("system", 40*time.Millisecond, errors.New("boom"))
+	s.observe("power", 5*time.Millisecond, nil)
+	s.sampled(true, 0)
+	s.sampled(false, 3)
+
+	var b strings.Builder
+	s.writePrometheus(&b)
+	out := b.String()
+	for _, want := range []string{
+		"# TYPE mtop_internal_collector_runs_total counter\n",
+		`mtop_internal_collector_runs_total{collector="power"} 1` + "\n",
+		`mtop_internal_collector_runs_total{collector="system"} 2` + "\n",
+		`mtop_internal_collector_errors_total{collector="system"} 1` + "\n",
+		`mtop_internal_collector_duration_seconds_total{collector="system"} 0.06` + "\n",
+		`mtop_internal_collector_max_duration_seconds{collector="system"} 0.04` + "\n",
+		"mtop_internal_samples_published_total 1\n",
+		"mtop_internal_samples_dropped_total 3\n",
+		"mtop_internal_goroutines ",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("exposition is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestMissedTicks(t *testing.T) {
+	start := time.Unix(100, 0)
+	tests := []struct {
+		prev, now time.Time
+		err       error
+		want      uint64
+	}{
+		{time.Time{}, start, nil, 0},
+		{start, start.Add(time.Second), nil, 0},
+		{start, start.Add(1200 * time.Millisecond), nil, 0}, // A late tick, not a lost one
+		{start, start.Add(3 * time.Second), nil, 2},
+		{start, start.Add(time.Second), errors.New("failed"), 1},
+	}
+	for _, tt := range tests {
+		if got := missedTicks(tt.prev, tt.now, time.Second, tt.err); got != tt.want {
+			t.Errorf("missedTicks(%v after prev, %v) = %d, want %d", tt.now.Sub(tt.prev), tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Longest refresh interval the TUI and --interval accept
+const maxRefreshRate = 5 * time.Second
+
+// intervalFlag is a refresh interval given as a duration ("500ms") or a
+// number of seconds ("2", "0.5"), as top and watch take it
+type intervalFlag time.Duration
+
+func (f *intervalFlag) String() string { return time.Duration(*f).String() }
+
+func (f *intervalFlag) Set(s string) error {
+	d, err := parseInterval(s)
+	if err != nil {
+		return err
+	}
+	*f = intervalFlag(d)
+	return nil
+}
+
+// parseInterval reads an interval and checks it is within the refresh
+// rate bounds
+func parseInterval(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		secs, ferr := strconv.ParseFloat(s, 64)
+		if ferr != nil {
+			return 0, fmt.Errorf("want a duration such as 500ms or a number of seconds, got %q", s)
+		}
+		d = time.Duration(secs * float64(time.Second))
+	}
+	return d, checkRefreshRate(d)
+}
+
+// checkRefreshRate reports a refresh interval outside the bounds the
+// history and the rate keys work within
+func checkRefreshRate(d time.Duration) error {
+	if d < minRefreshRate || d > maxRefreshRate {
+		return fmt.Errorf("must be between %v and %v", minRefreshRate, maxRefreshRate)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package tui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInterval(t *testing.T) {
+	for _, tt := range []struct {
+		input string
+		want  time.Duration
+	}{
+		{"500ms", 500 * time.Millisecond},
+		{"2", 2 * time.Second},
+		{"0.25", 250 * time.Millisecond},
+		{"5s", 5 * time.Second},
+	} {
+		got, err := parseInterval(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("parseInterval(%q) = %v, %v; want %v", tt.input, got, err, tt.want)
+		}
+	}
+	for _, input := range []string{"", "fast", "50ms", "0", "-1", "6s", "1m"} {
+		if _, err := parseInterval(input); err == nil {
+			t.Errorf("parseInterval(%q) succeeded", input)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/khoi/mtop/widgets"
+)
+
+// Default keys for each action; the first key is shown in the help. Keys
+// separated by a space form a sequence, e.g. "g g".
+var defaultKeyBindings = map[string][]string{
+	"quit":              {"q", "ctrl+c"},
+	"overview":          {"1"},
+	"cpu":               {"2"},
+	"memory":            {"3"},
+	"gpu":               {"4"},
+	"flame":             {"5"},
+	"treemap":           {"6"},
+	"processes":         {"7"},
+	"power":             {"8"},
+	"network":           {"9"},
+	"errors":            {"0"},
+	"explain":           {"e"},
+	"theme":             {"T"},
+	"copy_panel":        {"C"},
+	"faster":            {"+", "="},
+	"slower":            {"-", "_"},
+	"memory_accounting": {"a"},
+	"memory_debug":      {"d"},
+	"debug":             {"D"},
+	"help":              {"?"},
+	"pause":             {"p"},
+	"older":             {"["},
+	"newer":             {"]"},
+	"seek":              {":"},
+	"note":              {"m"},
+	"mute":              {"M"},
+	"split":             {"s"},
+	"focus_pane":        {"w"},
+	"next_view":         {"tab"},
+	"prev_view":         {"shift+tab"},
+	"split_grow":        {"}"},
+	"split_shrink":      {"{"},
+	"overlay":           {"v"},
+	"overlay_2":         {"V"},
+	"correlate":         {"r"},
+	"correlate_2":       {"R"},
+
+	// Navigation in the process table, column picker and explain panel
+	"up":        {"up", "k"},
+	"down":      {"down", "j"},
+	"left":      {"left", "h"},
+	"right":     {"right", "l"},
+	"top":       {"home"},
+	"bottom":    {"end"},
+	"page_up":   {"pgup"},
+	"page_down": {"pgdown"},
+	"select":    {" ", "enter"},
+
+	// Process table actions
+	"columns":      {"c"},
+	"group":        {"A"},
+	"copy_pid":     {"y"},
+	"copy_command": {"Y"},
+	"reveal":       {"o"},
+	"terminal":     {"t"},
+	"search":       {"/"},
+	"search_next":  {"n"},
+	"search_prev":  {"N"},
+}
+
+// Presets selectable with key_preset; each replaces the keys of the
+// actions it names, and [keys] applies on top
+var keyPresets = map[string]map[string][]string{
+	"default": {},
+	"vim": {
+		"top":       {"g g", "home"},
+		"bottom":    {"G", "end"},
+		"page_up":   {"ctrl+u", "pgup"},
+		"page_down": {"ctrl+d", "pgdown"},
+		"quit":      {"q", "Z Q", "ctrl+c"},
+	},
+}
+
+// Effective bindings: action to keys, key to action, and the first keys of
+// sequences; set with useKeys
+var (
+	actionKeys  = defaultKeyBindings
+	keyActions  = reverseBindings(defaultKeyBindings)
+	keyPrefixes = map[string]bool{}
+)
+
+// keyBindings is a built set of bindings, ready for useKeys
+type keyBindings struct {
+	actions  map[string][]string
+	reverse  map[string]string
+	prefixes map[string]bool
+}
+
+// defaultKeys are the bindings of Models without their own, set by
+// bindKeys; activeKeys are the ones in effect
+var (
+	defaultKeys = &keyBindings{actionKeys, keyActions, keyPrefixes}
+	activeKeys  = defaultKeys
+)
+
+// bindKeys makes a preset with overrides, as buildKeys takes them, the
+// bindings of every Model without its own
+func bindKeys(preset string, overrides map[string]keyList) error {
+	k, err := buildKeys(preset, overrides)
+	if err != nil {
+		return err
+	}
+	defaultKeys = k
+	useKeys(k)
+	return nil
+}
+
+// useKeys puts k in effect, for the Model about to handle a message or
+// render
+func useKeys(k *keyBindings) {
+	if k == activeKeys {
+		return
+	}
+	activeKeys = k
+	actionKeys, keyActions, keyPrefixes = k.actions, k.reverse, k.prefixes
+	widgets.Keys = k.reverse
+	cachedHelp = ""
+}
+
+// buildKeys applies a preset and then replaces the keys of the actions
+// named in overrides, keeping the defaults for the rest
+func buildKeys(preset string, overrides map[string]keyList) (*keyBindings, error) {
+	if preset == "" {
+		preset = "default"
+	}
+	presetKeys, ok := keyPresets[preset]
+	if !ok {
+		return nil, fmt.Errorf("unknown key preset %q (want %s)", preset, strings.Join(sortedKeys(keyPresets), ", "))
+	}
+	bindings := make(map[string][]string, len(defaultKeyBindings))
+	for action, keys := range defaultKeyBindings {
+		bindings[action] = keys
+	}
+	for action, keys := range presetKeys {
+		bindings[action] = keys
+	}
+	for action, keys := range overrides {
+		if _, ok := defaultKeyBindings[action]; !ok {
+			return nil, fmt.Errorf("unknown action %q (want %s)", action, strings.Join(sortedKeys(defaultKeyBindings), ", "))
+		}
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("no keys given for %q", action)
+		}
+		bindings[action] = keys
+	}
+
+	reverse := reverseBindings(bindings)
+	prefixes := make(map[string]bool)
+	for action, keys := range bindings {
+		for _, key := range keys {
+			if other := reverse[key]; other != action {
+				return nil, fmt.Errorf("key %q is bound to both %q and %q", key, action, other)
+			}
+			if seq := strings.Fields(key); len(seq) > 2 {
+				return nil, fmt.Errorf("key sequence %q for %q is longer than two keys", key, action)
+			} else if len(seq) == 2 {
+				prefixes[seq[0]] = true
+			}
+		}
+	}
+	// A key that starts a sequence waits for the next key, so it cannot
+	// also act on its own
+	for prefix := range prefixes {
+		if action, ok := reverse[prefix]; ok {
+			return nil, fmt.Errorf("key %q of %q also starts a key sequence", prefix, action)
+		}
+	}
+
+	return &keyBindings{bindings, reverse, prefixes}, nil
+}
+
+// reverseBindings maps each key to its action
+func reverseBindings(bindings map[string][]string) map[string]string {
+	reverse := make(map[string]string)
+	for action, keys := range bindings {
+		for _, key := range keys {
+			reverse[key] = action
+		}
+	}
+	return reverse
+}
+
+// pressKey resolves a key press to its action, completing a sequence
+// started by the previous key. ok is false while a sequence is pending.
+func (m *model) pressKey(key string) (action string, ok bool) {
+	if m.pendingKey != "" {
+		seq := m.pendingKey + " " + key
+		m.pendingKey = ""
+		if action, found := keyActions[seq]; found {
+			return action, true
+		}
+	}
+	if keyPrefixes[key] {
+		m.pendingKey = key
+		return "", false
+	}
+	return keyActions[key], true
+}
+
+// keyFor returns the key shown in the help for an action
+func keyFor(action string) string {
+	if keys := actionKeys[action]; len(keys) > 0 {
+		return keyLabel(keys[0])
+	}
+	return "?"
+}
+
+// keyLabel is how a key is shown in the help: arrows as symbols and
+// sequences written together, e.g. "gg"
+func keyLabel(key string) string {
+	if symbol, ok := glyphs.keys[key]; ok {
+		return symbol
+	}
+	return strings.ReplaceAll(key, " ", "")
+}
+
+// helpKeys lists the keys of each action, an action's keys joined by "/"
+// and actions separated by spaces, e.g. "↑/k ↓/j"
+func helpKeys(actions ...string) string {
+	parts := make([]string, len(actions))
+	for i, action := range actions {
+		labels := make([]string, len(actionKeys[action]))
+		for j, key := range actionKeys[action] {
+			labels[j] = keyLabel(key)
+		}
+		parts[i] = strings.Join(labels, "/")
+	}
+	return strings.Join(parts, " ")
+}
+
+// helpLine lists the global keys for the footer
+func helpLine() string {
+	items := []struct{ action, label string }{
+		{"explain", "Explain"},
+		{"theme", "Theme"},
+		{"copy_panel", "Copy panel"},
+	}
+	parts := make([]string, 0, len(items)+4)
+	parts = append(parts, keyFor("next_view")+"/"+keyFor("prev_view")+": Views")
+	for _, it := range items {
+		parts = append(parts, keyFor(it.action)+": "+it.label)
+	}
+	parts = append(parts, keyFor("faster")+"/"+keyFor("slower")+": Refresh rate",
+		keyFor("help")+": Help", keyFor("quit")+": Quit")
+	return strings.Join(parts, " | ")
+}
+
+// cachedHelp is the styled footer help, rebuilt after the theme or the key
+// bindings change
+var cachedHelp string
+
+// renderedHelp returns the styled footer help
+func renderedHelp() string {
+	if cachedHelp == "" {
+		cachedHelp = helpStyle.Render(helpLine())
+	}
+	return cachedHelp
+}
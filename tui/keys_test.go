@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestVimPresetSequences(t *testing.T) {
+	defer bindKeys("", nil)
+	if err := bindKeys("vim", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var m model
+	if action, ok := m.pressKey("g"); ok {
+		t.Fatalf("g resolved to %q before the sequence finished", action)
+	}
+	if action, ok := m.pressKey("g"); !ok || action != "top" {
+		t.Errorf("g g = %q, %v; want top", action, ok)
+	}
+	// A key that does not complete the sequence acts on its own
+	m.pressKey("g")
+	if action, _ := m.pressKey("G"); action != "bottom" {
+		t.Errorf("g G = %q, want bottom", action)
+	}
+
+	if err := bindKeys("vim", map[string]keyList{"theme": {"g"}}); err == nil {
+		t.Error("binding a key that starts a sequence was accepted")
+	}
+	if err := bindKeys("emacs", nil); err == nil {
+		t.Error("unknown preset was accepted")
+	}
+}
+
+func TestProcessSearch(t *testing.T) {
+	m := model{viewMode: ProcessMode, height: 40, stats: fixtureStats()}
+	procs := m.sortedProcesses()
+
+	m, _ = m.updateProcessKeys("/", "search")
+	for _, r := range "windowserver" {
+		m = m.updateSearchKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if p := procs[m.procCursor]; p.Name != "WindowServer" {
+		t.Errorf("search selected %q, want WindowServer", p.Name)
+	}
+	m = m.updateSearchKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.searching || m.search != "windowserver" {
+		t.Errorf("enter did not keep the search: searching=%v search=%q", m.searching, m.search)
+	}
+
+	// esc puts the cursor back where the prompt opened
+	m.procCursor = 1
+	m, _ = m.updateProcessKeys("/", "search")
+	m = m.updateSearchKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("mds")})
+	m = m.updateSearchKeys(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.procCursor != 1 || m.search != "" {
+		t.Errorf("esc left cursor %d and search %q", m.procCursor, m.search)
+	}
+}
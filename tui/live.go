@@ -0,0 +1,190 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Groups a /ws client can subscribe to: the top-level fields of a sample
+// as they are named in JSON
+var liveGroups = func() []string {
+	var names []string
+	t := reflect.TypeOf(SystemStats{})
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		names = append(names, name)
+	}
+	return names
+}()
+
+// Origins of browser pages allowed to open /ws besides the server's own;
+// "*" allows any. Set with --allow-origin.
+var liveOrigins []string
+
+// liveSample is a sample split into its groups, encoded once for every client
+type liveSample struct {
+	at     time.Time
+	groups map[string]json.RawMessage
+}
+
+// wsClient is a connected /ws client; it is handed the newest sample and
+// drops older ones it has not sent yet
+type wsClient struct {
+	samples chan liveSample
+}
+
+func (c *wsClient) push(sample liveSample) {
+	select {
+	case <-c.samples:
+	default:
+	}
+	c.samples <- sample
+}
+
+// splitSample encodes a sample's groups
+func splitSample(at time.Time, stats SystemStats) (liveSample, error) {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return liveSample{}, err
+	}
+	sample := liveSample{at: at}
+	return sample, json.Unmarshal(data, &sample.groups)
+}
+
+// message renders the sample as the JSON a client receives, with only the
+// groups it subscribed to, or all of them when it named none
+func (s liveSample) message(groups []string) ([]byte, error) {
+	stats := s.groups
+	if len(groups) > 0 {
+		stats = make(map[string]json.RawMessage, len(groups))
+		for _, g := range groups {
+			if v, ok := s.groups[g]; ok {
+				stats[g] = v
+			}
+		}
+	}
+	return json.Marshal(struct {
+		Time  time.Time                  `json:"time"`
+		Host  string                     `json:"host"`
+		Stats map[string]json.RawMessage `json:"stats"`
+	}{s.at, hostname, stats})
+}
+
+// parseLiveGroups checks the groups a client asked for
+func parseLiveGroups(groups []string) error {
+	for _, g := range groups {
+		if !slices.Contains(liveGroups, g) {
+			return fmt.Errorf("unknown group %q (want %s)", g, strings.Join(liveGroups, ", "))
+		}
+	}
+	return nil
+}
+
+// originAllowed reports whether a browser page from the request's Origin
+// may connect: clients that send none are not browsers, and pages must be
+// listed in --allow-origin, so any site open in a browser can't read the
+// process list. An Origin matching the Host header proves nothing: a page
+// whose domain was rebound to this address sends both.
+func originAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	return slices.Contains(liveOrigins, "*") || slices.Contains(liveOrigins, origin)
+}
+
+// handleWebSocket streams every new sample to a client as a JSON text
+// message. The groups query parameter (comma-separated, e.g. cpu,memory)
+// limits what is sent; the client can change it by sending
+// {"groups": [...]}, and an empty list subscribes to everything.
+func (s *apiServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	if !originAllowed(r) {
+		writeAPIError(w, http.StatusForbidden, "origin not allowed; see --allow-origin")
+		return
+	}
+	var groups []string
+	if v := r.URL.Query().Get("groups"); v != "" {
+		groups = strings.Split(v, ",")
+	}
+	if err := parseLiveGroups(groups); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	conn, err := upgradeWebSocket(w, r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer conn.close()
+
+	client := &wsClient{samples: make(chan liveSample, 1)}
+	s.mu.Lock()
+	s.clients[client] = true
+	if !s.at.IsZero() {
+		if sample, err := splitSample(s.at, s.stats); err == nil {
+			client.push(sample)
+		}
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, client)
+		s.mu.Unlock()
+	}()
+
+	// Subscription changes arrive on their own goroutine; errors in them are
+	// answered on the socket and the subscription is kept
+	changes := make(chan []string)
+	done, stop := make(chan struct{}), make(chan struct{})
+	defer close(stop)
+	go func() {
+		defer close(done)
+		for {
+			msg, err := conn.readMessage()
+			if err != nil {
+				return
+			}
+			var req struct {
+				Groups []string `json:"groups"`
+			}
+			if err = json.Unmarshal(msg, &req); err == nil {
+				err = parseLiveGroups(req.Groups)
+			}
+			if err != nil {
+				reply, _ := json.Marshal(map[string]string{"error": err.Error()})
+				conn.writeFrame(wsText, reply)
+				continue
+			}
+			select {
+			case changes <- req.Groups:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	var last liveSample
+	for {
+		select {
+		case last = <-client.samples:
+		case groups = <-changes:
+			if last.groups == nil {
+				continue
+			}
+		case <-done:
+			return
+		}
+		msg, err := last.message(groups)
+		if err != nil {
+			continue
+		}
+		if err := conn.writeFrame(wsText, msg); err != nil {
+			return
+		}
+	}
+}
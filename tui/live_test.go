@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dialLive opens a WebSocket to the test server's /ws
+func dialLive(t *testing.T, srv *httptest.Server, query string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	io.WriteString(conn, "GET /ws"+query+" HTTP/1.1\r\nHost: "+srv.Listener.Addr().String()+
+		"\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The accept value for this key from RFC 6455
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("handshake: %s %v", resp.Status, resp.Header)
+	}
+	return conn, r
+}
+
+// readLive reads one unmasked server text frame and decodes it
+func readLive(t *testing.T, r *bufio.Reader) map[string]json.RawMessage {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		t.Fatal(err)
+	}
+	n := int(head[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(r, ext[:])
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(r, ext[:])
+		n = int(binary.BigEndian.Uint64(ext[:]))
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatal(err)
+	}
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		t.Fatalf("%v: %s", err, payload)
+	}
+	return msg
+}
+
+// sendLive writes a masked client text frame
+func sendLive(conn net.Conn, text string) {
+	mask := [4]byte{1, 2, 3, 4}
+	frame := append([]byte{0x81, 0x80 | byte(len(text))}, mask[:]...)
+	for i := range len(text) {
+		frame = append(frame, text[i]^mask[i%4])
+	}
+	conn.Write(frame)
+}
+
+func statsGroupsOf(t *testing.T, msg map[string]json.RawMessage) []string {
+	t.Helper()
+	var stats map[string]json.RawMessage
+	if err := json.Unmarshal(msg["stats"], &stats); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for name := range stats {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestLiveStream(t *testing.T) {
+	s := newAPIServer()
+	s.record(time.Now(), fixtureStats())
+	srv := httptest.NewServer(s.handler())
+	defer srv.Close()
+
+	conn, r := dialLive(t, srv, "?groups=cpu")
+	defer conn.Close()
+
+	// The newest sample is sent on connect, with the subscribed group only
+	if got := statsGroupsOf(t, readLive(t, r)); len(got) != 1 || got[0] != "cpu" {
+		t.Errorf("first message groups = %v, want [cpu]", got)
+	}
+
+	sendLive(conn, `{"groups":["memory","disk"]}`)
+	if got := statsGroupsOf(t, readLive(t, r)); len(got) != 2 {
+		t.Errorf("groups after resubscribing = %v, want memory and disk", got)
+	}
+
+	sendLive(conn, `{"groups":["cpus"]}`)
+	if msg := readLive(t, r); !strings.Contains(string(msg["error"]), "unknown group") {
+		t.Errorf("bad subscription answered with %v", msg)
+	}
+
+	s.record(time.Now(), fixtureStats())
+	if got := statsGroupsOf(t, readLive(t, r)); len(got) != 2 {
+		t.Errorf("pushed sample groups = %v, want memory and disk", got)
+	}
+}
+
+func TestLiveOrigin(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/ws", nil)
+	if !originAllowed(req) {
+		t.Error("client without an Origin refused")
+	}
+	// A rebound domain's page sends an Origin matching the Host header
+	req = httptest.NewRequest(http.MethodGet, "http://evil.example:8080/ws", nil)
+	req.Header.Set("Origin", "http://evil.example:8080")
+	if originAllowed(req) {
+		t.Error("page with an Origin matching Host allowed without --allow-origin")
+	}
+	req.Header.Set("Origin", "https://example.com")
+	if originAllowed(req) {
+		t.Error("other site allowed without --allow-origin")
+	}
+	liveOrigins = []string{"https://example.com"}
+	defer func() { liveOrigins = nil }()
+	if !originAllowed(req) {
+		t.Error("listed origin refused")
+	}
+}
@@ -0,0 +1,215 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maintenanceConfig is a [[maintenance]] entry of the config file: a
+// window opening on a cron schedule, e.g. schedule = "0 2 * * *" and
+// duration = "1h" for a nightly backup. Alerts are muted while it is open.
+type maintenanceConfig struct {
+	Name     string        `toml:"name"`
+	Schedule string        `toml:"schedule"` // Cron fields: minute hour day-of-month month day-of-week, in local time
+	Duration time.Duration `toml:"duration"` // How long the window stays open; at most maxMaintenance
+	Alerts   []string      `toml:"alerts"`   // Alert names muted; every alert when empty
+}
+
+// Longest maintenance window; openAt looks this far back for its start
+const maxMaintenance = 24 * time.Hour
+
+// maintenanceWindow is a checked [[maintenance]] entry
+type maintenanceWindow struct {
+	name     string
+	schedule cronSchedule
+	duration time.Duration
+	alerts   map[string]bool // nil for every alert
+}
+
+// Maintenance windows from the config file, in its order
+var maintenanceWindows []maintenanceWindow
+
+// applyMaintenance checks and records the maintenance windows from the config
+func applyMaintenance(cfgs []maintenanceConfig) error {
+	windows := make([]maintenanceWindow, 0, len(cfgs))
+	for i, c := range cfgs {
+		if c.Name == "" {
+			return fmt.Errorf("maintenance window %d has no name", i+1)
+		}
+		schedule, err := parseCron(c.Schedule)
+		if err != nil {
+			return fmt.Errorf("maintenance window %q: %v", c.Name, err)
+		}
+		if c.Duration < time.Minute || c.Duration > maxMaintenance {
+			return fmt.Errorf("maintenance window %q: duration must be between 1m and %v", c.Name, maxMaintenance)
+		}
+		w := maintenanceWindow{name: c.Name, schedule: schedule, duration: c.Duration}
+		for _, alert := range c.Alerts {
+			if !knownAlert(alert) {
+				return fmt.Errorf("maintenance window %q: unknown alert %q (want %s, a metric with \" normal\" or a history metric)",
+					c.Name, alert, strings.Join(sortedKeys(alertMetrics), ", "))
+			}
+			if w.alerts == nil {
+				w.alerts = make(map[string]bool)
+			}
+			w.alerts[alert] = true
+		}
+		windows = append(windows, w)
+	}
+	maintenanceWindows = windows
+	return nil
+}
+
+// openAt reports whether the window is open at t: whether the schedule
+// matched a minute within the last duration
+func (w maintenanceWindow) openAt(t time.Time) bool {
+	start := t.Truncate(time.Minute)
+	for at := start; t.Sub(at) < w.duration; at = at.Add(-time.Minute) {
+		if w.schedule.matches(at) {
+			return true
+		}
+	}
+	return false
+}
+
+// openMaintenance names the maintenance windows open at t
+func openMaintenance(t time.Time) map[string]bool {
+	open := make(map[string]bool)
+	for _, w := range maintenanceWindows {
+		if w.openAt(t) {
+			open[w.name] = true
+		}
+	}
+	return open
+}
+
+// inMaintenance reports whether an open maintenance window mutes an alert
+func inMaintenance(name string, t time.Time) bool {
+	for _, w := range maintenanceWindows {
+		if (w.alerts == nil || w.alerts[name]) && w.openAt(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// noteMaintenance marks the history where maintenance windows open and
+// close, so the heavy jobs they cover are explained on the charts
+func (m *model) noteMaintenance(now time.Time) {
+	if len(maintenanceWindows) == 0 {
+		return
+	}
+	open := openMaintenance(now)
+	for _, name := range sortedKeys(open) {
+		if !m.maintenance[name] {
+			m.recordMark(now, "maintenance: "+name)
+			m.setStatus(fmt.Sprintf("Maintenance window %s open; its alerts are muted", name))
+		}
+	}
+	for _, name := range sortedKeys(m.maintenance) {
+		if !open[name] {
+			m.recordMark(now, "maintenance over: "+name)
+		}
+	}
+	m.maintenance = open
+}
+
+// cronSchedule is a parsed cron expression. Each field is a bit set of the
+// values it allows.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	eitherDay                     bool // Neither day field starts with *, so a day matches either
+}
+
+// Ranges of the cron fields, in order
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7}, // 7 is Sunday too
+}
+
+// parseCron reads the five fields of a cron schedule. Each field is *, a
+// number, a range a-b, or a list of them, and may step with /n. As in
+// cron, a day matches when either the day of month or the day of week
+// does, unless one of them starts with *.
+func parseCron(spec string) (cronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return cronSchedule{}, fmt.Errorf("schedule %q must have 5 fields: minute hour day-of-month month day-of-week", spec)
+	}
+	var sets [5]uint64
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i].min, cronFields[i].max)
+		if err != nil {
+			return cronSchedule{}, fmt.Errorf("schedule %q: %s: %v", spec, cronFields[i].name, err)
+		}
+		sets[i] = set
+	}
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+	return cronSchedule{
+		minute: sets[0], hour: sets[1], dom: sets[2], month: sets[3], dow: sets[4],
+		eitherDay: !strings.HasPrefix(fields[2], "*") && !strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseCronField reads one cron field as a bit set of the values in
+// [min, max] it allows
+func parseCronField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("step %q must be a positive number", stepText)
+			}
+			step = n
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			first, last, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(first); err != nil {
+				return 0, fmt.Errorf("%q is not a number", first)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(last); err != nil {
+					return 0, fmt.Errorf("%q is not a number", last)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// matches reports whether the schedule fires in the minute of t
+func (c cronSchedule) matches(t time.Time) bool {
+	has := func(set uint64, v int) bool { return set&(1<<v) != 0 }
+	if !has(c.minute, t.Minute()) || !has(c.hour, t.Hour()) || !has(c.month, int(t.Month())) {
+		return false
+	}
+	dom, dow := has(c.dom, t.Day()), has(c.dow, int(t.Weekday()))
+	if c.eitherDay {
+		return dom || dow
+	}
+	return dom && dow
+}
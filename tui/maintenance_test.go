@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/khoi/mtop/history"
+)
+
+func TestParseCron(t *testing.T) {
+	for _, bad := range []string{"", "0 2 * *", "60 2 * * *", "0 24 * * *", "0 2 0 * *", "0 2 * 13 *", "0 2 * * 8", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := parseCron(bad); err == nil {
+			t.Errorf("parseCron(%q): no error", bad)
+		}
+	}
+
+	// Sunday 2026-10-18
+	at := func(day, hour, min int) time.Time { return time.Date(2026, 10, day, hour, min, 0, 0, time.Local) }
+	tests := []struct {
+		spec string
+		at   time.Time
+		want bool
+	}{
+		{"0 2 * * *", at(18, 2, 0), true},
+		{"0 2 * * *", at(18, 2, 1), false},
+		{"*/15 9-17 * * 1-5", at(19, 9, 45), true},
+		{"*/15 9-17 * * 1-5", at(18, 9, 45), false}, // Sunday
+		{"30 3 * * 7", at(18, 3, 30), true},         // 7 is Sunday
+		{"0 0 1 * 0", at(18, 0, 0), true},           // Either day field matches
+		{"0 0 1 * 1", at(18, 0, 0), false},
+		{"0 0 1,15-20 10 *", at(18, 0, 0), true},
+	}
+	for _, tt := range tests {
+		c, err := parseCron(tt.spec)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", tt.spec, err)
+		}
+		if got := c.matches(tt.at); got != tt.want {
+			t.Errorf("%q matches %s = %v, want %v", tt.spec, tt.at.Format("Mon Jan 2 15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestMaintenanceWindows(t *testing.T) {
+	defer func(w []maintenanceWindow) { maintenanceWindows = w }(maintenanceWindows)
+	for _, bad := range []maintenanceConfig{
+		{Schedule: "0 2 * * *", Duration: time.Hour},
+		{Name: "backup", Schedule: "0 2 * * *"},
+		{Name: "backup", Schedule: "0 2 * * *", Duration: 25 * time.Hour},
+		{Name: "backup", Schedule: "0 2 * * *", Duration: time.Hour, Alerts: []string{"fans"}},
+	} {
+		if err := applyMaintenance([]maintenanceConfig{bad}); err == nil {
+			t.Errorf("applyMaintenance(%+v): no error", bad)
+		}
+	}
+	err := applyMaintenance([]maintenanceConfig{
+		{Name: "backup", Schedule: "0 23 * * *", Duration: 2 * time.Hour, Alerts: []string{"cpu"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	night := time.Date(2026, 10, 18, 23, 0, 0, 0, time.Local)
+	for _, tt := range []struct {
+		after time.Duration
+		alert string
+		want  bool
+	}{
+		{-time.Second, "cpu", false},
+		{0, "cpu", true},
+		{119 * time.Minute, "cpu", true}, // Past midnight
+		{2 * time.Hour, "cpu", false},
+		{time.Hour, "memory", false},
+	} {
+		if got := alertMuted(tt.alert, night.Add(tt.after)); got != tt.want {
+			t.Errorf("%s muted %v after the start = %v, want %v", tt.alert, tt.after, got, tt.want)
+		}
+	}
+
+	m := model{history: history.New(24*time.Hour, time.Second)}
+	m.noteMaintenance(night.Add(-time.Minute))
+	m.noteMaintenance(night.Add(time.Minute))
+	m.noteMaintenance(night.Add(3 * time.Hour))
+	var marks []string
+	for _, mark := range m.history.Marks() {
+		marks = append(marks, mark.Text)
+	}
+	if len(marks) != 2 || marks[0] != "maintenance: backup" || marks[1] != "maintenance over: backup" {
+		t.Errorf("marks = %q", marks)
+	}
+}
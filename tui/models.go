@@ -0,0 +1,774 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/khoi/mtop/history"
+	"github.com/khoi/mtop/stats"
+	"github.com/khoi/mtop/widgets"
+)
+
+// The sample types are defined in package stats, which other Go programs
+// can import to collect the same readings
+type (
+	SystemStats    = stats.SystemStats
+	ThermalState   = stats.ThermalState
+	CPUStats       = stats.CPUStats
+	ClusterStats   = stats.ClusterStats
+	MemoryStats    = stats.MemoryStats
+	MemoryCounters = stats.MemoryCounters
+	SwapStats      = stats.SwapStats
+	GPUStats       = stats.GPUStats
+	PowerStats     = stats.PowerStats
+	DiskStats      = stats.DiskStats
+	DiskDevice     = stats.DiskDevice
+	NetworkStats   = stats.NetworkStats
+	InterfaceStats = stats.InterfaceStats
+	SocketStats    = stats.SocketStats
+	WiFiStats      = stats.WiFiStats
+	ProcessStats   = stats.ProcessStats
+	CoalitionStats = stats.CoalitionStats
+
+	MemoryAccounting = stats.MemoryAccounting
+	MemoryPressure   = stats.MemoryPressure
+)
+
+const (
+	ThermalNominal  = stats.ThermalNominal
+	ThermalFair     = stats.ThermalFair
+	ThermalSerious  = stats.ThermalSerious
+	ThermalCritical = stats.ThermalCritical
+
+	AccountingDefault         = stats.AccountingDefault
+	AccountingActivityMonitor = stats.AccountingActivityMonitor
+
+	PressureNormal   = stats.PressureNormal
+	PressureWarn     = stats.PressureWarn
+	PressureCritical = stats.PressureCritical
+)
+
+// ViewMode represents different display modes
+type ViewMode int
+
+const (
+	OverviewMode ViewMode = iota
+	CPUDetailMode
+	MemoryDetailMode
+	GPUDetailMode
+	FlameMode
+	MemoryTreemapMode
+	ProcessMode
+	PowerMode
+	NetworkMode
+	ErrorsMode
+	CompareMode
+	CorrelateMode
+)
+
+type model struct {
+	stats         SystemStats
+	viewMode      ViewMode
+	refreshRate   time.Duration
+	tickGen       int          // Ticks from an older generation are dropped
+	sched         *scheduler   // Samples each collector group at its cadence
+	agent         *agentReader // Reads samples from a running agent instead, when attached
+	remote        *remoteFeed  // Relays another Mac's samples as the provider, with --remote
+	provider      Provider     // Supplies samples instead of collecting, when set
+	theme         *theme       // Own theme from WithTheme, else defaultTheme
+	keys          *keyBindings // Own bindings from WithKeymap, else defaultKeys
+	notifier      *notifier    // Posts notifications for sustained alerts
+	hooks         *hookRunner  // Runs the alert hooks; nil while replaying
+	lastUpdate    time.Time
+	width         int
+	height        int
+	quit          bool
+	lastError     string
+	statusMsg     string // Transient confirmation shown in the footer
+	statusAt      time.Time
+	session       *cpuSession
+	prevStats     SystemStats // Stats from the previous refresh
+	hasPrev       bool
+	sampledAt     time.Time      // When stats were collected
+	prevSampledAt time.Time      // When prevStats were collected
+	history       *history.Store // Timestamped samples of every metric
+	historyDB     *historyDB     // Database every sample is appended to, with --history-db
+	sampleLog     *sampleLog     // File every sample is appended to, with --log-file
+	baseline      *history.Store // Recording from --compare, if any
+	baselineName  string
+	week          weekBaseline // Usual readings by hour of the week, for [normal] alerts
+
+	memoryDebug bool // Show raw memory counters in the memory view
+
+	// Hidden raw counter dump, toggled with "D"
+	debugView bool
+	debug     []debugSection
+
+	help        bool   // Key binding overlay, toggled with "?"
+	pendingKey  string // First key of a sequence such as "g g"
+	noting      bool   // Typing a note for the timeline
+	note        string
+	muting      bool // Typing into the mute prompt
+	mute        string
+	maintenance map[string]bool // Maintenance windows open at the last sample
+
+	// Split layout: a second pane below or above the focused view
+	split        bool
+	splitFocus   int      // 0 when the top pane has focus, 1 for the bottom
+	splitPercent int      // Share of the rows the top pane gets
+	otherView    ViewMode // View of the pane without focus
+
+	// Pause and scroll-back state
+	paused      bool
+	pauseOffset int  // Samples back from the newest snapshot
+	replay      bool // Showing a recording from --replay; nothing is collected
+	playing     bool // Replay advancing on each tick
+	replaySpeed int  // Samples per tick while playing
+	seeking     bool // Seek prompt open
+	seek        string
+	scrollback  []snapshot // Recent samples, oldest first
+
+	// Metric explanation panel state
+	explain      bool
+	explainFocus int
+
+	// Process table state
+	columns      []string
+	procCursor   int
+	procOffset   int
+	procScroll   int // Columns scrolled past horizontally
+	columnPicker bool
+	pickerCursor int
+	searching    bool   // Typing into the process search prompt
+	search       string // Text searched for in process names and commands
+	searchFrom   int    // Cursor when the search prompt opened
+	procGroup    processGrouping
+
+	overlays  map[string]bool // Metrics drawn over the history charts, shared between copies
+	correlate [2]string       // Metrics of the correlation view; empty ones use the defaults
+}
+
+// prime takes the first sample, falling back to empty readings when it
+// fails
+func (m *model) prime() {
+	if stats, err := m.collect(time.Now()); err == nil {
+		m.stats = stats
+		m.sampledAt = time.Now()
+		m.session.update(stats.Processes)
+		m.recordHistory(m.sampledAt, stats)
+		m.recordSnapshot(m.sampledAt, stats)
+	} else {
+		m.lastError = fmt.Sprintf("Failed to initialize system stats: %v", err)
+		// Provide default stats as fallback
+		m.stats = SystemStats{
+			CPU: CPUStats{
+				Usage:   0.0,
+				Cores:   make([]float64, 8),
+				LoadAvg: [3]float64{0.0, 0.0, 0.0},
+				Temp:    0.0,
+			},
+			Memory: MemoryStats{
+				Total:     0,
+				Used:      0,
+				Available: 0,
+				Usage:     0.0,
+				Swap: SwapStats{
+					Total: 0,
+					Used:  0,
+					Usage: 0.0,
+				},
+			},
+			GPU: GPUStats{
+				Usage:       0.0,
+				MemoryUsage: 0.0,
+				MemoryUsed:  0,
+				MemoryTotal: 0,
+				Temp:        0.0,
+			},
+			Uptime: 0,
+		}
+	}
+}
+
+// Widest the overview sparklines get, one sample per cell
+const overviewSparkWidth = 60
+
+// Width of the usage bars in the overview and CPU view
+const overviewBarWidth = 20
+
+// How long a status message stays in the footer
+const statusDuration = 3 * time.Second
+
+func (m *model) setStatus(msg string) {
+	m.statusMsg = msg
+	m.statusAt = time.Now()
+}
+
+// TickMsg represents a periodic update message
+type TickMsg struct {
+	Time time.Time
+	Gen  int
+}
+
+func (m model) Init() tea.Cmd {
+	return m.tick()
+}
+
+// tick schedules the next update for the current tick generation
+func (m model) tick() tea.Cmd {
+	gen := m.tickGen
+	return tea.Tick(tickInterval(m.refreshRate), func(t time.Time) tea.Msg {
+		return TickMsg{Time: t, Gen: gen}
+	})
+}
+
+// collect samples the system, reading from the attached agent when there
+// is one, or from the provider a Model was built with. An agent that stops is detached and sampling continues locally.
+func (m *model) collect(now time.Time) (SystemStats, error) {
+	if m.provider != nil {
+		return m.provider.Sample(now)
+	}
+	if m.agent != nil {
+		stats, err := m.agent.latest()
+		if err == nil {
+			return stats, nil
+		}
+		m.agent.close()
+		m.agent = nil
+		m.setStatus(fmt.Sprintf("Detached from agent (%v), collecting locally", err))
+	}
+	subscribe("view", m.viewCollectors()...)
+	return m.sched.collect(context.Background(), now, m.refreshRate)
+}
+
+// setRefreshRate applies a new refresh rate right away. The pending tick
+// belongs to the old generation and is dropped when it arrives, so the
+// next update comes one new interval from now.
+func (m *model) setRefreshRate(rate time.Duration) tea.Cmd {
+	m.refreshRate = rate
+	m.tickGen++
+	return m.tick()
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m.useSettings()
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case TickMsg:
+		if msg.Gen != m.tickGen {
+			return m, nil
+		}
+		// Nothing is collected while paused; the ticks keep running so
+		// updates resume on the next one
+		if m.paused {
+			if m.replay {
+				m.stepReplay()
+			}
+			return m, m.tick()
+		}
+
+		// Update system stats with real data
+		if newStats, err := m.collect(msg.Time); err == nil {
+			selected, ok := m.selectedProcess()
+			m.noteClockJump(m.sampledAt, msg.Time)
+			m.prevStats, m.hasPrev = m.stats, true
+			m.stats = newStats
+			m.prevSampledAt, m.sampledAt = m.sampledAt, msg.Time
+			m.session.update(newStats.Processes)
+			m.recordHistory(msg.Time, newStats)
+			m.recordSnapshot(msg.Time, newStats)
+			m.logSample(msg.Time)
+			m.noteMaintenance(msg.Time)
+			if m.week != nil {
+				m.week.learn(msg.Time, newStats)
+			}
+			if m.debugView {
+				m.debug = collectDebugDump(newStats)
+			}
+			m.keepSelection(selected, ok)
+			m.lastError = "" // Clear any previous errors
+		} else {
+			m.lastError = fmt.Sprintf("Error collecting stats: %v", err)
+		}
+		m.lastUpdate = msg.Time
+
+		// Return next tick command
+		alerts := m.alerts()
+		var hooks tea.Cmd
+		if m.hooks != nil {
+			hooks = hookCmd(m.hooks.due(alerts, m.stats, msg.Time))
+		}
+		return m, tea.Batch(m.tick(), notifyCmd(m.notifier.due(alerts, msg.Time)), hooks)
+
+	case notifyFailedMsg:
+		m.setStatus(fmt.Sprintf("Failed to post notification: %v", msg.err))
+
+	case hookFailedMsg:
+		m.setStatus(fmt.Sprintf("Alert hook failed: %v", msg.err))
+
+	case tea.KeyMsg:
+		// The seek, note, mute and search prompts take every key as text
+		if m.seeking {
+			return m.updateSeekKeys(msg), nil
+		}
+		if m.noting {
+			return m.updateNoteKeys(msg), nil
+		}
+		if m.muting {
+			return m.updateMuteKeys(msg), nil
+		}
+		if m.searching {
+			return m.updateSearchKeys(msg), nil
+		}
+		key := msg.String()
+		action, ok := m.pressKey(key)
+		if !ok {
+			return m, nil
+		}
+		if m.help {
+			return m.updateHelpKeys(key, action)
+		}
+		if m.explain {
+			if em, ok := m.updateExplainKeys(key, action); ok {
+				return em, nil
+			}
+		}
+		if m.viewMode == ProcessMode {
+			if pm, ok := m.updateProcessKeys(key, action); ok {
+				return pm, nil
+			}
+		}
+
+		// Switch between view modes, by name or along the tab bar
+		if mode, ok := viewNames[action]; ok {
+			m.viewMode = mode
+		}
+		switch action {
+
+		// Exit the program
+		case "quit":
+			m.quit = true
+			return m, tea.Quit
+
+		case "next_view":
+			m.viewMode = cycleView(m.viewMode, 1)
+		case "prev_view":
+			m.viewMode = cycleView(m.viewMode, -1)
+
+		// Clipboard
+		case "copy_panel":
+			m.copyPanel()
+
+		// Memory view controls
+		case "memory_accounting":
+			if m.viewMode == MemoryDetailMode {
+				collector.MemoryAccounting = (collector.MemoryAccounting + 1) % 2
+			}
+		case "memory_debug":
+			if m.viewMode == MemoryDetailMode {
+				m.memoryDebug = !m.memoryDebug
+			}
+
+		// Cycle through the color themes
+		case "theme":
+			t := nextTheme()
+			if m.theme != nil {
+				m.theme = &t
+			} else {
+				defaultTheme = t
+			}
+			applyTheme(t)
+			m.setStatus("Theme: " + currentTheme.Name)
+
+		// Hidden raw counter dump
+		case "debug":
+			m.debugView = !m.debugView
+			if m.debugView {
+				m.debug = collectDebugDump(m.stats)
+			}
+
+		// Split layout
+		case "split":
+			m.toggleSplit()
+		case "focus_pane":
+			m.focusOtherPane()
+		case "split_grow":
+			m.resizeSplit(splitStep)
+		case "split_shrink":
+			m.resizeSplit(-splitStep)
+
+		// Freeze the display and scroll back through recent samples
+		case "pause":
+			if m.replay {
+				m.togglePlaying()
+			} else {
+				m.togglePause()
+			}
+		case "older":
+			m.scrollBack(-1)
+		case "newer":
+			m.scrollBack(1)
+		case "seek":
+			m.startSeek()
+		case "note":
+			m.startNote()
+		case "mute":
+			m.startMute()
+
+		// Series drawn over the history chart
+		case "overlay":
+			m.toggleOverlay(0)
+		case "overlay_2":
+			m.toggleOverlay(1)
+
+		// Metrics of the correlation view
+		case "correlate":
+			m.cycleCorrelation(0)
+		case "correlate_2":
+			m.cycleCorrelation(1)
+
+		// Key binding overlay
+		case "help":
+			m.help = true
+
+		// Metric explanations
+		case "explain":
+			m.explain = true
+			m.explainFocus = 0
+
+		// Refresh rate controls, or playback speed while replaying
+		case "faster":
+			if m.replay {
+				m.changeReplaySpeed(true)
+			} else if m.refreshRate > minRefreshRate {
+				return m, m.setRefreshRate(m.refreshRate - 100*time.Millisecond)
+			}
+		case "slower":
+			if m.replay {
+				m.changeReplaySpeed(false)
+			} else if m.refreshRate < maxRefreshRate {
+				return m, m.setRefreshRate(m.refreshRate + 100*time.Millisecond)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// Width of the separator between the header, content and footer
+const viewRuleWidth = 78
+
+// viewBufferSize estimates the bytes in a frame so View can allocate once.
+// Most rows are far shorter than the terminal, which makes up for the
+// three-byte box drawing characters and escape codes in the rest.
+func viewBufferSize(width, height int) int {
+	return width * height
+}
+
+func (m model) View() string {
+	m.useSettings()
+	if m.quit {
+		return ""
+	}
+
+	if m.paused {
+		m = m.pausedFrame()
+	}
+	if m.compact() {
+		return m.finishFrame(m.renderCompact())
+	}
+
+	var b strings.Builder
+	b.Grow(viewBufferSize(m.width, m.height))
+
+	// Header with the tab bar
+	b.WriteString(tabBar(m.viewMode, m.width))
+	b.WriteString(m.remoteNote())
+	if m.noting {
+		fmt.Fprintf(&b, "%s\n", m.notePrompt())
+	} else if m.muting {
+		fmt.Fprintf(&b, "%s\n", m.mutePrompt())
+	} else if m.paused {
+		fmt.Fprintf(&b, "%s | Thermal: %s%s\n", m.pausedStatus(), renderThermal(m.stats.Thermal), renderAlerts(m.alerts(), m.sampledAt))
+	} else {
+		b.WriteString(m.renderStatus(headerTemplate))
+		b.WriteString("\n")
+	}
+	b.WriteString(renderedRule)
+	b.WriteString("\n\n")
+
+	if m.split {
+		b.WriteString(m.renderSplit())
+	} else {
+		b.WriteString(m.renderContent())
+	}
+
+	// Footer with controls and error display
+	b.WriteString("\n")
+	b.WriteString(renderedRule)
+	b.WriteString("\n")
+	if m.lastError != "" {
+		fmt.Fprintf(&b, "%s %s\n", glyphs.warn, m.lastError)
+	}
+	if m.statusMsg != "" && time.Since(m.statusAt) < statusDuration {
+		fmt.Fprintf(&b, "%s %s\n", glyphs.ok, m.statusMsg)
+	}
+	b.WriteString(m.renderStatus(footerTemplate))
+	b.WriteString("\n")
+
+	return m.finishFrame(b.String())
+}
+
+// finishFrame draws the help overlay over a frame and applies ASCII mode
+func (m model) finishFrame(frame string) string {
+	if m.help {
+		frame = overlay(frame, renderHelpBox(), m.width, m.height)
+	}
+	if asciiOutput {
+		frame = asciiFrame(frame)
+	}
+	return frame
+}
+
+// renderContent renders the body of the current view mode
+func (m model) renderContent() string {
+	if m.explain {
+		return m.renderExplain()
+	}
+	if m.debugView {
+		return "Raw counters (D to close)\n\n" + formatDebugDump(m.debug)
+	}
+
+	switch m.viewMode {
+	case OverviewMode:
+		return m.renderOverview()
+	case CPUDetailMode:
+		return m.renderCPUDetail()
+	case MemoryDetailMode:
+		return m.renderMemoryDetail()
+	case GPUDetailMode:
+		return m.renderGPUDetail()
+	case FlameMode:
+		return m.renderFlame()
+	case MemoryTreemapMode:
+		return m.renderMemoryTreemap()
+	case ProcessMode:
+		return m.renderProcesses()
+	case PowerMode:
+		return m.renderPowerDetail()
+	case NetworkMode:
+		return m.renderNetworkDetail()
+	case ErrorsMode:
+		return m.renderErrors()
+	case CompareMode:
+		return m.renderCompare()
+	case CorrelateMode:
+		return m.renderCorrelation()
+	}
+	return ""
+}
+
+func (m model) renderCPUDetail() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Overall CPU Usage: %s\n", m.highlightChange(fmt.Sprintf("%.1f%%", m.stats.CPU.Usage),
+		m.stats.CPU.Usage, m.prevStats.CPU.Usage, changeThreshold))
+	fmt.Fprintf(&b, "Temperature: %.1f°C\n\n", m.stats.CPU.Temp)
+
+	if len(m.stats.CPU.Clusters) > 0 {
+		b.WriteString("Clusters:\n")
+		for _, c := range m.stats.CPU.Clusters {
+			fmt.Fprintf(&b, "%-8s %5.0f MHz | %5.1f%% active\n", c.Name, c.Frequency, c.Active)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("Per-Core Usage:\n")
+	// The grid takes at most half the room left, the rest goes to the
+	// load average and the history chart
+	rows := max((m.height-viewChromeLines-strings.Count(b.String(), "\n")-3)/2, 4)
+	b.WriteString(m.renderCoreGrid(rows))
+
+	fmt.Fprintf(&b, "\nLoad Average: %.2f, %.2f, %.2f\n",
+		m.stats.CPU.LoadAvg[0], m.stats.CPU.LoadAvg[1], m.stats.CPU.LoadAvg[2])
+
+	height := m.chartHeight(b.String() + "\n")
+	b.WriteString("\n")
+	b.WriteString(m.renderSeriesChart("CPU Usage History", CPUDetailMode, height))
+
+	return b.String()
+}
+
+func (m model) renderMemoryDetail() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Memory Usage: %s (%.2f GB used / %.2f GB total)\n",
+		m.highlightChange(fmt.Sprintf("%.1f%%", m.stats.Memory.Usage),
+			m.stats.Memory.Usage, m.prevStats.Memory.Usage, changeThreshold),
+		float64(m.stats.Memory.Used)/(1024*1024*1024),
+		float64(m.stats.Memory.Total)/(1024*1024*1024))
+	fmt.Fprintf(&b, "Available: %.2f GB\n", float64(m.stats.Memory.Available)/(1024*1024*1024))
+	fmt.Fprintf(&b, "Pressure: %s\n\n", renderPressure(m.stats.Memory.Pressure))
+
+	fmt.Fprintf(&b, "Swap Usage: %.1f%% (%.2f GB used / %.2f GB total)\n",
+		m.stats.Memory.Swap.Usage,
+		float64(m.stats.Memory.Swap.Used)/(1024*1024*1024),
+		float64(m.stats.Memory.Swap.Total)/(1024*1024*1024))
+
+	fmt.Fprintf(&b, "\nAccounting: %s (a: switch)\n", m.stats.Memory.Accounting)
+	if n := len(m.stats.Memory.Warnings); n > 0 && !m.memoryDebug {
+		fmt.Fprintf(&b, "%s %d inconsistencies in the raw counters (d: details)\n", glyphs.warn, n)
+	}
+	if m.memoryDebug {
+		b.WriteString(m.renderMemoryDebug())
+	}
+
+	height := m.chartHeight(b.String() + "\n")
+	b.WriteString("\n")
+	b.WriteString(m.renderSeriesChart("Memory Usage History", MemoryDetailMode, height))
+
+	return b.String()
+}
+
+func (m model) renderGPUDetail() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "GPU Usage: %s\n", m.highlightChange(fmt.Sprintf("%.1f%%", m.stats.GPU.Usage),
+		m.stats.GPU.Usage, m.prevStats.GPU.Usage, changeThreshold))
+	fmt.Fprintf(&b, "Temperature: %.1f°C\n\n", m.stats.GPU.Temp)
+
+	fmt.Fprintf(&b, "GPU Memory Usage: %.1f%% (%.2f GB used / %.2f GB total)\n",
+		m.stats.GPU.MemoryUsage,
+		float64(m.stats.GPU.MemoryUsed)/(1024*1024*1024),
+		float64(m.stats.GPU.MemoryTotal)/(1024*1024*1024))
+
+	height := m.chartHeight(b.String() + "\n")
+	b.WriteString("\n")
+	b.WriteString(m.renderSeriesChart("GPU Usage History", GPUDetailMode, height))
+
+	return b.String()
+}
+
+// renderMemoryDebug lists the raw page counters and any inconsistencies
+// found while deriving used and available memory
+func (m model) renderMemoryDebug() string {
+	raw := m.stats.Memory.Raw
+	s := fmt.Sprintf("\nRaw counters (pages of %d bytes):\n", raw.PageSize)
+	for _, c := range []struct {
+		name  string
+		value uint64
+	}{
+		{"total", raw.TotalPages},
+		{"free", raw.Free},
+		{"active", raw.Active},
+		{"inactive", raw.Inactive},
+		{"wired", raw.Wired},
+		{"speculative", raw.Speculative},
+		{"compressed", raw.Compressed},
+		{"purgeable", raw.Purgeable},
+		{"external", raw.External},
+		{"internal", raw.Internal},
+	} {
+		s += fmt.Sprintf("  %-12s %10d  (%s)\n", c.name, c.value, formatBytes(c.value*raw.PageSize))
+	}
+
+	if len(m.stats.Memory.Warnings) == 0 {
+		s += "No inconsistencies found.\n"
+	}
+	for _, w := range m.stats.Memory.Warnings {
+		s += fmt.Sprintf("%s %s\n", glyphs.warn, w)
+	}
+	return s
+}
+
+func (m model) renderPowerDetail() string {
+	width := m.width - 22
+	pkg := m.history.Summary(metricPowerPackage)
+	peak := pkg.Max
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Package: %6.2f W  %s\n", m.stats.Power.Package,
+		widgets.Spark(m.history.Values(metricPowerPackage), width, peak))
+	fmt.Fprintf(&b, "CPU:     %6.2f W  %s\n", m.stats.Power.CPU,
+		widgets.Spark(m.history.Values(metricPowerCPU), width, peak))
+	fmt.Fprintf(&b, "GPU:     %6.2f W  %s\n", m.stats.Power.GPU,
+		widgets.Spark(m.history.Values(metricPowerGPU), width, peak))
+	fmt.Fprintf(&b, "ANE:     %6.2f W  %s\n", m.stats.Power.ANE,
+		widgets.Spark(m.history.Values(metricPowerANE), width, peak))
+	fmt.Fprintf(&b, "DRAM:    %6.2f W\n\n", m.stats.Power.DRAM)
+	fmt.Fprintf(&b, "Peak package power: %.2f W over the last %d samples\n", peak, pkg.Count)
+
+	return b.String()
+}
+
+func (m model) renderNetworkDetail() string {
+	s := "Wi-Fi:\n"
+
+	wifi := m.stats.Network.WiFi
+	switch {
+	case wifi == nil:
+		s += "  No Wi-Fi interface\n"
+	case !wifi.PowerOn:
+		s += fmt.Sprintf("  %s: Off\n", wifi.Interface)
+	default:
+		ssid := wifi.SSID
+		if ssid == "" {
+			ssid = "(hidden or location access required)"
+		}
+		s += fmt.Sprintf("  Interface: %s\n", wifi.Interface)
+		s += fmt.Sprintf("  SSID:      %s\n", ssid)
+		s += fmt.Sprintf("  Signal:    %d dBm | Noise: %d dBm | SNR: %d dB\n",
+			wifi.RSSI, wifi.Noise, wifi.RSSI-wifi.Noise)
+		s += fmt.Sprintf("  Channel:   %d (%s, %d MHz)\n", wifi.Channel, wifi.Band, wifi.ChannelWidth)
+		s += fmt.Sprintf("  PHY Mode:  %s | Tx Rate: %.0f Mbps\n", wifi.PHYMode, wifi.TxRate)
+	}
+
+	sockets := m.stats.Network.Sockets
+	s += fmt.Sprintf("\nSockets: %d TCP | %d UDP\n", sockets.TCPTotal, sockets.UDP)
+	for _, state := range stats.TCPStates {
+		if n := sockets.TCP[state]; n > 0 {
+			s += fmt.Sprintf("  %-13s %5d\n", state, n)
+		}
+	}
+
+	return s
+}
+
+// renderPressure colors the pressure level green, yellow or red
+func renderPressure(p MemoryPressure) string {
+	var color lipgloss.Color
+	switch p {
+	case PressureNormal:
+		color = currentTheme.Good
+	case PressureWarn:
+		color = currentTheme.Warn
+	case PressureCritical:
+		color = currentTheme.Bad
+	default:
+		return p.String()
+	}
+	return lipgloss.NewStyle().Foreground(color).Bold(true).Render(p.String())
+}
+
+// renderThermal colors the thermal state from green (nominal) to red (critical)
+func renderThermal(t ThermalState) string {
+	var color lipgloss.Color
+	switch t {
+	case ThermalNominal:
+		color = currentTheme.Good
+	case ThermalFair:
+		color = currentTheme.Warn
+	case ThermalSerious:
+		color = currentTheme.Severe
+	case ThermalCritical:
+		color = currentTheme.Bad
+	default:
+		return t.String()
+	}
+	return lipgloss.NewStyle().Foreground(color).Bold(true).Render(t.String())
+}
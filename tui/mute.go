@@ -0,0 +1,242 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Muting an alert stops its notifications and hook fire commands; it still
+// shows in the header, marked muted. An alert muted while its hooks have
+// fired keeps them fired, so clear still runs once it stops.
+
+// muteConfig holds the [mute] config section: daily quiet hours for every
+// alert, and for single alerts, e.g. "22:00-07:00" or "Sat,Sun 00:00-24:00"
+type muteConfig struct {
+	QuietHours []string            `toml:"quiet_hours"`
+	Alerts     map[string][]string `toml:"alerts"` // Quiet hours by alert name, e.g. cpu = ["01:00-03:00"]
+}
+
+// quietSpan is a span of quiet hours on some days of the week. A span
+// ending before it starts runs past midnight into the next day.
+type quietSpan struct {
+	days     [7]bool // By time.Weekday, of the day the span starts
+	from, to int     // Minutes since midnight; to may be 24*60
+}
+
+// Quiet hours of every alert and of single alerts, set from [mute]
+var (
+	quietHours      []quietSpan
+	alertQuietHours map[string][]quietSpan
+)
+
+// Alerts muted from the TUI, by name or "*" for all, until when
+var alertSnoozes = map[string]time.Time{}
+
+// How long the mute prompt mutes for when given no duration
+const defaultSnooze = time.Hour
+
+// applyMute checks and records the quiet hours from the config
+func applyMute(cfg muteConfig) error {
+	global, err := parseQuietSpans(cfg.QuietHours)
+	if err != nil {
+		return fmt.Errorf("quiet_hours: %v", err)
+	}
+	perAlert := make(map[string][]quietSpan, len(cfg.Alerts))
+	for name, specs := range cfg.Alerts {
+		if !knownAlert(name) {
+			return fmt.Errorf("unknown alert %q (want %s, a metric with \" normal\" or a history metric)",
+				name, strings.Join(sortedKeys(alertMetrics), ", "))
+		}
+		if perAlert[name], err = parseQuietSpans(specs); err != nil {
+			return fmt.Errorf("alerts.%s: %v", name, err)
+		}
+	}
+	quietHours, alertQuietHours = global, perAlert
+	return nil
+}
+
+func parseQuietSpans(specs []string) ([]quietSpan, error) {
+	spans := make([]quietSpan, 0, len(specs))
+	for _, spec := range specs {
+		span, err := parseQuietSpan(spec)
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, span)
+	}
+	return spans, nil
+}
+
+// parseQuietSpan reads "[DAYS ]HH:MM-HH:MM", where DAYS is a comma-separated
+// list of days and ranges of days such as "Mon-Fri,Sun"; without DAYS the
+// span applies every day
+func parseQuietSpan(spec string) (quietSpan, error) {
+	var span quietSpan
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 1:
+		span.days = [7]bool{true, true, true, true, true, true, true}
+	case 2:
+		days, err := parseWeekdays(fields[0])
+		if err != nil {
+			return span, fmt.Errorf("%q: %v", spec, err)
+		}
+		span.days = days
+		fields = fields[1:]
+	default:
+		return span, fmt.Errorf("%q is not [DAYS ]HH:MM-HH:MM", spec)
+	}
+	from, to, ok := strings.Cut(fields[0], "-")
+	if !ok {
+		return span, fmt.Errorf("%q is not [DAYS ]HH:MM-HH:MM", spec)
+	}
+	var err error
+	if span.from, err = parseClock(from, false); err == nil {
+		span.to, err = parseClock(to, true)
+	}
+	if err != nil {
+		return span, fmt.Errorf("%q: %v", spec, err)
+	}
+	if span.from == span.to {
+		return span, fmt.Errorf("%q is empty", spec)
+	}
+	return span, nil
+}
+
+// parseClock reads HH:MM as minutes since midnight; 24:00 only as an end
+func parseClock(s string, end bool) (int, error) {
+	h, m, ok := strings.Cut(s, ":")
+	hour, herr := strconv.Atoi(h)
+	minute, merr := strconv.Atoi(m)
+	switch {
+	case !ok || herr != nil || merr != nil || len(m) != 2 || minute < 0 || minute > 59 || hour < 0:
+		return 0, fmt.Errorf("%q is not a time as HH:MM", s)
+	case hour == 24 && minute == 0 && end:
+		return 24 * 60, nil
+	case hour > 23:
+		return 0, fmt.Errorf("%q is past the end of the day", s)
+	}
+	return hour*60 + minute, nil
+}
+
+// Day names for quiet hours, as Go abbreviates them
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseWeekdays reads days such as "Mon-Fri,Sun"; a range may wrap past
+// Saturday, as in "Fri-Mon"
+func parseWeekdays(s string) ([7]bool, error) {
+	var days [7]bool
+	for _, part := range strings.Split(s, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		from, ok := weekdayNames[strings.ToLower(first)]
+		to := from
+		if isRange {
+			var ok2 bool
+			to, ok2 = weekdayNames[strings.ToLower(last)]
+			ok = ok && ok2
+		}
+		if !ok {
+			return days, fmt.Errorf("unknown days %q (want Mon, Tue, Wed, Thu, Fri, Sat or Sun, or a range such as Mon-Fri)", part)
+		}
+		for d := from; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == to {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// contains reports whether t, in local time, falls in the span
+func (q quietSpan) contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	today, yesterday := t.Weekday(), (t.Weekday()+6)%7
+	if q.from < q.to {
+		return q.days[today] && minute >= q.from && minute < q.to
+	}
+	return (q.days[today] && minute >= q.from) || (q.days[yesterday] && minute < q.to)
+}
+
+// alertMuted reports whether an alert is muted at now, by a snooze from
+// the TUI, quiet hours or a maintenance window
+func alertMuted(name string, now time.Time) bool {
+	if now.Before(alertSnoozes["*"]) || now.Before(alertSnoozes[name]) || inMaintenance(name, now) {
+		return true
+	}
+	for _, spans := range [][]quietSpan{quietHours, alertQuietHours[name]} {
+		for _, span := range spans {
+			if span.contains(now) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// startMute opens the mute prompt
+func (m *model) startMute() {
+	m.muting, m.mute = true, ""
+}
+
+// updateMuteKeys edits the mute prompt; enter applies it, esc cancels
+func (m model) updateMuteKeys(msg tea.KeyMsg) model {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.muting = false
+		m.setStatus(snooze(m.mute, m.sampledAt))
+	case tea.KeyEsc:
+		m.muting = false
+	case tea.KeyBackspace:
+		if r := []rune(m.mute); len(r) > 0 {
+			m.mute = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.mute += string(msg.Runes)
+	}
+	return m
+}
+
+// mutePrompt replaces the header status line while typing a mute
+func (m model) mutePrompt() string {
+	return fmt.Sprintf("Mute: %s%s | e.g. cpu 30m, 2h for all, off | enter: Apply | esc: Cancel", m.mute, glyphs.cursor)
+}
+
+// snooze applies a mute prompt entry at now: "[ALERT ][DURATION|off]".
+// Without an alert it applies to all; without a duration it mutes for
+// defaultSnooze. It returns a status line saying what changed.
+func snooze(entry string, now time.Time) string {
+	fields := strings.Fields(entry)
+	if len(fields) == 0 {
+		return "Nothing muted"
+	}
+	d, off := defaultSnooze, false
+	if last := fields[len(fields)-1]; last == "off" {
+		off, fields = true, fields[:len(fields)-1]
+	} else if parsed, err := time.ParseDuration(last); err == nil && parsed > 0 {
+		d, fields = parsed, fields[:len(fields)-1]
+	}
+	name := strings.Join(fields, " ")
+	switch {
+	case name == "" && off:
+		clear(alertSnoozes)
+		return "Unmuted all alerts"
+	case name == "":
+		alertSnoozes["*"] = now.Add(d)
+		return "Muted all alerts until " + now.Add(d).Format("15:04")
+	case !knownAlert(name):
+		return fmt.Sprintf("Unknown alert %q", name)
+	case off:
+		delete(alertSnoozes, name)
+		return "Unmuted " + name
+	}
+	alertSnoozes[name] = now.Add(d)
+	return fmt.Sprintf("Muted %s until %s", name, now.Add(d).Format("15:04"))
+}
@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseQuietSpan(t *testing.T) {
+	for _, bad := range []string{"", "22:00", "22:00-22:00", "25:00-07:00", "22:00-7", "24:00-07:00", "Someday 22:00-07:00", "Mon 22:00-07:00 x"} {
+		if _, err := parseQuietSpan(bad); err == nil {
+			t.Errorf("parseQuietSpan(%q): no error", bad)
+		}
+	}
+
+	// Sunday 2026-10-18 in local time
+	day := func(d, h, m int) time.Time { return time.Date(2026, 10, 18+d, h, m, 0, 0, time.Local) }
+	tests := []struct {
+		spec string
+		at   time.Time
+		want bool
+	}{
+		{"22:00-07:00", day(0, 23, 0), true},
+		{"22:00-07:00", day(0, 6, 59), true},
+		{"22:00-07:00", day(0, 7, 0), false},
+		{"09:00-17:00", day(0, 12, 0), true},
+		{"Mon-Fri 22:00-07:00", day(0, 23, 0), false}, // Sunday night
+		{"Mon-Fri 22:00-07:00", day(1, 6, 0), false},  // Started on Sunday
+		{"Mon-Fri 22:00-07:00", day(6, 6, 0), true},   // Saturday, started Friday
+		{"Sat,Sun 00:00-24:00", day(0, 23, 59), true},
+		{"Fri-Mon 00:00-24:00", day(1, 12, 0), true},
+		{"Fri-Mon 00:00-24:00", day(2, 12, 0), false},
+	}
+	for _, tt := range tests {
+		span, err := parseQuietSpan(tt.spec)
+		if err != nil {
+			t.Fatalf("parseQuietSpan(%q): %v", tt.spec, err)
+		}
+		if got := span.contains(tt.at); got != tt.want {
+			t.Errorf("%q contains %s = %v, want %v", tt.spec, tt.at.Format("Mon 15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestMuteHoldsBackNotifications(t *testing.T) {
+	defer func(th map[string]float64, after time.Duration, q []quietSpan, aq map[string][]quietSpan, s map[string]time.Time) {
+		alertThresholds, notifyAfter, quietHours, alertQuietHours, alertSnoozes = th, after, q, aq, s
+	}(alertThresholds, notifyAfter, quietHours, alertQuietHours, alertSnoozes)
+	alertThresholds = map[string]float64{"cpu": 30}
+	notifyAfter, quietHours, alertSnoozes = time.Second, nil, map[string]time.Time{}
+
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+	active := activeAlerts(fixtureStats())
+	n := newNotifier()
+	n.due(active, start)
+
+	if got := snooze("cpu 30m", start); got != "Muted cpu until 12:30" {
+		t.Errorf("snooze status = %q", got)
+	}
+	if got := snooze("fans 1h", start); got != `Unknown alert "fans"` {
+		t.Errorf("snooze of an unknown alert = %q", got)
+	}
+	if due := n.due(active, start.Add(time.Minute)); len(due) != 0 {
+		t.Errorf("muted alert notified: %v", due)
+	}
+	if header := renderAlerts(active, start.Add(time.Minute)); !strings.Contains(header, "cpu 37.5% > 30% (muted)") {
+		t.Errorf("header %q does not mark cpu muted", header)
+	}
+	if due := n.due(active, start.Add(31*time.Minute)); len(due) != 1 {
+		t.Errorf("alert not notified once the mute ended: %v", due)
+	}
+
+	snooze("2h", start)
+	if !alertMuted("memory", start.Add(time.Hour)) {
+		t.Error("muting all did not mute memory")
+	}
+	snooze("off", start)
+	if alertMuted("memory", start.Add(time.Hour)) {
+		t.Error("memory still muted after off")
+	}
+
+	if err := applyMute(muteConfig{Alerts: map[string][]string{"cpu": {"11:00-13:00"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if !alertMuted("cpu", start) || alertMuted("memory", start) {
+		t.Error("quiet hours of cpu should mute cpu alone")
+	}
+	if err := applyMute(muteConfig{Alerts: map[string][]string{"fans": {"11:00-13:00"}}}); err == nil {
+		t.Error("applyMute with an unknown alert: no error")
+	}
+}
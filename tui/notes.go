@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/khoi/mtop/history"
+)
+
+// Longest note kept, in runes
+const maxNoteLength = 120
+
+// Notes such as "started the heavy build here" are history marks like the
+// ones mtop adds itself. They are saved with the history, written to the
+// history database and the log file, and shown on the history charts.
+
+// startNote opens the note prompt
+func (m *model) startNote() {
+	m.noting, m.note = true, ""
+}
+
+// updateNoteKeys edits the note prompt; enter adds the note, esc cancels
+func (m model) updateNoteKeys(msg tea.KeyMsg) model {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.noting = false
+		m.addNote(m.noteTime(), m.note)
+	case tea.KeyEsc:
+		m.noting = false
+	case tea.KeyBackspace:
+		if r := []rune(m.note); len(r) > 0 {
+			m.note = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		if len([]rune(m.note)) < maxNoteLength {
+			m.note += string(msg.Runes)
+		}
+	}
+	return m
+}
+
+// noteTime is when a note applies: the viewed sample while paused, so past
+// events can be tagged after the fact, and the newest sample otherwise
+func (m model) noteTime() time.Time {
+	if m.paused {
+		return m.scrollback[len(m.scrollback)-1-m.pauseOffset].at
+	}
+	return m.sampledAt
+}
+
+// addNote marks the history at t with text and records it in the history
+// database, if one is open. Blank notes are dropped.
+func (m *model) addNote(t time.Time, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	if m.recordMark(t, text) {
+		m.setStatus("Noted at " + t.Format("15:04:05"))
+	}
+}
+
+// recordMark marks the history at t and records the mark as a note in the
+// history database and the log file, if open. It reports failures in the
+// status line and returns whether the mark was recorded everywhere.
+func (m *model) recordMark(t time.Time, text string) bool {
+	m.history.Annotate(t, text)
+	if m.historyDB != nil {
+		if err := m.historyDB.addNote(t, text); err != nil {
+			m.setStatus(fmt.Sprintf("Failed to record the note: %v", err))
+			return false
+		}
+	}
+	if m.sampleLog != nil {
+		if err := m.sampleLog.logNote(t, text); err != nil {
+			m.setStatus(fmt.Sprintf("Failed to log the note: %v", err))
+			return false
+		}
+	}
+	return true
+}
+
+// notePrompt replaces the header status line while typing a note
+func (m model) notePrompt() string {
+	return fmt.Sprintf("Note: %s%s | enter: Add | esc: Cancel", m.note, glyphs.cursor)
+}
+
+// noteMarks places a marker, by cell, at each mark of a chart width cells
+// wide, over the first sample taken at or after it. The chart holds its
+// newest samples on the right edge, two per cell, as widgets.Chart lays
+// them out.
+func noteMarks(width int, samples []history.Sample, marks []history.Mark) map[int]string {
+	at := make(map[int]string)
+	for _, mark := range marks {
+		i := sort.Search(len(samples), func(i int) bool { return !samples[i].Time.Before(mark.Time) })
+		if i == len(samples) {
+			continue
+		}
+		if x := width*2 - len(samples) + i; x >= 0 {
+			at[x/2] = titleStyle.Render(glyphs.mark)
+		}
+	}
+	return at
+}
@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNotePrompt(t *testing.T) {
+	m := testReplay(t)
+	m.startNote()
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("heavy")},
+		{Type: tea.KeySpace, Runes: []rune(" ")},
+		{Type: tea.KeyRunes, Runes: []rune("buildx")},
+		{Type: tea.KeyBackspace},
+		{Type: tea.KeyEnter},
+	} {
+		m = m.updateNoteKeys(key)
+	}
+	// Paused on the first sample, so the note is made there
+	marks := m.history.Marks()
+	if m.noting || len(marks) != 1 || marks[0].Text != "heavy build" || !marks[0].Time.Equal(m.scrollback[0].at) {
+		t.Fatalf("noting = %v, marks = %v", m.noting, marks)
+	}
+
+	samples := m.history.Samples(metricCPU)
+	chart := m.renderHistoryChart("CPU Usage History", samples, 4)
+	if top := strings.Split(chart, "\n")[1]; !strings.Contains(top, glyphs.mark) {
+		t.Errorf("no note marker on the top row:\n%s", chart)
+	}
+
+	m.startNote()
+	m = m.updateNoteKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	if len(m.history.Marks()) != 1 {
+		t.Errorf("blank note added: %v", m.history.Marks())
+	}
+}
+
+func TestNotesInRecordings(t *testing.T) {
+	input := replayInput + `{"time": "2026-10-17T10:00:01Z", "note": "started the build"}` + "\n"
+	shots, notes, err := loadReplay(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shots) != 3 || len(notes) != 1 || notes[0].Text != "started the build" {
+		t.Fatalf("loaded %d samples and notes %v", len(shots), notes)
+	}
+	if marks := newReplayModel(shots, notes).history.Marks(); len(marks) != 1 {
+		t.Errorf("replay marks = %v", marks)
+	}
+
+	store := newHistory()
+	if n, err := importSamples(store, nil, strings.NewReader(input), "session.mtop"); err != nil || n != 4 {
+		t.Fatalf("imported %d records: %v", n, err)
+	}
+	if marks := store.Marks(); len(marks) != 2 || marks[1].Text != "started the build" {
+		t.Errorf("imported marks = %v", marks)
+	}
+}
+
+func TestHistoryDBNotes(t *testing.T) {
+	h, err := openHistoryDB(filepath.Join(t.TempDir(), "history.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.close()
+	now := time.Now().Truncate(time.Millisecond)
+	h.record(now, map[string]float64{metricCPU: 5})
+	h.record(now.Add(2*time.Second), map[string]float64{metricCPU: 6})
+	if err := h.addNote(now.Add(time.Second), "heavy build"); err != nil {
+		t.Fatal(err)
+	}
+	rows, _ := h.query(now, nil)
+	notes, err := h.notes(now)
+	if err != nil || len(notes) != 1 {
+		t.Fatalf("notes = %v, %v", notes, err)
+	}
+
+	var b strings.Builder
+	writeHistoryRows(&b, rows, notes, "text")
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[1], "note                 heavy build") {
+		t.Errorf("text rows:\n%s", b.String())
+	}
+}
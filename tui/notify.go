@@ -0,0 +1,134 @@
+package tui
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// How long a metric must stay above its alert threshold before a
+// notification is posted, and the least time between two notifications
+// for the same metric; set from the [notify] config section. Notifications
+// are off while notifyAfter is 0.
+var (
+	notifyAfter    time.Duration
+	notifyCooldown = 10 * time.Minute
+)
+
+// The channels alerts notify unless routed elsewhere, and the routes by
+// alert name; set from the [notify] config section
+var (
+	notifyDefaultChannels = []string{desktopChannel}
+	notifyRoutes          map[string][]string
+)
+
+// notifyConfig holds the [notify] config section
+type notifyConfig struct {
+	After    time.Duration       `toml:"after"`    // e.g. "30s"
+	Cooldown time.Duration       `toml:"cooldown"` // e.g. "10m"
+	Channels []string            `toml:"channels"` // Channel names; default ["desktop"]
+	Route    map[string][]string `toml:"route"`    // Channel names by alert name, e.g. cpu = ["oncall"]
+}
+
+// applyNotify checks and records the notification settings from the
+// config; the channels it names must have been applied
+func applyNotify(cfg notifyConfig) error {
+	if cfg.After < 0 || cfg.Cooldown < 0 {
+		return fmt.Errorf("notify durations must not be negative")
+	}
+	for alert := range cfg.Route {
+		if !knownAlert(alert) {
+			return fmt.Errorf("route: unknown alert %q (want %s, a metric with \" normal\" or a history metric)",
+				alert, strings.Join(sortedKeys(alertMetrics), ", "))
+		}
+	}
+	if err := checkRoutes(cfg.Channels, cfg.Route); err != nil {
+		return err
+	}
+	notifyAfter = cfg.After
+	if cfg.Cooldown != 0 {
+		notifyCooldown = cfg.Cooldown
+	}
+	notifyDefaultChannels = []string{desktopChannel}
+	if cfg.Channels != nil {
+		notifyDefaultChannels = cfg.Channels
+	}
+	notifyRoutes = cfg.Route
+	return nil
+}
+
+// notifier tracks how long each metric has been alerting and when it was
+// last notified
+type notifier struct {
+	since map[string]time.Time // When the metric started alerting
+	sent  map[string]time.Time // When its last notification was posted
+}
+
+func newNotifier() *notifier {
+	return &notifier{since: make(map[string]time.Time), sent: make(map[string]time.Time)}
+}
+
+// due returns the alerts to notify about at now, given the active alerts
+// by name: those that have been active for notifyAfter, are not muted and
+// were not notified within the cooldown. An alert that stops starts over.
+func (n *notifier) due(active map[string]string, now time.Time) []alertNotice {
+	if notifyAfter <= 0 {
+		return nil
+	}
+	for name := range n.since {
+		if _, ok := active[name]; !ok {
+			delete(n.since, name)
+		}
+	}
+	var alerts []alertNotice
+	for _, name := range sortedKeys(active) {
+		since, ok := n.since[name]
+		if !ok {
+			n.since[name] = now
+			since = now
+		}
+		if now.Sub(since) < notifyAfter || alertMuted(name, now) {
+			continue
+		}
+		if sent, ok := n.sent[name]; ok && now.Sub(sent) < notifyCooldown {
+			continue
+		}
+		n.sent[name] = now
+		alerts = append(alerts, alertNotice{name, active[name]})
+	}
+	return alerts
+}
+
+// notifyCmd sends a notification per alert to its channels without
+// blocking the UI
+func notifyCmd(alerts []alertNotice) tea.Cmd {
+	if len(alerts) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		if err := sendNotices(alerts); err != nil {
+			return notifyFailedMsg{err}
+		}
+		return nil
+	}
+}
+
+// notifyFailedMsg reports a notification that could not be posted
+type notifyFailedMsg struct{ err error }
+
+// postNotification shows a macOS user notification through osascript. The
+// text is passed as arguments so it needs no AppleScript quoting.
+func postNotification(title, text string) error {
+	cmd := exec.Command("osascript",
+		"-e", "on run argv",
+		"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+		"-e", "end run",
+		title, text)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("osascript failed: %w: %s", err, out)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNotifierSustainAndCooldown(t *testing.T) {
+	defer func(thresholds map[string]float64, after, cooldown time.Duration) {
+		alertThresholds, notifyAfter, notifyCooldown = thresholds, after, cooldown
+	}(alertThresholds, notifyAfter, notifyCooldown)
+	alertThresholds = map[string]float64{"cpu": 30}
+	notifyAfter, notifyCooldown = 10*time.Second, time.Minute
+
+	hot := fixtureStats()
+	cool := hot
+	cool.CPU.Usage = 5
+	start := time.Unix(1000, 0)
+	at := func(sec int) time.Time { return start.Add(time.Duration(sec) * time.Second) }
+
+	n := newNotifier()
+	steps := []struct {
+		sec   int
+		stats SystemStats
+		want  []alertNotice
+	}{
+		{0, hot, nil},
+		{5, hot, nil},
+		{6, cool, nil}, // Dropping below the threshold starts over
+		{7, hot, nil},
+		{17, hot, []alertNotice{{"cpu", "cpu 37.5% > 30%"}}},
+		{30, hot, nil}, // Within the cooldown
+		{77, hot, []alertNotice{{"cpu", "cpu 37.5% > 30%"}}},
+	}
+	for _, s := range steps {
+		if got := n.due(activeAlerts(s.stats), at(s.sec)); !reflect.DeepEqual(got, s.want) {
+			t.Errorf("at %ds: due = %q, want %q", s.sec, got, s.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/khoi/mtop/stats"
+)
+
+// revealSelectedInFinder selects the selected process's executable in a
+// Finder window
+func (m *model) revealSelectedInFinder() {
+	p, ok := m.selectedProcess()
+	if !ok || m.remoteProcess(p) {
+		return
+	}
+	if p.Path == "" {
+		m.setStatus(fmt.Sprintf("Executable path of %s (%d) is not accessible", p.Name, p.PID))
+		return
+	}
+	if err := exec.Command("open", "-R", p.Path).Run(); err != nil {
+		m.setStatus(fmt.Sprintf("Failed to reveal %s: %v", p.Path, err))
+		return
+	}
+	m.setStatus(fmt.Sprintf("Revealed %s in Finder", p.Path))
+}
+
+// openSelectedInTerminal opens a new Terminal window in the selected
+// process's working directory
+func (m *model) openSelectedInTerminal() {
+	p, ok := m.selectedProcess()
+	if !ok || m.remoteProcess(p) {
+		return
+	}
+	cwd, err := stats.ProcessCwd(p.PID)
+	if err != nil || cwd == "" {
+		m.setStatus(fmt.Sprintf("Working directory of %s (%d) is not accessible", p.Name, p.PID))
+		return
+	}
+	if err := exec.Command("open", "-a", "Terminal", cwd).Run(); err != nil {
+		m.setStatus(fmt.Sprintf("Failed to open Terminal in %s: %v", cwd, err))
+		return
+	}
+	m.setStatus(fmt.Sprintf("Opened Terminal in %s", cwd))
+}
+
+// remoteProcess reports, in the footer, that p runs on the Mac --remote
+// shows rather than this one, where Finder and Terminal would open
+func (m *model) remoteProcess(p ProcessStats) bool {
+	if m.remote == nil {
+		return false
+	}
+	m.setStatus(fmt.Sprintf("%s (%d) runs on %s, not this Mac", p.Name, p.PID, m.remote.hostname()))
+	return true
+}
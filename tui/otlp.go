@@ -0,0 +1,321 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// OTLP/HTTP with the JSON encoding, which needs no protobuf or OpenTelemetry
+// SDK. Metric names follow the semantic conventions (and the collector's
+// hostmetrics receiver) where one fits and use mtop.* otherwise.
+
+// Aggregation temporality of cumulative sums
+const otlpCumulative = 2
+
+// Only the OTLP messages mtop sends, with the proto3 JSON field names;
+// 64-bit integers are encoded as strings
+type otlpRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpMetric struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Unit        string    `json:"unit"`
+	Gauge       *otlpData `json:"gauge,omitempty"`
+	Sum         *otlpData `json:"sum,omitempty"`
+}
+
+type otlpData struct {
+	DataPoints             []otlpPoint `json:"dataPoints"`
+	AggregationTemporality int         `json:"aggregationTemporality,omitempty"`
+	IsMonotonic            bool        `json:"isMonotonic,omitempty"`
+}
+
+type otlpPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano int64          `json:"startTimeUnixNano,omitempty,string"`
+	TimeUnixNano      int64          `json:"timeUnixNano,string"`
+	AsDouble          float64        `json:"asDouble"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+}
+
+func otlpString(key, value string) otlpKeyValue {
+	return otlpKeyValue{key, otlpAnyValue{StringValue: &value}}
+}
+
+func otlpInt(key string, value int) otlpKeyValue {
+	s := strconv.Itoa(value)
+	return otlpKeyValue{key, otlpAnyValue{IntValue: &s}}
+}
+
+// otlpBuilder collects the data points of one sample, grouping points of
+// the same metric in the order the metrics are first added
+type otlpBuilder struct {
+	at      int64 // Sample time in Unix nanoseconds
+	boot    int64 // Start of cumulative sums counted since boot
+	metrics []otlpMetric
+}
+
+func (b *otlpBuilder) add(name, unit, help string, kind func(*otlpMetric) *otlpData, p otlpPoint) {
+	p.TimeUnixNano = b.at
+	for i := range b.metrics {
+		if b.metrics[i].Name == name {
+			data := kind(&b.metrics[i])
+			data.DataPoints = append(data.DataPoints, p)
+			return
+		}
+	}
+	m := otlpMetric{Name: name, Description: help, Unit: unit}
+	data := kind(&m)
+	data.DataPoints = append(data.DataPoints, p)
+	b.metrics = append(b.metrics, m)
+}
+
+// gauge adds a reading of a level, such as a utilization
+func (b *otlpBuilder) gauge(name, unit, help string, v float64, attrs ...otlpKeyValue) {
+	b.add(name, unit, help, func(m *otlpMetric) *otlpData {
+		if m.Gauge == nil {
+			m.Gauge = &otlpData{}
+		}
+		return m.Gauge
+	}, otlpPoint{Attributes: attrs, AsDouble: v})
+}
+
+// upDown adds a non-monotonic cumulative sum, the instrument for amounts
+// whose parts add up, such as memory by state
+func (b *otlpBuilder) upDown(name, unit, help string, v float64, attrs ...otlpKeyValue) {
+	b.add(name, unit, help, func(m *otlpMetric) *otlpData {
+		if m.Sum == nil {
+			m.Sum = &otlpData{AggregationTemporality: otlpCumulative}
+		}
+		return m.Sum
+	}, otlpPoint{Attributes: attrs, AsDouble: v})
+}
+
+// counter adds a monotonic sum counted since boot
+func (b *otlpBuilder) counter(name, unit, help string, v float64, attrs ...otlpKeyValue) {
+	b.add(name, unit, help, func(m *otlpMetric) *otlpData {
+		if m.Sum == nil {
+			m.Sum = &otlpData{AggregationTemporality: otlpCumulative, IsMonotonic: true}
+		}
+		return m.Sum
+	}, otlpPoint{Attributes: attrs, StartTimeUnixNano: b.boot, AsDouble: v})
+}
+
+// buildOTLPRequest converts a sample into an export request for the CPU,
+// memory, GPU, power, disk, network and process readings, with host.name
+// and os.type resource attributes. Percentages become ratios.
+func buildOTLPRequest(at time.Time, stats SystemStats) otlpRequest {
+	b := &otlpBuilder{at: at.UnixNano(), boot: at.Add(-stats.Uptime).UnixNano()}
+
+	for i, usage := range stats.CPU.Cores {
+		b.gauge("system.cpu.utilization", "1", "Usage of each CPU core.", usage/100, otlpInt("cpu.logical_number", i))
+	}
+	b.gauge("mtop.cpu.utilization", "1", "CPU usage across all cores.", stats.CPU.Usage/100)
+	for i, period := range []string{"1m", "5m", "15m"} {
+		b.gauge("system.cpu.load_average."+period, "{thread}", "Load average over "+period+".", stats.CPU.LoadAvg[i])
+	}
+	b.gauge("mtop.cpu.temperature", "Cel", "CPU temperature.", stats.CPU.Temp)
+	b.gauge("mtop.thermal.state", "1", "Thermal state: 0 nominal, 1 fair, 2 serious, 3 critical.", float64(stats.Thermal))
+	b.gauge("system.uptime", "s", "Time since boot.", stats.Uptime.Seconds())
+
+	free := float64(stats.Memory.Total) - float64(stats.Memory.Used)
+	b.upDown("system.memory.usage", "By", "Memory by state.", float64(stats.Memory.Used), otlpString("system.memory.state", "used"))
+	b.upDown("system.memory.usage", "By", "Memory by state.", max(free, 0), otlpString("system.memory.state", "free"))
+	b.gauge("system.memory.utilization", "1", "Memory in use as a share of physical memory.", stats.Memory.Usage/100, otlpString("system.memory.state", "used"))
+	b.gauge("mtop.memory.pressure", "1", "Kernel memory pressure level: 1 normal, 2 warn, 4 critical.", float64(stats.Memory.Pressure))
+	swap := stats.Memory.Swap
+	b.upDown("system.paging.usage", "By", "Swap by state.", float64(swap.Used), otlpString("system.paging.state", "used"))
+	b.upDown("system.paging.usage", "By", "Swap by state.", float64(swap.Total-min(swap.Used, swap.Total)), otlpString("system.paging.state", "free"))
+
+	b.gauge("mtop.gpu.utilization", "1", "GPU usage.", stats.GPU.Usage/100)
+	b.upDown("mtop.gpu.memory.usage", "By", "GPU memory in use.", float64(stats.GPU.MemoryUsed))
+	b.gauge("mtop.gpu.temperature", "Cel", "GPU temperature.", stats.GPU.Temp)
+
+	for _, d := range []struct {
+		name  string
+		watts float64
+	}{
+		{"cpu", stats.Power.CPU}, {"gpu", stats.Power.GPU}, {"ane", stats.Power.ANE},
+		{"dram", stats.Power.DRAM}, {"package", stats.Power.Package},
+	} {
+		b.gauge("mtop.power", "W", "Power draw by domain.", d.watts, otlpString("mtop.power.domain", d.name))
+	}
+
+	b.counter("system.disk.io_time", "s", "Time the disks spent on reads and writes.", stats.Disk.IOTime.Seconds())
+	b.gauge("mtop.disk.utilization", "1", "Share of the last interval the disks spent on I/O.", stats.Disk.Busy/100)
+	for _, d := range stats.Disk.Devices {
+		b.counter("system.disk.io", "By", "Bytes each disk has read and written.", float64(d.Read),
+			otlpString("system.device", d.Name), otlpString("disk.io.direction", "read"))
+		b.counter("system.disk.io", "By", "Bytes each disk has read and written.", float64(d.Written),
+			otlpString("system.device", d.Name), otlpString("disk.io.direction", "write"))
+	}
+
+	sockets := stats.Network.Sockets
+	for _, state := range sortedKeys(sockets.TCP) {
+		b.upDown("system.network.connections", "{connection}", "Open sockets.", float64(sockets.TCP[state]),
+			otlpString("network.transport", "tcp"), otlpString("system.network.state", strings.ToLower(state)))
+	}
+	b.upDown("system.network.connections", "{connection}", "Open sockets.", float64(sockets.UDP), otlpString("network.transport", "udp"))
+	for _, i := range stats.Network.Interfaces {
+		b.counter("system.network.io", "By", "Bytes each network interface has received and sent.", float64(i.Received),
+			otlpString("network.interface.name", i.Name), otlpString("network.io.direction", "receive"))
+		b.counter("system.network.io", "By", "Bytes each network interface has received and sent.", float64(i.Sent),
+			otlpString("network.interface.name", i.Name), otlpString("network.io.direction", "transmit"))
+	}
+	if wifi := stats.Network.WiFi; wifi != nil && wifi.PowerOn {
+		b.gauge("mtop.wifi.signal_strength", "dBm", "Wi-Fi signal strength.", float64(wifi.RSSI),
+			otlpString("network.interface.name", wifi.Interface))
+	}
+	for _, proc := range stats.Processes {
+		b.gauge("process.cpu.utilization", "1", "CPU usage of the exported processes.", proc.CPU/100,
+			otlpInt("process.pid", proc.PID), otlpString("process.executable.name", proc.Name))
+		b.upDown("process.memory.usage", "By", "Resident memory of the exported processes.", float64(proc.RSS),
+			otlpInt("process.pid", proc.PID), otlpString("process.executable.name", proc.Name))
+	}
+
+	return otlpRequest{ResourceMetrics: []otlpResourceMetrics{{
+		Resource: otlpResource{Attributes: []otlpKeyValue{
+			otlpString("service.name", "mtop"),
+			otlpString("host.name", hostname),
+			otlpString("os.type", "darwin"),
+		}},
+		ScopeMetrics: []otlpScopeMetrics{{Scope: otlpScope{Name: "mtop"}, Metrics: b.metrics}},
+	}}}
+}
+
+// otlpMetricsURL appends the OTLP/HTTP metrics path to an endpoint given
+// as a base URL, such as the collector's http://localhost:4318
+func otlpMetricsURL(endpoint string) string {
+	if strings.HasSuffix(endpoint, "/v1/metrics") {
+		return endpoint
+	}
+	return strings.TrimSuffix(endpoint, "/") + "/v1/metrics"
+}
+
+// otlpHeaders parses $OTEL_EXPORTER_OTLP_HEADERS: comma-separated
+// key=value pairs, e.g. an API key for a hosted collector
+func otlpHeaders(spec string) (http.Header, error) {
+	headers := make(http.Header)
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("%q is not key=value", pair)
+		}
+		headers.Set(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+	return headers, nil
+}
+
+// otlpExporter pushes samples to an OTLP/HTTP endpoint. Like the InfluxDB
+// writer it reports and drops failed exports, so a collector restart does
+// not stop mtop.
+type otlpExporter struct {
+	url     string
+	headers http.Header
+	client  *http.Client
+	errs    io.Writer
+}
+
+func newOTLPExporter(endpoint string) (*otlpExporter, error) {
+	headers, err := otlpHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	if err != nil {
+		return nil, fmt.Errorf("OTEL_EXPORTER_OTLP_HEADERS: %v", err)
+	}
+	return &otlpExporter{
+		url:     otlpMetricsURL(endpoint),
+		headers: headers,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		errs:    os.Stderr,
+	}, nil
+}
+
+func (e *otlpExporter) record(at time.Time, stats SystemStats) {
+	if err := e.export(buildOTLPRequest(at, exportProcesses(at, stats))); err != nil {
+		fmt.Fprintf(e.errs, "Warning: OTLP export failed: %v\n", err)
+	}
+}
+
+func (e *otlpExporter) export(r otlpRequest) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range e.headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// runOTLP pushes a sample to endpoint every interval until interrupted
+func runOTLP(endpoint string, interval time.Duration) error {
+	e, err := newOTLPExporter(endpoint)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	samples, err := streamStats(ctx, interval, withCollectors("sockets", "interfaces"))
+	if err != nil {
+		return err
+	}
+	for stats := range samples {
+		e.record(time.Now(), stats)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOTLPRequest(t *testing.T) {
+	defer func(h string) { hostname = h }(hostname)
+	hostname = "mac"
+	at := time.Unix(1700000000, 0)
+	r := buildOTLPRequest(at, fixtureStats())
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`{"key":"host.name","value":{"stringValue":"mac"}}`,
+		`{"key":"os.type","value":{"stringValue":"darwin"}}`,
+		`"timeUnixNano":"1700000000000000000"`,
+		`{"key":"cpu.logical_number","value":{"intValue":"0"}}`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("request lacks %s", want)
+		}
+	}
+
+	metrics := map[string]otlpMetric{}
+	for _, m := range r.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		if _, dup := metrics[m.Name]; dup {
+			t.Errorf("metric %s appears twice", m.Name)
+		}
+		metrics[m.Name] = m
+	}
+	if m := metrics["mtop.cpu.utilization"]; m.Gauge == nil || m.Gauge.DataPoints[0].AsDouble != 0.375 {
+		t.Errorf("CPU utilization = %+v, want a 0.375 gauge", m)
+	}
+	if m := metrics["system.memory.usage"]; m.Sum == nil || m.Sum.IsMonotonic || len(m.Sum.DataPoints) != 2 {
+		t.Errorf("memory usage = %+v, want a non-monotonic sum by state", m)
+	}
+	if m := metrics["system.disk.io_time"]; m.Sum == nil || !m.Sum.IsMonotonic || m.Sum.DataPoints[0].StartTimeUnixNano == 0 {
+		t.Errorf("disk I/O time = %+v, want a monotonic sum since boot", m)
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var path, key, kind string
+	var body otlpRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, key, kind = r.URL.Path, r.Header.Get("Api-Key"), r.Header.Get("Content-Type")
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+	}))
+	defer srv.Close()
+
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key=secret")
+	e, err := newOTLPExporter(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var errs strings.Builder
+	e.errs = &errs
+	e.record(time.Now(), fixtureStats())
+	if path != "/v1/metrics" || key != "secret" || kind != "application/json" || len(body.ResourceMetrics) != 1 {
+		t.Errorf("posted to %q with key %q, type %q: %+v", path, key, kind, body)
+	}
+	if errs.Len() > 0 {
+		t.Errorf("export reported %q", errs.String())
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "novalue")
+	if _, err := newOTLPExporter(srv.URL); err == nil {
+		t.Error("malformed OTEL_EXPORTER_OTLP_HEADERS accepted")
+	}
+}
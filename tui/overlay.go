@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// chartSeries is a history metric drawn on a detail view's history chart
+type chartSeries struct {
+	metric string
+	label  string
+	format func(float64) string
+	scale  float64 // Value at the top of the chart; 0 scales to the largest shown
+}
+
+func formatSeriesPercent(v float64) string { return fmt.Sprintf("%.1f%%", v) }
+func formatSeriesCelsius(v float64) string { return fmt.Sprintf("%.0f°C", v) }
+func formatSeriesWatts(v float64) string   { return fmt.Sprintf("%.2f W", v) }
+func formatSeriesRate(v float64) string    { return formatBytes(uint64(v)) + "/s" }
+
+// Series of each detail view's history chart. The first is always drawn;
+// the overlay keys toggle the others, in order, over it.
+var viewSeries = map[ViewMode][]chartSeries{
+	CPUDetailMode: {
+		{metricCPU, "Usage", formatSeriesPercent, 100},
+		{metricCPUTemp, "Temp", formatSeriesCelsius, 100},
+		{metricPowerCPU, "Power", formatSeriesWatts, 0},
+	},
+	MemoryDetailMode: {
+		{metricMemory, "Usage", formatSeriesPercent, 100},
+		{metricSwap, "Swap", formatSeriesPercent, 100},
+		{metricSwapRate, "Swap rate", formatSeriesRate, 0},
+	},
+	GPUDetailMode: {
+		{metricGPU, "Usage", formatSeriesPercent, 100},
+		{metricGPUTemp, "Temp", formatSeriesCelsius, 100},
+		{metricPowerGPU, "Power", formatSeriesWatts, 0},
+	},
+}
+
+// Actions toggling the overlays of a chart, in series order
+var overlayActions = []string{"overlay", "overlay_2"}
+
+// Colors of the overlays, in series order, set by applyTheme. The first
+// series keeps the terminal's color.
+var overlayStyles []lipgloss.Style
+
+// toggleOverlay shows or hides the i-th overlay of the focused view's
+// chart. The set is copied, as models share it.
+func (m *model) toggleOverlay(i int) {
+	series := viewSeries[m.viewMode]
+	if i+1 >= len(series) {
+		return
+	}
+	metric := series[i+1].metric
+	overlays := maps.Clone(m.overlays)
+	if overlays == nil {
+		overlays = make(map[string]bool)
+	}
+	overlays[metric] = !overlays[metric]
+	m.overlays = overlays
+	state := "hidden"
+	if overlays[metric] {
+		state = "shown"
+	}
+	m.setStatus(fmt.Sprintf("%s %s", series[i+1].label, state))
+}
+
+// renderSeriesChart draws a detail view's history chart with the overlays
+// that are on, followed by its legend
+func (m model) renderSeriesChart(title string, mode ViewMode, height int) string {
+	series := viewSeries[mode]
+	var layers []chartLayer
+	for i, s := range series[1:] {
+		if !m.overlays[s.metric] {
+			continue
+		}
+		values := m.history.Values(s.metric)
+		scale := seriesScale(s, values)
+		scaled := make([]float64, len(values))
+		for j, v := range values {
+			scaled[j] = v / scale * 100
+		}
+		layers = append(layers, chartLayer{scaled, overlayStyles[i%len(overlayStyles)]})
+	}
+	return m.renderHistoryChart(title, m.history.Samples(series[0].metric), height, layers...) +
+		m.chartLegend(series) + "\n"
+}
+
+// seriesScale is the value drawn at the top of the chart for a series
+func seriesScale(s chartSeries, values []float64) float64 {
+	if s.scale > 0 {
+		return s.scale
+	}
+	top := 0.0
+	for _, v := range values {
+		top = max(top, v)
+	}
+	if top == 0 {
+		return 1
+	}
+	return top
+}
+
+// chartLegend names each series of a chart in its color with its newest
+// value. Overlays show their key, and are muted while hidden; those scaled
+// to what is shown say what the top of the chart stands for.
+func (m model) chartLegend(series []chartSeries) string {
+	entries := make([]string, len(series))
+	for i, s := range series {
+		value := "-"
+		if samples := m.history.Samples(s.metric); len(samples) > 0 {
+			value = s.format(samples[len(samples)-1].Value)
+		}
+		entry := fmt.Sprintf("%s %s", s.label, value)
+		if i == 0 {
+			entries[i] = glyphs.head + " " + entry
+			continue
+		}
+		if s.scale == 0 && m.overlays[s.metric] {
+			entry += ", top " + s.format(seriesScale(s, m.history.Values(s.metric)))
+		}
+		if i-1 < len(overlayActions) {
+			entry += " (" + keyFor(overlayActions[i-1]) + ")"
+		}
+		if m.overlays[s.metric] {
+			entries[i] = overlayStyles[(i-1)%len(overlayStyles)].Render(glyphs.head) + " " + entry
+		} else {
+			entries[i] = helpStyle.Render(glyphs.hollow + " " + entry)
+		}
+	}
+	return ansi.Truncate(strings.Join(entries, "  "), m.width, "")
+}
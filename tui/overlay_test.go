@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestChartOverlays(t *testing.T) {
+	m := model{width: 80, height: 24, history: newHistory(), viewMode: CPUDetailMode}
+	start := time.Date(2026, 10, 17, 10, 0, 0, 0, time.Local)
+	for i := range 10 {
+		m.history.Record(start.Add(time.Duration(i)*time.Second), map[string]float64{metricCPU: 10, metricCPUTemp: 95, metricPowerCPU: float64(i)})
+	}
+	topRow := func(m model) string {
+		return strings.Split(m.renderSeriesChart("CPU Usage History", CPUDetailMode, 4), "\n")[1]
+	}
+	legend := func(m model) string {
+		lines := strings.Split(strings.TrimSuffix(m.renderSeriesChart("CPU Usage History", CPUDetailMode, 4), "\n"), "\n")
+		return lines[len(lines)-1]
+	}
+
+	if strings.TrimSpace(strings.TrimPrefix(topRow(m), " 100%"+glyphs.axis)) != "" {
+		t.Errorf("top row drawn without overlays: %q", topRow(m))
+	}
+	if l := legend(m); !strings.Contains(l, glyphs.hollow+" Temp 95°C (v)") || !strings.Contains(l, "Usage 10.0%") {
+		t.Errorf("legend = %q", l)
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	on := next.(model)
+	if !on.overlays[metricCPUTemp] || m.overlays[metricCPUTemp] {
+		t.Fatalf("overlays = %v, before = %v", on.overlays, m.overlays)
+	}
+	if strings.TrimSpace(strings.TrimPrefix(topRow(on), " 100%"+glyphs.axis)) == "" {
+		t.Errorf("temperature not drawn near the top: %q", topRow(on))
+	}
+	if l := legend(on); strings.Contains(l, glyphs.hollow+" Temp") {
+		t.Errorf("legend still shows the temperature hidden: %q", l)
+	}
+
+	on.toggleOverlay(1)
+	if l := legend(on); !strings.Contains(l, "Power 9.00 W, top 9.00 W (V)") {
+		t.Errorf("auto-scaled overlay legend = %q", l)
+	}
+	on.toggleOverlay(5)
+	if len(on.overlays) != 2 {
+		t.Errorf("toggled a series the view does not have: %v", on.overlays)
+	}
+}
@@ -0,0 +1,213 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/khoi/mtop/widgets"
+)
+
+// overviewWidget renders one panel of the overview at the given width
+type overviewWidget func(m model, width int) string
+
+// Widgets that can be placed in the overview layout
+var overviewWidgets = map[string]overviewWidget{
+	"cpu":       overviewCPU,
+	"memory":    overviewMemory,
+	"gpu":       overviewGPU,
+	"load":      overviewLoad,
+	"uptime":    overviewUptime,
+	"network":   overviewNetwork,
+	"disk":      overviewDisk,
+	"processes": overviewProcesses,
+	"pressure":  overviewPressure,
+	"plugins":   overviewPlugins,
+}
+
+// Rows of widgets in the overview, each row split into equal columns; set
+// from the config file
+var overviewLayout = [][]string{{"cpu"}, {"memory"}, {"gpu"}, {"load"}, {"pressure"}, {"uptime"}}
+
+// Space between the columns of an overview row
+const overviewGap = "  "
+
+// Number of processes listed by the processes widget and the widest their
+// names get
+const (
+	overviewTopProcesses = 5
+	overviewNameWidth    = 32
+)
+
+// applyOverviewLayout checks and sets the overview layout from the config
+func applyOverviewLayout(layout [][]string) error {
+	if len(layout) == 0 {
+		return nil
+	}
+	for i, row := range layout {
+		if len(row) == 0 {
+			return fmt.Errorf("overview row %d is empty", i+1)
+		}
+		for _, name := range row {
+			if _, ok := overviewWidgets[name]; !ok {
+				return fmt.Errorf("unknown overview widget %q (want %s)", name,
+					strings.Join(sortedKeys(overviewWidgets), ", "))
+			}
+		}
+	}
+	overviewLayout = layout
+	return nil
+}
+
+// renderOverview composes the configured widgets row by row
+func (m model) renderOverview() string {
+	var b strings.Builder
+	for _, row := range overviewLayout {
+		if len(row) == 1 {
+			b.WriteString(overviewWidgets[row[0]](m, m.width))
+			continue
+		}
+
+		width := (m.width - len(overviewGap)*(len(row)-1)) / len(row)
+		columns := make([][]string, len(row))
+		height := 0
+		for i, name := range row {
+			columns[i] = strings.Split(strings.TrimSuffix(overviewWidgets[name](m, width), "\n"), "\n")
+			height = max(height, len(columns[i]))
+		}
+		for y := 0; y < height; y++ {
+			for i, lines := range columns {
+				if i > 0 {
+					b.WriteString(overviewGap)
+				}
+				line := ""
+				if y < len(lines) {
+					line = lines[y]
+				}
+				b.WriteString(fitColumn(line, width, i == len(columns)-1))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// fitColumn cuts a styled line to width cells and pads it unless it ends
+// the row
+func fitColumn(line string, width int, last bool) string {
+	line = ansi.Truncate(line, width, "")
+	if w := ansi.StringWidth(line); !last && w < width {
+		line += strings.Repeat(" ", width-w)
+	}
+	return line
+}
+
+// overviewTrend renders a metric's sparkline scaled to 0-100%, padded to
+// the room left next to a gauge line of the given width
+func (m model) overviewTrend(metric string, width int) string {
+	// Sparklines sit between each gauge and its details
+	width = width - 50 - overviewBarWidth - 1
+	if width > overviewSparkWidth {
+		width = overviewSparkWidth
+	}
+	if width < 0 {
+		width = 0
+	}
+	spark := widgets.Spark(m.history.Values(metric), width, 100)
+	return spark + strings.Repeat(" ", width-len([]rune(spark)))
+}
+
+func overviewCPU(m model, width int) string {
+	cpu := m.highlightChange(fmt.Sprintf("%5.1f%%", m.stats.CPU.Usage),
+		m.stats.CPU.Usage, m.prevStats.CPU.Usage, changeThreshold)
+	return fmt.Sprintf("CPU Usage:    %s %s %s | Temp: %s\n", alertGauge(m.stats, "cpu", m.stats.CPU.Usage, overviewBarWidth),
+		cpu, m.overviewTrend(metricCPU, width), alertText(m.stats, "temp", fmt.Sprintf("%.1f°C", m.stats.CPU.Temp)))
+}
+
+func overviewMemory(m model, width int) string {
+	mem := m.highlightChange(fmt.Sprintf("%5.1f%%", m.stats.Memory.Usage),
+		m.stats.Memory.Usage, m.prevStats.Memory.Usage, changeThreshold)
+	return fmt.Sprintf("Memory Usage: %s %s %s | %.1f GB / %.1f GB\n", alertGauge(m.stats, "memory", m.stats.Memory.Usage, overviewBarWidth),
+		mem, m.overviewTrend(metricMemory, width),
+		float64(m.stats.Memory.Used)/(1024*1024*1024),
+		float64(m.stats.Memory.Total)/(1024*1024*1024))
+}
+
+func overviewGPU(m model, width int) string {
+	gpu := m.highlightChange(fmt.Sprintf("%5.1f%%", m.stats.GPU.Usage),
+		m.stats.GPU.Usage, m.prevStats.GPU.Usage, changeThreshold)
+	return fmt.Sprintf("GPU Usage:    %s %s %s | Memory: %.1f%%\n", alertGauge(m.stats, "gpu", m.stats.GPU.Usage, overviewBarWidth),
+		gpu, m.overviewTrend(metricGPU, width), m.stats.GPU.MemoryUsage)
+}
+
+func overviewLoad(m model, width int) string {
+	return fmt.Sprintf("Load Average: %s, %.2f, %.2f\n",
+		alertText(m.stats, "load", fmt.Sprintf("%.2f", m.stats.CPU.LoadAvg[0])), m.stats.CPU.LoadAvg[1], m.stats.CPU.LoadAvg[2])
+}
+
+func overviewUptime(m model, width int) string {
+	return fmt.Sprintf("Uptime:       %v\n", m.stats.Uptime.Round(time.Second))
+}
+
+func overviewNetwork(m model, width int) string {
+	wifi := "no Wi-Fi"
+	if w := m.stats.Network.WiFi; w != nil {
+		switch {
+		case !w.PowerOn:
+			wifi = "Wi-Fi off"
+		case w.SSID != "":
+			wifi = fmt.Sprintf("%s %d dBm", w.SSID, w.RSSI)
+		default:
+			wifi = fmt.Sprintf("%s %d dBm", w.Interface, w.RSSI)
+		}
+	}
+	sockets := m.stats.Network.Sockets
+	return fmt.Sprintf("Network:      %s | TCP: %d est / %d | UDP: %d\n",
+		wifi, sockets.TCP["ESTABLISHED"], sockets.TCPTotal, sockets.UDP)
+}
+
+// overviewDisk sums the disk I/O of all processes since the last refresh.
+// Processes that exited in between are not counted.
+func overviewDisk(m model, width int) string {
+	elapsed := m.sampledAt.Sub(m.prevSampledAt).Seconds()
+	if !m.hasPrev || elapsed <= 0 {
+		return "Disk I/O:     read - | write -\n"
+	}
+
+	prev := make(map[procKey]ProcessStats, len(m.prevStats.Processes))
+	for _, p := range m.prevStats.Processes {
+		prev[procKey{p.PID, p.StartTime}] = p
+	}
+	var read, written uint64
+	for _, p := range m.stats.Processes {
+		if old, ok := prev[procKey{p.PID, p.StartTime}]; ok {
+			if p.DiskRead > old.DiskRead {
+				read += p.DiskRead - old.DiskRead
+			}
+			if p.DiskWritten > old.DiskWritten {
+				written += p.DiskWritten - old.DiskWritten
+			}
+		}
+	}
+	return fmt.Sprintf("Disk I/O:     read %s/s | write %s/s\n",
+		formatBytes(uint64(float64(read)/elapsed)), formatBytes(uint64(float64(written)/elapsed)))
+}
+
+func overviewProcesses(m model, width int) string {
+	procs := make([]ProcessStats, len(m.stats.Processes))
+	copy(procs, m.stats.Processes)
+	sort.SliceStable(procs, func(i, j int) bool { return procs[i].CPU > procs[j].CPU })
+	if len(procs) > overviewTopProcesses {
+		procs = procs[:overviewTopProcesses]
+	}
+
+	nameWidth := min(max(width-19, 8), overviewNameWidth)
+	var b strings.Builder
+	b.WriteString("Top Processes:\n")
+	for _, p := range procs {
+		fmt.Fprintf(&b, "  %s %5.1f%% %9s\n", widgets.Pad(p.Name, nameWidth, false), p.CPU, formatBytes(p.RSS))
+	}
+	return b.String()
+}
@@ -0,0 +1,24 @@
+package tui
+
+import "testing"
+
+func TestOverviewLayoutGolden(t *testing.T) {
+	defer func(layout [][]string) { overviewLayout = layout }(overviewLayout)
+
+	err := applyOverviewLayout([][]string{{"cpu"}, {"load", "uptime"}, {"network", "disk"}, {"processes"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "overview_custom_120x40", Render(fixtureStats(), 120, 40, OverviewMode))
+}
+
+func TestApplyOverviewLayoutErrors(t *testing.T) {
+	defer func(layout [][]string) { overviewLayout = layout }(overviewLayout)
+
+	if err := applyOverviewLayout([][]string{{"cpu"}, {}}); err == nil {
+		t.Error("empty row was accepted")
+	}
+	if err := applyOverviewLayout([][]string{{"cpu", "disks"}}); err == nil {
+		t.Error("unknown widget was accepted")
+	}
+}
@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Most snapshots kept for scrolling back while paused. Each holds a full
+// process list, so this is far shorter than the metric history.
+const scrollbackSamples = 300
+
+// Style of the PAUSED marker in the header, set by applyTheme
+var pausedStyle lipgloss.Style
+
+// snapshot is a sample kept for scrolling back while paused
+type snapshot struct {
+	at    time.Time
+	stats SystemStats
+}
+
+// recordSnapshot keeps stats for scroll-back, dropping the oldest snapshot
+// once scrollbackSamples are held
+func (m *model) recordSnapshot(at time.Time, stats SystemStats) {
+	if len(m.scrollback) == scrollbackSamples {
+		m.scrollback = m.scrollback[1:]
+	}
+	m.scrollback = append(m.scrollback, snapshot{at, stats})
+}
+
+// togglePause freezes the display on the newest sample or resumes updates
+func (m *model) togglePause() {
+	m.paused = !m.paused && len(m.scrollback) > 0
+	m.pauseOffset = 0
+}
+
+// scrollBack moves the paused display by delta samples, negative going
+// back in time
+func (m *model) scrollBack(delta int) {
+	if !m.paused {
+		return
+	}
+	m.pauseOffset = min(max(m.pauseOffset-delta, 0), len(m.scrollback)-1)
+}
+
+// pausedFrame is the model as it was when the viewed snapshot was taken:
+// its stats, the sample before it and the history up to it
+func (m model) pausedFrame() model {
+	i := len(m.scrollback) - 1 - m.pauseOffset
+	shot := m.scrollback[i]
+	m.stats, m.sampledAt = shot.stats, shot.at
+	m.hasPrev = i > 0
+	if m.hasPrev {
+		m.prevStats, m.prevSampledAt = m.scrollback[i-1].stats, m.scrollback[i-1].at
+	}
+	m.history = m.history.Until(shot.at)
+	return m
+}
+
+// pausedStatus is the header status line while paused or replaying
+func (m model) pausedStatus() string {
+	switch {
+	case m.seeking:
+		return m.seekPrompt()
+	case m.replay:
+		return m.replayNote()
+	}
+	return m.pausedNote()
+}
+
+// pausedNote is the header marker naming the viewed sample
+func (m model) pausedNote() string {
+	i := len(m.scrollback) - m.pauseOffset
+	return pausedStyle.Render(fmt.Sprintf(" PAUSED %s ", m.sampledAt.Format("15:04:05"))) +
+		fmt.Sprintf(" sample %d/%d (%s/%s: older/newer, %s: resume)",
+			i, len(m.scrollback), keyFor("older"), keyFor("newer"), keyFor("pause"))
+}
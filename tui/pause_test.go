@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPauseScrollBack(t *testing.T) {
+	m := model{
+		viewMode:    OverviewMode,
+		refreshRate: time.Second,
+		width:       120,
+		height:      40,
+		history:     newHistory(),
+	}
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	for i := 0; i < 5; i++ {
+		stats := fixtureStats()
+		stats.CPU.Usage = float64(10 * i)
+		at := base.Add(time.Duration(i) * time.Second)
+		m.stats = stats
+		m.recordHistory(at, stats)
+		m.recordSnapshot(at, stats)
+	}
+
+	m.scrollBack(-1)
+	if m.pauseOffset != 0 {
+		t.Fatal("scrolled while not paused")
+	}
+	m.togglePause()
+	m.scrollBack(-2)
+	frame := m.pausedFrame()
+	if frame.stats.CPU.Usage != 20 || frame.prevStats.CPU.Usage != 10 {
+		t.Errorf("viewing CPU %v after %v, want 20 after 10", frame.stats.CPU.Usage, frame.prevStats.CPU.Usage)
+	}
+	if values := frame.history.Values(metricCPU); len(values) != 3 {
+		t.Errorf("history up to the viewed sample = %v, want 3 values", values)
+	}
+	if view := m.View(); !strings.Contains(view, "PAUSED 12:00:02") || !strings.Contains(view, "sample 3/5") {
+		t.Errorf("header does not name the viewed sample:\n%s", view)
+	}
+
+	// Scrolling stops at either end
+	m.scrollBack(-10)
+	if m.pauseOffset != 4 {
+		t.Errorf("offset = %d, want the oldest sample (4)", m.pauseOffset)
+	}
+	m.scrollBack(10)
+	if m.pauseOffset != 0 {
+		t.Errorf("offset = %d, want the newest sample (0)", m.pauseOffset)
+	}
+
+	m.togglePause()
+	if m.paused || strings.Contains(m.View(), "PAUSED") {
+		t.Error("still paused after resuming")
+	}
+}
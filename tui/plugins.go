@@ -0,0 +1,207 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// pluginConfig is a [[plugins]] entry of the config file: a program that
+// prints a JSON object of metric names to numbers on stdout, e.g.
+// {"clients": 12, "ops_per_sec": 1500}, and exits
+type pluginConfig struct {
+	Name    string        `toml:"name"`
+	Command []string      `toml:"command"` // Program and arguments; a leading ~/ is the home directory
+	Timeout time.Duration `toml:"timeout"` // How long a run may take; default pluginTimeout
+}
+
+// How long a plugin run may take unless its config says otherwise
+const pluginTimeout = 2 * time.Second
+
+// How long a timed out plugin's output may stay open after it is killed,
+// e.g. by a child it started that still holds stdout
+const pluginWaitDelay = 500 * time.Millisecond
+
+// Plugin and metric names become part of exported metric names, so they
+// keep to a safe set
+var pluginNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// plugin is a configured exec plugin and the result of its latest run.
+// Runs happen in the background so a slow plugin never holds up a sample.
+type plugin struct {
+	pluginConfig
+	mu      sync.Mutex
+	running bool
+	first   chan struct{} // Closed when the first run finishes
+	metrics map[string]float64
+	err     error
+}
+
+// The plugins configured in the config file, in its order
+var plugins []*plugin
+
+// applyPlugins checks and sets the plugins from the config, adding their
+// panel to the default overview layout
+func applyPlugins(cfgs []pluginConfig) error {
+	var configured []*plugin
+	for i, c := range cfgs {
+		switch {
+		case !pluginNamePattern.MatchString(c.Name):
+			return fmt.Errorf("plugin %d: name %q must be letters, digits, '-' or '_'", i+1, c.Name)
+		case slices.ContainsFunc(configured, func(p *plugin) bool { return p.Name == c.Name }):
+			return fmt.Errorf("plugin %q is configured twice", c.Name)
+		case len(c.Command) == 0 || c.Command[0] == "":
+			return fmt.Errorf("plugin %q has no command", c.Name)
+		case c.Timeout < 0:
+			return fmt.Errorf("plugin %q: timeout must not be negative", c.Name)
+		}
+		if c.Timeout == 0 {
+			c.Timeout = pluginTimeout
+		}
+		configured = append(configured, &plugin{pluginConfig: c, first: make(chan struct{})})
+	}
+	plugins = configured
+	if len(plugins) > 0 {
+		overviewLayout = append(slices.Clone(overviewLayout), []string{"plugins"})
+	}
+	return nil
+}
+
+// collectPlugins starts a run of each plugin that is not still running
+// and fills stats.Plugins with the latest readings. Only a plugin's first
+// run is waited for, so the first sample has its readings.
+func collectPlugins(ctx context.Context, stats *SystemStats) error {
+	for _, p := range plugins {
+		p.start()
+	}
+	for _, p := range plugins {
+		select {
+		case <-p.first:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if metrics, _ := p.latest(); metrics != nil {
+			if stats.Plugins == nil {
+				stats.Plugins = make(map[string]map[string]float64, len(plugins))
+			}
+			stats.Plugins[p.Name] = metrics
+		}
+	}
+	return nil
+}
+
+// start runs the plugin in the background unless a run is under way
+func (p *plugin) start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.running {
+		return
+	}
+	p.running = true
+	go func() {
+		metrics, err := runPlugin(p.Command, p.Timeout)
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		select {
+		case <-p.first:
+		default:
+			close(p.first)
+		}
+		p.running, p.metrics, p.err = false, metrics, err
+	}()
+}
+
+// latest returns the readings of the last finished run, or why it failed
+func (p *plugin) latest() (map[string]float64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.metrics, p.err
+}
+
+// runPlugin runs a plugin's command and parses what it prints
+func runPlugin(command []string, timeout time.Duration) (map[string]float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, expandHome(command[0]), command[1:]...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = pluginWaitDelay
+	out, err := cmd.Output()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("timed out after %v", timeout)
+	}
+	if err != nil {
+		if msg, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n"); msg != "" {
+			return nil, fmt.Errorf("%v: %s", err, msg)
+		}
+		return nil, err
+	}
+	return parsePluginOutput(out)
+}
+
+// parsePluginOutput reads a plugin's JSON object of metric names to
+// numbers. Names are held to pluginNamePattern, as the exporters write
+// them into metric names as they are.
+func parsePluginOutput(out []byte) (map[string]float64, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(out, &raw); err != nil {
+		return nil, fmt.Errorf("output is not a JSON object: %v", err)
+	}
+	metrics := make(map[string]float64, len(raw))
+	for name, v := range raw {
+		if !pluginNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("metric name %q must be letters, digits, '-' or '_'", name)
+		}
+		n, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("metric %q is not a number", name)
+		}
+		metrics[name] = n
+	}
+	return metrics, nil
+}
+
+// overviewPlugins shows each plugin's readings on a line, or why its last
+// run failed. Plugins of a remote or replayed sample show too.
+func overviewPlugins(m model, width int) string {
+	lines := map[string]string{}
+	for name, metrics := range m.stats.Plugins {
+		var fields []string
+		for _, metric := range sortedKeys(metrics) {
+			fields = append(fields, fmt.Sprintf("%s %s", metric, formatPluginValue(metrics[metric])))
+		}
+		lines[name] = strings.Join(fields, " | ")
+	}
+	for _, p := range plugins {
+		if _, err := p.latest(); err != nil {
+			lines[p.Name] = fdWarnStyle.Render("error: " + err.Error())
+		}
+	}
+	if len(lines) == 0 {
+		return "Plugins:      waiting for readings\n"
+	}
+	var b strings.Builder
+	for _, name := range sortedKeys(lines) {
+		b.WriteString(ansi.Truncate(fmt.Sprintf("%-14s%s", name+":", lines[name]), width, "") + "\n")
+	}
+	return b.String()
+}
+
+// formatPluginValue prints whole numbers without a fraction and others to
+// two decimals
+func formatPluginValue(v float64) string {
+	if v == float64(int64(v)) {
+		return fmt.Sprintf("%d", int64(v))
+	}
+	return fmt.Sprintf("%.2f", v)
+}
@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePluginOutput(t *testing.T) {
+	metrics, err := parsePluginOutput([]byte(`{"clients": 12, "ops_per_sec": 1500.5}`))
+	if err != nil || metrics["clients"] != 12 || metrics["ops_per_sec"] != 1500.5 {
+		t.Errorf("parsePluginOutput = %v, %v", metrics, err)
+	}
+	for _, bad := range []string{`[1, 2]`, `{"state": "up"}`, `clients 12`, `{"ops sec": 1}`, `{"ops,host=x": 1}`, `{"ops:1|c\nx": 1}`} {
+		if _, err := parsePluginOutput([]byte(bad)); err == nil {
+			t.Errorf("parsePluginOutput(%q): no error", bad)
+		}
+	}
+}
+
+func TestPlugins(t *testing.T) {
+	defer func(p []*plugin, layout [][]string) { plugins, overviewLayout = p, layout }(plugins, overviewLayout)
+
+	for _, bad := range [][]pluginConfig{
+		{{Name: "redis stats", Command: []string{"true"}}},
+		{{Name: "redis"}},
+		{{Name: "redis", Command: []string{"true"}}, {Name: "redis", Command: []string{"true"}}},
+	} {
+		if err := applyPlugins(bad); err == nil {
+			t.Errorf("applyPlugins(%+v): no error", bad)
+		}
+	}
+
+	err := applyPlugins([]pluginConfig{
+		{Name: "redis", Command: []string{"sh", "-c", `echo '{"clients": 12}'`}},
+		{Name: "broken", Command: []string{"sh", "-c", "echo no server >&2; exit 1"}},
+		{Name: "slow", Command: []string{"sleep", "5"}, Timeout: 50 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := overviewLayout[len(overviewLayout)-1]; len(last) != 1 || last[0] != "plugins" {
+		t.Errorf("overview layout ends in %v, want the plugins panel", last)
+	}
+
+	var stats SystemStats
+	if err := collectPlugins(context.Background(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Plugins) != 1 || stats.Plugins["redis"]["clients"] != 12 {
+		t.Errorf("plugin readings = %v, want redis only", stats.Plugins)
+	}
+	if historyMetrics(stats)["plugin.redis.clients"] != 12 {
+		t.Error("history lacks plugin.redis.clients")
+	}
+
+	m := model{stats: stats}
+	out := overviewPlugins(m, 80)
+	for _, want := range []string{"redis:        clients 12", "no server", "timed out"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("plugins panel lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPluginTimeoutWithChildHoldingOutput(t *testing.T) {
+	start := time.Now()
+	_, err := runPlugin([]string{"sh", "-c", "sleep 5; echo {}"}, 50*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("runPlugin = %v, want a timeout", err)
+	}
+	if took := time.Since(start); took > 2*time.Second {
+		t.Errorf("runPlugin took %v, want it to stop waiting for the orphaned sleep", took)
+	}
+}
@@ -0,0 +1,8 @@
+package tui
+
+// Explanations for the power metrics, shown with the "e" key
+var powerDocs = []metricDoc{
+	{Name: "Package", Text: "CPU + GPU + ANE power, like powermetrics' \"Combined Power\"."},
+	{Name: "CPU / GPU / ANE / DRAM", Text: "Energy counted by the IOReport \"Energy Model\" channels since the " +
+		"last refresh, divided by the time elapsed, giving average watts over the refresh interval."},
+}
@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/khoi/mtop/history"
+)
+
+// CPU usage at which a sample counts as CPU saturated: little idle time
+// is left, so runnable threads are likely waiting for a core
+const cpuSaturatedUsage = 90
+
+// Windows the pressure readings average over, as Linux PSI's avg10,
+// avg60 and avg300
+var pressureWindows = []time.Duration{10 * time.Second, time.Minute, 5 * time.Minute}
+
+// stalled records whether a sample was saturated as 100 or 0, so that
+// averaging over a window gives the percentage of time spent saturated
+func stalled(saturated bool) float64 {
+	if saturated {
+		return 100
+	}
+	return 0
+}
+
+// windowAverage averages samples over the window ending at the newest,
+// weighting each by the time since the one before it, so the result does
+// not depend on how often samples were taken. It reports false until a
+// second sample gives a first interval.
+func windowAverage(samples []history.Sample, window time.Duration) (float64, bool) {
+	if len(samples) < 2 {
+		return 0, false
+	}
+	start := samples[len(samples)-1].Time.Add(-window)
+	var sum, total float64
+	for i := len(samples) - 1; i > 0 && samples[i].Time.After(start); i-- {
+		from := samples[i-1].Time
+		if from.Before(start) {
+			from = start
+		}
+		weight := samples[i].Time.Sub(from).Seconds()
+		sum += samples[i].Value * weight
+		total += weight
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return sum / total, true
+}
+
+// pressureReadings formats a metric's averages over each pressure window
+// as "12/8/3%", or a dash before there are two samples
+func (m model) pressureReadings(metric string) string {
+	samples := m.history.Samples(metric)
+	readings := make([]string, len(pressureWindows))
+	for i, window := range pressureWindows {
+		avg, ok := windowAverage(samples, window)
+		if !ok {
+			return "-"
+		}
+		readings[i] = fmt.Sprintf("%.0f", avg)
+	}
+	return strings.Join(readings, "/") + "%"
+}
+
+// overviewPressure shows the share of recent time the CPU was saturated,
+// memory was under pressure and the disks were busy, over the last 10
+// seconds, minute and 5 minutes
+func overviewPressure(m model, width int) string {
+	return fmt.Sprintf("Saturation:   CPU %s | Memory %s | Disk %s (10s/1m/5m)\n",
+		m.pressureReadings(metricCPUStall), m.pressureReadings(metricMemoryStall), m.pressureReadings(metricDiskBusy))
+}
@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khoi/mtop/history"
+)
+
+func TestWindowAverage(t *testing.T) {
+	start := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	at := func(sec int) time.Time { return start.Add(time.Duration(sec) * time.Second) }
+	// Saturated for the 8s up to 20s, then not for 10s sampled twice
+	samples := []history.Sample{
+		{Time: at(0), Value: 0},
+		{Time: at(12), Value: 0},
+		{Time: at(20), Value: 100},
+		{Time: at(25), Value: 0},
+		{Time: at(30), Value: 0},
+	}
+	for _, tt := range []struct {
+		window time.Duration
+		want   float64
+	}{
+		{10 * time.Second, 0},
+		{15 * time.Second, 100.0 * 5 / 15},
+		{time.Minute, 100.0 * 8 / 30},
+	} {
+		got, ok := windowAverage(samples, tt.window)
+		if !ok || got != tt.want {
+			t.Errorf("window %v: average %v, %v; want %v", tt.window, got, ok, tt.want)
+		}
+	}
+	if _, ok := windowAverage(samples[:1], time.Minute); ok {
+		t.Error("a single sample gave an average")
+	}
+}
+
+func TestOverviewPressure(t *testing.T) {
+	m := model{history: newHistory()}
+	if got := overviewPressure(m, 80); !strings.Contains(got, "CPU - |") {
+		t.Errorf("pressure without history = %q", got)
+	}
+
+	start := time.Now()
+	stats := fixtureStats()
+	for i := range 20 {
+		stats.CPU.Usage = 20
+		if i >= 15 {
+			stats.CPU.Usage = 95
+		}
+		stats.Memory.Pressure = PressureNormal
+		stats.Disk.Busy = 40
+		m.history.Record(start.Add(time.Duration(i)*time.Second), historyMetrics(stats))
+	}
+	want := "Saturation:   CPU 50/26/26% | Memory 0/0/0% | Disk 40/40/40% (10s/1m/5m)\n"
+	if got := overviewPressure(m, 80); got != want {
+		t.Errorf("pressure = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// appName returns the app bundle a process belongs to, so helpers are grouped
+// with their app, or the process name when it is not part of a bundle
+func appName(p ProcessStats) string {
+	if i := strings.Index(p.Path, ".app/"); i >= 0 {
+		return filepath.Base(p.Path[:i])
+	}
+	return p.Name
+}
+
+// Explanations for the process metrics, shown with the "e" key
+var processDocs = []metricDoc{
+	{Name: "CPU%", Text: "User + system CPU time from proc_pidinfo(PROC_PIDTASKINFO) consumed since the " +
+		"last refresh, divided by the wall time elapsed. A process using several cores can exceed 100%."},
+	{Name: "TIME", Text: "Total user + system CPU time used since the process started."},
+	{Name: "MEM", Text: "Resident size from proc_pidinfo: the physical memory currently mapped by the " +
+		"process, including shared pages."},
+	{Name: "READ / WRITE", Text: "Bytes read from and written to disk since the process started, from proc_pid_rusage."},
+	{Name: "FD", Text: "Open file descriptors from proc_pidinfo(PROC_PIDLISTFDS). macOS does not expose " +
+		"other processes' RLIMIT_NOFILE, so the count is only compared against the kernel's ceiling, " +
+		"kern.maxfilesperproc: yellow from 80%, red from 95%. That ceiling is far above the usual soft " +
+		"limits (256, or 10240 once raised), so a process running out of its own limit is not flagged. " +
+		"Not counted with --quiet."},
+	{Name: "PORTS", Text: "TCP ports the process listens on and UDP ports it has bound, from " +
+		"proc_pidfdinfo(PROC_PIDFDSOCKETINFO) on each of its sockets. Other users' processes show none " +
+		"unless mtop runs as root."},
+	{Name: "GPU%", Text: "GPU time accumulated by the process's IOAccelerator user clients (their " +
+		"AppUsage accumulatedGPUTime in the I/O registry) since the last refresh, divided by the wall time elapsed."},
+	{Name: "NET IN / NET OUT", Text: "Bytes per second received and sent by the process since the last " +
+		"refresh, as reported by nettop, which mtop runs once per refresh while these columns are shown. " +
+		"nettop reads the kernel's network statistics (ntstat); mtop does not open that private interface itself."},
+	{Name: "ENERGY", Text: "Energy the kernel has billed to the process since it started, from proc_pid_rusage."},
+	{Name: "Grouping", Text: "The group key folds the table into one row per app, by the .app bundle in " +
+		"the executable path, or per resource coalition: the app or launchd job with the XPC services " +
+		"and helpers the kernel spawned on its behalf, named after the member with the lowest PID. " +
+		"Coalition rows show the kernel's coalition accounting (coalition_info_resource_usage) for CPU, " +
+		"GPU, energy and disk, which includes members that have exited or whose task info is restricted."},
+	{Name: "Restricted processes", Text: "Without root, task information for other users' processes is " +
+		"not accessible and their usage columns show zero."},
+}
@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Orders --export-process-sort accepts: by CPU usage or resident memory
+var exportProcessOrders = []string{"cpu", "memory"}
+
+// How many processes Prometheus, OTLP and InfluxDB get per-process series
+// for, which ones and how often they are picked again; set from
+// --export-processes, --export-process-sort and --export-process-interval.
+// An interval of zero picks them at every sample.
+var (
+	exportProcessCount    = 50
+	exportProcessOrder    = "cpu"
+	exportProcessInterval time.Duration
+)
+
+// checkExportProcesses validates the --export-process* flags
+func checkExportProcesses(count int, order string, interval time.Duration) error {
+	switch {
+	case count < 0:
+		return fmt.Errorf("--export-processes must not be negative")
+	case !slices.Contains(exportProcessOrders, order):
+		return fmt.Errorf("invalid --export-process-sort %q (want cpu or memory)", order)
+	case interval < 0:
+		return fmt.Errorf("--export-process-interval must not be negative")
+	}
+	return nil
+}
+
+// processPicker keeps the set of exported processes steady between picks,
+// so a process moving in and out of the top N does not start and end a
+// series at every sample. Exports in between get fresh readings of the
+// picked processes that are still running.
+type processPicker struct {
+	mu   sync.Mutex
+	pids []int // Picked processes, in export order
+	at   time.Time
+}
+
+// Picks the processes of every exporter in this run
+var exportPicker processPicker
+
+// pick returns the readings of the exported processes in procs, picking
+// the top count by order again once interval has passed since the last pick
+func (p *processPicker) pick(now time.Time, procs []ProcessStats, count int, order string, interval time.Duration) []ProcessStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if count == 0 {
+		return nil
+	}
+	if p.pids == nil || now.Sub(p.at) >= interval {
+		ranked := slices.Clone(procs)
+		sort.SliceStable(ranked, func(i, j int) bool {
+			if order == "memory" {
+				return ranked[i].RSS > ranked[j].RSS
+			}
+			return ranked[i].CPU > ranked[j].CPU
+		})
+		ranked = ranked[:min(len(ranked), count)]
+		p.pids = make([]int, len(ranked))
+		for i, proc := range ranked {
+			p.pids[i] = proc.PID
+		}
+		p.at = now
+		return ranked
+	}
+	byPID := make(map[int]ProcessStats, len(procs))
+	for _, proc := range procs {
+		byPID[proc.PID] = proc
+	}
+	var picked []ProcessStats
+	for _, pid := range p.pids {
+		if proc, ok := byPID[pid]; ok {
+			picked = append(picked, proc)
+		}
+	}
+	return picked
+}
+
+// exportProcesses replaces a sample's processes with the ones exporters
+// give series to
+func exportProcesses(at time.Time, stats SystemStats) SystemStats {
+	stats.Processes = exportPicker.pick(at, stats.Processes, exportProcessCount, exportProcessOrder, exportProcessInterval)
+	return stats
+}
@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProcessPicker(t *testing.T) {
+	procs := []ProcessStats{
+		{PID: 1, CPU: 5, RSS: 900},
+		{PID: 2, CPU: 80, RSS: 100},
+		{PID: 3, CPU: 40, RSS: 500},
+	}
+	pids := func(procs []ProcessStats) []int {
+		var out []int
+		for _, p := range procs {
+			out = append(out, p.PID)
+		}
+		return out
+	}
+	equal := func(got, want []int) bool {
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	var p processPicker
+	at := time.Unix(1700000000, 0)
+	if got := pids(p.pick(at, procs, 2, "cpu", time.Minute)); !equal(got, []int{2, 3}) {
+		t.Errorf("top 2 by cpu = %v, want [2 3]", got)
+	}
+
+	// Within the interval the same processes are exported with new readings,
+	// leaving out those that exited
+	later := []ProcessStats{{PID: 1, CPU: 99}, {PID: 3, CPU: 1}}
+	got := p.pick(at.Add(30*time.Second), later, 2, "cpu", time.Minute)
+	if !equal(pids(got), []int{3}) || got[0].CPU != 1 {
+		t.Errorf("before the interval = %+v, want PID 3 with CPU 1", got)
+	}
+	if got := pids(p.pick(at.Add(time.Minute), later, 2, "cpu", time.Minute)); !equal(got, []int{1, 3}) {
+		t.Errorf("after the interval = %v, want [1 3]", got)
+	}
+
+	var q processPicker
+	if got := pids(q.pick(at, procs, 1, "memory", 0)); !equal(got, []int{1}) {
+		t.Errorf("top 1 by memory = %v, want [1]", got)
+	}
+	if got := q.pick(at, procs, 0, "cpu", 0); len(got) != 0 {
+		t.Errorf("count 0 exported %d processes", len(got))
+	}
+}
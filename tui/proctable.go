@@ -0,0 +1,514 @@
+package tui
+
+import (
+	"fmt"
+	"os/user"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/khoi/mtop/widgets"
+)
+
+// processColumn describes one column of the process table
+type processColumn struct {
+	ID    string // Name used by --columns
+	Title string
+	Width int  // Minimum width; the flexible column grows to fill the row
+	Right bool // Right-align the values
+	Flex  bool // Takes up the remaining width
+	Value func(p ProcessStats) string
+	// Change returns the metric used to highlight movement between
+	// refreshes, with the threshold worth highlighting
+	Change    func(p ProcessStats) float64
+	Threshold float64
+	// Decorate optionally styles a padded cell, e.g. to flag a value
+	Decorate func(p ProcessStats, cell string) string
+}
+
+// processColumns lists every available column in display order
+var processColumns = []processColumn{
+	{ID: "pid", Title: "PID", Width: 6, Right: true,
+		Value: func(p ProcessStats) string { return strconv.Itoa(p.PID) }},
+	{ID: "ppid", Title: "PPID", Width: 6, Right: true,
+		Value: func(p ProcessStats) string { return strconv.Itoa(p.PPID) }},
+	{ID: "user", Title: "USER", Width: 10,
+		Value: func(p ProcessStats) string { return userName(p.UID) }},
+	{ID: "cpu", Title: "CPU%", Width: 6, Right: true,
+		Value:     func(p ProcessStats) string { return fmt.Sprintf("%.1f", p.CPU) },
+		Change:    func(p ProcessStats) float64 { return p.CPU },
+		Threshold: 5},
+	{ID: "gpu", Title: "GPU%", Width: 6, Right: true,
+		Value:     func(p ProcessStats) string { return fmt.Sprintf("%.1f", p.GPU) },
+		Change:    func(p ProcessStats) float64 { return p.GPU },
+		Threshold: 5},
+	{ID: "time", Title: "TIME", Width: 9, Right: true,
+		Value: func(p ProcessStats) string { return formatCPUTime(p.CPUTime) }},
+	{ID: "mem", Title: "MEM", Width: 9, Right: true,
+		Value:     func(p ProcessStats) string { return formatBytes(p.RSS) },
+		Change:    func(p ProcessStats) float64 { return float64(p.RSS) / (1024 * 1024) },
+		Threshold: 10},
+	{ID: "threads", Title: "THR", Width: 4, Right: true,
+		Value: func(p ProcessStats) string { return strconv.Itoa(p.Threads) }},
+	{ID: "fds", Title: "FD", Width: 5, Right: true,
+		Value:    func(p ProcessStats) string { return strconv.Itoa(p.FDs) },
+		Decorate: decorateFDs},
+	{ID: "ports", Title: "PORTS", Width: 12,
+		Value: func(p ProcessStats) string { return formatPorts(p.Ports) }},
+	{ID: "state", Title: "STATE", Width: 8,
+		Value: func(p ProcessStats) string { return p.State }},
+	{ID: "started", Title: "STARTED", Width: 8,
+		Value: func(p ProcessStats) string { return formatStarted(p.StartTime) }},
+	{ID: "read", Title: "READ", Width: 9, Right: true,
+		Value: func(p ProcessStats) string { return formatBytes(p.DiskRead) }},
+	{ID: "write", Title: "WRITE", Width: 9, Right: true,
+		Value: func(p ProcessStats) string { return formatBytes(p.DiskWritten) }},
+	{ID: "netin", Title: "NET IN", Width: 10, Right: true,
+		Value: func(p ProcessStats) string { return formatBytes(uint64(p.NetIn)) + "/s" }},
+	{ID: "netout", Title: "NET OUT", Width: 10, Right: true,
+		Value: func(p ProcessStats) string { return formatBytes(uint64(p.NetOut)) + "/s" }},
+	{ID: "energy", Title: "ENERGY", Width: 9, Right: true,
+		Value: func(p ProcessStats) string { return fmt.Sprintf("%.1f J", p.Energy) }},
+	{ID: "name", Title: "COMMAND", Width: 10, Flex: true,
+		Value: func(p ProcessStats) string { return p.Name }},
+	{ID: "path", Title: "PATH", Width: 20, Flex: true,
+		Value: func(p ProcessStats) string { return p.Path }},
+}
+
+// Columns shown when none are configured
+var defaultProcessColumns = []string{"pid", "user", "cpu", "mem", "threads", "state", "name"}
+
+// parseColumns validates a comma-separated list of column IDs, keeping
+// their order and dropping repeats
+func parseColumns(s string) ([]string, error) {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range strings.Split(s, ",") {
+		id = strings.TrimSpace(strings.ToLower(id))
+		if id == "" || seen[id] {
+			continue
+		}
+		if findColumn(id) == nil {
+			return nil, fmt.Errorf("unknown column %q", id)
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no columns given")
+	}
+	return ids, nil
+}
+
+func findColumn(id string) *processColumn {
+	for i := range processColumns {
+		if processColumns[i].ID == id {
+			return &processColumns[i]
+		}
+	}
+	return nil
+}
+
+func (m model) columnEnabled(id string) bool {
+	for _, c := range m.columns {
+		if c == id {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleColumn enables a column at the end of the row or disables it,
+// keeping the order of the others
+func (m *model) toggleColumn(id string) {
+	var cols []string
+	for _, c := range m.columns {
+		if c != id {
+			cols = append(cols, c)
+		}
+	}
+	if !m.columnEnabled(id) {
+		cols = append(cols, id)
+	}
+	// Always keep at least one column
+	if len(cols) > 0 {
+		m.columns = cols
+	}
+}
+
+// layoutColumns returns the enabled columns, in their order, with the
+// widths they get in a row of the given width. The first column stays frozen while the rest are
+// scrolled horizontally by scroll columns. Fixed columns that don't fit are
+// dropped from the right; flexible columns share whatever space is left.
+// It also reports how many columns are hidden on either side.
+func layoutColumns(ids []string, width, scroll int) (cols []processColumn, widths []int, hiddenLeft, hiddenRight int) {
+	for _, id := range ids {
+		if c := findColumn(id); c != nil {
+			cols = append(cols, *c)
+		}
+	}
+
+	if scroll > len(cols)-2 {
+		scroll = len(cols) - 2
+	}
+	if scroll > 0 {
+		cols = append(cols[:1:1], cols[1+scroll:]...)
+		hiddenLeft = scroll
+	}
+
+	for len(cols) > 0 {
+		used, flex := 0, 0
+		for _, c := range cols {
+			used += c.Width + 1
+			if c.Flex {
+				flex++
+			}
+		}
+		if used-1 <= width || len(cols) == 1 {
+			widths = make([]int, len(cols))
+			spare := width - (used - 1)
+			for i, c := range cols {
+				widths[i] = c.Width
+				if c.Flex && flex > 0 && spare > 0 {
+					extra := spare / flex
+					widths[i] += extra
+					spare -= extra
+					flex--
+				}
+			}
+			return cols, widths, hiddenLeft, hiddenRight
+		}
+		cols = cols[:len(cols)-1]
+		hiddenRight++
+	}
+	return nil, nil, hiddenLeft, hiddenRight
+}
+
+// sortedProcesses returns the table rows ordered by CPU usage, busiest first
+func (m model) sortedProcesses() []ProcessStats {
+	rows := m.tableProcesses(m.stats)
+	procs := make([]ProcessStats, len(rows))
+	copy(procs, rows)
+	sort.SliceStable(procs, func(i, j int) bool {
+		if procs[i].CPU == procs[j].CPU {
+			return procs[i].PID < procs[j].PID
+		}
+		return procs[i].CPU > procs[j].CPU
+	})
+	return procs
+}
+
+// selectedProcess returns the process under the cursor
+func (m model) selectedProcess() (ProcessStats, bool) {
+	procs := m.sortedProcesses()
+	if m.procCursor < 0 || m.procCursor >= len(procs) {
+		return ProcessStats{}, false
+	}
+	return procs[m.procCursor], true
+}
+
+// keepSelection puts the cursor back on the process selected before a
+// refresh re-sorted the table, found by PID and start time so a reused PID
+// is not taken for it. If it exited, the cursor stays within the rows.
+func (m *model) keepSelection(prev ProcessStats, selected bool) {
+	procs := m.sortedProcesses()
+	if selected {
+		for i, p := range procs {
+			if p.PID == prev.PID && p.StartTime.Equal(prev.StartTime) {
+				m.procCursor = i
+				m.scrollToCursor()
+				return
+			}
+		}
+	}
+	if m.procCursor >= len(procs) && m.procCursor > 0 {
+		m.procCursor = len(procs) - 1
+	}
+}
+
+// processRows is the number of table rows that fit on screen
+func (m model) processRows() int {
+	height := m.height
+	if m.split {
+		height = m.focusedPaneHeight()
+	}
+	rows := height - 10
+	if rows < 5 {
+		rows = 5
+	}
+	return rows
+}
+
+// Selected row style, set by applyTheme
+var selectedRowStyle lipgloss.Style
+
+func (m model) renderProcesses() string {
+	if m.columnPicker {
+		return m.renderColumnPicker()
+	}
+
+	procs := m.sortedProcesses()
+	cols, widths, hiddenLeft, hiddenRight := layoutColumns(m.columns, m.width, m.procScroll)
+
+	prevRows := m.tableProcesses(m.prevStats)
+	prev := make(map[int]ProcessStats, len(prevRows))
+	for _, p := range prevRows {
+		prev[p.PID] = p
+	}
+
+	var b strings.Builder
+	if m.searching {
+		fmt.Fprintf(&b, "Search: %s%s | enter: Keep | esc: Cancel", m.search, glyphs.cursor)
+	} else {
+		fmt.Fprintf(&b, "%d %s, sorted by CPU | %s/%s: Select | %s/%s: Scroll | %s: Columns | %s: Group | %s/%s: Copy PID/command | %s: Finder | %s: Terminal | %s: Search",
+			len(procs), groupingNouns[m.procGroup], keyFor("up"), keyFor("down"), keyFor("left"), keyFor("right"), keyFor("columns"),
+			keyFor("group"), keyFor("copy_pid"), keyFor("copy_command"), keyFor("reveal"), keyFor("terminal"), keyFor("search"))
+	}
+	if hiddenLeft > 0 {
+		fmt.Fprintf(&b, " | %s %d more", glyphs.scrollLeft, hiddenLeft)
+	}
+	if hiddenRight > 0 {
+		fmt.Fprintf(&b, " | %d more %s", hiddenRight, glyphs.scrollRight)
+	}
+	b.WriteString("\n\n")
+
+	// The header is rendered on every frame above the scrolled rows, so it
+	// stays in place however far the table is scrolled
+	cells := make([]string, len(cols))
+	for i, c := range cols {
+		cells[i] = widgets.Pad(c.Title, widths[i], c.Right)
+	}
+	b.WriteString(widgets.HeaderStyle.Render(strings.Join(cells, " ")) + "\n")
+
+	end := m.procOffset + m.processRows()
+	if end > len(procs) {
+		end = len(procs)
+	}
+	for row := m.procOffset; row < end; row++ {
+		p := procs[row]
+		old, seen := prev[p.PID]
+		for i, c := range cols {
+			cells[i] = widgets.Pad(c.Value(p), widths[i], c.Right)
+			if c.Decorate != nil {
+				cells[i] = c.Decorate(p, cells[i])
+			}
+			if m.hasPrev && seen && c.Change != nil {
+				if diff := c.Change(p) - c.Change(old); diff >= c.Threshold || diff <= -c.Threshold {
+					cells[i] = changedStyle.Render(cells[i])
+				}
+			}
+		}
+		line := strings.Join(cells, " ")
+		if row == m.procCursor {
+			line = selectedRowStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+
+	return b.String()
+}
+
+func (m model) renderColumnPicker() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Choose columns | %s/%s: Move | %s: Toggle | esc/%s: Done\n\n",
+		keyFor("up"), keyFor("down"), keyFor("select"), keyFor("columns"))
+	for i, c := range processColumns {
+		mark := "[ ]"
+		if m.columnEnabled(c.ID) {
+			mark = "[x]"
+		}
+		line := fmt.Sprintf("%s %-8s %s", mark, c.ID, c.Title)
+		if i == m.pickerCursor {
+			line = selectedRowStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// updateProcessKeys handles keys specific to the process view. It reports
+// whether the key was consumed.
+func (m model) updateProcessKeys(key, action string) (model, bool) {
+	if m.columnPicker {
+		if key == "esc" {
+			action = "columns"
+		}
+		switch action {
+		case "up":
+			if m.pickerCursor > 0 {
+				m.pickerCursor--
+			}
+		case "down":
+			if m.pickerCursor < len(processColumns)-1 {
+				m.pickerCursor++
+			}
+		case "select":
+			m.toggleColumn(processColumns[m.pickerCursor].ID)
+		case "columns":
+			m.columnPicker = false
+		default:
+			return m, false
+		}
+		return m, true
+	}
+
+	last := len(m.tableProcesses(m.stats)) - 1
+	switch action {
+	case "up":
+		if m.procCursor > 0 {
+			m.procCursor--
+		}
+	case "down":
+		if m.procCursor < last {
+			m.procCursor++
+		}
+	case "page_up":
+		m.procCursor = max(m.procCursor-m.processRows(), 0)
+	case "page_down":
+		m.procCursor = max(min(m.procCursor+m.processRows(), last), 0)
+	case "top":
+		m.procCursor = 0
+	case "bottom":
+		m.procCursor = max(last, 0)
+	case "left":
+		if m.procScroll > 0 {
+			m.procScroll--
+		}
+	case "right":
+		if m.procScroll < len(m.columns)-2 {
+			m.procScroll++
+		}
+	case "search":
+		m.searching, m.search, m.searchFrom = true, "", m.procCursor
+		return m, true
+	case "search_next":
+		m.findProcess(m.procCursor+1, 1)
+	case "search_prev":
+		m.findProcess(m.procCursor-1, -1)
+	case "columns":
+		m.columnPicker = true
+		return m, true
+	case "group":
+		m.cycleGrouping()
+		return m, true
+	case "copy_pid":
+		m.copySelectedPID()
+		return m, true
+	case "copy_command":
+		m.copySelectedCommand()
+		return m, true
+	case "reveal":
+		m.revealSelectedInFinder()
+		return m, true
+	case "terminal":
+		m.openSelectedInTerminal()
+		return m, true
+	default:
+		return m, false
+	}
+
+	m.scrollToCursor()
+	return m, true
+}
+
+// scrollToCursor keeps the cursor within the visible window
+func (m *model) scrollToCursor() {
+	if m.procCursor < m.procOffset {
+		m.procOffset = m.procCursor
+	}
+	if rows := m.processRows(); m.procCursor >= m.procOffset+rows {
+		m.procOffset = m.procCursor - rows + 1
+	}
+}
+
+// updateSearchKeys edits the process search prompt. The cursor jumps to
+// the first match as the text changes; esc puts it back.
+func (m model) updateSearchKeys(msg tea.KeyMsg) model {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.searching = false
+	case tea.KeyEsc:
+		m.searching, m.search = false, ""
+		m.procCursor = m.searchFrom
+	case tea.KeyBackspace:
+		if r := []rune(m.search); len(r) > 0 {
+			m.search = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.search += string(msg.Runes)
+	default:
+		return m
+	}
+	if m.searching {
+		m.procCursor = m.searchFrom
+		m.findProcess(m.searchFrom, 1)
+	}
+	m.scrollToCursor()
+	return m
+}
+
+// findProcess moves the cursor to the nearest process from index from,
+// stepping by dir and wrapping around, whose name or path contains the
+// search text, ignoring case
+func (m *model) findProcess(from, dir int) {
+	if m.search == "" {
+		return
+	}
+	procs := m.sortedProcesses()
+	query := strings.ToLower(m.search)
+	for i := range procs {
+		j := ((from+i*dir)%len(procs) + len(procs)) % len(procs)
+		if strings.Contains(strings.ToLower(procs[j].Name), query) ||
+			strings.Contains(strings.ToLower(procs[j].Path), query) {
+			m.procCursor = j
+			return
+		}
+	}
+	m.setStatus(fmt.Sprintf("No process matches %q", m.search))
+}
+
+// formatPorts lists ports separated by commas
+func formatPorts(ports []int) string {
+	parts := make([]string, len(ports))
+	for i, port := range ports {
+		parts[i] = strconv.Itoa(port)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Cache of uid to user name lookups
+var userNames = make(map[uint32]string)
+
+func userName(uid uint32) string {
+	if name, ok := userNames[uid]; ok {
+		return name
+	}
+	name := strconv.FormatUint(uint64(uid), 10)
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+	userNames[uid] = name
+	return name
+}
+
+// formatCPUTime renders CPU time like top: minutes:seconds.hundredths
+func formatCPUTime(d time.Duration) string {
+	minutes := int(d.Minutes())
+	seconds := d.Seconds() - float64(minutes*60)
+	return fmt.Sprintf("%d:%05.2f", minutes, seconds)
+}
+
+// formatStarted shows the time for processes started today, else the date
+func formatStarted(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	now := time.Now()
+	if t.YearDay() == now.YearDay() && t.Year() == now.Year() {
+		return t.Format("15:04:05")
+	}
+	return t.Format("Jan 02")
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectionFollowsProcess(t *testing.T) {
+	m := model{viewMode: ProcessMode, height: 40, stats: fixtureStats()}
+	m.procCursor = 2
+	selected, ok := m.selectedProcess()
+	if !ok {
+		t.Fatal("no process under the cursor")
+	}
+
+	// The refresh sorts the selected process to the top
+	next := fixtureStats()
+	for i := range next.Processes {
+		if next.Processes[i].PID == selected.PID {
+			next.Processes[i].CPU = 400
+		}
+	}
+	m.stats = next
+	m.keepSelection(selected, ok)
+	if p, _ := m.selectedProcess(); p.PID != selected.PID || m.procCursor != 0 {
+		t.Errorf("after the refresh the cursor is on row %d, PID %d; want row 0, PID %d", m.procCursor, p.PID, selected.PID)
+	}
+
+	// A new process reusing the PID is not the one selected
+	for i := range next.Processes {
+		if next.Processes[i].PID == selected.PID {
+			next.Processes[i].StartTime = selected.StartTime.Add(1)
+		}
+	}
+	m.stats, m.procCursor = next, 1
+	m.keepSelection(selected, ok)
+	if m.procCursor != 1 {
+		t.Errorf("cursor moved to row %d for a reused PID", m.procCursor)
+	}
+}
+
+func TestColumnOrder(t *testing.T) {
+	ids, err := parseColumns("name, cpu,pid,cpu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"name", "cpu", "pid"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("parseColumns = %q, want %q", ids, want)
+	}
+	cols, _, _, _ := layoutColumns(ids, 200, 0)
+	var got []string
+	for _, c := range cols {
+		got = append(got, c.ID)
+	}
+	if !reflect.DeepEqual(got, ids) {
+		t.Errorf("layout order = %q, want %q", got, ids)
+	}
+
+	m := model{columns: ids}
+	m.toggleColumn("cpu")
+	m.toggleColumn("mem")
+	if want := []string{"name", "pid", "mem"}; !reflect.DeepEqual(m.columns, want) {
+		t.Errorf("after toggling cpu and mem: %q, want %q", m.columns, want)
+	}
+}
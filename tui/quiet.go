@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// Darwin setpriority arguments from sys/resource.h, which x/sys does not
+// define. PRIO_DARWIN_BG puts the whole process under the background
+// policy, as taskpolicy -b does: lowest CPU priority, efficiency cores
+// and throttled disk I/O.
+const (
+	prioDarwinProcess = 4
+	prioDarwinBG      = 0x1000
+)
+
+// quietMode keeps mtop from perturbing the benchmarks it watches; set with
+// --quiet
+var quietMode bool
+
+// Collectors quiet mode turns off: the on-demand ones, which spawn nettop,
+// walk the I/O registry or scan every socket and coalition, and the Wi-Fi
+// query
+var quietCollectors = []string{"process_gpu", "process_net", "process_ports", "sockets", "coalitions", "wifi"}
+
+// enterQuietMode moves mtop to the background policy and drops the work
+// it can do without: the costlier collectors, per-process open file counts
+// and notifications, which each start osascript
+func enterQuietMode() error {
+	quietMode = true
+	collector.SkipFDs = true
+	for _, name := range quietCollectors {
+		disabledCollectors[name] = true
+	}
+	notifyAfter = 0
+	if err := unix.Setpriority(prioDarwinProcess, 0, prioDarwinBG); err != nil {
+		return fmt.Errorf("failed to lower priority: %w", err)
+	}
+	return nil
+}
+
+// quietNote marks the refresh rate in the header in quiet mode
+func (m model) quietNote() string {
+	if !quietMode {
+		return ""
+	}
+	return " (quiet)"
+}
@@ -0,0 +1,181 @@
+package tui
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Sessions written by mtop record are the import format JSON Lines that
+// --json --stream prints, gzip-compressed. Everything that reads
+// recordings (--replay, --compare, mtop import) goes through
+// openRecording, so it takes either form.
+
+// openRecording opens a recording, decompressing it when it is gzipped
+func openRecording(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(f)
+	if magic, _ := r.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{gz, f}, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, f}, nil
+}
+
+// runRecord implements "mtop record": it writes a sample every interval
+// to a session file until interrupted or the duration is up
+func runRecord(args []string) int {
+	fs := flag.NewFlagSet("record", flag.ExitOnError)
+	out := fs.String("o", "", "Session file to write, e.g. session.mtop")
+	interval := intervalFlag(time.Second)
+	fs.Var(&interval, "interval", "Time between samples, as a duration (500ms) or seconds (2)")
+	fs.Var(&interval, "d", "Shorthand for --interval")
+	duration := fs.Duration("duration", 0, "Stop after this long (default: when interrupted)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s record -o FILE [OPTIONS]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Records full samples, with every collector running, to a compressed session\n")
+		fmt.Fprintf(os.Stderr, "file for %s replay FILE. Lines typed while recording are saved as notes\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "on the timeline. Stop with ctrl+c.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *out == "" || fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+	if *duration < 0 {
+		fmt.Fprintf(os.Stderr, "Invalid --duration: must not be negative\n")
+		return 2
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+	fmt.Fprintf(os.Stderr, "Recording to %s every %v; type a line to add a note, ctrl+c stops\n", *out, time.Duration(interval))
+	n, err := recordSession(ctx, f, time.Duration(interval), readNotes(os.Stdin))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error recording %s: %v\n", *out, err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Recorded %d samples to %s\n", n, *out)
+	return 0
+}
+
+// readNotes sends each non-blank line of r as a note until r ends
+func readNotes(r io.Reader) <-chan string {
+	notes := make(chan string)
+	go func() {
+		defer close(notes)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			if note := strings.TrimSpace(scanner.Text()); note != "" {
+				notes <- note
+			}
+		}
+	}()
+	return notes
+}
+
+// recordSession writes gzipped samples, and the notes received meanwhile,
+// to w until ctx is done, flushing each line so a session cut short by a
+// crash is still readable up to it. The first sample only primes the
+// rate-based collectors and is dropped.
+func recordSession(ctx context.Context, w io.Writer, interval time.Duration, notes <-chan string) (int, error) {
+	samples, err := streamStats(ctx, interval, withCollectors(onDemandCollectors...))
+	if err != nil {
+		return 0, err
+	}
+	gz := gzip.NewWriter(w)
+	write := func(line []byte) error {
+		if _, err := gz.Write(line); err != nil {
+			return err
+		}
+		return gz.Flush()
+	}
+	n, primed := 0, false
+	for {
+		select {
+		case note, ok := <-notes:
+			if !ok {
+				notes = nil
+				continue
+			}
+			at := time.Now()
+			line, err := json.Marshal(importRecord{Time: at, Host: hostname, Note: note})
+			if err == nil {
+				err = write(append(line, '\n'))
+			}
+			if err != nil {
+				return n, err
+			}
+			fmt.Fprintf(os.Stderr, "Noted at %s\n", at.Format("15:04:05"))
+		case stats, ok := <-samples:
+			if !ok {
+				return n, gz.Close()
+			}
+			if !primed {
+				primed = true
+				continue
+			}
+			line, err := formatSampleLine(time.Now(), stats)
+			if err == nil {
+				err = write([]byte(line))
+			}
+			if err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+}
+
+// recordedInterval is the usual time between a recording's samples, the
+// median gap, kept within the refresh rates the TUI supports. Replaying a
+// sample per tick at this rate plays the session at its original pace.
+func recordedInterval(shots []snapshot) time.Duration {
+	if len(shots) < 2 {
+		return time.Second
+	}
+	gaps := make([]time.Duration, len(shots)-1)
+	for i := range gaps {
+		gaps[i] = shots[i+1].at.Sub(shots[i].at)
+	}
+	slices.Sort(gaps)
+	return min(max(gaps[len(gaps)/2], minRefreshRate), maxRefreshRate)
+}
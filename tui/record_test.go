@@ -0,0 +1,42 @@
+package tui
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReplayReadsGzippedSession(t *testing.T) {
+	start := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "session.mtop")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	for _, gap := range []time.Duration{0, 2 * time.Second, 4 * time.Second, 5 * time.Second} {
+		line, err := formatSampleLine(start.Add(gap), fixtureStats())
+		if err != nil {
+			t.Fatal(err)
+		}
+		gz.Write([]byte(line))
+	}
+	gz.Close()
+	f.Close()
+
+	shots, _, err := readReplay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shots) != 4 || !shots[3].at.Equal(start.Add(5*time.Second)) {
+		t.Fatalf("read %d samples, last at %v", len(shots), shots[len(shots)-1].at)
+	}
+	if got := recordedInterval(shots); got != 2*time.Second {
+		t.Errorf("recorded interval = %v, want the median gap of 2s", got)
+	}
+	if got := recordedInterval(shots[:1]); got != time.Second {
+		t.Errorf("interval of a single sample = %v, want the default", got)
+	}
+}
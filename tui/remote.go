@@ -0,0 +1,325 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/khoi/mtop/history"
+)
+
+// How long --remote waits for the first sample, including SSH logging in
+const remoteConnectTimeout = 30 * time.Second
+
+// runFeed implements "mtop feed", which --remote runs over SSH: it prints
+// a sample per interval as import format JSON lines until stdout closes.
+// Samples come from the agent on this machine when one is running, with
+// its history sent first; otherwise the feed collects them itself.
+func runFeed(args []string) int {
+	fs := flag.NewFlagSet("feed", flag.ExitOnError)
+	interval := intervalFlag(time.Second)
+	fs.Var(&interval, "interval", "Time between samples, as a duration (500ms) or seconds (2)")
+	window := fs.Duration("history", 0, "Send this much of a running agent's history before the samples")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s feed [OPTIONS]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Prints samples for mtop --remote on another Mac, which runs it over SSH.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if err := checkRefreshRate(time.Duration(interval)); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --interval: %v\n", err)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
+	if err := feedSamples(ctx, os.Stdout, time.Duration(interval), *window); err != nil && ctx.Err() == nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// feedSamples writes samples to w every interval until ctx is done or a
+// write fails. A running agent's samples are relayed, preceded by window
+// of its history; if there is no agent, or it stops, they are collected
+// here with every collector running.
+func feedSamples(ctx context.Context, w io.Writer, interval, window time.Duration) error {
+	agent, err := attachAgent(sampleRingPath())
+	if err == nil {
+		defer func() {
+			if agent != nil {
+				agent.close()
+			}
+		}()
+		if window > 0 {
+			store := history.New(window, minRefreshRate)
+			if fetchAgentHistory(agentSocketPath(), store, time.Now().Add(-window)) == nil {
+				if err := writeFeedHistory(w, store); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var samples <-chan SystemStats
+	for {
+		var stats SystemStats
+		if agent != nil {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return nil
+			}
+			if stats, err = agent.latest(); err != nil {
+				agent.close()
+				agent = nil
+				continue
+			}
+		} else {
+			if samples == nil {
+				if samples, err = streamStats(ctx, interval, withCollectors(onDemandCollectors...)); err != nil {
+					return err
+				}
+				// The first sample only primes the rate-based collectors
+				if _, ok := <-samples; !ok {
+					return nil
+				}
+			}
+			var ok bool
+			if stats, ok = <-samples; !ok {
+				return nil
+			}
+		}
+		line, err := formatSampleLine(time.Now(), stats)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+}
+
+// writeFeedHistory writes the samples of store as import format lines of
+// named metrics, one per sample time, oldest first
+func writeFeedHistory(w io.Writer, store *history.Store) error {
+	byTime := make(map[int64]map[string]float64)
+	for _, name := range store.Names() {
+		for _, s := range store.Samples(name) {
+			at := s.Time.UnixNano()
+			if byTime[at] == nil {
+				byTime[at] = make(map[string]float64)
+			}
+			byTime[at][name] = s.Value
+		}
+	}
+	times := make([]int64, 0, len(byTime))
+	for at := range byTime {
+		times = append(times, at)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	bw := bufio.NewWriter(w)
+	for _, at := range times {
+		line, err := json.Marshal(importRecord{Time: time.Unix(0, at), Host: hostname, Metrics: byTime[at]})
+		if err != nil {
+			return err
+		}
+		bw.Write(append(line, '\n'))
+	}
+	return bw.Flush()
+}
+
+// remoteFeed relays the samples of mtop feed running on another Mac, read
+// from the command that reaches it, normally ssh. It is the Provider of a
+// model started with --remote.
+type remoteFeed struct {
+	target string // user@host as given
+	cmd    *exec.Cmd
+	stale  time.Duration // Age after which the newest sample is an error
+
+	mu      sync.Mutex
+	stats   SystemStats
+	at      time.Time // When the newest sample arrived
+	host    string    // Hostname the remote reports
+	backlog map[string][]history.Sample
+	err     error // Why the feed ended
+
+	ready chan struct{} // Closed at the first sample
+	done  chan struct{} // Closed when the command has exited
+}
+
+// startRemote runs mtop feed on target over SSH, with command naming mtop
+// there, and waits for its first sample. SSH can still ask for a password
+// or host key on the terminal, as the TUI has not started yet.
+func startRemote(target, command string, interval time.Duration) (*remoteFeed, error) {
+	cmd := exec.Command("ssh", remoteArgs(target, command, interval, historyRetention)...)
+	return startFeed(cmd, target, interval)
+}
+
+// remoteArgs are the ssh arguments running mtop feed on target
+func remoteArgs(target, command string, interval, window time.Duration) []string {
+	return []string{
+		"-T", "-o", "ConnectTimeout=10", "-o", "ServerAliveInterval=10",
+		target, command, "feed", "--interval", interval.String(), "--history", window.String(),
+	}
+}
+
+// startFeed starts cmd and reads its samples until it exits
+func startFeed(cmd *exec.Cmd, target string, interval time.Duration) (*remoteFeed, error) {
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	r := &remoteFeed{
+		target:  target,
+		cmd:     cmd,
+		stale:   max(agentStale, 3*interval),
+		backlog: make(map[string][]history.Sample),
+		ready:   make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go r.read(out, &stderr)
+	select {
+	case <-r.ready:
+		return r, nil
+	case <-r.done:
+		return nil, r.err
+	case <-time.After(remoteConnectTimeout):
+		r.close()
+		return nil, fmt.Errorf("no sample from %s within %v", target, remoteConnectTimeout)
+	}
+}
+
+// read keeps the newest sample from out, and the history sent before the
+// first, until the command exits or sends a line that is not a sample
+func (r *remoteFeed) read(out io.Reader, stderr *bytes.Buffer) {
+	defer close(r.done)
+	err := r.scan(out)
+	if err != nil {
+		r.cmd.Process.Kill()
+	}
+	werr := r.cmd.Wait()
+	if err == nil {
+		err = werr
+	}
+	if err == nil {
+		err = errors.New("feed ended")
+	}
+	// Whatever went wrong there, such as mtop missing from the PATH, is
+	// in the last line of its errors
+	if lines := strings.Split(strings.TrimSpace(stderr.String()), "\n"); lines[len(lines)-1] != "" {
+		err = fmt.Errorf("%w: %s", err, lines[len(lines)-1])
+	}
+	r.mu.Lock()
+	r.err = fmt.Errorf("connection to %s ended: %w", r.target, err)
+	r.mu.Unlock()
+}
+
+func (r *remoteFeed) scan(out io.Reader) error {
+	var once sync.Once
+	scanner := bufio.NewScanner(out)
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		var rec importRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return fmt.Errorf("unexpected output %.60q", scanner.Text())
+		}
+		r.mu.Lock()
+		if rec.Host != "" {
+			r.host = rec.Host
+		}
+		switch {
+		case rec.Stats != nil:
+			r.stats, r.at = *rec.Stats, time.Now()
+		case r.at.IsZero():
+			for name, v := range rec.Metrics {
+				r.backlog[name] = append(r.backlog[name], history.Sample{Time: rec.Time, Value: v})
+			}
+		}
+		r.mu.Unlock()
+		if rec.Stats != nil {
+			once.Do(func() { close(r.ready) })
+		}
+	}
+	return scanner.Err()
+}
+
+// Sample returns the newest sample, or why there is none: the connection
+// ended or the remote stopped sending
+func (r *remoteFeed) Sample(now time.Time) (SystemStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return SystemStats{}, r.err
+	}
+	if age := time.Since(r.at); age > r.stale {
+		return SystemStats{}, fmt.Errorf("no sample from %s for %v", r.target, age.Round(time.Second))
+	}
+	return r.stats, nil
+}
+
+// hostname is the name the remote reports, or the target until it has
+func (r *remoteFeed) hostname() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.host != "" {
+		return r.host
+	}
+	return r.target
+}
+
+// close stops the command reaching the remote
+func (r *remoteFeed) close() {
+	r.cmd.Process.Kill()
+	<-r.done
+}
+
+// withRemote shows the samples of a remote feed instead of this Mac's
+func withRemote(r *remoteFeed) Option {
+	return func(m *model) error {
+		m.remote = r
+		m.provider = r
+		return nil
+	}
+}
+
+// restoreRemoteHistory starts the model's history with the history the
+// remote's agent sent. Its times are the remote's clock.
+func (m *model) restoreRemoteHistory() {
+	m.remote.mu.Lock()
+	defer m.remote.mu.Unlock()
+	for _, name := range sortedKeys(m.remote.backlog) {
+		m.history.Merge(name, m.remote.backlog[name])
+	}
+	m.remote.backlog = nil
+}
+
+// remoteNote starts the header with the host whose samples are shown
+func (m model) remoteNote() string {
+	if m.remote == nil {
+		return ""
+	}
+	return titleStyle.Render(m.remote.hostname()) + " | "
+}
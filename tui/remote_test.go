@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khoi/mtop/history"
+)
+
+func TestRemoteFeed(t *testing.T) {
+	at := time.Now().Add(-time.Minute).Round(0)
+	store := history.New(time.Hour, time.Second)
+	store.Record(at, map[string]float64{metricCPU: 10, metricGPU: 5})
+	store.Record(at.Add(time.Second), map[string]float64{metricCPU: 20, metricGPU: 6})
+	var b strings.Builder
+	if err := writeFeedHistory(&b, store); err != nil {
+		t.Fatal(err)
+	}
+	stats := fixtureStats()
+	line, _ := formatSampleLine(at.Add(2*time.Second), stats)
+	b.WriteString(strings.Replace(line, `"host":"`+hostname+`"`, `"host":"build-1"`, 1))
+
+	// The feed stays up after its first sample, like ssh would
+	script := "cat <<'EOF'\n" + b.String() + "EOF\nexec sleep 5"
+	feed, err := startFeed(exec.Command("sh", "-c", script), "me@build-1.local", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer feed.close()
+	got, err := feed.Sample(time.Now())
+	if err != nil || got.CPU.Usage != stats.CPU.Usage {
+		t.Errorf("sample: cpu %v, %v", got.CPU.Usage, err)
+	}
+
+	m, err := NewModel(withRemote(feed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.restoreRemoteHistory()
+	if cpu := m.history.Values(metricCPU); len(cpu) != 3 || cpu[0] != 10 || cpu[1] != 20 {
+		t.Errorf("history = %v, want the remote's two samples before the live one", cpu)
+	}
+	if header := m.View(); !strings.Contains(header, "build-1 | ") {
+		t.Errorf("header does not name the remote host:\n%s", header)
+	}
+	m.viewMode = ProcessMode
+	m.revealSelectedInFinder()
+	if !strings.Contains(m.statusMsg, "runs on build-1, not this Mac") {
+		t.Errorf("status = %q", m.statusMsg)
+	}
+}
+
+func TestRemoteFeedFails(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "echo 'zsh:1: command not found: mtop' >&2; exit 127")
+	if _, err := startFeed(cmd, "me@build-1", time.Second); err == nil || !strings.Contains(err.Error(), "command not found: mtop") {
+		t.Errorf("err = %v, want the remote's own error", err)
+	}
+	cmd = exec.Command("sh", "-c", "echo 'Welcome to build-1'; exec sleep 5")
+	if _, err := startFeed(cmd, "me@build-1", time.Second); err == nil || !strings.Contains(err.Error(), `unexpected output "Welcome to build-1"`) {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package tui
+
+import (
+	"time"
+)
+
+// Render draws one full frame of a view for the given stats and terminal
+// size, without a running program or live collectors. Histories and the
+// flame graph session are seeded from stats alone, so the output depends
+// only on the arguments.
+func Render(stats SystemStats, width, height int, mode ViewMode) string {
+	m := model{
+		stats:       stats,
+		viewMode:    mode,
+		refreshRate: time.Second,
+		width:       width,
+		height:      height,
+		session:     &cpuSession{procs: make(map[procKey]*sessionProc)},
+		history:     newHistory(),
+		columns:     defaultProcessColumns,
+	}
+	m.session.update(stats.Processes)
+	m.recordHistory(time.Time{}, stats)
+	return m.View()
+}
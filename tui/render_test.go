@@ -0,0 +1,201 @@
+package tui
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files in testdata/golden")
+
+func TestMain(m *testing.M) {
+	// Golden frames are compared as plain text
+	lipgloss.SetColorProfile(termenv.Ascii)
+	os.Exit(m.Run())
+}
+
+// Views covered by the golden tests and their file name prefixes
+var goldenViews = []struct {
+	mode ViewMode
+	name string
+}{
+	{OverviewMode, "overview"},
+	{CPUDetailMode, "cpu"},
+	{MemoryDetailMode, "memory"},
+	{GPUDetailMode, "gpu"},
+	{FlameMode, "flame"},
+	{MemoryTreemapMode, "treemap"},
+	{ProcessMode, "processes"},
+	{PowerMode, "power"},
+	{NetworkMode, "network"},
+}
+
+// Terminal sizes each view is rendered at
+var goldenSizes = []struct{ width, height int }{
+	{80, 24},
+	{120, 40},
+	{60, 16},
+}
+
+func TestRenderGolden(t *testing.T) {
+	stats := fixtureStats()
+	for _, v := range goldenViews {
+		for _, size := range goldenSizes {
+			name := fmt.Sprintf("%s_%dx%d", v.name, size.width, size.height)
+			t.Run(name, func(t *testing.T) {
+				checkGolden(t, name, Render(stats, size.width, size.height, v.mode))
+			})
+		}
+	}
+}
+
+// checkGolden compares a rendered frame with testdata/golden/<name>.txt,
+// rewriting the file instead when -update is set
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name+".txt")
+
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("missing golden file, run go test -update: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from the golden file; if the change is intended, run go test -update\n--- got ---\n%s\n--- want ---\n%s",
+			path, got, want)
+	}
+}
+
+// fixtureStats is a fixed snapshot of a busy machine used by the golden tests
+func fixtureStats() SystemStats {
+	started := time.Date(2024, time.March, 2, 9, 30, 0, 0, time.UTC)
+	return SystemStats{
+		CPU: CPUStats{
+			Usage:   37.5,
+			Cores:   []float64{82.0, 64.5, 12.0, 3.5, 55.0, 21.0, 7.5, 0.0},
+			LoadAvg: [3]float64{3.12, 2.48, 1.97},
+			Temp:    58.0,
+			Clusters: []ClusterStats{
+				{Name: "ECPU", Frequency: 1284, Active: 42.0},
+				{Name: "PCPU", Frequency: 3228, Active: 71.5},
+			},
+			CoreFreqs: []float64{1020, 1284, 972, 1404, 3228, 3504, 2424, 600},
+		},
+		Memory: MemoryStats{
+			Total:      16 << 30,
+			Used:       11 << 30,
+			Available:  5 << 30,
+			Usage:      68.75,
+			Pressure:   PressureWarn,
+			Accounting: AccountingDefault,
+			Swap:       SwapStats{Total: 2 << 30, Used: 512 << 20, Usage: 25.0},
+			Raw: MemoryCounters{
+				PageSize:    16384,
+				TotalPages:  1048576,
+				Free:        52000,
+				Active:      310000,
+				Inactive:    298000,
+				Wired:       180000,
+				Speculative: 12000,
+				Compressed:  96000,
+				Purgeable:   4000,
+				External:    150000,
+				Internal:    420000,
+			},
+		},
+		GPU: GPUStats{
+			Usage:       23.0,
+			MemoryUsage: 12.5,
+			MemoryUsed:  2 << 30,
+			MemoryTotal: 16 << 30,
+			Temp:        51.0,
+		},
+		Uptime:  52 * time.Hour,
+		Thermal: ThermalFair,
+		Power:   PowerStats{CPU: 4.25, GPU: 1.5, ANE: 0.0, DRAM: 0.75, Package: 5.75},
+		Network: NetworkStats{
+			WiFi: &WiFiStats{
+				Interface:    "en0",
+				SSID:         "studio",
+				BSSID:        "a4:83:e7:12:34:56",
+				PowerOn:      true,
+				RSSI:         -54,
+				Noise:        -92,
+				Channel:      149,
+				Band:         "5 GHz",
+				ChannelWidth: 80,
+				PHYMode:      "802.11ax",
+				TxRate:       1200,
+			},
+			Sockets: SocketStats{
+				TCP:      map[string]int{"ESTABLISHED": 42, "LISTEN": 9, "TIME_WAIT": 3},
+				TCPTotal: 54,
+				UDP:      17,
+			},
+		},
+		Processes: []ProcessStats{
+			{PID: 1, PPID: 0, Name: "launchd", Path: "/sbin/launchd", CPU: 0.4,
+				CPUTime: 95 * time.Second, RSS: 24 << 20, Threads: 4, FDs: 180, State: "sleeping", StartTime: started},
+			{PID: 412, PPID: 1, Name: "WindowServer",
+				Path: "/System/Library/PrivateFrameworks/SkyLight.framework/Resources/WindowServer",
+				CPU:  18.2, CPUTime: 40 * time.Minute, RSS: 410 << 20, Threads: 23, FDs: 1200, State: "running",
+				StartTime: started, GPU: 14.5},
+			{PID: 2201, PPID: 1, Name: "Safari", Path: "/Applications/Safari.app/Contents/MacOS/Safari",
+				CPU: 9.8, CPUTime: 12 * time.Minute, RSS: 820 << 20, Threads: 31, FDs: 340, State: "sleeping",
+				StartTime: started, DiskRead: 180 << 20, DiskWritten: 42 << 20, Energy: 310.5,
+				GPU: 6.0, NetIn: 125000, NetOut: 8200},
+			{PID: 2230, PPID: 2201, Name: "com.apple.WebKit.WebContent",
+				Path: "/Applications/Safari.app/Contents/MacOS/com.apple.WebKit.WebContent",
+				CPU:  21.0, CPUTime: 7 * time.Minute, RSS: 1200 << 20, Threads: 12, FDs: 90, State: "running",
+				StartTime: started, Energy: 122.0, GPU: 2.5},
+			{PID: 3050, PPID: 1, Name: "go", Path: "/usr/local/go/bin/go", CPU: 64.0,
+				CPUTime: 3 * time.Minute, RSS: 256 << 20, Threads: 18, FDs: 40, State: "running",
+				StartTime: started, DiskRead: 2 << 30, DiskWritten: 300 << 20, Energy: 95.25},
+			{PID: 3051, PPID: 3050, Name: "compile", Path: "/usr/local/go/pkg/tool/darwin_arm64/compile",
+				CPU: 98.5, CPUTime: 90 * time.Second, RSS: 512 << 20, Threads: 9, FDs: 12, State: "running",
+				StartTime: started},
+			{PID: 501, PPID: 1, Name: "mds_stores", Path: "/System/Library/Frameworks/CoreServices.framework/mds_stores",
+				CPU: 2.1, CPUTime: 5 * time.Minute, RSS: 96 << 20, Threads: 7, FDs: 220, State: "sleeping",
+				StartTime: started, DiskRead: 900 << 20},
+		},
+	}
+}
+
+func BenchmarkView(b *testing.B) {
+	stats := fixtureStats()
+	for _, v := range goldenViews {
+		b.Run(v.name, func(b *testing.B) {
+			m := model{
+				stats:       stats,
+				viewMode:    v.mode,
+				refreshRate: time.Second,
+				width:       120,
+				height:      40,
+				session:     &cpuSession{procs: make(map[procKey]*sessionProc)},
+				history:     newHistory(),
+				columns:     defaultProcessColumns,
+			}
+			m.session.update(stats.Processes)
+			for i := 0; i < 300; i++ {
+				m.recordHistory(time.Unix(int64(i), 0), stats)
+			}
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m.View()
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package tui
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/khoi/mtop/history"
+)
+
+// Playback speeds in samples per tick
+const (
+	minReplaySpeed = 1
+	maxReplaySpeed = 64
+)
+
+// Cells in the replay scrubber bar
+const scrubberWidth = 24
+
+// loadReplay reads the snapshots and notes of a recording in the import
+// format, oldest first. Lines with only named metrics are skipped, as the
+// views need full snapshots.
+func loadReplay(r io.Reader) ([]snapshot, []history.Mark, error) {
+	var shots []snapshot
+	var notes []history.Mark
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec importRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if rec.Time.IsZero() {
+			return nil, nil, fmt.Errorf("line %d: no time", line)
+		}
+		if rec.Note != "" {
+			notes = append(notes, history.Mark{Time: rec.Time.Local(), Text: rec.Note})
+		}
+		if rec.Stats != nil {
+			shots = append(shots, snapshot{rec.Time.Local(), *rec.Stats})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(shots) == 0 {
+		return nil, nil, errors.New("no samples with \"stats\" to replay")
+	}
+	sort.SliceStable(shots, func(i, j int) bool { return shots[i].at.Before(shots[j].at) })
+	sort.SliceStable(notes, func(i, j int) bool { return notes[i].Time.Before(notes[j].Time) })
+	return shots, notes, nil
+}
+
+// readReplay loads the recording at path
+func readReplay(path string) ([]snapshot, []history.Mark, error) {
+	f, err := openRecording(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	return loadReplay(f)
+}
+
+// newReplayModel shows a recording instead of live samples, paused on its
+// first sample, with its notes marked in the history. Nothing is
+// collected; ticks only drive playback.
+func newReplayModel(shots []snapshot, notes []history.Mark) model {
+	m := model{
+		viewMode:    OverviewMode,
+		refreshRate: time.Second,
+		width:       80,
+		height:      24,
+		session:     newCPUSession(),
+		sched:       newScheduler(),
+		notifier:    newNotifier(),
+		history:     newHistory(),
+		columns:     defaultProcessColumns,
+		replay:      true,
+		replaySpeed: minReplaySpeed,
+		paused:      true,
+		scrollback:  shots,
+		pauseOffset: len(shots) - 1,
+	}
+	for _, shot := range shots {
+		m.recordHistory(shot.at, shot.stats)
+		m.session.update(shot.stats.Processes)
+	}
+	for _, note := range notes {
+		m.history.Annotate(note.Time, note.Text)
+	}
+	m.stats, m.sampledAt = shots[len(shots)-1].stats, shots[len(shots)-1].at
+	return m
+}
+
+// stepReplay advances playback by one tick's worth of samples, stopping
+// at the end of the recording
+func (m *model) stepReplay() {
+	if !m.playing {
+		return
+	}
+	m.pauseOffset = max(m.pauseOffset-m.replaySpeed, 0)
+	if m.pauseOffset == 0 {
+		m.playing = false
+	}
+}
+
+// togglePlaying starts or stops playback, from the start once at the end
+func (m *model) togglePlaying() {
+	m.playing = !m.playing
+	if m.playing && m.pauseOffset == 0 {
+		m.pauseOffset = len(m.scrollback) - 1
+	}
+}
+
+// changeReplaySpeed doubles or halves the playback speed
+func (m *model) changeReplaySpeed(faster bool) {
+	if faster {
+		m.replaySpeed = min(m.replaySpeed*2, maxReplaySpeed)
+	} else {
+		m.replaySpeed = max(m.replaySpeed/2, minReplaySpeed)
+	}
+}
+
+// seekTo views the last snapshot taken at or before t, or the first one
+// if t is earlier than all of them
+func (m *model) seekTo(t time.Time) {
+	i := sort.Search(len(m.scrollback), func(i int) bool { return m.scrollback[i].at.After(t) })
+	m.pauseOffset = len(m.scrollback) - max(i, 1)
+}
+
+// parseSeek reads a seek target: a clock time (15:04 or 15:04:05) on the
+// day of the viewed sample, or a duration relative to it such as -30s
+func parseSeek(input string, from time.Time) (time.Time, error) {
+	input = strings.TrimSpace(input)
+	if strings.HasPrefix(input, "+") || strings.HasPrefix(input, "-") {
+		d, err := time.ParseDuration(input)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return from.Add(d), nil
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if clock, err := time.ParseInLocation(layout, input, from.Location()); err == nil {
+			y, mo, d := from.Date()
+			return time.Date(y, mo, d, clock.Hour(), clock.Minute(), clock.Second(), 0, from.Location()), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("want 15:04, 15:04:05 or a duration such as -30s, got %q", input)
+}
+
+// updateSeekKeys edits the seek prompt; enter jumps, esc cancels
+func (m model) updateSeekKeys(msg tea.KeyMsg) model {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.seeking = false
+		t, err := parseSeek(m.seek, m.scrollback[len(m.scrollback)-1-m.pauseOffset].at)
+		if err != nil {
+			m.setStatus("Seek: " + err.Error())
+			return m
+		}
+		m.seekTo(t)
+	case tea.KeyEsc:
+		m.seeking = false
+	case tea.KeyBackspace:
+		if r := []rune(m.seek); len(r) > 0 {
+			m.seek = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes:
+		m.seek += string(msg.Runes)
+	}
+	return m
+}
+
+// startSeek opens the seek prompt while paused or replaying
+func (m *model) startSeek() {
+	if m.paused {
+		m.seeking, m.seek = true, ""
+	}
+}
+
+// scrubber draws the position of the viewed sample in the recording
+func (m model) scrubber(width int) string {
+	pos := 0
+	if n := len(m.scrollback); n > 1 {
+		pos = (n - 1 - m.pauseOffset) * (width - 1) / (n - 1)
+	}
+	return helpStyle.Render(strings.Repeat(glyphs.rule, pos)) + titleStyle.Render(glyphs.head) +
+		helpStyle.Render(strings.Repeat(glyphs.line, width-1-pos))
+}
+
+// replayNote is the header line while replaying: the viewed sample's time
+// and place in the recording, and the playback state
+func (m model) replayNote() string {
+	i := len(m.scrollback) - m.pauseOffset
+	state := "stopped"
+	if m.playing {
+		state = fmt.Sprintf("playing %dx", m.replaySpeed)
+	}
+	return pausedStyle.Render(fmt.Sprintf(" REPLAY %s ", m.sampledAt.Format("15:04:05"))) +
+		fmt.Sprintf(" %s %d/%d %s (%s: play, %s: seek)",
+			m.scrubber(scrubberWidth), i, len(m.scrollback), state, keyFor("pause"), keyFor("seek"))
+}
+
+// seekPrompt replaces the header status line while typing a seek target
+func (m model) seekPrompt() string {
+	return fmt.Sprintf("Seek to (15:04:05 or -30s): %s%s | enter: Go | esc: Cancel", m.seek, glyphs.cursor)
+}
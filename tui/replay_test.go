@@ -0,0 +1,151 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const replayInput = `{"time": "2026-10-17T10:00:02Z", "stats": {"cpu": {"usage": 30}}}
+{"time": "2026-10-17T10:00:00Z", "stats": {"cpu": {"usage": 10}}}
+{"time": "2026-10-17T10:00:01Z", "metrics": {"cpu.usage": 99}}
+{"time": "2026-10-17T10:00:01Z", "stats": {"cpu": {"usage": 20}}}
+`
+
+func testReplay(t *testing.T) model {
+	t.Helper()
+	shots, notes, err := loadReplay(strings.NewReader(replayInput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newReplayModel(shots, notes)
+}
+
+func TestLoadReplay(t *testing.T) {
+	m := testReplay(t)
+	if len(m.scrollback) != 3 {
+		t.Fatalf("loaded %d snapshots, want 3 (metric-only lines skipped)", len(m.scrollback))
+	}
+	for i, want := range []float64{10, 20, 30} {
+		if got := m.scrollback[i].stats.CPU.Usage; got != want {
+			t.Errorf("snapshot %d cpu = %v, want %v", i, got, want)
+		}
+	}
+	if !m.paused || m.pauseOffset != 2 {
+		t.Errorf("paused = %v, offset = %d; want paused on the first sample", m.paused, m.pauseOffset)
+	}
+
+	for _, input := range []string{"", `{"time": "2026-10-17T10:00:00Z", "metrics": {"cpu.usage": 1}}`, `{"stats": {}}`, "not json"} {
+		if _, _, err := loadReplay(strings.NewReader(input)); err == nil {
+			t.Errorf("loadReplay(%q) succeeded", input)
+		}
+	}
+}
+
+func TestReplayPlayback(t *testing.T) {
+	m := testReplay(t)
+	m.stepReplay()
+	if m.pauseOffset != 2 {
+		t.Errorf("stopped replay moved to offset %d", m.pauseOffset)
+	}
+	m.togglePlaying()
+	m.changeReplaySpeed(true)
+	m.stepReplay()
+	if m.pauseOffset != 0 || m.playing {
+		t.Errorf("offset = %d, playing = %v; want stopped at the end", m.pauseOffset, m.playing)
+	}
+	m.togglePlaying()
+	if m.pauseOffset != 2 {
+		t.Errorf("playing from the end starts at offset %d, want 2", m.pauseOffset)
+	}
+
+	for range 10 {
+		m.changeReplaySpeed(true)
+	}
+	if m.replaySpeed != maxReplaySpeed {
+		t.Errorf("speed = %d, want capped at %d", m.replaySpeed, maxReplaySpeed)
+	}
+	for range 10 {
+		m.changeReplaySpeed(false)
+	}
+	if m.replaySpeed != minReplaySpeed {
+		t.Errorf("speed = %d, want floored at %d", m.replaySpeed, minReplaySpeed)
+	}
+}
+
+func TestParseSeek(t *testing.T) {
+	from := time.Date(2026, 10, 17, 10, 0, 30, 0, time.UTC)
+	for _, tt := range []struct {
+		input string
+		want  time.Time
+	}{
+		{"-30s", time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)},
+		{"+1m", time.Date(2026, 10, 17, 10, 1, 30, 0, time.UTC)},
+		{"09:15", time.Date(2026, 10, 17, 9, 15, 0, 0, time.UTC)},
+		{" 09:15:07 ", time.Date(2026, 10, 17, 9, 15, 7, 0, time.UTC)},
+	} {
+		got, err := parseSeek(tt.input, from)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseSeek(%q) = %v, %v; want %v", tt.input, got, err, tt.want)
+		}
+	}
+	for _, input := range []string{"", "soon", "25:00", "-30"} {
+		if _, err := parseSeek(input, from); err == nil {
+			t.Errorf("parseSeek(%q) succeeded", input)
+		}
+	}
+}
+
+func TestSeekTo(t *testing.T) {
+	m := testReplay(t)
+	start := m.scrollback[0].at
+	for _, tt := range []struct {
+		at   time.Duration
+		want int
+	}{
+		{-time.Minute, 2},
+		{0, 2},
+		{1500 * time.Millisecond, 1},
+		{time.Hour, 0},
+	} {
+		m.seekTo(start.Add(tt.at))
+		if m.pauseOffset != tt.want {
+			t.Errorf("seek to start%+v: offset %d, want %d", tt.at, m.pauseOffset, tt.want)
+		}
+	}
+}
+
+func TestSeekPrompt(t *testing.T) {
+	m := testReplay(t)
+	m.startSeek()
+	if !m.seeking {
+		t.Fatal("seek prompt did not open")
+	}
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("+2s")},
+		{Type: tea.KeyRunes, Runes: []rune("x")},
+		{Type: tea.KeyBackspace},
+		{Type: tea.KeyEnter},
+	} {
+		m = m.updateSeekKeys(key)
+	}
+	if m.seeking || m.pauseOffset != 0 {
+		t.Errorf("seeking = %v, offset = %d; want the last sample", m.seeking, m.pauseOffset)
+	}
+}
+
+func TestScrubber(t *testing.T) {
+	m := testReplay(t)
+	for offset, want := range map[int]int{2: 0, 1: 5, 0: 10} {
+		m.pauseOffset = offset
+		bar := []rune(m.scrubber(11))
+		if len(bar) != 11 || string(bar[want]) != glyphs.head {
+			t.Errorf("offset %d: scrubber %q, want head at %d", offset, string(bar), want)
+		}
+	}
+	if view := m.View(); !strings.Contains(view, "REPLAY") {
+		t.Errorf("replay view has no REPLAY marker:\n%s", view)
+	}
+}
@@ -0,0 +1,208 @@
+package tui
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Defaults of --log-max-size (in MB), --log-max-age and --log-keep
+const (
+	defaultLogMaxSize = 10
+	defaultLogMaxAge  = 24 * time.Hour
+	defaultLogKeep    = 5
+)
+
+// logEvent is something that happened between samples, logged on a line
+// of its own next to them. Alerts are the only kind so far.
+type logEvent struct {
+	Kind    string `json:"kind"`  // "alert"
+	Name    string `json:"name"`  // Alert name, e.g. "cpu"
+	State   string `json:"state"` // "firing" or "resolved"
+	Message string `json:"message,omitempty"`
+}
+
+// sampleLog appends every sample to a file as import format JSON Lines,
+// with a line per alert that starts or stops, so the file can be read
+// back with --replay or mtop import. The file is renamed to path.1 and a
+// new one started once it holds maxSize bytes or its first line is maxAge
+// old; the previous path.1 becomes path.2, and so on up to keep files.
+type sampleLog struct {
+	path    string
+	maxSize int64         // 0 never rotates by size
+	maxAge  time.Duration // 0 never rotates by age
+	keep    int
+
+	f       *os.File
+	size    int64
+	started time.Time // Time of the file's first line; zero while it is empty
+
+	alerts map[string]string // Alerts active at the previous sample
+}
+
+// openSampleLog opens path for appending, creating it if needed
+func openSampleLog(path string, maxSize int64, maxAge time.Duration, keep int) (*sampleLog, error) {
+	if maxSize < 0 || maxAge < 0 || keep < 0 {
+		return nil, errors.New("log rotation limits must not be negative")
+	}
+	l := &sampleLog{path: path, maxSize: maxSize, maxAge: maxAge, keep: keep}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// open starts appending to path, reading when an existing file began
+func (l *sampleLog) open() error {
+	if dir := filepath.Dir(l.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.f, l.size, l.started = f, info.Size(), time.Time{}
+	if l.size > 0 {
+		l.started = firstLineTime(l.path)
+	}
+	return nil
+}
+
+// firstLineTime reads the time of a log's first line, or the zero time if
+// it has none
+func firstLineTime(path string) time.Time {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}
+	}
+	defer f.Close()
+	line, _ := bufio.NewReader(f).ReadBytes('\n')
+	var rec struct {
+		Time time.Time `json:"time"`
+	}
+	json.Unmarshal(line, &rec)
+	return rec.Time
+}
+
+// logSample appends a sample, preceded by a line for each alert that
+// started or stopped since the previous one
+func (l *sampleLog) logSample(at time.Time, stats SystemStats, alerts map[string]string) error {
+	for _, name := range sortedKeys(alerts) {
+		if _, ok := l.alerts[name]; !ok {
+			if err := l.logEvent(at, logEvent{Kind: "alert", Name: name, State: "firing", Message: alerts[name]}); err != nil {
+				return err
+			}
+		}
+	}
+	for _, name := range sortedKeys(l.alerts) {
+		if _, ok := alerts[name]; !ok {
+			if err := l.logEvent(at, logEvent{Kind: "alert", Name: name, State: "resolved"}); err != nil {
+				return err
+			}
+		}
+	}
+	l.alerts = alerts
+
+	line, err := formatSampleLine(at, stats)
+	if err != nil {
+		return err
+	}
+	return l.write(at, []byte(line))
+}
+
+// logEvent appends an event line
+func (l *sampleLog) logEvent(at time.Time, event logEvent) error {
+	line, err := json.Marshal(importRecord{Time: at, Host: hostname, Event: &event})
+	if err != nil {
+		return err
+	}
+	return l.write(at, append(line, '\n'))
+}
+
+// logNote appends a note made at t
+func (l *sampleLog) logNote(at time.Time, text string) error {
+	line, err := json.Marshal(importRecord{Time: at, Host: hostname, Note: text})
+	if err != nil {
+		return err
+	}
+	return l.write(at, append(line, '\n'))
+}
+
+// write appends a line, rotating first when it would take the file past
+// its size or the file is past its age
+func (l *sampleLog) write(at time.Time, line []byte) error {
+	full := l.maxSize > 0 && l.size > 0 && l.size+int64(len(line)) > l.maxSize
+	old := l.maxAge > 0 && !l.started.IsZero() && at.Sub(l.started) >= l.maxAge
+	if full || old {
+		if err := l.rotate(); err != nil {
+			return fmt.Errorf("failed to rotate %s: %w", l.path, err)
+		}
+	}
+	n, err := l.f.Write(line)
+	l.size += int64(n)
+	if l.started.IsZero() {
+		l.started = at
+	}
+	return err
+}
+
+// rotate shifts path.N to path.N+1, dropping the file past keep, renames
+// the log to path.1 and starts a new one. With keep 0 the log is cleared.
+func (l *sampleLog) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return err
+	}
+	if l.keep == 0 {
+		if err := os.Remove(l.path); err != nil {
+			return err
+		}
+		return l.open()
+	}
+	if err := os.Remove(rotatedLogPath(l.path, l.keep)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for i := l.keep - 1; i >= 1; i-- {
+		if err := os.Rename(rotatedLogPath(l.path, i), rotatedLogPath(l.path, i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Rename(l.path, rotatedLogPath(l.path, 1)); err != nil {
+		return err
+	}
+	return l.open()
+}
+
+// logSample appends the newest sample and alert changes to the log file,
+// if one is open. A log that fails to write is closed and the footer says
+// why.
+func (m *model) logSample(at time.Time) {
+	if m.sampleLog == nil {
+		return
+	}
+	if err := m.sampleLog.logSample(at, m.stats, m.alerts()); err != nil {
+		m.setStatus(fmt.Sprintf("Stopped logging to %s: %v", m.sampleLog.path, err))
+		m.sampleLog.close()
+		m.sampleLog = nil
+	}
+}
+
+// rotatedLogPath names the i-th newest rotated log
+func rotatedLogPath(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
+
+func (l *sampleLog) close() error {
+	return l.f.Close()
+}
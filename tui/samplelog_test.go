@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSampleLogAlertEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mtop.jsonl")
+	l, err := openSampleLog(path, 0, 0, defaultLogKeep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	for i, alerts := range []map[string]string{
+		nil,
+		{"cpu": "cpu 95.0% > 90%"},
+		{"cpu": "cpu 96.0% > 90%"},
+		nil,
+	} {
+		if err := l.logSample(start.Add(time.Duration(i)*time.Second), fixtureStats(), alerts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("got %d lines, want 4 samples and 2 events:\n%s", len(lines), data)
+	}
+	if !strings.Contains(lines[1], `"event":{"kind":"alert","name":"cpu","state":"firing","message":"cpu 95.0% \u003e 90%"}`) ||
+		!strings.Contains(lines[4], `"state":"resolved"`) {
+		t.Errorf("event lines:\n%s\n%s", lines[1], lines[4])
+	}
+
+	// The log reads back like any recording
+	shots, _, err := readReplay(path)
+	if err != nil || len(shots) != 4 {
+		t.Errorf("replayed %d samples: %v", len(shots), err)
+	}
+	if n, err := importSamples(newHistory(), nil, strings.NewReader(string(data)), "mtop.jsonl"); err != nil || n != 4 {
+		t.Errorf("imported %d samples: %v", n, err)
+	}
+}
+
+func TestSampleLogRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mtop.jsonl")
+	line, _ := formatSampleLine(time.Now(), fixtureStats())
+	// Room for two samples per file
+	l, err := openSampleLog(path, int64(len(line)*2+10), time.Hour, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	for i := range 7 {
+		if err := l.logSample(start.Add(time.Duration(i)*time.Second), fixtureStats(), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, want := range map[string]int{path: 1, path + ".1": 2, path + ".2": 2, path + ".3": -1} {
+		data, err := os.ReadFile(name)
+		if want < 0 {
+			if err == nil {
+				t.Errorf("%s kept past --log-keep", filepath.Base(name))
+			}
+			continue
+		}
+		if got := strings.Count(string(data), "\n"); err != nil || got != want {
+			t.Errorf("%s holds %d samples (%v), want %d", filepath.Base(name), got, err, want)
+		}
+	}
+
+	// An hour after the current file began, it is rotated however small
+	if err := l.logSample(start.Add(time.Hour+6*time.Second), fixtureStats(), nil); err != nil {
+		t.Fatal(err)
+	}
+	l.close()
+	data, _ := os.ReadFile(path)
+	if got := strings.Count(string(data), "\n"); got != 1 {
+		t.Errorf("after an hour the log holds %d samples, want a new file", got)
+	}
+
+	// Reopening picks up when the existing file began
+	l, err = openSampleLog(path, 0, time.Hour, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.close()
+	if want := start.Add(time.Hour + 6*time.Second); !l.started.Equal(want) {
+		t.Errorf("started = %v, want %v", l.started, want)
+	}
+}
@@ -0,0 +1,307 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// statsGroup is a set of collectors sampled together. Each group fills its
+// own fields of SystemStats, so groups sampled at different cadences can be
+// merged into one snapshot.
+type statsGroup struct {
+	name    string
+	collect func(ctx context.Context, stats *SystemStats) error
+	keep    func(dst *SystemStats, prev SystemStats) // Copy the group's fields
+	signal  func(stats SystemStats) []float64        // Readings watched by adaptive sampling
+	watch   bool                                     // Run under the watchdog
+	reset   func()                                   // Drop collector state so the next run starts fresh
+}
+
+// Collector groups in collection order, each sampling the group of
+// stats.Groups it is named after, then the exec plugins. Groups named after
+// a collector can be turned off in the config. The system and process
+// groups are required; a failure aborts the whole sample.
+var statsGroups = []statsGroup{
+	{
+		name:    "system",
+		collect: collectGroup("system"),
+		keep: func(dst *SystemStats, prev SystemStats) {
+			clusters, freqs := dst.CPU.Clusters, dst.CPU.CoreFreqs
+			dst.Memory, dst.CPU, dst.GPU, dst.Uptime = prev.Memory, prev.CPU, prev.GPU, prev.Uptime
+			dst.CPU.Clusters, dst.CPU.CoreFreqs = clusters, freqs
+		},
+		signal: func(s SystemStats) []float64 {
+			return []float64{s.CPU.Usage, s.Memory.Usage, s.Memory.Swap.Usage}
+		},
+	},
+	{
+		name: "processes",
+		collect: func(ctx context.Context, stats *SystemStats) error {
+			collector.ProcessGPU = collectorActive("process_gpu")
+			collector.ProcessNet = collectorActive("process_net")
+			collector.ProcessPorts = collectorActive("process_ports")
+			return collector.CollectGroup(ctx, "processes", stats)
+		},
+		keep: func(dst *SystemStats, prev SystemStats) { dst.Processes = prev.Processes },
+	},
+	{
+		// Reads the coalitions of the processes collected above, so it
+		// can't run under the watchdog, which starts from empty stats
+		name: "coalitions",
+		collect: func(ctx context.Context, stats *SystemStats) error {
+			collector.CollectGroup(ctx, "coalitions", stats)
+			return nil
+		},
+		keep: func(dst *SystemStats, prev SystemStats) { dst.Coalitions = prev.Coalitions },
+		signal: func(s SystemStats) []float64 {
+			var cpu float64
+			for _, p := range s.Processes {
+				cpu += p.CPU
+			}
+			return []float64{float64(len(s.Processes)), cpu}
+		},
+	},
+	{
+		name:    "cpufreq",
+		watch:   true,
+		reset:   func() { collector.Reset("cpufreq") },
+		collect: collectGroup("cpufreq"),
+		keep: func(dst *SystemStats, prev SystemStats) {
+			dst.CPU.Clusters, dst.CPU.CoreFreqs = prev.CPU.Clusters, prev.CPU.CoreFreqs
+		},
+		signal: func(s SystemStats) []float64 {
+			var values []float64
+			for _, c := range s.CPU.Clusters {
+				values = append(values, c.Frequency, c.Active)
+			}
+			return values
+		},
+	},
+	{
+		name:    "thermal",
+		watch:   true,
+		collect: collectGroup("thermal"),
+		keep:    func(dst *SystemStats, prev SystemStats) { dst.Thermal = prev.Thermal },
+		signal:  func(s SystemStats) []float64 { return []float64{float64(s.Thermal)} },
+	},
+	{
+		name:    "power",
+		watch:   true,
+		reset:   func() { collector.Reset("power") },
+		collect: collectGroup("power"),
+		keep:    func(dst *SystemStats, prev SystemStats) { dst.Power = prev.Power },
+		signal:  func(s SystemStats) []float64 { return []float64{s.Power.Package, s.Power.DRAM} },
+	},
+	{
+		name:    "wifi",
+		watch:   true,
+		collect: collectGroup("wifi"),
+		keep:    func(dst *SystemStats, prev SystemStats) { dst.Network.WiFi = prev.Network.WiFi },
+		signal: func(s SystemStats) []float64 {
+			if s.Network.WiFi == nil {
+				return nil
+			}
+			return []float64{float64(s.Network.WiFi.RSSI), s.Network.WiFi.TxRate}
+		},
+	},
+	{
+		name:    "disk",
+		watch:   true,
+		reset:   func() { collector.Reset("disk") },
+		collect: collectGroup("disk"),
+		keep:    func(dst *SystemStats, prev SystemStats) { dst.Disk = prev.Disk },
+		signal:  func(s SystemStats) []float64 { return []float64{s.Disk.Busy} },
+	},
+	{
+		name:    "interfaces",
+		watch:   true,
+		reset:   func() { collector.Reset("interfaces") },
+		collect: collectGroup("interfaces"),
+		keep:    func(dst *SystemStats, prev SystemStats) { dst.Network.Interfaces = prev.Network.Interfaces },
+	},
+	{
+		name:    "sockets",
+		watch:   true,
+		collect: collectGroup("sockets"),
+		keep:    func(dst *SystemStats, prev SystemStats) { dst.Network.Sockets = prev.Network.Sockets },
+		signal: func(s SystemStats) []float64 {
+			return []float64{float64(s.Network.Sockets.TCPTotal), float64(s.Network.Sockets.UDP)}
+		},
+	},
+	{
+		// Plugins run in the background, so the group only waits for
+		// each one's first run
+		name:    "plugins",
+		collect: collectPlugins,
+		keep:    func(dst *SystemStats, prev SystemStats) { dst.Plugins = prev.Plugins },
+	},
+}
+
+// collectGroup samples the named group of the shared collector
+func collectGroup(name string) func(ctx context.Context, stats *SystemStats) error {
+	return func(ctx context.Context, stats *SystemStats) error {
+		return collector.CollectGroup(ctx, name, stats)
+	}
+}
+
+// Cadence of each collector group set in the [cadence] config section;
+// groups without one are sampled at the refresh rate
+var groupCadences = map[string]time.Duration{}
+
+// applyCadences checks and records the per-group cadences from the config
+func applyCadences(cadences map[string]time.Duration) error {
+	for name, every := range cadences {
+		known := false
+		for _, g := range statsGroups {
+			known = known || g.name == name
+		}
+		if !known {
+			names := make([]string, len(statsGroups))
+			for i, g := range statsGroups {
+				names[i] = g.name
+			}
+			return fmt.Errorf("unknown collector %q in [cadence] (want %s)", name, strings.Join(names, ", "))
+		}
+		if every < minRefreshRate {
+			return fmt.Errorf("cadence of %s must be at least %v", name, minRefreshRate)
+		}
+		groupCadences[name] = every
+	}
+	return nil
+}
+
+// adaptiveSampling stretches the cadence of collector groups whose readings
+// hold steady; set with --adaptive or adaptive in the config
+var adaptiveSampling bool
+
+// Adaptive sampling limits: a group's cadence doubles after each steady
+// sample up to adaptiveMaxBackoff times its base. Readings count as steady
+// when they move less than adaptiveTolerance of their value, or at most
+// adaptiveFloor for values near zero.
+const (
+	adaptiveMaxBackoff = 8
+	adaptiveTolerance  = 0.05
+	adaptiveFloor      = 0.5
+)
+
+// scheduler samples each collector group when its cadence is due and merges
+// the fresh results with the last results of the groups that were not
+type scheduler struct {
+	last    map[string]time.Time
+	stats   SystemStats
+	signals map[string][]float64 // Last readings of each group
+	backoff int                  // Cadence multiplier under adaptive sampling
+	health  map[string]*groupHealth
+	events  []watchdogEvent
+}
+
+func newScheduler() *scheduler {
+	return &scheduler{
+		last:    make(map[string]time.Time),
+		signals: make(map[string][]float64),
+		backoff: 1,
+		health:  make(map[string]*groupHealth),
+	}
+}
+
+// collect returns a snapshot combining the groups due at now with the
+// previous results of the others. refresh is the cadence of groups without
+// one of their own. Cancelling ctx stops the groups at their next check and
+// the watched groups' calls still running; it should outlive the sample.
+func (s *scheduler) collect(ctx context.Context, now time.Time, refresh time.Duration) (SystemStats, error) {
+	var stats SystemStats
+	sampled, changed := false, false
+	for _, g := range statsGroups {
+		if !collectorActive(g.name) {
+			continue
+		}
+		every, ok := groupCadences[g.name]
+		if !ok {
+			every = refresh
+		}
+		every *= time.Duration(s.backoff)
+
+		// Allow a little slack so ticks arriving just early are not skipped
+		last, seen := s.last[g.name]
+		if seen && now.Sub(last) < every-every/10 {
+			g.keep(&stats, s.stats)
+			continue
+		}
+		if g.watch {
+			s.collectWatched(ctx, g, &stats, now)
+		} else {
+			start := time.Now()
+			err := runGroup(ctx, g, &stats)
+			internals.observe(g.name, time.Since(start), err)
+			if err != nil {
+				return stats, err
+			}
+			s.last[g.name] = now
+		}
+
+		if g.signal != nil {
+			signal := g.signal(stats)
+			if prev, ok := s.signals[g.name]; ok {
+				sampled = true
+				changed = changed || !steady(prev, signal)
+			}
+			s.signals[g.name] = signal
+		}
+	}
+	s.stats = stats
+
+	// Any change brings every group back to its base cadence, so activity
+	// shows up everywhere at once; steady samples back off gradually
+	if adaptiveSampling && sampled {
+		if changed {
+			s.backoff = 1
+		} else {
+			s.backoff = min(s.backoff*2, adaptiveMaxBackoff)
+		}
+	}
+	return stats, nil
+}
+
+// runGroup collects a group unless ctx is already done
+func runGroup(ctx context.Context, g statsGroup, stats *SystemStats) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return g.collect(ctx, stats)
+}
+
+// steady reports whether two sets of readings are within the adaptive
+// sampling tolerance of each other
+func steady(prev, cur []float64) bool {
+	if len(prev) != len(cur) {
+		return false
+	}
+	for i := range cur {
+		if math.Abs(cur[i]-prev[i]) > max(math.Abs(prev[i])*adaptiveTolerance, adaptiveFloor) {
+			return false
+		}
+	}
+	return true
+}
+
+// tickInterval is how often the model wakes up: the refresh rate, or the
+// shortest group cadence when one is faster
+func tickInterval(refresh time.Duration) time.Duration {
+	interval := refresh
+	for _, every := range groupCadences {
+		interval = min(interval, every)
+	}
+	return interval
+}
+
+// adaptiveNote marks the refresh rate in the header while adaptive sampling
+// has stretched it
+func (m model) adaptiveNote() string {
+	if !adaptiveSampling || m.sched == nil || m.sched.backoff == 1 {
+		return ""
+	}
+	return fmt.Sprintf(" (adaptive %s%d)", glyphs.times, m.sched.backoff)
+}
@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSchedulerCadences(t *testing.T) {
+	defer func(groups []statsGroup, cadences map[string]time.Duration) {
+		statsGroups, groupCadences = groups, cadences
+	}(statsGroups, groupCadences)
+
+	runs := map[string]int{}
+	statsGroups = []statsGroup{
+		{
+			name: "system",
+			collect: func(_ context.Context, s *SystemStats) error {
+				runs["system"]++
+				s.CPU.Usage = float64(runs["system"])
+				return nil
+			},
+			keep: func(dst *SystemStats, prev SystemStats) { dst.CPU = prev.CPU },
+		},
+		{
+			name: "thermal",
+			collect: func(_ context.Context, s *SystemStats) error {
+				runs["thermal"]++
+				s.Thermal = ThermalSerious
+				return nil
+			},
+			keep: func(dst *SystemStats, prev SystemStats) { dst.Thermal = prev.Thermal },
+		},
+	}
+	groupCadences = map[string]time.Duration{}
+	if err := applyCadences(map[string]time.Duration{"thermal": 5 * time.Second}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newScheduler()
+	start := time.Date(2024, time.March, 2, 9, 30, 0, 0, time.UTC)
+	var stats SystemStats
+	for i := 0; i < 6; i++ {
+		var err error
+		if stats, err = s.collect(context.Background(), start.Add(time.Duration(i)*time.Second), time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if runs["system"] != 6 || runs["thermal"] != 2 {
+		t.Errorf("runs = %v, want system every tick and thermal at 0s and 5s", runs)
+	}
+	// Groups that were not due keep their last reading
+	if stats.Thermal != ThermalSerious || stats.CPU.Usage != 6 {
+		t.Errorf("merged stats = thermal %v, cpu %v", stats.Thermal, stats.CPU.Usage)
+	}
+	if got := tickInterval(time.Second); got != time.Second {
+		t.Errorf("tick interval = %v, want the refresh rate", got)
+	}
+}
+
+func TestSchedulerStopsWhenCancelled(t *testing.T) {
+	defer func(groups []statsGroup) { statsGroups = groups }(statsGroups)
+
+	ran := false
+	statsGroups = []statsGroup{{
+		name: "system",
+		collect: func(_ context.Context, s *SystemStats) error {
+			ran = true
+			return nil
+		},
+		keep: func(dst *SystemStats, prev SystemStats) {},
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := newScheduler().collect(ctx, time.Now(), time.Second); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if ran {
+		t.Error("a group ran after the context was cancelled")
+	}
+}
+
+func TestApplyCadences(t *testing.T) {
+	defer func(cadences map[string]time.Duration) { groupCadences = cadences }(groupCadences)
+	groupCadences = map[string]time.Duration{}
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("[cadence]\nsystem = \"500ms\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfig(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := applyCadences(cfg.Cadence); err != nil {
+		t.Fatal(err)
+	}
+	if got := tickInterval(time.Second); got != 500*time.Millisecond {
+		t.Errorf("tick interval = %v, want the faster cadence", got)
+	}
+
+	if err := applyCadences(map[string]time.Duration{"sensors": time.Second}); err == nil {
+		t.Error("unknown group was accepted")
+	}
+	if err := applyCadences(map[string]time.Duration{"power": time.Millisecond}); err == nil {
+		t.Error("cadence below the minimum was accepted")
+	}
+}
+
+func TestAdaptiveSampling(t *testing.T) {
+	defer func(groups []statsGroup, adaptive bool) {
+		statsGroups, adaptiveSampling = groups, adaptive
+	}(statsGroups, adaptiveSampling)
+	adaptiveSampling = true
+
+	usage, runs := 10.0, 0
+	statsGroups = []statsGroup{{
+		name: "system",
+		collect: func(_ context.Context, s *SystemStats) error {
+			runs++
+			s.CPU.Usage = usage
+			return nil
+		},
+		keep:   func(dst *SystemStats, prev SystemStats) { dst.CPU = prev.CPU },
+		signal: func(s SystemStats) []float64 { return []float64{s.CPU.Usage} },
+	}}
+
+	s := newScheduler()
+	start := time.Date(2024, time.March, 2, 9, 30, 0, 0, time.UTC)
+	for i := 0; i < 30; i++ {
+		if _, err := s.collect(context.Background(), start.Add(time.Duration(i)*time.Second), time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Samples at 0, 1, 3, 7, 15 and 23s as the cadence doubles up to 8s
+	if runs != 6 || s.backoff != adaptiveMaxBackoff {
+		t.Errorf("steady readings: %d samples, backoff %d", runs, s.backoff)
+	}
+
+	usage = 50
+	if _, err := s.collect(context.Background(), start.Add(31*time.Second), time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if s.backoff != 1 {
+		t.Errorf("backoff after a change = %d, want 1", s.backoff)
+	}
+}
+
+func TestSteady(t *testing.T) {
+	cases := []struct {
+		prev, cur []float64
+		want      bool
+	}{
+		{[]float64{40}, []float64{41.5}, true},
+		{[]float64{40}, []float64{43}, false},
+		{[]float64{0}, []float64{0.4}, true},
+		{[]float64{0}, []float64{1}, false},
+		{[]float64{1, 2}, []float64{1}, false},
+	}
+	for _, c := range cases {
+		if got := steady(c.prev, c.cur); got != c.want {
+			t.Errorf("steady(%v, %v) = %v, want %v", c.prev, c.cur, got, c.want)
+		}
+	}
+}
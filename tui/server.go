@@ -0,0 +1,279 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/khoi/mtop/history"
+)
+
+// apiServer answers the --serve JSON endpoints from the newest sample and
+// the history recorded since the server started, and pushes each sample
+// to the connected WebSocket clients
+type apiServer struct {
+	mu      sync.Mutex
+	stats   SystemStats
+	at      time.Time
+	history *history.Store
+	clients map[*wsClient]bool
+}
+
+// apiSample is a history sample as the API encodes it
+type apiSample struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Orders the processes endpoint accepts, busiest or largest first
+var apiProcessSorts = map[string]func(a, b ProcessStats) bool{
+	"cpu":  func(a, b ProcessStats) bool { return a.CPU > b.CPU },
+	"mem":  func(a, b ProcessStats) bool { return a.RSS > b.RSS },
+	"gpu":  func(a, b ProcessStats) bool { return a.GPU > b.GPU },
+	"pid":  func(a, b ProcessStats) bool { return a.PID < b.PID },
+	"name": func(a, b ProcessStats) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) },
+}
+
+// Windows the summary endpoint rolls each metric up over, ending at the
+// newest sample
+var apiRollupWindows = []struct {
+	name string
+	d    time.Duration
+}{{"1m", time.Minute}, {"5m", 5 * time.Minute}, {"15m", 15 * time.Minute}}
+
+// Processes the summary endpoint lists per order unless asked for more
+const apiSummaryTop = 5
+
+// apiRollup is a metric's average and peak over one window
+type apiRollup struct {
+	Samples int     `json:"samples"`
+	Avg     float64 `json:"avg"`
+	Max     float64 `json:"max"`
+}
+
+// Groupings the processes endpoint accepts, as in the process view
+var apiGroupings = map[string]processGrouping{
+	"":          groupNone,
+	"app":       groupApp,
+	"coalition": groupCoalition,
+}
+
+// runServer collects a sample every interval and serves it, with the
+// metric history, as JSON on addr
+func runServer(addr string, interval time.Duration) error {
+	s := newAPIServer()
+	return serveSampling(addr, s.handler(), interval, s.record, withCollectors(onDemandCollectors...))
+}
+
+// newAPIServer keeps the --history window, and at least the longest
+// summary window so its rollups cover what they say
+func newAPIServer() *apiServer {
+	retention := max(historyRetention, apiRollupWindows[len(apiRollupWindows)-1].d)
+	return &apiServer{history: history.New(retention, minRefreshRate), clients: make(map[*wsClient]bool)}
+}
+
+// record keeps a sample as the newest, adds it to the history and hands it
+// to the WebSocket clients
+func (s *apiServer) record(at time.Time, stats SystemStats) {
+	sample, err := splitSample(at, stats)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats, s.at = stats, at
+	s.history.Record(at, historyMetrics(stats))
+	if err == nil {
+		for c := range s.clients {
+			c.push(sample)
+		}
+	}
+}
+
+// handler routes the API endpoints
+func (s *apiServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/stats", s.handleStats)
+	mux.HandleFunc("GET /api/v1/processes", s.handleProcesses)
+	mux.HandleFunc("GET /api/v1/history", s.handleHistory)
+	mux.HandleFunc("GET /api/v1/summary", s.handleSummary)
+	mux.HandleFunc("GET /ws", s.handleWebSocket)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusNotFound, "not found; the API serves GET /api/v1/stats, /api/v1/processes, /api/v1/history, /api/v1/summary and /ws")
+	})
+	return mux
+}
+
+// latest returns the newest sample, or false before the first
+func (s *apiServer) latest() (SystemStats, time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats, s.at, !s.at.IsZero()
+}
+
+// handleStats returns the newest sample in the import format, so saved
+// responses can be fed to "mtop import"
+func (s *apiServer) handleStats(w http.ResponseWriter, r *http.Request) {
+	stats, at, ok := s.latest()
+	if !ok {
+		writeAPIError(w, http.StatusServiceUnavailable, "no sample collected yet")
+		return
+	}
+	writeJSON(w, http.StatusOK, importRecord{Time: at, Host: hostname, Stats: &stats, Metrics: historyMetrics(stats)})
+}
+
+// handleProcesses returns the newest process list. Query parameters:
+// sort (cpu, mem, gpu, pid or name), limit and group (app or coalition).
+func (s *apiServer) handleProcesses(w http.ResponseWriter, r *http.Request) {
+	stats, at, ok := s.latest()
+	if !ok {
+		writeAPIError(w, http.StatusServiceUnavailable, "no sample collected yet")
+		return
+	}
+	q := r.URL.Query()
+	grouping, ok := apiGroupings[q.Get("group")]
+	if !ok {
+		writeAPIError(w, http.StatusBadRequest, "group must be app or coalition")
+		return
+	}
+	order := q.Get("sort")
+	if order == "" {
+		order = "cpu"
+	}
+	less, ok := apiProcessSorts[order]
+	if !ok {
+		writeAPIError(w, http.StatusBadRequest, "sort must be cpu, mem, gpu, pid or name")
+		return
+	}
+	limit := -1
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeAPIError(w, http.StatusBadRequest, "limit must be a non-negative number")
+			return
+		}
+		limit = n
+	}
+
+	procs := slices.Clone(groupProcesses(stats.Processes, grouping, stats.Coalitions))
+	sort.SliceStable(procs, func(i, j int) bool { return less(procs[i], procs[j]) })
+	if limit >= 0 && limit < len(procs) {
+		procs = procs[:limit]
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Time      time.Time      `json:"time"`
+		Total     int            `json:"total"`
+		Processes []ProcessStats `json:"processes"`
+	}{at, len(stats.Processes), procs})
+}
+
+// handleHistory returns the recorded samples of each metric. Query
+// parameters: metric (comma-separated names, default all) and since (a
+// duration such as 1m, default the whole history).
+func (s *apiServer) handleHistory(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var since time.Time
+	if v := q.Get("since"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			writeAPIError(w, http.StatusBadRequest, "since must be a positive duration such as 1m")
+			return
+		}
+		since = time.Now().Add(-d)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := s.history.Names()
+	if v := q.Get("metric"); v != "" {
+		names = strings.Split(v, ",")
+		for _, name := range names {
+			if !slices.Contains(s.history.Names(), name) {
+				writeAPIError(w, http.StatusNotFound, fmt.Sprintf("unknown metric %q", name))
+				return
+			}
+		}
+	}
+	metrics := make(map[string][]apiSample, len(names))
+	for _, name := range names {
+		samples := []apiSample{}
+		for _, sample := range s.history.Samples(name) {
+			if !sample.Time.Before(since) {
+				samples = append(samples, apiSample{sample.Time, sample.Value})
+			}
+		}
+		metrics[name] = samples
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Retention string                 `json:"retention"`
+		Metrics   map[string][]apiSample `json:"metrics"`
+	}{s.history.Retention().String(), metrics})
+}
+
+// handleSummary returns each metric's average and peak over the last 1, 5
+// and 15 minutes of history, and the processes using the most CPU and
+// memory in the newest sample, so dashboards need not aggregate the raw
+// history. Query parameter: top (processes per order, default 5). A window
+// longer than the history so far covers what there is.
+func (s *apiServer) handleSummary(w http.ResponseWriter, r *http.Request) {
+	top := apiSummaryTop
+	if v := r.URL.Query().Get("top"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeAPIError(w, http.StatusBadRequest, "top must be a non-negative number")
+			return
+		}
+		top = n
+	}
+
+	s.mu.Lock()
+	stats, at := s.stats, s.at
+	metrics := make(map[string]map[string]apiRollup)
+	if !at.IsZero() {
+		for _, name := range s.history.Names() {
+			samples := s.history.Samples(name)
+			rollups := make(map[string]apiRollup, len(apiRollupWindows))
+			for _, window := range apiRollupWindows {
+				start := sort.Search(len(samples), func(i int) bool { return !samples[i].Time.Before(at.Add(-window.d)) })
+				sum := history.Summarize(samples[start:])
+				rollups[window.name] = apiRollup{Samples: sum.Count, Avg: sum.Mean, Max: sum.Max}
+			}
+			metrics[name] = rollups
+		}
+	}
+	s.mu.Unlock()
+	if at.IsZero() {
+		writeAPIError(w, http.StatusServiceUnavailable, "no sample collected yet")
+		return
+	}
+
+	busiest := func(order string) []ProcessStats {
+		less := apiProcessSorts[order]
+		procs := slices.Clone(stats.Processes)
+		sort.SliceStable(procs, func(i, j int) bool { return less(procs[i], procs[j]) })
+		return procs[:min(len(procs), top)]
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Time    time.Time                       `json:"time"`
+		Metrics map[string]map[string]apiRollup `json:"metrics"`
+		TopCPU  []ProcessStats                  `json:"top_cpu"`
+		TopMem  []ProcessStats                  `json:"top_mem"`
+	}{at, metrics, busiest("cpu"), busiest("mem")})
+}
+
+// writeJSON writes v as an indented JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// writeAPIError writes an error response as {"error": message}
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
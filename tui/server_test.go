@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAPIServer(t *testing.T) {
+	s := newAPIServer()
+	h := s.handler()
+	get := func(path string, v any) int {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if v != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+				t.Fatalf("%s: %v\n%s", path, err, rec.Body)
+			}
+		}
+		return rec.Code
+	}
+
+	if code := get("/api/v1/stats", nil); code != http.StatusServiceUnavailable {
+		t.Errorf("stats before the first sample: status %d, want 503", code)
+	}
+
+	now := time.Now()
+	s.record(now.Add(-2*time.Minute), fixtureStats())
+	s.record(now, fixtureStats())
+
+	var rec importRecord
+	if code := get("/api/v1/stats", &rec); code != http.StatusOK {
+		t.Fatalf("stats: status %d", code)
+	}
+	if rec.Stats == nil || rec.Stats.CPU.Usage != 37.5 || rec.Metrics[metricCPU] != 37.5 {
+		t.Errorf("stats = %+v", rec)
+	}
+
+	var procs struct {
+		Total     int            `json:"total"`
+		Processes []ProcessStats `json:"processes"`
+	}
+	if code := get("/api/v1/processes?sort=mem&limit=2", &procs); code != http.StatusOK {
+		t.Fatalf("processes: status %d", code)
+	}
+	if procs.Total != len(fixtureStats().Processes) || len(procs.Processes) != 2 ||
+		procs.Processes[0].RSS < procs.Processes[1].RSS {
+		t.Errorf("processes by memory, limit 2 = %+v", procs)
+	}
+	if code := get("/api/v1/processes?sort=size", nil); code != http.StatusBadRequest {
+		t.Errorf("unknown sort: status %d, want 400", code)
+	}
+
+	var hist struct {
+		Metrics map[string][]apiSample `json:"metrics"`
+	}
+	if code := get("/api/v1/history?metric=cpu.usage&since=1m", &hist); code != http.StatusOK {
+		t.Fatalf("history: status %d", code)
+	}
+	if len(hist.Metrics) != 1 || len(hist.Metrics[metricCPU]) != 1 {
+		t.Errorf("history of cpu.usage over 1m = %+v, want the newest sample only", hist.Metrics)
+	}
+	if code := get("/api/v1/history?metric=cpu.nope", nil); code != http.StatusNotFound {
+		t.Errorf("unknown metric: status %d, want 404", code)
+	}
+
+	var summary struct {
+		Metrics map[string]map[string]apiRollup `json:"metrics"`
+		TopCPU  []ProcessStats                  `json:"top_cpu"`
+		TopMem  []ProcessStats                  `json:"top_mem"`
+	}
+	if code := get("/api/v1/summary?top=1", &summary); code != http.StatusOK {
+		t.Fatalf("summary: status %d", code)
+	}
+	cpu := summary.Metrics[metricCPU]
+	if cpu["1m"].Samples != 1 || cpu["5m"].Samples != 2 || cpu["15m"].Avg != 37.5 || cpu["15m"].Max != 37.5 {
+		t.Errorf("cpu.usage rollups = %+v, want 1 sample over 1m and 2 over 5m", cpu)
+	}
+	if len(summary.TopCPU) != 1 || len(summary.TopMem) != 1 {
+		t.Errorf("top=1 gave %d and %d processes", len(summary.TopCPU), len(summary.TopMem))
+	}
+	if code := get("/api/v1/summary?top=-1", nil); code != http.StatusBadRequest {
+		t.Errorf("negative top: status %d, want 400", code)
+	}
+	if code := get("/api/v2/stats", nil); code != http.StatusNotFound {
+		t.Errorf("unknown endpoint: status %d, want 404", code)
+	}
+}
+
+func TestAPISummaryCoversFifteenMinutes(t *testing.T) {
+	s := newAPIServer()
+	now := time.Now()
+	for _, ago := range []time.Duration{12 * time.Minute, 8 * time.Minute, 0} {
+		s.record(now.Add(-ago), fixtureStats())
+	}
+
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/summary", nil))
+	var summary struct {
+		Metrics map[string]map[string]apiRollup `json:"metrics"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("summary: %v\n%s", err, rec.Body)
+	}
+	cpu := summary.Metrics[metricCPU]
+	if cpu["5m"].Samples != 1 || cpu["15m"].Samples != 3 {
+		t.Errorf("cpu.usage rollups = %+v, want 1 sample over 5m and 3 over 15m with the default %v history", cpu, historyRetention)
+	}
+}
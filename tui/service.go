@@ -0,0 +1,378 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Services are launchd user agents labelled servicePrefix + their name,
+// with their plist in ~/Library/LaunchAgents
+const servicePrefix = "com.github.khoi.mtop."
+
+// serviceModes are the mtop flags that run without a terminal, in the order
+// main picks between them, and the service name each gets by default
+var serviceModes = []struct{ arg, name string }{
+	{"influx-url", "influx"},
+	{"statsd", "statsd"},
+	{"stream", "stream"},
+	{"prometheus", "prometheus"},
+	{"otlp-endpoint", "otlp"},
+	{"serve", "serve"},
+}
+
+// serviceUsage is shared by "mtop service" and its subcommands
+func serviceUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s service install [--name NAME] [--log FILE] [-- MTOP_ARGS...]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s service uninstall [--name NAME]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s service status [--name NAME]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Runs mtop at login as a launchd agent, restarted if it fails. MTOP_ARGS\n")
+	fmt.Fprintf(os.Stderr, "default to \"agent\"; any mode that needs no terminal works, e.g.\n")
+	fmt.Fprintf(os.Stderr, "  %s service install -- --prometheus :9100\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s service install --log ~/mtop.jsonl -- --json --stream -d 10\n", os.Args[0])
+}
+
+// runService implements "mtop service": install, uninstall or show the
+// launchd agents that run mtop modes at login
+func runService(args []string) int {
+	if len(args) == 0 {
+		serviceUsage()
+		return 2
+	}
+	switch args[0] {
+	case "install":
+		return runServiceInstall(args[1:])
+	case "uninstall":
+		return runServiceUninstall(args[1:])
+	case "status":
+		return runServiceStatus(args[1:])
+	case "-h", "-help", "--help":
+		serviceUsage()
+		return 0
+	}
+	fmt.Fprintf(os.Stderr, "Unknown service command %q\n\n", args[0])
+	serviceUsage()
+	return 2
+}
+
+func runServiceInstall(args []string) int {
+	fs := flag.NewFlagSet("service install", flag.ExitOnError)
+	name := fs.String("name", "", "Service name, to run several (default: from the mode, e.g. agent or prometheus)")
+	logPath := fs.String("log", "", "File that gets the service's output and errors (default ~/Library/Logs/mtop/NAME.log)")
+	fs.Usage = serviceUsage
+	fs.Parse(args)
+	mtopArgs := fs.Args()
+	if len(mtopArgs) == 0 {
+		mtopArgs = []string{"agent"}
+	}
+	mode, ok := serviceMode(mtopArgs)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "%s would start the TUI, which needs a terminal; give a mode such as agent or --prometheus ADDR\n", strings.Join(mtopArgs, " "))
+		return 2
+	}
+	if *name == "" {
+		*name = mode
+	}
+	if err := checkServiceName(*name); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --name: %v\n", err)
+		return 2
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if *logPath == "" {
+		*logPath = filepath.Join(serviceLogDir(), *name+".log")
+	}
+	*logPath, err = filepath.Abs(expandHome(*logPath))
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(*logPath), 0o755)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --log: %v\n", err)
+		return 1
+	}
+	// launchd starts agents without the shell's environment. The agent's
+	// ring and socket live in TMPDIR, so it must be the one TUIs see.
+	env := map[string]string{"TMPDIR": os.TempDir()}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		env["XDG_CONFIG_HOME"] = dir
+	}
+
+	label := servicePrefix + *name
+	path := servicePlistPath(*name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	// A service installed again is replaced
+	launchctl("bootout", serviceTarget(label))
+	plist := servicePlist(label, append([]string{exe}, mtopArgs...), *logPath, env)
+	if err := os.WriteFile(path, plist, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if out, err := launchctl("bootstrap", "gui/"+strconv.Itoa(os.Getuid()), path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading %s: %v %s\n", path, err, out)
+		return 1
+	}
+	fmt.Printf("Installed %s: %s\nOutput goes to %s\n", *name, strings.Join(mtopArgs, " "), *logPath)
+	return 0
+}
+
+func runServiceUninstall(args []string) int {
+	fs := flag.NewFlagSet("service uninstall", flag.ExitOnError)
+	name := fs.String("name", "agent", "Service to remove")
+	fs.Usage = serviceUsage
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		serviceUsage()
+		return 2
+	}
+	if err := checkServiceName(*name); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --name: %v\n", err)
+		return 2
+	}
+	path := servicePlistPath(*name)
+	if _, err := os.Stat(path); err != nil {
+		fmt.Fprintf(os.Stderr, "No service %s: %v\n", *name, err)
+		return 1
+	}
+	// Not being loaded is fine; the plist goes either way
+	launchctl("bootout", serviceTarget(servicePrefix+*name))
+	if err := os.Remove(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Uninstalled %s\n", *name)
+	return 0
+}
+
+func runServiceStatus(args []string) int {
+	fs := flag.NewFlagSet("service status", flag.ExitOnError)
+	name := fs.String("name", "", "Service to show (default: all)")
+	fs.Usage = serviceUsage
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		serviceUsage()
+		return 2
+	}
+	names := []string{*name}
+	if *name != "" {
+		if err := checkServiceName(*name); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --name: %v\n", err)
+			return 2
+		}
+	} else {
+		plists, _ := filepath.Glob(servicePlistPath("*"))
+		names = names[:0]
+		for _, p := range plists {
+			names = append(names, strings.TrimSuffix(strings.TrimPrefix(filepath.Base(p), servicePrefix), ".plist"))
+		}
+		if len(names) == 0 {
+			fmt.Printf("No services installed; add one with %s service install\n", os.Args[0])
+			return 0
+		}
+	}
+	code := 0
+	for _, n := range names {
+		data, err := os.ReadFile(servicePlistPath(n))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "No service %s: %v\n", n, err)
+			code = 1
+			continue
+		}
+		state := "not loaded"
+		if out, err := launchctl("print", serviceTarget(servicePrefix+n)); err == nil {
+			state = parseLaunchctlPrint(out).String()
+		}
+		argv := plistArguments(data)
+		if len(argv) > 0 {
+			argv[0] = filepath.Base(argv[0])
+		}
+		fmt.Printf("%-12s %-24s %s\n", n, state, strings.Join(argv, " "))
+	}
+	return code
+}
+
+// serviceMode is the default service name of the first mode in args that
+// runs without a terminal, or false when they would start the TUI
+func serviceMode(args []string) (string, bool) {
+	if len(args) > 0 && (args[0] == "agent" || args[0] == "record") {
+		return args[0], true
+	}
+	for _, m := range serviceModes {
+		for _, a := range args {
+			if !strings.HasPrefix(a, "-") {
+				continue
+			}
+			a, _, _ = strings.Cut(strings.TrimLeft(a, "-"), "=")
+			if a == m.arg {
+				return m.name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// checkServiceName accepts names that make a valid label and file name
+func checkServiceName(name string) error {
+	if name == "" {
+		return fmt.Errorf("empty name")
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return fmt.Errorf("%q: use letters, digits, - and _", name)
+		}
+	}
+	return nil
+}
+
+// servicePlistPath is where the plist of the named service lives
+func servicePlistPath(name string) string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "Library", "LaunchAgents", servicePrefix+name+".plist")
+}
+
+// serviceLogDir holds the services' output unless --log says otherwise
+func serviceLogDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "Library", "Logs", "mtop")
+}
+
+// serviceTarget names a service to launchctl in the user's login session
+func serviceTarget(label string) string {
+	return fmt.Sprintf("gui/%d/%s", os.Getuid(), label)
+}
+
+// launchctl runs launchctl with args and returns what it printed
+func launchctl(args ...string) (string, error) {
+	out, err := exec.Command("launchctl", args...).CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+// servicePlist is the launchd property list running argv at login and
+// again whenever it exits with an error, with its output in logPath
+func servicePlist(label string, argv []string, logPath string, env map[string]string) []byte {
+	var b bytes.Buffer
+	str := func(s string) string {
+		var e bytes.Buffer
+		xml.EscapeText(&e, []byte(s))
+		return "<string>" + e.String() + "</string>"
+	}
+	b.WriteString(xml.Header)
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString("<plist version=\"1.0\">\n<dict>\n")
+	fmt.Fprintf(&b, "\t<key>Label</key>\n\t%s\n", str(label))
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, a := range argv {
+		fmt.Fprintf(&b, "\t\t%s\n", str(a))
+	}
+	b.WriteString("\t</array>\n")
+	if len(env) > 0 {
+		b.WriteString("\t<key>EnvironmentVariables</key>\n\t<dict>\n")
+		for _, k := range sortedKeys(env) {
+			fmt.Fprintf(&b, "\t\t<key>%s</key>\n\t\t%s\n", k, str(env[k]))
+		}
+		b.WriteString("\t</dict>\n")
+	}
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	b.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	b.WriteString("\t<key>ThrottleInterval</key>\n\t<integer>10</integer>\n")
+	fmt.Fprintf(&b, "\t<key>StandardOutPath</key>\n\t%s\n", str(logPath))
+	fmt.Fprintf(&b, "\t<key>StandardErrorPath</key>\n\t%s\n", str(logPath))
+	b.WriteString("</dict>\n</plist>\n")
+	return b.Bytes()
+}
+
+// plistArguments reads ProgramArguments back from a service's plist
+func plistArguments(data []byte) []string {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var args []string
+	var key, text string
+	inArgs := false
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return args
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			text = ""
+			if t.Name.Local == "array" && key == "ProgramArguments" {
+				inArgs = true
+			}
+		case xml.CharData:
+			text += string(t)
+		case xml.EndElement:
+			switch {
+			case t.Name.Local == "key":
+				key = text
+			case t.Name.Local == "array" && inArgs:
+				return args
+			case t.Name.Local == "string" && inArgs:
+				args = append(args, text)
+			}
+		}
+	}
+}
+
+// serviceState is what launchctl print says about a loaded service
+type serviceState struct {
+	state    string // running, or not running between restarts
+	pid      int
+	lastExit string // The last exit's code or signal, when it has exited
+}
+
+func (s serviceState) String() string {
+	switch {
+	case s.pid > 0:
+		return fmt.Sprintf("%s (pid %d)", s.state, s.pid)
+	case s.lastExit != "":
+		return fmt.Sprintf("%s (exit %s)", s.state, s.lastExit)
+	}
+	return s.state
+}
+
+// parseLaunchctlPrint reads the top-level state, pid and last exit code of
+// launchctl print output, skipping the nested blocks
+func parseLaunchctlPrint(out string) serviceState {
+	s := serviceState{state: "loaded"}
+	depth := 0
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasSuffix(line, "{") {
+			depth++
+			continue
+		}
+		if line == "}" {
+			depth--
+			continue
+		}
+		key, value, ok := strings.Cut(line, " = ")
+		if !ok || depth != 1 {
+			continue
+		}
+		switch key {
+		case "state":
+			s.state = value
+		case "pid":
+			s.pid, _ = strconv.Atoi(value)
+		case "last exit code":
+			if !strings.HasPrefix(value, "(") { // (never exited)
+				s.lastExit = value
+			}
+		}
+	}
+	return s
+}
@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestServiceMode(t *testing.T) {
+	for _, c := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"agent", "--interval", "2s"}, "agent"},
+		{[]string{"record", "-o", "a.mtop"}, "record"},
+		{[]string{"--prometheus", ":9100", "--select-disk", "disk0"}, "prometheus"},
+		{[]string{"-otlp-endpoint=http://localhost:4318"}, "otlp"},
+		{[]string{"--json", "--stream", "-d", "10"}, "stream"},
+		// main streams to InfluxDB before it would serve metrics
+		{[]string{"--prometheus", ":9100", "--influx-url", "http://db"}, "influx"},
+		{[]string{"--theme", "solarized"}, ""},
+		{[]string{"history"}, ""},
+	} {
+		got, ok := serviceMode(c.args)
+		if got != c.want || ok != (c.want != "") {
+			t.Errorf("serviceMode(%q) = %q, %v, want %q", c.args, got, ok, c.want)
+		}
+	}
+	if checkServiceName("prom-9100") != nil || checkServiceName("../evil") == nil || checkServiceName("") == nil {
+		t.Error("checkServiceName accepts the wrong names")
+	}
+}
+
+func TestServicePlist(t *testing.T) {
+	argv := []string{"/opt/homebrew/bin/mtop", "--serve", "127.0.0.1:8080", "--allow-origin", "http://a&b"}
+	plist := servicePlist(servicePrefix+"serve", argv, "/Users/me/Library/Logs/mtop/serve.log", map[string]string{"TMPDIR": "/var/folders/x/T/"})
+	for _, want := range []string{
+		"<key>Label</key>\n\t<string>com.github.khoi.mtop.serve</string>",
+		"<string>http://a&amp;b</string>",
+		"<key>TMPDIR</key>\n\t\t<string>/var/folders/x/T/</string>",
+		"<key>SuccessfulExit</key>\n\t\t<false/>",
+		"<key>StandardErrorPath</key>\n\t<string>/Users/me/Library/Logs/mtop/serve.log</string>",
+	} {
+		if !strings.Contains(string(plist), want) {
+			t.Errorf("plist lacks %q:\n%s", want, plist)
+		}
+	}
+	if got := plistArguments(plist); !slices.Equal(got, argv) {
+		t.Errorf("plistArguments = %q, want %q", got, argv)
+	}
+}
+
+func TestParseLaunchctlPrint(t *testing.T) {
+	out := `gui/501/com.github.khoi.mtop.agent = {
+	active count = 1
+	path = /Users/me/Library/LaunchAgents/com.github.khoi.mtop.agent.plist
+	state = running
+
+	program = /opt/homebrew/bin/mtop
+	arguments = {
+		/opt/homebrew/bin/mtop
+		agent
+	}
+
+	environment = {
+		state = ignored
+	}
+
+	pid = 412
+	last exit code = (never exited)
+}`
+	if got := parseLaunchctlPrint(out).String(); got != "running (pid 412)" {
+		t.Errorf("running service = %q", got)
+	}
+	out = strings.NewReplacer("state = running", "state = not running", "\tpid = 412\n", "", "(never exited)", "1").Replace(out)
+	if got := parseLaunchctlPrint(out).String(); got != "not running (exit 1)" {
+		t.Errorf("failed service = %q", got)
+	}
+}
@@ -0,0 +1,7 @@
+package tui
+
+// Explanations for the socket metrics, shown with the "e" key
+var socketDocs = []metricDoc{
+	{Name: "Sockets", Text: "TCP connections by state and open UDP sockets, parsed from the " +
+		"net.inet.tcp.pcblist_n and net.inet.udp.pcblist_n sysctls, the same source netstat uses."},
+}
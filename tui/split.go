@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Share of the content rows the top pane gets, in percent, and how far one
+// resize step moves the split
+const (
+	defaultSplitPercent = 50
+	minSplitPercent     = 20
+	maxSplitPercent     = 80
+	splitStep           = 10
+)
+
+// Fewest content rows a pane keeps however the split is resized
+const minPaneRows = 3
+
+// toggleSplit shows a second pane under the current view, processes unless
+// that is the current view, or closes it keeping the focused view
+func (m *model) toggleSplit() {
+	m.split = !m.split
+	if !m.split {
+		return
+	}
+	m.splitFocus = 0
+	if m.splitPercent == 0 {
+		m.splitPercent = defaultSplitPercent
+	}
+	m.otherView = ProcessMode
+	if m.viewMode == ProcessMode {
+		m.otherView = CPUDetailMode
+	}
+}
+
+// focusOtherPane moves the focus to the other pane. The focused pane's view
+// is always m.viewMode, so keys act on it.
+func (m *model) focusOtherPane() {
+	if !m.split {
+		return
+	}
+	m.viewMode, m.otherView = m.otherView, m.viewMode
+	m.splitFocus = 1 - m.splitFocus
+	m.explain, m.columnPicker = false, false
+}
+
+// resizeSplit moves the split by delta percent of the content rows
+func (m *model) resizeSplit(delta int) {
+	if m.split {
+		m.splitPercent = min(max(m.splitPercent+delta, minSplitPercent), maxSplitPercent)
+	}
+}
+
+// paneViews returns the views of the top and bottom panes
+func (m model) paneViews() (top, bottom ViewMode) {
+	if m.splitFocus == 0 {
+		return m.viewMode, m.otherView
+	}
+	return m.otherView, m.viewMode
+}
+
+// paneRows splits the rows between the header and footer, less a title
+// line per pane, between the two panes
+func (m model) paneRows() (top, bottom int) {
+	rows := max(m.height-viewChromeLines-2, 2*minPaneRows)
+	top = min(max(rows*m.splitPercent/100, minPaneRows), rows-minPaneRows)
+	return top, rows - top
+}
+
+// paneHeight is the terminal height at which a full-screen view gets rows
+// content rows, so views size charts and tables to their pane
+func paneHeight(rows int) int {
+	return rows + viewChromeLines - 1
+}
+
+// focusedPaneHeight is paneHeight for the focused pane
+func (m model) focusedPaneHeight() int {
+	top, bottom := m.paneRows()
+	if m.splitFocus == 0 {
+		return paneHeight(top)
+	}
+	return paneHeight(bottom)
+}
+
+// renderSplit stacks the two panes, each under a title line that is
+// highlighted for the focused pane
+func (m model) renderSplit() string {
+	topView, bottomView := m.paneViews()
+	topRows, bottomRows := m.paneRows()
+
+	var b strings.Builder
+	b.WriteString(m.renderPane(topView, topRows, m.splitFocus == 0))
+	b.WriteString(m.renderPane(bottomView, bottomRows, m.splitFocus == 1))
+	return b.String()
+}
+
+// renderPane renders a view into exactly rows lines under its title. The
+// view is laid out as if the terminal were only as tall as the pane.
+func (m model) renderPane(view ViewMode, rows int, focused bool) string {
+	pane := m
+	pane.split, pane.viewMode = false, view
+	pane.height = paneHeight(rows)
+	if !focused {
+		pane.explain, pane.debugView, pane.columnPicker = false, false, false
+	}
+
+	title := glyphs.line + " " + tabLabel(view) + " "
+	title += strings.Repeat(glyphs.line, max(m.width-ansi.StringWidth(title), 0))
+	style := helpStyle
+	if focused {
+		style = titleStyle
+	}
+
+	var b strings.Builder
+	b.WriteString(style.Render(title) + "\n")
+	lines := strings.Split(strings.TrimSuffix(pane.renderContent(), "\n"), "\n")
+	for i := 0; i < rows; i++ {
+		if i < len(lines) {
+			b.WriteString(lines[i])
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// splitModel renders the fixture like Render, with the split open
+func splitModel(width, height int) model {
+	stats := fixtureStats()
+	m := model{
+		stats:       stats,
+		viewMode:    CPUDetailMode,
+		refreshRate: time.Second,
+		width:       width,
+		height:      height,
+		session:     &cpuSession{procs: make(map[procKey]*sessionProc)},
+		history:     newHistory(),
+		columns:     defaultProcessColumns,
+	}
+	m.session.update(stats.Processes)
+	m.recordHistory(time.Time{}, stats)
+	m.toggleSplit()
+	return m
+}
+
+func TestSplitGolden(t *testing.T) {
+	checkGolden(t, "split_120x40", splitModel(120, 40).View())
+}
+
+func TestSplitFocusAndResize(t *testing.T) {
+	m := splitModel(100, 30)
+	if top, bottom := m.paneViews(); top != CPUDetailMode || bottom != ProcessMode {
+		t.Fatalf("panes = %v, %v; want CPU above processes", top, bottom)
+	}
+
+	// Keys act on the focused pane: the bottom one after moving focus
+	m.focusOtherPane()
+	if m.viewMode != ProcessMode || m.splitFocus != 1 {
+		t.Errorf("focus did not move to the process pane")
+	}
+	m.viewMode = MemoryDetailMode
+	if top, bottom := m.paneViews(); top != CPUDetailMode || bottom != MemoryDetailMode {
+		t.Errorf("switching views changed the wrong pane: %v, %v", top, bottom)
+	}
+
+	for i := 0; i < 10; i++ {
+		m.resizeSplit(splitStep)
+	}
+	if m.splitPercent != maxSplitPercent {
+		t.Errorf("split grew to %d%%, want the %d%% limit", m.splitPercent, maxSplitPercent)
+	}
+	top, bottom := m.paneRows()
+	if top+bottom != m.height-viewChromeLines-2 || bottom < minPaneRows {
+		t.Errorf("pane rows = %d + %d for height %d", top, bottom, m.height)
+	}
+
+	// The frame keeps the footer on screen whatever the panes hold
+	if lines := strings.Count(m.View(), "\n"); lines > m.height {
+		t.Errorf("frame has %d lines, taller than %d", lines, m.height)
+	}
+
+	m.toggleSplit()
+	if m.split || m.viewMode != MemoryDetailMode {
+		t.Errorf("closing the split did not keep the focused view")
+	}
+}
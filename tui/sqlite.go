@@ -0,0 +1,143 @@
+package tui
+
+/*
+#cgo LDFLAGS: -lsqlite3
+#include <sqlite3.h>
+#include <stdlib.h>
+
+// SQLITE_TRANSIENT is a cast cgo cannot express; it makes SQLite copy the
+// text before the Go memory is freed
+static int bindText(sqlite3_stmt *stmt, int i, const char *text, int n) {
+    return sqlite3_bind_text(stmt, i, text, n, SQLITE_TRANSIENT);
+}
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// sqliteDB is a connection to a database through the system libsqlite3.
+// It is not safe for concurrent use.
+type sqliteDB struct {
+	db *C.sqlite3
+}
+
+// sqliteStmt is a prepared statement of a sqliteDB
+type sqliteStmt struct {
+	stmt *C.sqlite3_stmt
+	db   *sqliteDB
+}
+
+// openSQLite opens the database at path, creating it if needed
+func openSQLite(path string) (*sqliteDB, error) {
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+	var db *C.sqlite3
+	rc := C.sqlite3_open_v2(cpath, &db, C.SQLITE_OPEN_READWRITE|C.SQLITE_OPEN_CREATE, nil)
+	if rc != C.SQLITE_OK {
+		err := fmt.Errorf("failed to open %s: %s", path, C.GoString(C.sqlite3_errstr(rc)))
+		C.sqlite3_close(db)
+		return nil, err
+	}
+	// Another mtop may be writing; wait for its transaction instead of failing
+	C.sqlite3_busy_timeout(db, 5000)
+	return &sqliteDB{db: db}, nil
+}
+
+func (d *sqliteDB) err() error {
+	return errors.New(C.GoString(C.sqlite3_errmsg(d.db)))
+}
+
+// exec runs one or more statements that take no parameters
+func (d *sqliteDB) exec(sql string) error {
+	csql := C.CString(sql)
+	defer C.free(unsafe.Pointer(csql))
+	if C.sqlite3_exec(d.db, csql, nil, nil, nil) != C.SQLITE_OK {
+		return d.err()
+	}
+	return nil
+}
+
+// prepare compiles a statement with ? parameters
+func (d *sqliteDB) prepare(sql string) (*sqliteStmt, error) {
+	csql := C.CString(sql)
+	defer C.free(unsafe.Pointer(csql))
+	var stmt *C.sqlite3_stmt
+	if C.sqlite3_prepare_v2(d.db, csql, -1, &stmt, nil) != C.SQLITE_OK {
+		return nil, d.err()
+	}
+	return &sqliteStmt{stmt: stmt, db: d}, nil
+}
+
+func (d *sqliteDB) close() error {
+	if C.sqlite3_close(d.db) != C.SQLITE_OK {
+		return d.err()
+	}
+	return nil
+}
+
+// bind resets the statement and binds args, which are int64, float64 or
+// string, to its parameters in order
+func (s *sqliteStmt) bind(args ...any) error {
+	C.sqlite3_reset(s.stmt)
+	C.sqlite3_clear_bindings(s.stmt)
+	for i, arg := range args {
+		n := C.int(i + 1)
+		var rc C.int
+		switch v := arg.(type) {
+		case int64:
+			rc = C.sqlite3_bind_int64(s.stmt, n, C.sqlite3_int64(v))
+		case float64:
+			rc = C.sqlite3_bind_double(s.stmt, n, C.double(v))
+		case string:
+			ctext := C.CString(v)
+			rc = C.bindText(s.stmt, n, ctext, C.int(len(v)))
+			C.free(unsafe.Pointer(ctext))
+		default:
+			return fmt.Errorf("cannot bind %T", arg)
+		}
+		if rc != C.SQLITE_OK {
+			return s.db.err()
+		}
+	}
+	return nil
+}
+
+// step advances to the next row, reporting false once there are no more
+func (s *sqliteStmt) step() (bool, error) {
+	switch C.sqlite3_step(s.stmt) {
+	case C.SQLITE_ROW:
+		return true, nil
+	case C.SQLITE_DONE:
+		return false, nil
+	}
+	return false, s.db.err()
+}
+
+// exec binds args and runs a statement that returns no rows
+func (s *sqliteStmt) exec(args ...any) error {
+	if err := s.bind(args...); err != nil {
+		return err
+	}
+	_, err := s.step()
+	return err
+}
+
+// Columns of the current row
+func (s *sqliteStmt) int64(col int) int64 {
+	return int64(C.sqlite3_column_int64(s.stmt, C.int(col)))
+}
+
+func (s *sqliteStmt) float64(col int) float64 {
+	return float64(C.sqlite3_column_double(s.stmt, C.int(col)))
+}
+
+func (s *sqliteStmt) text(col int) string {
+	return C.GoString((*C.char)(unsafe.Pointer(C.sqlite3_column_text(s.stmt, C.int(col)))))
+}
+
+func (s *sqliteStmt) finalize() {
+	C.sqlite3_finalize(s.stmt)
+}
@@ -0,0 +1,26 @@
+//go:build !cgo
+
+package tui
+
+import "errors"
+
+// Without cgo there is no libsqlite3, so --history-db fails to open
+
+var errSQLiteNoCgo = errors.New("the history database needs mtop built with cgo")
+
+type sqliteDB struct{}
+
+type sqliteStmt struct{}
+
+func openSQLite(path string) (*sqliteDB, error) { return nil, errSQLiteNoCgo }
+
+func (d *sqliteDB) exec(sql string) error                   { return errSQLiteNoCgo }
+func (d *sqliteDB) prepare(sql string) (*sqliteStmt, error) { return nil, errSQLiteNoCgo }
+func (d *sqliteDB) close() error                            { return nil }
+func (s *sqliteStmt) bind(args ...any) error                { return errSQLiteNoCgo }
+func (s *sqliteStmt) step() (bool, error)                   { return false, errSQLiteNoCgo }
+func (s *sqliteStmt) exec(args ...any) error                { return errSQLiteNoCgo }
+func (s *sqliteStmt) int64(col int) int64                   { return 0 }
+func (s *sqliteStmt) float64(col int) float64               { return 0 }
+func (s *sqliteStmt) text(col int) string                   { return "" }
+func (s *sqliteStmt) finalize()                             {}
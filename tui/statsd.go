@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Prepended to every StatsD metric name. Set with --statsd-prefix.
+var statsdPrefix = "mtop."
+
+// Largest datagram sent, small enough not to fragment on a 1500 byte MTU
+const statsdMaxPacket = 1432
+
+// checkStatsdPrefix rejects prefixes that would break the StatsD line format
+func checkStatsdPrefix(prefix string) error {
+	if strings.ContainsAny(prefix, ":|@# \t\n") {
+		return fmt.Errorf("%q: must not contain ':', '|', '@', '#' or whitespace", prefix)
+	}
+	return nil
+}
+
+// formatStatsdGauges renders one sample as StatsD gauges, one per history
+// metric sorted by name, e.g. "mtop.cpu.usage:42.1|g", followed by the
+// rates of each disk and interface, e.g. "mtop.net.en0.rx_rate:1024|g"
+func formatStatsdGauges(stats SystemStats) string {
+	metrics := historyMetrics(stats)
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	gauge := func(name string, v float64) {
+		fmt.Fprintf(&b, "%s%s:%s|g\n", statsdPrefix, name, strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	for _, name := range names {
+		gauge(name, metrics[name])
+	}
+	// Then the rates of each disk and interface, named after it
+	for _, d := range stats.Disk.Devices {
+		gauge("disk."+d.Name+".read_rate", d.ReadRate)
+		gauge("disk."+d.Name+".write_rate", d.WriteRate)
+		gauge("disk."+d.Name+".busy", d.Busy)
+	}
+	for _, i := range stats.Network.Interfaces {
+		gauge("net."+i.Name+".rx_rate", i.RxRate)
+		gauge("net."+i.Name+".tx_rate", i.TxRate)
+	}
+	return b.String()
+}
+
+// statsdSender sends what is written to it to a StatsD server over UDP,
+// packing whole lines into datagrams of up to statsdMaxPacket bytes. As
+// with any UDP sink a lost packet is not noticed; a failed send is
+// reported and dropped.
+type statsdSender struct {
+	conn net.Conn
+	errs io.Writer
+}
+
+func newStatsdSender(addr string) (*statsdSender, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &statsdSender{conn: conn, errs: os.Stderr}, nil
+}
+
+func (s *statsdSender) Write(lines []byte) (int, error) {
+	for _, packet := range statsdPackets(lines) {
+		if _, err := s.conn.Write(packet); err != nil {
+			fmt.Fprintf(s.errs, "Warning: StatsD send failed: %v\n", err)
+			break
+		}
+	}
+	return len(lines), nil
+}
+
+// statsdPackets splits newline-terminated lines into datagrams without
+// breaking a line; a line longer than a packet is sent on its own
+func statsdPackets(lines []byte) [][]byte {
+	var packets [][]byte
+	start, end := 0, 0
+	for end < len(lines) {
+		next := len(lines)
+		if i := bytes.IndexByte(lines[end:], '\n'); i >= 0 {
+			next = end + i + 1
+		}
+		if next-start > statsdMaxPacket && end > start {
+			packets = append(packets, lines[start:end])
+			start = end
+		}
+		end = next
+	}
+	if end > start {
+		packets = append(packets, lines[start:end])
+	}
+	return packets
+}
@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsdGauges(t *testing.T) {
+	defer func(p string) { statsdPrefix = p }(statsdPrefix)
+	statsdPrefix = "box."
+	out := formatStatsdGauges(fixtureStats())
+	if !strings.Contains(out, "box.cpu.usage:37.5|g\n") {
+		t.Errorf("CPU usage gauge missing:\n%s", out)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		if !strings.HasPrefix(line, "box.") || !strings.HasSuffix(line, "|g") || strings.Count(line, ":") != 1 {
+			t.Errorf("malformed gauge %q", line)
+		}
+	}
+	if err := checkStatsdPrefix("mtop:"); err == nil {
+		t.Error("a prefix with ':' was accepted")
+	}
+}
+
+func TestStatsdPackets(t *testing.T) {
+	line := strings.Repeat("x", 600) + "\n"
+	lines := []byte(strings.Repeat(line, 5))
+	packets := statsdPackets(lines)
+	if len(packets) != 3 || !bytes.Equal(bytes.Join(packets, nil), lines) {
+		t.Fatalf("%d packets of %d lines, want 3 holding them all", len(packets), 5)
+	}
+	for _, p := range packets {
+		if len(p) > statsdMaxPacket || p[len(p)-1] != '\n' {
+			t.Errorf("packet of %d bytes splits a line or is too big", len(p))
+		}
+	}
+}
+
+func TestStatsdSender(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+	s, err := newStatsdSender(conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Write([]byte("mtop.cpu.usage:1|g\n"))
+
+	buf := make([]byte, statsdMaxPacket)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil || string(buf[:n]) != "mtop.cpu.usage:1|g\n" {
+		t.Errorf("received %q, %v", buf[:n], err)
+	}
+}
@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Default header and footer templates, matching the built-in layout
+const (
+	defaultHeaderTemplate = "Last update: {updated} | Refresh rate: {refresh} | Thermal: {thermal}{alerts}"
+	defaultFooterTemplate = "{keys}"
+)
+
+// statusFields are the placeholders a header or footer template can use
+var statusFields = map[string]func(m model) string{
+	"hostname": func(m model) string {
+		if m.remote != nil {
+			return m.remote.hostname()
+		}
+		return hostname
+	},
+	"clock":   func(m model) string { return time.Now().Format("15:04:05") },
+	"updated": func(m model) string { return m.lastUpdate.Format("15:04:05") },
+	"uptime":  func(m model) string { return m.stats.Uptime.Round(time.Second).String() },
+	"refresh": func(m model) string {
+		return fmt.Sprint(m.refreshRate) + m.adaptiveNote() + m.quietNote() + m.agentNote()
+	},
+	"view":        func(m model) string { return tabLabel(m.viewMode) },
+	"thermal":     func(m model) string { return renderThermal(m.stats.Thermal) },
+	"alerts":      func(m model) string { return renderAlerts(m.alerts(), m.sampledAt) },
+	"alert_count": func(m model) string { return fmt.Sprint(len(m.alerts())) },
+	"cpu":         func(m model) string { return fmt.Sprintf("%.1f%%", m.stats.CPU.Usage) },
+	"memory":      func(m model) string { return fmt.Sprintf("%.1f%%", m.stats.Memory.Usage) },
+	"keys":        func(m model) string { return renderedHelp() },
+}
+
+// Name of this machine for the hostname placeholder
+var hostname, _ = os.Hostname()
+
+// statusPart is literal text or, when field is set, a placeholder
+type statusPart struct {
+	text  string
+	field string
+}
+
+// Parsed header and footer templates, set by applyStatusTemplates
+var (
+	headerTemplate = mustParseStatus(defaultHeaderTemplate)
+	footerTemplate = mustParseStatus(defaultFooterTemplate)
+)
+
+// parseStatusTemplate splits a template into text and {field}
+// placeholders; "{{" stands for a literal brace
+func parseStatusTemplate(tmpl string) ([]statusPart, error) {
+	var parts []statusPart
+	var text strings.Builder
+	for i := 0; i < len(tmpl); i++ {
+		switch {
+		case strings.HasPrefix(tmpl[i:], "{{"):
+			text.WriteByte('{')
+			i++
+		case tmpl[i] == '{':
+			end := strings.IndexByte(tmpl[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed { in %q", tmpl)
+			}
+			name := tmpl[i+1 : i+end]
+			if _, ok := statusFields[name]; !ok {
+				return nil, fmt.Errorf("unknown field {%s} (want %s)", name, strings.Join(statusFieldNames(), ", "))
+			}
+			if text.Len() > 0 {
+				parts = append(parts, statusPart{text: text.String()})
+				text.Reset()
+			}
+			parts = append(parts, statusPart{field: name})
+			i += end
+		default:
+			text.WriteByte(tmpl[i])
+		}
+	}
+	if text.Len() > 0 {
+		parts = append(parts, statusPart{text: text.String()})
+	}
+	return parts, nil
+}
+
+func mustParseStatus(tmpl string) []statusPart {
+	parts, err := parseStatusTemplate(tmpl)
+	if err != nil {
+		panic(err)
+	}
+	return parts
+}
+
+// statusFieldNames lists the placeholders in sorted order
+func statusFieldNames() []string {
+	names := make([]string, 0, len(statusFields))
+	for name := range statusFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyStatusTemplates installs the header and footer templates from the
+// config; empty ones keep the defaults
+func applyStatusTemplates(header, footer string) error {
+	if header != "" {
+		parts, err := parseStatusTemplate(header)
+		if err != nil {
+			return fmt.Errorf("header: %w", err)
+		}
+		headerTemplate = parts
+	}
+	if footer != "" {
+		parts, err := parseStatusTemplate(footer)
+		if err != nil {
+			return fmt.Errorf("footer: %w", err)
+		}
+		footerTemplate = parts
+	}
+	return nil
+}
+
+// renderStatus fills in a template for the current frame
+func (m model) renderStatus(parts []statusPart) string {
+	var b strings.Builder
+	for _, p := range parts {
+		if p.field != "" {
+			b.WriteString(statusFields[p.field](m))
+		} else {
+			b.WriteString(p.text)
+		}
+	}
+	return b.String()
+}
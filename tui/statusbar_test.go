@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatusTemplate(t *testing.T) {
+	parts, err := parseStatusTemplate("{view} {{cpu}: {cpu} | up {uptime} | {alert_count} alerts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := model{viewMode: ProcessMode, stats: SystemStats{CPU: CPUStats{Usage: 37.5}, Uptime: 90 * time.Minute}}
+	if got, want := m.renderStatus(parts), "Processes {cpu}: 37.5% | up 1h30m0s | 0 alerts"; got != want {
+		t.Errorf("rendered %q, want %q", got, want)
+	}
+
+	for _, bad := range []string{"{host}", "load {cpu"} {
+		if _, err := parseStatusTemplate(bad); err == nil {
+			t.Errorf("%q was accepted", bad)
+		}
+	}
+}
+
+func TestCustomHeaderAndFooter(t *testing.T) {
+	defer func() {
+		headerTemplate, footerTemplate = mustParseStatus(defaultHeaderTemplate), mustParseStatus(defaultFooterTemplate)
+	}()
+	if err := applyStatusTemplates("CPU {cpu} on {view}", "{keys} | {memory}"); err != nil {
+		t.Fatal(err)
+	}
+	frame := Render(fixtureStats(), 80, 24, OverviewMode)
+	lines := strings.Split(strings.TrimSuffix(frame, "\n"), "\n")
+	if lines[1] != "CPU 37.5% on Overview" {
+		t.Errorf("header = %q", lines[1])
+	}
+	if last := lines[len(lines)-1]; !strings.HasPrefix(last, "tab/shift+tab: Views") || !strings.HasSuffix(last, "| 68.8%") {
+		t.Errorf("footer = %q", last)
+	}
+}
@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/khoi/mtop/stats"
+)
+
+// streamConfig holds what the stream options set
+type streamConfig struct {
+	collectors []string // On-demand collectors to run for the stream
+}
+
+// streamOption configures a stream started by streamStats
+type streamOption func(*streamConfig) error
+
+// withCollectors runs the named on-demand collectors, which are otherwise
+// skipped because no view subscribes to them
+func withCollectors(names ...string) streamOption {
+	return func(c *streamConfig) error {
+		for _, name := range names {
+			if !slices.Contains(onDemandCollectors, name) {
+				return fmt.Errorf("unknown on-demand collector %q (want %s)", name, strings.Join(onDemandCollectors, ", "))
+			}
+		}
+		c.collectors = append(c.collectors, names...)
+		return nil
+	}
+}
+
+// streamStats is the push-style entry point the long-running modes share:
+// stats.Subscribe sampling with the same scheduler as the TUI, so cadences,
+// the watchdog and plugins apply. It pushes a sample every interval until
+// ctx is done, when the channel is closed; a consumer that falls behind
+// gets the newest sample rather than a backlog.
+func streamStats(ctx context.Context, interval time.Duration, opts ...streamOption) (<-chan SystemStats, error) {
+	if err := checkRefreshRate(interval); err != nil {
+		return nil, fmt.Errorf("interval %v", err)
+	}
+	var cfg streamConfig
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return nil, err
+		}
+	}
+	return stats.Subscribe(ctx, interval, stats.WithSampler(&streamSampler{
+		sched:      newScheduler(),
+		interval:   interval,
+		collectors: cfg.collectors,
+	}))
+}
+
+// streamSampler samples a stream with the scheduler. Demand is only
+// touched by the goroutine that collects, which calls Collect and Close.
+type streamSampler struct {
+	sched      *scheduler
+	interval   time.Duration
+	collectors []string
+	subscribed bool
+}
+
+func (s *streamSampler) Collect(ctx context.Context) (SystemStats, error) {
+	if !s.subscribed {
+		subscribe("stream", s.collectors...)
+		s.subscribed = true
+	}
+	sample, err := s.sched.collect(ctx, time.Now(), s.interval)
+	return selectDevices(sample), err
+}
+
+// Close unsubscribes the stream's on-demand collectors
+func (s *streamSampler) Close() error {
+	subscribe("stream")
+	return nil
+}
@@ -0,0 +1,40 @@
+package tui
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStreamStatsOptions(t *testing.T) {
+	ctx := context.Background()
+	if _, err := streamStats(ctx, time.Millisecond); err == nil {
+		t.Error("interval below the minimum refresh rate accepted")
+	}
+	if _, err := streamStats(ctx, time.Second, withCollectors("cpufreq")); err == nil {
+		t.Error("collector that always runs accepted as on-demand")
+	}
+}
+
+func TestStreamStatsClosesOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	samples, err := streamStats(ctx, minRefreshRate, withCollectors("sockets"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-samples:
+			if !ok {
+				if collectorActive("sockets") {
+					t.Error("sockets collector still subscribed after the stream ended")
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("stream not closed after cancel")
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Length of one CPU worker duty cycle; each cycle is split into a busy and
+// an idle part according to --load
+const stressCycle = 100 * time.Millisecond
+
+// stressConfig describes the load "mtop stress" generates
+type stressConfig struct {
+	CPUWorkers int           // Busy threads to run
+	Load       int           // Percentage of each cycle a CPU worker stays busy
+	Memory     uint64        // Bytes to allocate and keep resident
+	Duration   time.Duration // How long to hold the load
+	Seed       int64         // Seed for the generated memory contents and CPU work
+}
+
+// runStress implements "mtop stress": generate a known CPU and memory load
+// so mtop's readings can be checked against it
+func runStress(args []string) int {
+	fs := flag.NewFlagSet("stress", flag.ExitOnError)
+	cpu := fs.Int("cpu", 0, "Number of CPU worker threads")
+	load := fs.Int("load", 100, "Percentage of time each CPU worker stays busy (1-100)")
+	mem := fs.String("mem", "", "Memory to allocate and keep resident (e.g. 512M, 2G)")
+	duration := fs.Duration("duration", 30*time.Second, "How long to hold the load")
+	seed := fs.Int64("seed", 1, "Seed for the generated work, so runs are reproducible")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s stress [OPTIONS]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Generates a controlled CPU and memory load until the duration elapses or it is interrupted.\n")
+		fmt.Fprintf(os.Stderr, "Memory is filled with seeded pseudo-random data so the compressor cannot shrink it.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	cfg := stressConfig{CPUWorkers: *cpu, Load: *load, Duration: *duration, Seed: *seed}
+	if *mem != "" {
+		size, err := parseSize(*mem)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --mem: %v\n", err)
+			return 2
+		}
+		cfg.Memory = size
+	}
+	if cfg.CPUWorkers < 0 || cfg.Load < 1 || cfg.Load > 100 || cfg.Duration <= 0 {
+		fs.Usage()
+		return 2
+	}
+	if cfg.CPUWorkers == 0 && cfg.Memory == 0 {
+		fmt.Fprintf(os.Stderr, "Nothing to do: set --cpu and/or --mem\n")
+		return 2
+	}
+
+	fmt.Fprintf(os.Stderr, "mtop stress: pid %d, %d CPU workers at %d%%, %s memory, %v, seed %d\n",
+		os.Getpid(), cfg.CPUWorkers, cfg.Load, formatBytes(cfg.Memory), cfg.Duration, cfg.Seed)
+
+	// An interrupt during the allocation waits here and ends the run as
+	// soon as it is done
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+
+	start := time.Now()
+	resident := stressMemory(cfg.Memory, cfg.Seed)
+	fmt.Fprintf(os.Stderr, "Allocated %s in %v\n", formatBytes(uint64(len(resident))),
+		time.Since(start).Round(time.Millisecond))
+
+	// --duration counts from here, so the full load is held for all of it
+	// however long touching the pages took
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-interrupted:
+		case <-time.After(cfg.Duration):
+		}
+		close(stop)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.CPUWorkers; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			stressCPU(cfg.Seed+int64(worker), cfg.Load, stop)
+		}(i)
+	}
+	<-stop
+	wg.Wait()
+
+	// Keep the allocation alive until the load is released
+	runtime.KeepAlive(resident)
+	fmt.Fprintf(os.Stderr, "Done after %v\n", time.Since(start).Round(time.Millisecond))
+	return 0
+}
+
+// stressMemory allocates size bytes and fills every page with seeded
+// pseudo-random data, making the whole allocation resident
+func stressMemory(size uint64, seed int64) []byte {
+	if size == 0 {
+		return nil
+	}
+	buf := make([]byte, size)
+	rand.New(rand.NewSource(seed)).Read(buf)
+	return buf
+}
+
+// stressCPU keeps one OS thread busy for load percent of every cycle until
+// stop is closed
+func stressCPU(seed int64, load int, stop <-chan struct{}) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	rng := rand.New(rand.NewSource(seed))
+	busy := stressCycle * time.Duration(load) / 100
+	var sink uint64
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		cycleStart := time.Now()
+		for time.Since(cycleStart) < busy {
+			for i := 0; i < 1000; i++ {
+				sink ^= rng.Uint64()
+			}
+		}
+		if idle := stressCycle - time.Since(cycleStart); idle > 0 {
+			time.Sleep(idle)
+		}
+	}
+}
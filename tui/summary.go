@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"time"
+)
+
+// metricSummary is the spread of one metric over a batch run
+type metricSummary struct {
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+	Max float64 `json:"max"`
+}
+
+// runSummary ends a JSON batch run with --summary: the percentiles of each
+// history metric across the run's samples, so consumers need not work
+// them out from every line
+type runSummary struct {
+	Start   time.Time                `json:"start"`
+	Samples int                      `json:"samples"`
+	Metrics map[string]metricSummary `json:"metrics"`
+}
+
+// runSummarizer gathers the metrics of each sample for the summary
+type runSummarizer struct {
+	start   time.Time
+	end     time.Time
+	samples int
+	values  map[string][]float64
+}
+
+func newRunSummarizer() *runSummarizer {
+	return &runSummarizer{values: make(map[string][]float64)}
+}
+
+func (s *runSummarizer) add(at time.Time, stats SystemStats) {
+	if s.samples == 0 {
+		s.start = at
+	}
+	s.end = at
+	s.samples++
+	for name, v := range historyMetrics(stats) {
+		s.values[name] = append(s.values[name], v)
+	}
+}
+
+func (s *runSummarizer) summary() runSummary {
+	sum := runSummary{Start: s.start, Samples: s.samples, Metrics: make(map[string]metricSummary, len(s.values))}
+	for name, values := range s.values {
+		sorted := slices.Clone(values)
+		slices.Sort(sorted)
+		sum.Metrics[name] = metricSummary{
+			P50: percentile(sorted, 50),
+			P95: percentile(sorted, 95),
+			Max: sorted[len(sorted)-1],
+		}
+	}
+	return sum
+}
+
+// formatSummaryLine renders the summary as an import format JSON line at
+// the time of the last sample, which mtop import and --replay skip
+func (s *runSummarizer) formatSummaryLine() (string, error) {
+	sum := s.summary()
+	line, err := json.Marshal(importRecord{Time: s.end, Host: hostname, Summary: &sum})
+	if err != nil {
+		return "", err
+	}
+	return string(line) + "\n", nil
+}
+
+// percentile is the nearest-rank p-th percentile of sorted values: the
+// smallest value at least p percent of them are at or below
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
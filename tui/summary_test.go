@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	for p, want := range map[float64]float64{0: 1, 50: 5, 95: 10, 100: 10, 10: 1, 11: 2} {
+		if got := percentile(sorted, p); got != want {
+			t.Errorf("p%v = %v, want %v", p, got, want)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("percentile of nothing = %v", got)
+	}
+}
+
+func TestRunSummaryLine(t *testing.T) {
+	sum := newRunSummarizer()
+	start := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	var b strings.Builder
+	for i := range 20 {
+		stats := fixtureStats()
+		stats.CPU.Usage = float64(20 - i)
+		at := start.Add(time.Duration(i) * time.Second)
+		sum.add(at, stats)
+		line, _ := formatSampleLine(at, stats)
+		b.WriteString(line)
+	}
+	line, err := sum.formatSummaryLine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.WriteString(line)
+
+	var rec importRecord
+	if err := json.Unmarshal([]byte(line), &rec); err != nil || rec.Summary == nil {
+		t.Fatalf("summary line %q: %v", line, err)
+	}
+	if !rec.Time.Equal(start.Add(19*time.Second)) || !rec.Summary.Start.Equal(start) || rec.Summary.Samples != 20 {
+		t.Errorf("summary covers %v to %v, %d samples", rec.Summary.Start, rec.Time, rec.Summary.Samples)
+	}
+	if got := rec.Summary.Metrics[metricCPU]; got != (metricSummary{P50: 10, P95: 19, Max: 20}) {
+		t.Errorf("cpu.usage summary = %+v", got)
+	}
+	if got := rec.Summary.Metrics[metricGPU]; got.P50 != 23 || got.Max != 23 {
+		t.Errorf("gpu.usage summary = %+v", got)
+	}
+
+	// Readers of the stream skip the summary
+	if n, err := importSamples(newHistory(), nil, strings.NewReader(b.String()), "run.jsonl"); err != nil || n != 20 {
+		t.Errorf("imported %d samples: %v", n, err)
+	}
+	if shots, _, err := loadReplay(strings.NewReader(b.String())); err != nil || len(shots) != 20 {
+		t.Errorf("replayed %d samples: %v", len(shots), err)
+	}
+}
@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"context"
+
+	"github.com/khoi/mtop/stats"
+)
+
+// collector samples this Mac for every collection path. Its memory
+// accounting is set from the --memory-mode flag and toggled from the memory
+// view.
+var collector = stats.NewCollector()
+
+// collectSystemStats gathers all system statistics, stopping early with
+// ctx's error once it is done
+func collectSystemStats(ctx context.Context) (SystemStats, error) {
+	var stats SystemStats
+	for _, g := range statsGroups {
+		if !collectorActive(g.name) {
+			continue
+		}
+		// Only the required groups, which the watchdog does not run, abort;
+		// the others leave their readings empty
+		err := runGroup(ctx, g, &stats)
+		if ctx.Err() != nil {
+			return stats, ctx.Err()
+		}
+		if err != nil && !g.watch {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// Explanations for the memory metrics, shown with the "e" key
+var memoryDocs = []metricDoc{
+	{Name: "Memory Usage", Text: "Used memory as a percentage of physical memory (hw.memsize)."},
+	{Name: "Used", Text: "Pages from host_statistics64: active + inactive + wired + speculative + " +
+		"compressed - purgeable - external (file-backed), times the page size. " +
+		"In Activity Monitor mode (a key or --memory-mode activity-monitor) it is App Memory " +
+		"(internal - purgeable) + wired + compressed instead, matching Activity Monitor's Memory Used. " +
+		"If the result is negative or used + free exceeds the physical page count, it is clamped and " +
+		"the inconsistency is reported in the debug section (d key)."},
+	{Name: "Available", Text: "Memory that can be handed to applications without swapping: " +
+		"free + inactive + purgeable pages, capped at physical memory."},
+	{Name: "Pressure", Text: "The kernel's own memory health signal from kern.memorystatus_vm_pressure_level. " +
+		"Normal means memory is plentiful, Warn that the system is compressing and reclaiming " +
+		"aggressively, Critical that it is about to swap heavily or terminate processes. " +
+		"This is a better indicator of trouble than the used percentage, since macOS keeps memory full on purpose."},
+	{Name: "Swap Usage", Text: "Swap file space in use out of the space currently allocated for swap."},
+	{Name: "Swap Rate", Text: "Bytes swapped in and out per second since the previous sample, from the swapins and " +
+		"swapouts page counters of host_statistics64. Sustained swapping slows everything down far more than a full swap file."},
+}
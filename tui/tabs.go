@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// viewTab is a view in the tab bar. Adding a view means adding its mode,
+// a tab here, a case in renderContent and, if it gets a shortcut, a key
+// binding under its name.
+type viewTab struct {
+	mode  ViewMode
+	name  string // Name in default_view and the key binding action
+	label string // Label in the tab bar and split pane titles
+	help  string // Label in the help overlay
+}
+
+// Views in tab order
+var viewTabs = []viewTab{
+	{OverviewMode, "overview", "Overview", "Overview"},
+	{CPUDetailMode, "cpu", "CPU", "CPU details"},
+	{MemoryDetailMode, "memory", "Memory", "Memory details"},
+	{GPUDetailMode, "gpu", "GPU", "GPU details"},
+	{FlameMode, "flame", "Flame", "CPU flame graph"},
+	{MemoryTreemapMode, "treemap", "Treemap", "Memory treemap"},
+	{ProcessMode, "processes", "Processes", "Processes"},
+	{PowerMode, "power", "Power", "Power"},
+	{NetworkMode, "network", "Network", "Network"},
+	{ErrorsMode, "errors", "Errors", "Collector errors"},
+	{CompareMode, "compare", "Compare", "Recorded vs live"},
+	{CorrelateMode, "correlate", "Correlate", "Two metrics correlated"},
+}
+
+// Views that can be named in default_view and key bindings
+var viewNames = func() map[string]ViewMode {
+	names := make(map[string]ViewMode, len(viewTabs))
+	for _, tab := range viewTabs {
+		names[tab.name] = tab.mode
+	}
+	return names
+}()
+
+// tabIndex returns the position of a view in the tab bar
+func tabIndex(mode ViewMode) int {
+	for i, tab := range viewTabs {
+		if tab.mode == mode {
+			return i
+		}
+	}
+	return 0
+}
+
+// tabLabel returns the tab bar label of a view
+func tabLabel(mode ViewMode) string {
+	return viewTabs[tabIndex(mode)].label
+}
+
+// cycleView returns the view delta tabs away, wrapping around
+func cycleView(mode ViewMode, delta int) ViewMode {
+	n := len(viewTabs)
+	return viewTabs[((tabIndex(mode)+delta)%n+n)%n].mode
+}
+
+// tabBarPrefix is shown before the tabs
+func tabBarPrefix() string {
+	return "mtop " + glyphs.sep + " "
+}
+
+// Full tab bar with each view active, set by applyTheme
+var renderedTabBars map[ViewMode]string
+
+// renderTabBars renders the full tab bar with each view active
+func renderTabBars() map[ViewMode]string {
+	bars := make(map[ViewMode]string, len(viewTabs))
+	for _, tab := range viewTabs {
+		bars[tab.mode] = renderTabBar(tab.mode, 0, len(viewTabs))
+	}
+	return bars
+}
+
+// renderTabBar renders the tabs from first up to last, marking the active
+// one with brackets so it shows without colors too
+func renderTabBar(active ViewMode, first, last int) string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tabBarPrefix()))
+	if first > 0 {
+		b.WriteString(helpStyle.Render(glyphs.moreLeft + " "))
+	}
+	for i := first; i < last; i++ {
+		if i > first {
+			b.WriteString(" ")
+		}
+		if viewTabs[i].mode == active {
+			b.WriteString(titleStyle.Render("[" + viewTabs[i].label + "]"))
+		} else {
+			b.WriteString(helpStyle.Render(viewTabs[i].label))
+		}
+	}
+	if last < len(viewTabs) {
+		b.WriteString(helpStyle.Render(" " + glyphs.moreRight))
+	}
+	return b.String()
+}
+
+// tabBar returns the header line naming every view with the active one
+// marked. When the bar is wider than the terminal only the tabs around the
+// active one are shown, with arrows where some are hidden.
+func tabBar(active ViewMode, width int) string {
+	bar := renderedTabBars[active]
+	if width <= 0 || ansi.StringWidth(bar) <= width {
+		return bar + "\n"
+	}
+
+	// Widen the window one tab at a time, right first, while it fits with
+	// both arrows
+	room := width - ansi.StringWidth(tabBarPrefix()) - 4
+	i := tabIndex(active)
+	first, last := i, i+1
+	used := len(viewTabs[i].label) + 2
+	for grown := true; grown; {
+		grown = false
+		if last < len(viewTabs) && used+1+len(viewTabs[last].label) <= room {
+			used += 1 + len(viewTabs[last].label)
+			last++
+			grown = true
+		}
+		if first > 0 && used+1+len(viewTabs[first-1].label) <= room {
+			used += 1 + len(viewTabs[first-1].label)
+			first--
+			grown = true
+		}
+	}
+	return renderTabBar(active, first, last) + "\n"
+}
@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestTabKeysCycleViews(t *testing.T) {
+	var tm tea.Model = model{viewMode: OverviewMode, history: newHistory()}
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if mode := tm.(model).viewMode; mode != CPUDetailMode {
+		t.Errorf("tab from the overview went to %v, want CPU", mode)
+	}
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if mode := tm.(model).viewMode; mode != viewTabs[len(viewTabs)-1].mode {
+		t.Errorf("shift+tab from the overview went to %v, want the last tab", mode)
+	}
+}
+
+func TestTabBarFitsWidth(t *testing.T) {
+	for _, tab := range viewTabs {
+		for _, width := range []int{40, 60, 80, 200} {
+			bar := strings.TrimSuffix(tabBar(tab.mode, width), "\n")
+			if w := ansi.StringWidth(bar); w > width {
+				t.Errorf("%s at width %d: bar is %d wide: %q", tab.name, width, w, bar)
+			}
+			if !strings.Contains(bar, "["+tab.label+"]") {
+				t.Errorf("%s at width %d: active tab not shown: %q", tab.name, width, bar)
+			}
+		}
+	}
+}
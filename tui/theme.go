@@ -0,0 +1,181 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/khoi/mtop/widgets"
+)
+
+// theme is a named set of colors used across the views. An empty color
+// leaves the terminal's default.
+type theme struct {
+	Name      string
+	Title     lipgloss.Color   // View title in the header
+	Rule      lipgloss.Color   // Separator lines
+	Muted     lipgloss.Color   // Key help and empty bar cells
+	Good      lipgloss.Color   // Low usage and healthy states
+	Warn      lipgloss.Color   // Elevated usage
+	Bad       lipgloss.Color   // High usage and critical states
+	Severe    lipgloss.Color   // Between Warn and Bad, e.g. serious thermal pressure
+	Changed   lipgloss.Color   // Values that moved since the last refresh
+	Selection lipgloss.Color   // Selected row background; empty uses reverse video
+	Flame     []lipgloss.Color // Flame graph cell backgrounds
+	Treemap   []lipgloss.Color // Treemap cell backgrounds
+}
+
+// Built-in themes; the first is the default
+var themes = []theme{
+	{
+		Name:      "default",
+		Title:     "12",
+		Rule:      "240",
+		Muted:     "244",
+		Good:      "2",
+		Warn:      "3",
+		Bad:       "1",
+		Severe:    "208",
+		Changed:   "11",
+		Selection: "237",
+		Flame:     []lipgloss.Color{"160", "166", "172", "178", "202", "208", "214", "220"},
+		Treemap:   []lipgloss.Color{"24", "30", "66", "96", "132", "60", "29", "94", "61", "95"},
+	},
+	{
+		Name:      "solarized",
+		Title:     "#268bd2",
+		Rule:      "#586e75",
+		Muted:     "#657b83",
+		Good:      "#859900",
+		Warn:      "#b58900",
+		Bad:       "#dc322f",
+		Severe:    "#cb4b16",
+		Changed:   "#2aa198",
+		Selection: "#073642",
+		Flame:     []lipgloss.Color{"#dc322f", "#cb4b16", "#b58900", "#d33682"},
+		Treemap:   []lipgloss.Color{"#268bd2", "#2aa198", "#6c71c4", "#859900", "#586e75", "#d33682"},
+	},
+	{
+		Name:    "monochrome",
+		Flame:   []lipgloss.Color{"236", "239", "242", "245"},
+		Treemap: []lipgloss.Color{"235", "238", "241", "244", "247"},
+	},
+	{
+		Name:      "high-contrast",
+		Title:     "15",
+		Rule:      "15",
+		Muted:     "250",
+		Good:      "10",
+		Warn:      "11",
+		Bad:       "9",
+		Severe:    "202",
+		Changed:   "14",
+		Selection: "4",
+		Flame:     []lipgloss.Color{"9", "202", "11", "13"},
+		Treemap:   []lipgloss.Color{"4", "5", "6", "2", "1", "3"},
+	},
+}
+
+// currentTheme is the active theme; change it with applyTheme
+var currentTheme theme
+
+// defaultTheme is the theme of Models without their own: --theme, or the
+// one cycled to in such a Model
+var defaultTheme = themes[0]
+
+// Styles derived from the current theme
+var (
+	titleStyle lipgloss.Style
+	ruleStyle  lipgloss.Style
+	helpStyle  lipgloss.Style
+	barStyles  [3]lipgloss.Style // Good, warn and bad bar cells
+)
+
+// Header and footer pieces that only change with the theme, rendered once
+var (
+	renderedRule string
+)
+
+func init() {
+	applyTheme(themes[0])
+}
+
+// findTheme looks up a built-in theme by name
+func findTheme(name string) (theme, error) {
+	var names []string
+	for _, t := range themes {
+		if t.Name == name {
+			return t, nil
+		}
+		names = append(names, t.Name)
+	}
+	return theme{}, fmt.Errorf("unknown theme %q (want %s)", name, strings.Join(names, ", "))
+}
+
+// nextTheme returns the theme after the current one, wrapping around
+func nextTheme() theme {
+	for i, t := range themes {
+		if t.Name == currentTheme.Name {
+			return themes[(i+1)%len(themes)]
+		}
+	}
+	return themes[0]
+}
+
+// applyTheme makes t the current theme and rebuilds the styles derived from it
+func applyTheme(t theme) {
+	currentTheme = t
+
+	titleStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Title)
+	ruleStyle = lipgloss.NewStyle().Foreground(t.Rule)
+	helpStyle = lipgloss.NewStyle().Foreground(t.Muted)
+	barStyles = [3]lipgloss.Style{
+		lipgloss.NewStyle().Foreground(t.Good),
+		lipgloss.NewStyle().Foreground(t.Warn),
+		lipgloss.NewStyle().Foreground(t.Bad),
+	}
+
+	renderedRule = ruleStyle.Render(strings.Repeat(glyphs.rule, viewRuleWidth))
+	renderedTabBars = renderTabBars()
+	cachedHelp = ""
+
+	changedStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Changed)
+	risingStyle = lipgloss.NewStyle().Foreground(t.Bad)
+	fallingStyle = lipgloss.NewStyle().Foreground(t.Good)
+
+	fdWarnStyle = lipgloss.NewStyle().Foreground(t.Warn).Bold(true)
+	fdCriticalStyle = lipgloss.NewStyle().Foreground(t.Bad).Bold(true)
+
+	selectedRowStyle = lipgloss.NewStyle().Background(t.Selection)
+	if t.Selection == "" {
+		selectedRowStyle = lipgloss.NewStyle().Reverse(true)
+	}
+
+	alertStyle = lipgloss.NewStyle().Foreground(t.Bad).Bold(true)
+	alertIndicatorStyle = alertStyle.Blink(true)
+	pausedStyle = lipgloss.NewStyle().Bold(true).Reverse(true).Foreground(t.Warn)
+
+	helpBoxStyle = lipgloss.NewStyle().Border(glyphs.border).BorderForeground(t.Title).Padding(0, 1)
+	helpSectionStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Title)
+
+	overlayStyles = []lipgloss.Style{
+		lipgloss.NewStyle().Foreground(t.Severe),
+		lipgloss.NewStyle().Foreground(t.Title),
+	}
+
+	flamePalette = t.Flame
+	treemapPalette = t.Treemap
+
+	widgets.BarStyles = barStyles
+	widgets.BarEmptyStyle = helpStyle
+	widgets.SelectedStyle = selectedRowStyle
+}
+
+// alertBar draws a usage bar with every filled cell in the bad color
+func alertBar(percent float64, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	filled := min(max(int(percent/100*float64(width)+0.5), 0), width)
+	return barStyles[2].Render(strings.Repeat(glyphs.barFull, filled)) + helpStyle.Render(strings.Repeat(glyphs.barEmpty, width-filled))
+}
@@ -0,0 +1,8 @@
+package tui
+
+// Explanations for the thermal metrics, shown with the "e" key
+var thermalDocs = []metricDoc{
+	{Name: "Thermal", Text: "NSProcessInfo thermalState. Nominal means no thermal constraints; Fair that " +
+		"fans may be running; Serious that performance is being throttled; Critical that the machine " +
+		"must cool down and is heavily throttled."},
+}
@@ -0,0 +1,167 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/khoi/mtop/history"
+)
+
+// Metric names recorded in the history store
+const (
+	metricCPU          = "cpu.usage"
+	metricLoad1        = "cpu.load1"
+	metricCPUTemp      = "cpu.temp"
+	metricMemory       = "memory.usage"
+	metricMemoryUsed   = "memory.used"
+	metricSwap         = "memory.swap"
+	metricSwapRate     = "memory.swap_rate"
+	metricGPU          = "gpu.usage"
+	metricGPUTemp      = "gpu.temp"
+	metricPowerCPU     = "power.cpu"
+	metricPowerGPU     = "power.gpu"
+	metricPowerANE     = "power.ane"
+	metricPowerDRAM    = "power.dram"
+	metricPowerPackage = "power.package"
+	metricTCP          = "net.tcp"
+	metricUDP          = "net.udp"
+	metricWiFiRSSI     = "net.wifi_rssi"
+	metricDiskBusy     = "disk.busy"
+	metricCPUStall     = "pressure.cpu"
+	metricMemoryStall  = "pressure.memory"
+)
+
+// Shortest refresh interval; the history rings are sized for it
+const minRefreshRate = 100 * time.Millisecond
+
+// historyRetention is how far back the metric history reaches; set from
+// the --history flag
+var historyRetention = 5 * time.Minute
+
+// newHistory returns an empty metric history using the configured retention
+func newHistory() *history.Store {
+	return history.New(historyRetention, minRefreshRate)
+}
+
+// historyMetrics flattens a snapshot into the named metrics kept in history
+func historyMetrics(stats SystemStats) map[string]float64 {
+	metrics := map[string]float64{
+		metricCPU:          stats.CPU.Usage,
+		metricLoad1:        stats.CPU.LoadAvg[0],
+		metricCPUTemp:      stats.CPU.Temp,
+		metricMemory:       stats.Memory.Usage,
+		metricMemoryUsed:   float64(stats.Memory.Used),
+		metricSwap:         stats.Memory.Swap.Usage,
+		metricSwapRate:     stats.Memory.Swap.Rate,
+		metricGPU:          stats.GPU.Usage,
+		metricGPUTemp:      stats.GPU.Temp,
+		metricPowerCPU:     stats.Power.CPU,
+		metricPowerGPU:     stats.Power.GPU,
+		metricPowerANE:     stats.Power.ANE,
+		metricPowerDRAM:    stats.Power.DRAM,
+		metricPowerPackage: stats.Power.Package,
+		metricTCP:          float64(stats.Network.Sockets.TCPTotal),
+		metricUDP:          float64(stats.Network.Sockets.UDP),
+		metricDiskBusy:     stats.Disk.Busy,
+		metricCPUStall:     stalled(stats.CPU.Usage >= cpuSaturatedUsage),
+		metricMemoryStall:  stalled(stats.Memory.Pressure >= PressureWarn),
+	}
+	if wifi := stats.Network.WiFi; wifi != nil && wifi.PowerOn {
+		metrics[metricWiFiRSSI] = float64(wifi.RSSI)
+	}
+	// Plugin readings as plugin.<plugin>.<metric>. Those of a remote or
+	// imported sample were never checked, so unsafe names are left out.
+	for name, readings := range stats.Plugins {
+		if !pluginNamePattern.MatchString(name) {
+			continue
+		}
+		for metric, v := range readings {
+			if pluginNamePattern.MatchString(metric) {
+				metrics["plugin."+name+"."+metric] = v
+			}
+		}
+	}
+	return metrics
+}
+
+// recordHistory adds a snapshot taken at t to the metric history and to
+// the history database, if one is open. A database that fails to record is
+// closed and the footer says why.
+func (m *model) recordHistory(t time.Time, stats SystemStats) {
+	metrics := historyMetrics(stats)
+	m.history.Record(t, metrics)
+	if m.historyDB != nil {
+		if err := m.historyDB.record(t, metrics); err != nil {
+			m.historyDB.close()
+			m.historyDB = nil
+			m.setStatus(fmt.Sprintf("Stopped recording to the history database: %v", err))
+		}
+	}
+}
+
+// historyPath is where the history is kept between runs
+func historyPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "mtop", "history.gob")
+}
+
+// saveHistory writes the history to path, replacing the file only once the
+// new one is complete
+func saveHistory(path string, store *history.Store) error {
+	return writeCacheFile(path, store.Save)
+}
+
+// writeCacheFile writes a file in the cache directory through write,
+// replacing it only once the new one is complete
+func writeCacheFile(path string, write func(w io.Writer) error) error {
+	if path == "" {
+		return errors.New("no cache directory")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// restoreHistory loads the history saved at path into the model, keeping
+// only samples within the retention window of now. The gap while mtop was
+// not running is marked, as the charts draw samples side by side.
+func (m *model) restoreHistory(path string, now time.Time) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	before := len(m.history.Values(metricCPU))
+	if err := m.history.Restore(f, now.Add(-historyRetention)); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if len(m.history.Values(metricCPU)) > before {
+		m.history.Annotate(now, "restarted")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistoryKeptAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mtop", "history.gob")
+	now := time.Now()
+
+	before := model{history: newHistory()}
+	for i := 10; i > 0; i-- {
+		before.recordHistory(now.Add(-time.Duration(i)*time.Second), SystemStats{CPU: CPUStats{Usage: float64(i)}})
+	}
+	if err := saveHistory(path, before.history); err != nil {
+		t.Fatal(err)
+	}
+
+	after := model{history: newHistory()}
+	after.recordHistory(now, SystemStats{CPU: CPUStats{Usage: 99}})
+	if err := after.restoreHistory(path, now); err != nil {
+		t.Fatal(err)
+	}
+	values := after.history.Values(metricCPU)
+	if len(values) != 11 || values[0] != 10 || values[10] != 99 {
+		t.Errorf("cpu history = %v, want the saved samples then the new one", values)
+	}
+	if after.historyNote() == "" {
+		t.Error("the restart is not marked")
+	}
+
+	// Nothing saved yet is not an error; a damaged file is
+	missing := model{history: newHistory()}
+	if err := missing.restoreHistory(filepath.Join(t.TempDir(), "none"), now); err != nil {
+		t.Errorf("restore without a file = %v", err)
+	}
+	os.WriteFile(path, []byte("garbage"), 0o644)
+	if err := missing.restoreHistory(path, now); err == nil {
+		t.Error("restore of a damaged file succeeded")
+	}
+}
@@ -0,0 +1,244 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Number of apps shown individually before the rest are folded into "other"
+const treemapMaxItems = 24
+
+// treemapItem is one labelled, sized cell of a treemap
+type treemapItem struct {
+	Label string
+	Value float64
+	Note  string // Secondary line, e.g. a formatted size
+}
+
+// tmRect is a rectangle in treemap layout space
+type tmRect struct {
+	x, y, w, h float64
+}
+
+// Palette used to tell adjacent treemap cells apart, set by applyTheme
+var treemapPalette []lipgloss.Color
+
+func (m model) renderMemoryTreemap() string {
+	byApp := make(map[string]uint64)
+	var total uint64
+	for _, p := range m.stats.Processes {
+		byApp[appName(p)] += p.RSS
+		total += p.RSS
+	}
+	if total == 0 {
+		return "No process memory information available.\n"
+	}
+
+	items := make([]treemapItem, 0, len(byApp))
+	for name, rss := range byApp {
+		if rss > 0 {
+			items = append(items, treemapItem{Label: name, Value: float64(rss)})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Value == items[j].Value {
+			return items[i].Label < items[j].Label
+		}
+		return items[i].Value > items[j].Value
+	})
+	if len(items) > treemapMaxItems {
+		other := treemapItem{Label: "other"}
+		for _, it := range items[treemapMaxItems-1:] {
+			other.Value += it.Value
+		}
+		items = append(items[:treemapMaxItems-1], other)
+	}
+	for i := range items {
+		items[i].Note = formatBytes(uint64(items[i].Value))
+	}
+
+	height := m.height - 9
+	if height < 4 {
+		height = 4
+	}
+
+	s := fmt.Sprintf("Resident memory by app: %s across %d processes\n\n", formatBytes(total), len(m.stats.Processes))
+	s += renderTreemap(items, m.width, height)
+	return s
+}
+
+// renderTreemap draws items, sorted by descending positive value, as a
+// squarified treemap filling a width x height block of terminal cells
+func renderTreemap(items []treemapItem, width, height int) string {
+	if width <= 0 || height <= 0 || len(items) == 0 {
+		return ""
+	}
+
+	var sum float64
+	for _, it := range items {
+		sum += it.Value
+	}
+	if sum <= 0 {
+		return ""
+	}
+
+	// Terminal cells are about twice as tall as they are wide, so lay out in
+	// a space with doubled height to keep the cells visually square
+	area := float64(width) * float64(height*2)
+	values := make([]float64, len(items))
+	for i, it := range items {
+		values[i] = it.Value / sum * area
+	}
+	rects := squarify(values, tmRect{w: float64(width), h: float64(height * 2)})
+
+	owner := make([][]int, height)
+	text := make([][]rune, height)
+	for y := range owner {
+		owner[y] = make([]int, width)
+		text[y] = []rune(strings.Repeat(" ", width))
+		for x := range owner[y] {
+			owner[y][x] = -1
+		}
+	}
+
+	for i, r := range rects {
+		x0, x1 := int(r.x+0.5), int(r.x+r.w+0.5)
+		y0, y1 := int(r.y/2+0.5), int((r.y+r.h)/2+0.5)
+		if x1 > width {
+			x1 = width
+		}
+		if y1 > height {
+			y1 = height
+		}
+		if x1 <= x0 || y1 <= y0 {
+			continue
+		}
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				owner[y][x] = i
+			}
+		}
+		placeLabel(text[y0], x0, x1, items[i].Label)
+		if y1-y0 >= 2 {
+			placeLabel(text[y0+1], x0, x1, items[i].Note)
+		}
+	}
+
+	var b strings.Builder
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; {
+			start, id := x, owner[y][x]
+			for x < width && owner[y][x] == id {
+				x++
+			}
+			run := string(text[y][start:x])
+			if id < 0 {
+				b.WriteString(run)
+				continue
+			}
+			style := lipgloss.NewStyle().
+				Foreground(lipgloss.Color("15")).
+				Background(treemapPalette[id%len(treemapPalette)])
+			b.WriteString(style.Render(run))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// placeLabel writes a label into a row of cells, clipped to [x0, x1) and
+// leaving a one-cell margin on the left
+func placeLabel(row []rune, x0, x1 int, label string) {
+	x := x0 + 1
+	for _, r := range label {
+		if x >= x1 {
+			return
+		}
+		row[x] = r
+		x++
+	}
+}
+
+// squarify lays out values, sorted descending and summing to the area of r,
+// using the squarified treemap algorithm so cells stay close to square
+func squarify(values []float64, r tmRect) []tmRect {
+	out := make([]tmRect, 0, len(values))
+	for len(values) > 0 {
+		short := r.w
+		if r.h < short {
+			short = r.h
+		}
+
+		n := 1
+		for n < len(values) && worstRatio(values[:n+1], short) <= worstRatio(values[:n], short) {
+			n++
+		}
+
+		var rowSum float64
+		for _, v := range values[:n] {
+			rowSum += v
+		}
+
+		if r.w >= r.h {
+			// Fill a column along the left edge
+			colW := rowSum / r.h
+			y := r.y
+			for _, v := range values[:n] {
+				h := v / colW
+				out = append(out, tmRect{x: r.x, y: y, w: colW, h: h})
+				y += h
+			}
+			r.x += colW
+			r.w -= colW
+		} else {
+			// Fill a row along the top edge
+			rowH := rowSum / r.w
+			x := r.x
+			for _, v := range values[:n] {
+				w := v / rowH
+				out = append(out, tmRect{x: x, y: r.y, w: w, h: rowH})
+				x += w
+			}
+			r.y += rowH
+			r.h -= rowH
+		}
+
+		values = values[n:]
+	}
+	return out
+}
+
+// worstRatio returns the worst aspect ratio of a row of values laid out
+// along a side of the given length
+func worstRatio(row []float64, side float64) float64 {
+	var sum, max float64
+	min := row[0]
+	for _, v := range row {
+		sum += v
+		if v > max {
+			max = v
+		}
+		if v < min {
+			min = v
+		}
+	}
+	if sum == 0 || min == 0 {
+		return 0
+	}
+	s2, w2 := sum*sum, side*side
+	a, b := w2*max/s2, s2/(w2*min)
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Explanations for the memory treemap, shown with the "e" key
+var treemapDocs = []metricDoc{
+	{Name: "Resident memory by app", Text: "Resident size of every process, grouped by the app bundle its " +
+		"executable lives in so helpers count towards their app. Shared pages are counted in each process, " +
+		"so the total can exceed used memory."},
+}
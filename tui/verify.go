@@ -0,0 +1,309 @@
+package tui
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Exit code used when mtop disagrees with a system tool beyond the tolerance
+const verifyDiscrepancy = 3
+
+// verifyCheck pairs one of mtop's readings with the same quantity as
+// reported by a system tool
+type verifyCheck struct {
+	Metric    string
+	Source    string // Tool the reference value came from
+	Mtop      float64
+	Reference float64
+	Format    func(float64) string
+}
+
+// verifyPages maps vm_stat labels to mtop's raw page counters
+var verifyPages = []struct {
+	label string
+	get   func(MemoryCounters) uint64
+}{
+	{"Pages free", func(c MemoryCounters) uint64 { return c.Free }},
+	{"Pages active", func(c MemoryCounters) uint64 { return c.Active }},
+	{"Pages inactive", func(c MemoryCounters) uint64 { return c.Inactive }},
+	{"Pages speculative", func(c MemoryCounters) uint64 { return c.Speculative }},
+	{"Pages wired down", func(c MemoryCounters) uint64 { return c.Wired }},
+	{"Pages purgeable", func(c MemoryCounters) uint64 { return c.Purgeable }},
+	{"Pages occupied by compressor", func(c MemoryCounters) uint64 { return c.Compressed }},
+	{"File-backed pages", func(c MemoryCounters) uint64 { return c.External }},
+	{"Anonymous pages", func(c MemoryCounters) uint64 { return c.Internal }},
+}
+
+// runVerify implements "mtop verify": sample mtop's collectors and the
+// system tools at the same time and report where they disagree
+func runVerify(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	tolerance := fs.Float64("tolerance", 5, "Relative difference in percent above which a reading is flagged")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s verify [OPTIONS]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Samples mtop alongside vm_stat, top and iostat and reports discrepancies.\n")
+		fmt.Fprintf(os.Stderr, "Exits with status %d when any reading is outside the tolerance.\n\n", verifyDiscrepancy)
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	checks, errs := collectVerifyChecks()
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if len(checks) == 0 {
+		fmt.Fprintf(os.Stderr, "No readings to compare\n")
+		return 1
+	}
+
+	if printVerifyReport(os.Stdout, checks, *tolerance) > 0 {
+		return verifyDiscrepancy
+	}
+	return 0
+}
+
+// collectVerifyChecks runs mtop's collectors and the system tools
+// concurrently, so every pair of readings covers the same moment
+func collectVerifyChecks() ([]verifyCheck, []error) {
+	var (
+		wg                  sync.WaitGroup
+		stats               SystemStats
+		vmStat, top, iostat string
+		statsErr, vmErr     error
+		topErr, iostatErr   error
+	)
+
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		// Rates need two samples; the second lines up with the tools' interval
+		collectSystemStats(context.Background())
+		time.Sleep(time.Second)
+		stats, statsErr = collectSystemStats(context.Background())
+	}()
+	go func() {
+		defer wg.Done()
+		// Page counters are instantaneous, so read them mid-interval
+		time.Sleep(time.Second)
+		vmStat, vmErr = runVerifyTool("vm_stat")
+	}()
+	go func() {
+		defer wg.Done()
+		top, topErr = runVerifyTool("top", "-l", "2", "-n", "0", "-s", "1")
+	}()
+	go func() {
+		defer wg.Done()
+		iostat, iostatErr = runVerifyTool("iostat", "-n", "0", "-c", "2", "-w", "1")
+	}()
+	wg.Wait()
+
+	if statsErr != nil {
+		return nil, []error{fmt.Errorf("collecting mtop stats: %w", statsErr)}
+	}
+
+	var checks []verifyCheck
+	var errs []error
+	pages := func(v float64) string { return fmt.Sprintf("%.0f pages", v) }
+	bytes := func(v float64) string { return formatBytes(uint64(v)) }
+	percent := func(v float64) string { return fmt.Sprintf("%.1f%%", v) }
+	plain := func(v float64) string { return fmt.Sprintf("%.2f", v) }
+
+	if vmErr != nil {
+		errs = append(errs, vmErr)
+	} else {
+		pageSize, counts := parseVMStat(vmStat)
+		if pageSize != 0 {
+			checks = append(checks, verifyCheck{"Page size", "vm_stat",
+				float64(stats.Memory.Raw.PageSize), float64(pageSize), plain})
+		}
+		for _, p := range verifyPages {
+			if ref, ok := counts[p.label]; ok {
+				checks = append(checks, verifyCheck{p.label, "vm_stat",
+					float64(p.get(stats.Memory.Raw)), float64(ref), pages})
+			}
+		}
+	}
+
+	if topErr != nil {
+		errs = append(errs, topErr)
+	} else {
+		ref := parseTop(top)
+		raw := stats.Memory.Raw
+		if v, ok := ref["unused"]; ok {
+			checks = append(checks, verifyCheck{"Free memory", "top",
+				float64(raw.Free * raw.PageSize), v, bytes})
+		}
+		if v, ok := ref["wired"]; ok {
+			checks = append(checks, verifyCheck{"Wired memory", "top",
+				float64(raw.Wired * raw.PageSize), v, bytes})
+		}
+		if v, ok := ref["compressor"]; ok {
+			checks = append(checks, verifyCheck{"Compressed memory", "top",
+				float64(raw.Compressed * raw.PageSize), v, bytes})
+		}
+		if v, ok := ref["processes"]; ok {
+			checks = append(checks, verifyCheck{"Processes", "top",
+				float64(len(stats.Processes)), v, plain})
+		}
+		if v, ok := ref["cpu"]; ok {
+			checks = append(checks, verifyCheck{"CPU usage", "top", stats.CPU.Usage, v, percent})
+		}
+	}
+
+	if iostatErr != nil {
+		errs = append(errs, iostatErr)
+	} else {
+		ref := parseIostat(iostat)
+		if idle, ok := ref["id"]; ok {
+			checks = append(checks, verifyCheck{"CPU usage", "iostat", stats.CPU.Usage, 100 - idle, percent})
+		}
+		for i, col := range []string{"1m", "5m", "15m"} {
+			if v, ok := ref[col]; ok {
+				checks = append(checks, verifyCheck{"Load average " + col, "iostat",
+					stats.CPU.LoadAvg[i], v, plain})
+			}
+		}
+	}
+
+	return checks, errs
+}
+
+// runVerifyTool runs a system tool and returns its output
+func runVerifyTool(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("running %s: %w", name, err)
+	}
+	return string(out), nil
+}
+
+// vm_stat's header line, e.g. "(page size of 16384 bytes)"
+var vmStatPageSize = regexp.MustCompile(`page size of (\d+) bytes`)
+
+// parseVMStat returns the page size and page counts reported by vm_stat,
+// keyed by their label
+func parseVMStat(out string) (uint64, map[string]uint64) {
+	var pageSize uint64
+	counts := make(map[string]uint64)
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := vmStatPageSize.FindStringSubmatch(line); m != nil {
+			pageSize, _ = strconv.ParseUint(m[1], 10, 64)
+			continue
+		}
+		label, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		// Quoted labels like "Translation faults" are counters, not pages
+		label = strings.Trim(strings.TrimSpace(label), `"`)
+		if n, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(value), "."), 10, 64); err == nil {
+			counts[label] = n
+		}
+	}
+	return pageSize, counts
+}
+
+// Lines of interest in top's header
+var (
+	topPhysMem   = regexp.MustCompile(`PhysMem: (\S+) used \((\S+) wired(?:, (\S+) compressor)?\), (\S+) unused`)
+	topProcesses = regexp.MustCompile(`Processes: (\d+) total`)
+	topCPU       = regexp.MustCompile(`CPU usage: [\d.]+% user, [\d.]+% sys, ([\d.]+)% idle`)
+)
+
+// parseTop extracts memory sizes in bytes, the process count and CPU usage
+// from the last sample in top's logging mode output
+func parseTop(out string) map[string]float64 {
+	// Each sample starts with a Processes line; only the last one covers
+	// the sampling interval rather than the time since boot
+	if i := strings.LastIndex(out, "Processes:"); i >= 0 {
+		out = out[i:]
+	}
+
+	ref := make(map[string]float64)
+	if m := topProcesses.FindStringSubmatch(out); m != nil {
+		ref["processes"], _ = strconv.ParseFloat(m[1], 64)
+	}
+	if m := topCPU.FindStringSubmatch(out); m != nil {
+		if idle, err := strconv.ParseFloat(m[1], 64); err == nil {
+			ref["cpu"] = 100 - idle
+		}
+	}
+	if m := topPhysMem.FindStringSubmatch(out); m != nil {
+		for key, s := range map[string]string{"wired": m[2], "compressor": m[3], "unused": m[4]} {
+			if size, err := parseSize(s); err == nil && s != "" {
+				ref[key] = float64(size)
+			}
+		}
+	}
+	return ref
+}
+
+// parseIostat maps iostat's CPU and load average column names to the
+// values of its last report
+func parseIostat(out string) map[string]float64 {
+	var header, last []string
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 0:
+		case fields[0] == "us":
+			header = fields
+		case header != nil && len(fields) == len(header):
+			last = fields
+		}
+	}
+
+	ref := make(map[string]float64)
+	for i, name := range header {
+		if i < len(last) {
+			if v, err := strconv.ParseFloat(last[i], 64); err == nil {
+				ref[name] = v
+			}
+		}
+	}
+	return ref
+}
+
+// printVerifyReport writes each comparison and returns the number of
+// readings outside the tolerance
+func printVerifyReport(w io.Writer, checks []verifyCheck, tolerance float64) int {
+	discrepancies := 0
+	fmt.Fprintf(w, "%-30s %-7s %16s %16s %9s\n", "METRIC", "SOURCE", "MTOP", "REFERENCE", "DIFF")
+	for _, c := range checks {
+		diff := relativeDiff(c.Mtop, c.Reference)
+		status := "ok"
+		if diff > tolerance {
+			status = "MISMATCH"
+			discrepancies++
+		}
+		fmt.Fprintf(w, "%-30s %-7s %16s %16s %8.1f%%  %s\n",
+			c.Metric, c.Source, c.Format(c.Mtop), c.Format(c.Reference), diff, status)
+	}
+	fmt.Fprintf(w, "\n%d of %d readings differ by more than %.1f%%\n", discrepancies, len(checks), tolerance)
+	return discrepancies
+}
+
+// relativeDiff returns the difference between a and the reference b as a
+// percentage of b. Values near zero are compared absolutely.
+func relativeDiff(a, b float64) float64 {
+	if math.Abs(b) < 1 {
+		return math.Abs(a-b) * 100
+	}
+	return math.Abs(a-b) / math.Abs(b) * 100
+}
@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"testing"
+)
+
+const vmStatOutput = `Mach Virtual Memory Statistics: (page size of 16384 bytes)
+Pages free:                               12345.
+Pages active:                            300000.
+Pages wired down:                        150000.
+Pages occupied by compressor:             90000.
+"Translation faults":                 123456789.
+`
+
+const topOutput = `Processes: 600 total, 2 running, 598 sleeping, 3000 threads
+CPU usage: 10.00% user, 10.00% sys, 80.00% idle
+PhysMem: 15G used (2048M wired, 1024M compressor), 512M unused.
+Processes: 601 total, 3 running, 598 sleeping, 3001 threads
+CPU usage: 20.50% user, 4.50% sys, 75.00% idle
+PhysMem: 15G used (2048M wired, 1024M compressor), 256M unused.
+`
+
+const iostatOutput = `    cpu    load average
+ us sy id   1m   5m   15m
+  8  4 88  2.10 1.90 1.80
+ 15  5 80  2.20 1.95 1.82
+`
+
+func TestParseVMStat(t *testing.T) {
+	pageSize, counts := parseVMStat(vmStatOutput)
+	if pageSize != 16384 {
+		t.Errorf("page size = %d, want 16384", pageSize)
+	}
+	if counts["Pages free"] != 12345 || counts["Pages occupied by compressor"] != 90000 {
+		t.Errorf("unexpected counts %v", counts)
+	}
+}
+
+func TestParseTopUsesLastSample(t *testing.T) {
+	ref := parseTop(topOutput)
+	want := map[string]float64{
+		"processes":  601,
+		"cpu":        25,
+		"wired":      2048 << 20,
+		"compressor": 1024 << 20,
+		"unused":     256 << 20,
+	}
+	for key, v := range want {
+		if ref[key] != v {
+			t.Errorf("%s = %v, want %v", key, ref[key], v)
+		}
+	}
+}
+
+func TestParseIostatUsesLastReport(t *testing.T) {
+	ref := parseIostat(iostatOutput)
+	if ref["id"] != 80 || ref["1m"] != 2.20 {
+		t.Errorf("unexpected report %v", ref)
+	}
+}
@@ -0,0 +1,194 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Watchdog limits. A watched collector group that has not returned within
+// watchdogDeadline overran; after watchdogStrikes overruns in a row it is
+// restarted, and after watchdogMaxRestarts restarts it is given up on.
+const (
+	watchdogDeadline    = 500 * time.Millisecond
+	watchdogStrikes     = 3
+	watchdogMaxRestarts = 3
+	watchdogMaxEvents   = 50
+)
+
+// groupResult is what one run of a watched group produced
+type groupResult struct {
+	stats SystemStats
+	err   error
+	took  time.Duration
+}
+
+// groupHealth tracks a watched group's runs. A call that is stuck, e.g. in
+// an IOKit request that never returns, cannot be cancelled: a restart
+// abandons it and starts over with fresh collector state, and the stuck
+// goroutine is left to finish whenever it does.
+type groupHealth struct {
+	inflight  chan groupResult   // Result of the running call, nil when idle
+	cancel    context.CancelFunc // Cancels the running call's context
+	strikes   int                // Overruns in a row
+	restarts  int
+	givenUp   bool
+	lastTook  time.Duration
+	lastError error
+}
+
+// watchdogEvent is an entry in the Errors view
+type watchdogEvent struct {
+	at    time.Time
+	group string
+	text  string
+}
+
+// collectWatched runs a watched group without letting it block collection.
+// A new call is waited on up to the deadline; a call still running from an
+// earlier sample is only checked. Until a result arrives the group keeps
+// its previous readings.
+func (s *scheduler) collectWatched(ctx context.Context, g statsGroup, stats *SystemStats, now time.Time) {
+	h := s.health[g.name]
+	if h == nil {
+		h = &groupHealth{}
+		s.health[g.name] = h
+	}
+	if h.givenUp {
+		g.keep(stats, s.stats)
+		return
+	}
+
+	var result groupResult
+	var ok bool
+	if h.inflight == nil {
+		h.inflight, h.cancel = startGroup(ctx, g)
+		select {
+		case result, ok = <-h.inflight:
+		case <-time.After(watchdogDeadline):
+		}
+	} else {
+		select {
+		case result, ok = <-h.inflight:
+		default:
+		}
+	}
+
+	if ok {
+		h.cancel()
+		h.inflight, h.strikes = nil, 0
+		h.lastTook, h.lastError = result.took, result.err
+		internals.observe(g.name, result.took, result.err)
+		g.keep(stats, result.stats)
+		s.last[g.name] = now
+		return
+	}
+
+	g.keep(stats, s.stats)
+	h.strikes++
+	internals.timedOut(g.name)
+	if h.strikes < watchdogStrikes {
+		return
+	}
+
+	// The abandoned call stops at its next context check, if it has one
+	h.cancel()
+	h.inflight, h.strikes = nil, 0
+	if h.restarts == watchdogMaxRestarts {
+		h.givenUp = true
+		s.event(now, g.name, fmt.Sprintf("still stuck after %d restarts, stopped collecting", h.restarts))
+		return
+	}
+	h.restarts++
+	if g.reset != nil {
+		g.reset()
+	}
+	internals.restarted(g.name)
+	s.event(now, g.name, fmt.Sprintf("no result for %d samples in a row, restarted", watchdogStrikes))
+}
+
+// startGroup runs a group's collector in its own goroutine, under a
+// context the watchdog cancels when it abandons the call
+func startGroup(ctx context.Context, g statsGroup) (chan groupResult, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan groupResult, 1)
+	go func() {
+		var r groupResult
+		start := time.Now()
+		r.err = runGroup(ctx, g, &r.stats)
+		r.took = time.Since(start)
+		done <- r
+	}()
+	return done, cancel
+}
+
+// event records a watchdog event, keeping the most recent ones
+func (s *scheduler) event(at time.Time, group, text string) {
+	if len(s.events) == watchdogMaxEvents {
+		s.events = s.events[1:]
+	}
+	s.events = append(s.events, watchdogEvent{at, group, text})
+}
+
+// groupStatus describes a watched group's health in the Errors view
+func (h *groupHealth) status() string {
+	switch {
+	case h == nil:
+		return "not run"
+	case h.givenUp:
+		return "given up"
+	case h.strikes > 0:
+		return fmt.Sprintf("overrunning (%d)", h.strikes)
+	case h.lastError != nil:
+		if cause := errorCause(h.lastError); cause != "" {
+			return cause
+		}
+		return "failing"
+	}
+	return "ok"
+}
+
+// renderErrors lists the health of each watched collector and the recent
+// watchdog events, newest first
+func (m model) renderErrors() string {
+	var b strings.Builder
+	if m.agent != nil {
+		pid, _ := m.agent.ring.Owner()
+		fmt.Fprintf(&b, "Samples come from agent %d, which watches its own collectors.\n", pid)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "Collector health (deadline %v, restart after %d overruns):\n", watchdogDeadline, watchdogStrikes)
+	fmt.Fprintf(&b, "%-10s %-16s %8s %10s  %s\n", "GROUP", "STATUS", "RESTARTS", "LAST RUN", "LAST ERROR")
+	for _, g := range statsGroups {
+		if !g.watch || !collectorEnabled(g.name) {
+			continue
+		}
+		h := m.sched.health[g.name]
+		restarts, took, lastErr := 0, "-", ""
+		if h != nil {
+			restarts = h.restarts
+			if h.lastTook > 0 {
+				took = h.lastTook.Round(time.Microsecond).String()
+			}
+			if h.lastError != nil {
+				lastErr = h.lastError.Error()
+			}
+		}
+		fmt.Fprintf(&b, "%-10s %-16s %8d %10s  %s\n", g.name, h.status(), restarts, took, lastErr)
+	}
+
+	b.WriteString("\nRecent events:\n")
+	if len(m.sched.events) == 0 {
+		b.WriteString("None\n")
+	}
+	for i := len(m.sched.events) - 1; i >= 0; i-- {
+		e := m.sched.events[i]
+		fmt.Fprintf(&b, "%s %s: %s\n", e.at.Format("15:04:05"), e.group, e.text)
+	}
+	if m.lastError != "" {
+		fmt.Fprintf(&b, "\nLast collection error: %s\n", m.lastError)
+	}
+	return b.String()
+}
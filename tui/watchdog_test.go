@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stuckGroup is a watched group whose collector blocks until release closes
+func stuckGroup(release chan struct{}, resets *int) statsGroup {
+	return statsGroup{
+		name: "thermal",
+		collect: func(_ context.Context, stats *SystemStats) error {
+			<-release
+			stats.Thermal = ThermalCritical
+			return nil
+		},
+		keep:  func(dst *SystemStats, prev SystemStats) { dst.Thermal = prev.Thermal },
+		watch: true,
+		reset: func() { *resets++ },
+	}
+}
+
+func TestWatchdogRestartsStuckGroup(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	resets := 0
+	g := stuckGroup(release, &resets)
+	s := newScheduler()
+	s.stats.Thermal = ThermalFair
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for i := 0; i < watchdogStrikes; i++ {
+		var stats SystemStats
+		s.collectWatched(context.Background(), g, &stats, now)
+		if stats.Thermal != ThermalFair {
+			t.Fatalf("overrun %d: thermal = %v, want the previous reading", i, stats.Thermal)
+		}
+	}
+	h := s.health["thermal"]
+	if resets != 1 || h.restarts != 1 || h.inflight != nil {
+		t.Fatalf("after %d overruns: resets %d, restarts %d; want one restart", watchdogStrikes, resets, h.restarts)
+	}
+	if len(s.events) != 1 || !strings.Contains(s.events[0].text, "restarted") {
+		t.Errorf("events = %v, want a restart", s.events)
+	}
+
+	// Out of restarts, the group is given up on and no longer run
+	h.restarts = watchdogMaxRestarts
+	for i := 0; i < watchdogStrikes; i++ {
+		var stats SystemStats
+		s.collectWatched(context.Background(), g, &stats, now)
+	}
+	if !h.givenUp || h.status() != "given up" || resets != 1 {
+		t.Errorf("status %q after running out of restarts", h.status())
+	}
+}
+
+func TestWatchdogCancelsAbandonedCall(t *testing.T) {
+	cancelled := make(chan struct{})
+	g := statsGroup{
+		name: "thermal",
+		collect: func(ctx context.Context, stats *SystemStats) error {
+			<-ctx.Done()
+			close(cancelled)
+			return ctx.Err()
+		},
+		keep:  func(dst *SystemStats, prev SystemStats) {},
+		watch: true,
+		reset: func() {},
+	}
+	s := newScheduler()
+	for i := 0; i < watchdogStrikes; i++ {
+		var stats SystemStats
+		s.collectWatched(context.Background(), g, &stats, time.Now())
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("the restarted call's context was not cancelled")
+	}
+}
+
+func TestWatchdogKeepsHealthyResult(t *testing.T) {
+	release := make(chan struct{})
+	close(release)
+	resets := 0
+	g := stuckGroup(release, &resets)
+	s := newScheduler()
+
+	var stats SystemStats
+	s.collectWatched(context.Background(), g, &stats, time.Now())
+	if stats.Thermal != ThermalCritical {
+		t.Errorf("thermal = %v, want the fresh reading", stats.Thermal)
+	}
+	if h := s.health["thermal"]; h.status() != "ok" || h.strikes != 0 {
+		t.Errorf("status %q, strikes %d for a group that returned", h.status(), h.strikes)
+	}
+}
+
+func TestRenderErrorsListsEvents(t *testing.T) {
+	m := model{sched: newScheduler()}
+	m.sched.health["thermal"] = &groupHealth{restarts: 2, strikes: 1}
+	m.sched.event(time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local), "thermal", "no result for 3 samples in a row, restarted")
+
+	out := m.renderErrors()
+	for _, want := range []string{"overrunning (1)", "03:04:05 thermal: no result", "power"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("errors view is missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Hours in the week the usual readings are learned for
+const hoursPerWeek = 7 * 24
+
+// Samples an hour of the week needs before readings are compared with it,
+// and the smallest deviation a reading is measured against, so an hour
+// that has only seen one steady value does not alert on any change
+const (
+	normalMinSamples   = 30
+	normalMinDeviation = 1.0
+)
+
+// Deviations above the usual reading for the hour of the week at which a
+// metric alerts, by alert metric name; set from the [normal] config section
+var normalLimits = map[string]float64{}
+
+// applyNormal checks and records the above-normal alert conditions
+func applyNormal(limits map[string]float64) error {
+	for name, devs := range limits {
+		if _, ok := alertMetrics[name]; !ok {
+			return fmt.Errorf("unknown normal metric %q (want %s)", name, strings.Join(sortedKeys(alertMetrics), ", "))
+		}
+		if devs <= 0 {
+			return fmt.Errorf("normal deviations for %s must be positive", name)
+		}
+		normalLimits[name] = devs
+	}
+	return nil
+}
+
+// hourStats is a running mean and variance (Welford's method) of the
+// readings seen in one hour of the week
+type hourStats struct {
+	N    int
+	Mean float64
+	M2   float64 // Sum of squared differences from the mean
+}
+
+func (h *hourStats) add(v float64) {
+	h.N++
+	d := v - h.Mean
+	h.Mean += d / float64(h.N)
+	h.M2 += d * (v - h.Mean)
+}
+
+func (h hourStats) deviation() float64 {
+	if h.N < 2 {
+		return 0
+	}
+	return math.Sqrt(h.M2 / float64(h.N-1))
+}
+
+// weekBaseline holds the usual reading of each alert metric for every
+// hour of the week, learned from live samples and imported recordings
+type weekBaseline map[string]*[hoursPerWeek]hourStats
+
+// hourOfWeek numbers the local hours of the week from Sunday midnight
+func hourOfWeek(t time.Time) int {
+	t = t.Local()
+	return int(t.Weekday())*24 + t.Hour()
+}
+
+// learn adds a sample's readings to the hour of the week it was taken in
+func (b weekBaseline) learn(t time.Time, stats SystemStats) {
+	hour := hourOfWeek(t)
+	for name, metric := range alertMetrics {
+		week, ok := b[name]
+		if !ok {
+			week = new([hoursPerWeek]hourStats)
+			b[name] = week
+		}
+		week[hour].add(metric.value(stats))
+	}
+}
+
+// aboveNormal describes each metric in normalLimits that reads more
+// deviations above its usual value for this hour of the week than allowed,
+// by "<metric> normal"
+func (b weekBaseline) aboveNormal(t time.Time, stats SystemStats) map[string]string {
+	alerts := make(map[string]string)
+	hour := hourOfWeek(t)
+	for name, devs := range normalLimits {
+		week, ok := b[name]
+		if !ok || week[hour].N < normalMinSamples {
+			continue
+		}
+		usual := week[hour]
+		metric := alertMetrics[name]
+		v := metric.value(stats)
+		if v > usual.Mean+devs*max(usual.deviation(), normalMinDeviation) {
+			alerts[name+" normal"] = fmt.Sprintf("%s %.1f%s above normal %.1f%s for %s %02d:00",
+				name, v, metric.unit, usual.Mean, metric.unit, t.Local().Weekday().String()[:3], t.Local().Hour())
+		}
+	}
+	return alerts
+}
+
+// weekBaselinePath is where the learned baseline is kept between runs
+func weekBaselinePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "mtop", "baseline.gob")
+}
+
+// readWeekBaseline loads the baseline saved at path, or an empty one if
+// nothing was learned yet
+func readWeekBaseline(path string) (weekBaseline, error) {
+	b := make(weekBaseline)
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := gob.NewDecoder(f).Decode(&b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// saveWeekBaseline writes the baseline to path
+func saveWeekBaseline(path string, b weekBaseline) error {
+	return writeCacheFile(path, func(w io.Writer) error { return gob.NewEncoder(w).Encode(b) })
+}
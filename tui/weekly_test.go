@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWeekBaseline(t *testing.T) {
+	defer func(limits map[string]float64) { normalLimits = limits }(normalLimits)
+	normalLimits = map[string]float64{}
+	if err := applyNormal(map[string]float64{"cpu": 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Tuesday mornings are quiet, around 10-12%
+	tuesday := time.Date(2026, 10, 13, 9, 0, 0, 0, time.Local)
+	week := make(weekBaseline)
+	stats := fixtureStats()
+	for i := range normalMinSamples {
+		stats.CPU.Usage = 10 + float64(i%3)
+		week.learn(tuesday.AddDate(0, 0, -7*(i%4)).Add(time.Duration(i)*time.Minute), stats)
+	}
+
+	stats.CPU.Usage = 12
+	if alerts := week.aboveNormal(tuesday, stats); len(alerts) != 0 {
+		t.Errorf("usual reading alerts: %v", alerts)
+	}
+	stats.CPU.Usage = 40
+	alerts := week.aboveNormal(tuesday, stats)
+	if got := alerts["cpu normal"]; got != "cpu 40.0% above normal 11.0% for Tue 09:00" {
+		t.Errorf("alert = %q", got)
+	}
+	// Other hours have not been learned, so nothing is compared
+	if alerts := week.aboveNormal(tuesday.Add(time.Hour), stats); len(alerts) != 0 {
+		t.Errorf("unlearned hour alerts: %v", alerts)
+	}
+
+	path := filepath.Join(t.TempDir(), "baseline.gob")
+	if err := saveWeekBaseline(path, week); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := readWeekBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded["cpu"][hourOfWeek(tuesday)]; got != week["cpu"][hourOfWeek(tuesday)] {
+		t.Errorf("loaded %+v, saved %+v", got, week["cpu"][hourOfWeek(tuesday)])
+	}
+	if empty, err := readWeekBaseline(filepath.Join(t.TempDir(), "none.gob")); err != nil || len(empty) != 0 {
+		t.Errorf("missing file: %v, %v", empty, err)
+	}
+
+	if err := applyNormal(map[string]float64{"cpu": 0}); err == nil {
+		t.Error("zero deviations were accepted")
+	}
+	if err := applyNormal(map[string]float64{"cpu.usage": 2}); err == nil {
+		t.Error("unknown metric was accepted")
+	}
+}
+
+func TestImportLearnsBaseline(t *testing.T) {
+	input := `{"time": "2026-10-13T09:00:00Z", "stats": {"cpu": {"usage": 10}}}
+{"time": "2026-10-13T09:00:01Z", "metrics": {"cpu.usage": 99}}
+{"time": "2026-10-13T09:00:02Z", "stats": {"cpu": {"usage": 20}}}
+`
+	week := make(weekBaseline)
+	if _, err := importSamples(newHistory(), week, strings.NewReader(input), "x"); err != nil {
+		t.Fatal(err)
+	}
+	hour := week["cpu"][hourOfWeek(time.Date(2026, 10, 13, 9, 0, 0, 0, time.UTC))]
+	if hour.N != 2 || hour.Mean != 15 {
+		t.Errorf("learned %+v, want the two snapshots", hour)
+	}
+
+	week = make(weekBaseline)
+	importSamples(newHistory(), week, strings.NewReader(input+"bad\n"), "x")
+	if len(week) != 0 {
+		t.Errorf("learned from a file that failed to import: %v", week)
+	}
+}
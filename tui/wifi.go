@@ -0,0 +1,9 @@
+package tui
+
+// Explanations for the Wi-Fi metrics, shown with the "e" key
+var wifiDocs = []metricDoc{
+	{Name: "Signal (RSSI)", Text: "Received signal strength from CoreWLAN in dBm. Above -60 is good, below -75 is poor."},
+	{Name: "Noise / SNR", Text: "Noise floor in dBm and the signal-to-noise ratio (RSSI - noise). " +
+		"An SNR under 20 dB usually means unreliable throughput."},
+	{Name: "Tx Rate", Text: "Current transmit rate negotiated with the access point, in Mbps."},
+}
@@ -0,0 +1,184 @@
+package widgets
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Braille dot bits indexed by [row][column] within a 2x4 cell
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// Glyphs of bars and sparklines, lowest spark level first
+var (
+	unicodeBar    = [2]string{"█", "░"}
+	asciiBar      = [2]string{"#", "."}
+	unicodeSparks = []rune("▁▂▃▄▅▆▇█")
+	asciiSparks   = []rune("_.-=+*#@")
+)
+
+// level returns 0, 1 or 2 for good, warn and bad usage percentages
+func level(percent float64) int {
+	switch {
+	case percent >= BadLevel:
+		return 2
+	case percent >= WarnLevel:
+		return 1
+	}
+	return 0
+}
+
+// Bar draws a width-cell bar filled to percent. Each filled cell is
+// colored by its own position, so the bar shifts from good through warn
+// to bad as it grows. Cells of the same level are styled as one run.
+func Bar(percent float64, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	filled := min(max(int(percent/100*float64(width)+0.5), 0), width)
+	glyphs := unicodeBar
+	if ASCII {
+		glyphs = asciiBar
+	}
+
+	var b strings.Builder
+	for start := 0; start < filled; {
+		lvl := level((float64(start) + 0.5) / float64(width) * 100)
+		end := start + 1
+		for end < filled && level((float64(end)+0.5)/float64(width)*100) == lvl {
+			end++
+		}
+		b.WriteString(BarStyles[lvl].Render(strings.Repeat(glyphs[0], end-start)))
+		start = end
+	}
+	b.WriteString(BarEmptyStyle.Render(strings.Repeat(glyphs[1], width-filled)))
+	return b.String()
+}
+
+// Spark renders the most recent width values as a row of sparkline
+// glyphs scaled against max. A max of zero scales against the largest
+// value shown.
+func Spark(values []float64, width int, max float64) string {
+	if width <= 0 {
+		return ""
+	}
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	if max <= 0 {
+		for _, v := range values {
+			if v > max {
+				max = v
+			}
+		}
+	}
+
+	sparks := unicodeSparks
+	if ASCII {
+		sparks = asciiSparks
+	}
+	var b strings.Builder
+	for _, v := range values {
+		lvl := 0
+		if max > 0 {
+			lvl = int(v / max * float64(len(sparks)-1))
+		}
+		if lvl < 0 {
+			lvl = 0
+		}
+		if lvl >= len(sparks) {
+			lvl = len(sparks) - 1
+		}
+		b.WriteRune(sparks[lvl])
+	}
+	return b.String()
+}
+
+// Chart draws the most recent values as a line chart of braille dots
+// filling width x height cells, scaled against scale. Each cell holds 2x4
+// dots, so the chart shows up to 2*width values.
+func Chart(values []float64, width, height int, scale float64) []string {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	dotsW, dotsH := width*2, height*4
+	if len(values) > dotsW {
+		values = values[len(values)-dotsW:]
+	}
+
+	cells := make([][]rune, height)
+	for y := range cells {
+		cells[y] = make([]rune, width)
+	}
+	set := func(x, y int) {
+		cells[y/4][x/2] |= brailleDots[y%4][x%2]
+	}
+
+	// Newest values are on the right edge
+	offset := dotsW - len(values)
+	prev := -1
+	for i, v := range values {
+		y := dotsH - 1
+		if scale > 0 {
+			y = dotsH - 1 - int(v/scale*float64(dotsH-1)+0.5)
+		}
+		y = min(max(y, 0), dotsH-1)
+
+		// Join consecutive points with a vertical run so steep changes
+		// stay connected
+		from, to := y, y
+		if prev >= 0 {
+			from, to = min(prev, y), max(prev, y)
+		}
+		for dy := from; dy <= to; dy++ {
+			set(offset+i, dy)
+		}
+		prev = y
+	}
+
+	lines := make([]string, height)
+	for y, row := range cells {
+		var b strings.Builder
+		for _, c := range row {
+			switch {
+			case c == 0:
+				b.WriteRune(' ')
+			case ASCII:
+				b.WriteByte(asciiCell(c))
+			default:
+				b.WriteRune(0x2800 + c)
+			}
+		}
+		lines[y] = b.String()
+	}
+	return lines
+}
+
+// asciiCell stands in for a braille cell with ASCII: a mark in the top or
+// bottom half of the cell, or a colon when dots are set in both
+func asciiCell(c rune) byte {
+	top := c&(brailleDots[0][0]|brailleDots[0][1]|brailleDots[1][0]|brailleDots[1][1]) != 0
+	bottom := c&(brailleDots[2][0]|brailleDots[2][1]|brailleDots[3][0]|brailleDots[3][1]) != 0
+	switch {
+	case top && bottom:
+		return ':'
+	case top:
+		return '\''
+	}
+	return '.'
+}
+
+// Pad truncates or pads a value to exactly width cells
+func Pad(s string, width int, right bool) string {
+	if r := []rune(s); len(r) > width {
+		return string(r[:width])
+	}
+	if right {
+		return fmt.Sprintf("%*s", width, s)
+	}
+	return fmt.Sprintf("%-*s", width, s)
+}
@@ -0,0 +1,232 @@
+// Package widgets holds mtop's gauges, sparklines, charts and tables as
+// standalone components for other bubbletea programs, in the style of the
+// bubbles package: set the data with the fields and methods, forward
+// messages to Update and place View in the parent's view. Bar, Spark,
+// Chart and Pad are the drawing functions under them.
+//
+// All widgets draw with the styles, levels and glyphs set in the package
+// variables, which mtop sets from its theme and config.
+package widgets
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Styles the widgets draw with
+var (
+	BarStyles     [3]lipgloss.Style // Good, warn and bad bar cells
+	BarEmptyStyle lipgloss.Style
+	HeaderStyle   = lipgloss.NewStyle().Bold(true).Reverse(true) // Table header
+	SelectedStyle = lipgloss.NewStyle().Reverse(true)            // Table's selected row
+)
+
+// Usage percentages at which bar cells turn from good to warn and from
+// warn to bad
+var (
+	WarnLevel = 60.0
+	BadLevel  = 85.0
+)
+
+// ASCII draws with plain ASCII instead of block and braille glyphs
+var ASCII bool
+
+// Keys maps the keys Table follows to its actions: up, down, top, bottom,
+// page_up and page_down. Other actions are ignored.
+var Keys = map[string]string{
+	"up": "up", "k": "up",
+	"down": "down", "j": "down",
+	"home": "top", "end": "bottom",
+	"pgup": "page_up", "pgdown": "page_down",
+}
+
+// Gauge is a labelled usage bar, as in the overview
+type Gauge struct {
+	Label   string
+	Percent float64
+	Width   int // Cells of the bar itself
+}
+
+// NewGauge returns a gauge with a bar width cells wide
+func NewGauge(label string, width int) Gauge {
+	return Gauge{Label: label, Width: width}
+}
+
+// Update does nothing: a gauge shows whatever Percent is set to
+func (g Gauge) Update(msg tea.Msg) (Gauge, tea.Cmd) {
+	return g, nil
+}
+
+func (g Gauge) View() string {
+	label := g.Label
+	if label != "" {
+		label += " "
+	}
+	return fmt.Sprintf("%s%s %5.1f%%", label, Bar(g.Percent, g.Width), g.Percent)
+}
+
+// Sparkline is a one-row chart of the newest Width values
+type Sparkline struct {
+	Width  int
+	Max    float64 // Value drawn as a full cell; 0 scales to the largest shown
+	values []float64
+}
+
+// NewSparkline returns an empty sparkline width cells wide
+func NewSparkline(width int, max float64) Sparkline {
+	return Sparkline{Width: width, Max: max}
+}
+
+// Push appends a value, forgetting those that no longer fit
+func (s *Sparkline) Push(v float64) {
+	s.values = keepNewest(append(s.values, v), s.Width)
+}
+
+func (s Sparkline) Update(msg tea.Msg) (Sparkline, tea.Cmd) {
+	return s, nil
+}
+
+func (s Sparkline) View() string {
+	return Spark(s.values, s.Width, s.Max)
+}
+
+// Graph is a braille line chart of the newest values, two per cell
+type Graph struct {
+	Width, Height int
+	Scale         float64 // Value at the top row
+	values        []float64
+}
+
+// NewGraph returns an empty graph of width x height cells
+func NewGraph(width, height int, scale float64) Graph {
+	return Graph{Width: width, Height: height, Scale: scale}
+}
+
+// Push appends a value, forgetting those that no longer fit
+func (g *Graph) Push(v float64) {
+	g.values = keepNewest(append(g.values, v), 2*g.Width)
+}
+
+func (g Graph) Update(msg tea.Msg) (Graph, tea.Cmd) {
+	return g, nil
+}
+
+func (g Graph) View() string {
+	return strings.Join(Chart(g.values, g.Width, g.Height, g.Scale), "\n")
+}
+
+// keepNewest drops all but the last n values
+func keepNewest(values []float64, n int) []float64 {
+	if n < 1 {
+		n = 1
+	}
+	if len(values) > n {
+		values = append(values[:0], values[len(values)-n:]...)
+	}
+	return values
+}
+
+// TableColumn is a column of a Table
+type TableColumn struct {
+	Title string
+	Width int
+	Right bool // Right-align, for numbers
+}
+
+// Table is a scrolling table with a selected row, moved with the keys
+// in Keys
+type Table struct {
+	Columns []TableColumn
+	Height  int // Rows shown below the header
+	rows    [][]string
+	cursor  int
+	offset  int
+}
+
+// NewTable returns an empty table showing height rows at a time
+func NewTable(columns []TableColumn, height int) Table {
+	return Table{Columns: columns, Height: height}
+}
+
+// SetRows replaces the rows, keeping the cursor in range
+func (t *Table) SetRows(rows [][]string) {
+	t.rows = rows
+	t.moveTo(t.cursor)
+}
+
+// Cursor returns the index of the selected row
+func (t Table) Cursor() int {
+	return t.cursor
+}
+
+// SelectedRow returns the selected row, or nil when there are none
+func (t Table) SelectedRow() []string {
+	if t.cursor < len(t.rows) {
+		return t.rows[t.cursor]
+	}
+	return nil
+}
+
+func (t *Table) moveTo(row int) {
+	t.cursor = max(min(row, len(t.rows)-1), 0)
+	height := max(t.Height, 1)
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+height {
+		t.offset = t.cursor - height + 1
+	}
+}
+
+// Update moves the selection on key presses. Only single keys are
+// followed, not sequences such as vim's "g g".
+func (t Table) Update(msg tea.Msg) (Table, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return t, nil
+	}
+	switch Keys[key.String()] {
+	case "up":
+		t.moveTo(t.cursor - 1)
+	case "down":
+		t.moveTo(t.cursor + 1)
+	case "top":
+		t.moveTo(0)
+	case "bottom":
+		t.moveTo(len(t.rows) - 1)
+	case "page_up":
+		t.moveTo(t.cursor - t.Height)
+	case "page_down":
+		t.moveTo(t.cursor + t.Height)
+	}
+	return t, nil
+}
+
+func (t Table) View() string {
+	var b strings.Builder
+	cells := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		cells[i] = Pad(c.Title, c.Width, c.Right)
+	}
+	b.WriteString(HeaderStyle.Render(strings.Join(cells, " ")))
+
+	end := min(t.offset+t.Height, len(t.rows))
+	for row := t.offset; row < end; row++ {
+		for i, c := range t.Columns {
+			cells[i] = ""
+			if i < len(t.rows[row]) {
+				cells[i] = t.rows[row][i]
+			}
+			cells[i] = Pad(cells[i], c.Width, c.Right)
+		}
+		line := strings.Join(cells, " ")
+		if row == t.cursor {
+			line = SelectedStyle.Render(line)
+		}
+		b.WriteString("\n" + line)
+	}
+	return b.String()
+}
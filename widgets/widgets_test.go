@@ -0,0 +1,65 @@
+package widgets
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestWidgetsKeepNewestValues(t *testing.T) {
+	s := NewSparkline(4, 10)
+	g := NewGraph(2, 1, 10)
+	for i := range 10 {
+		s.Push(float64(i))
+		g.Push(float64(i))
+	}
+	if len(s.values) != 4 || s.values[0] != 6 || len([]rune(s.View())) != 4 {
+		t.Errorf("sparkline keeps %v, view %q", s.values, s.View())
+	}
+	if len(g.values) != 4 || strings.Count(g.View(), "\n") != 0 {
+		t.Errorf("graph keeps %v, view %q", g.values, g.View())
+	}
+	if got := ansi.Strip(NewGauge("CPU", 10).View()); got != "CPU "+strings.Repeat(unicodeBar[1], 10)+"   0.0%" {
+		t.Errorf("empty gauge = %q", got)
+	}
+}
+
+func TestTableNavigation(t *testing.T) {
+	table := NewTable([]TableColumn{{Title: "PID", Width: 5, Right: true}, {Title: "Name", Width: 8}}, 2)
+	table.SetRows([][]string{{"1", "launchd"}, {"2", "kernel"}, {"3", "Finder"}})
+	press := func(key string) {
+		table, _ = table.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+
+	press("j")
+	press("j")
+	press("j")
+	if table.Cursor() != 2 || table.SelectedRow()[1] != "Finder" {
+		t.Fatalf("cursor %d after moving past the end", table.Cursor())
+	}
+	lines := strings.Split(ansi.Strip(table.View()), "\n")
+	if len(lines) != 3 || lines[1] != "    2 kernel  " || lines[2] != "    3 Finder  " {
+		t.Errorf("table scrolled to the last row:\n%s", strings.Join(lines, "\n"))
+	}
+
+	table.SetRows([][]string{{"1", "launchd"}})
+	if table.Cursor() != 0 {
+		t.Errorf("cursor %d after the rows shrank", table.Cursor())
+	}
+}
+
+func TestASCIIDrawing(t *testing.T) {
+	ASCII = true
+	defer func() { ASCII = false }()
+	if got := ansi.Strip(Bar(50, 4)); got != "##.." {
+		t.Errorf("ASCII bar = %q", got)
+	}
+	if got := Spark([]float64{0, 10}, 2, 10); got != "_@" {
+		t.Errorf("ASCII sparkline = %q", got)
+	}
+	if got := Chart([]float64{0, 100}, 1, 1, 100); got[0] != ":" {
+		t.Errorf("ASCII chart = %q", got)
+	}
+}